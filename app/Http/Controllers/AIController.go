@@ -3,26 +3,67 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
 	"github.com/clarkzhu2020/aidecms/pkg/ai"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
 )
 
+// defaultConversationMaxLen 默认保留的对话消息数
+const defaultConversationMaxLen = 50
+
+// defaultConversationTTL 默认对话过期时间
+const defaultConversationTTL = 24 * time.Hour
+
 // AIController AI控制器
 type AIController struct {
-	manager *ai.Manager
+	manager         *ai.Manager
+	conversations   ai.ConversationStore
+	conversationTTL time.Duration
+	summarize       bool
+	usage           *ai.UsageLedger
+	quotas          *ai.QuotaPolicy
+	search          *services.PostSearchService
 }
 
-// NewAIController 创建AI控制器
+// NewAIController 创建AI控制器（默认使用内存对话存储）
 func NewAIController(manager *ai.Manager) *AIController {
 	return &AIController{
-		manager: manager,
+		manager:         manager,
+		conversations:   ai.NewMemoryConversationStore(10000),
+		conversationTTL: defaultConversationTTL,
 	}
 }
 
+// SetConversationSummarization 设置超出上下文窗口时是否将较早的对话压缩为摘要
+func (c *AIController) SetConversationSummarization(enabled bool) {
+	c.summarize = enabled
+}
+
+// SetUsageLedger 设置用量账本与额度策略，未设置时不记录用量也不限制额度
+func (c *AIController) SetUsageLedger(ledger *ai.UsageLedger, quotas *ai.QuotaPolicy) {
+	c.usage = ledger
+	c.quotas = quotas
+}
+
+// SetPostSearch 设置站内问答使用的文章检索服务
+func (c *AIController) SetPostSearch(search *services.PostSearchService) {
+	c.search = search
+}
+
+// SetConversationStore 设置对话存储及过期时间
+func (c *AIController) SetConversationStore(store ai.ConversationStore, ttl time.Duration) {
+	c.conversations = store
+	c.conversationTTL = ttl
+}
+
 // ChatRequest 聊天请求
 type ChatRequest struct {
 	Message     string                 `json:"message" binding:"required"`
@@ -36,9 +77,11 @@ type ChatRequest struct {
 
 // ChatResponse 聊天响应
 type ChatResponse struct {
-	Message string    `json:"message"`
-	Usage   *ai.Usage `json:"usage,omitempty"`
-	Model   string    `json:"model"`
+	Message  string              `json:"message"`
+	Usage    *ai.Usage           `json:"usage,omitempty"`
+	Model    string              `json:"model"`
+	Provider string              `json:"provider,omitempty"` // 实际应答的客户端
+	Routing  *ai.RoutingMetadata `json:"routing,omitempty"`
 }
 
 // Chat 聊天接口
@@ -57,8 +100,7 @@ func (c *AIController) Chat(ctx context.Context, hCtx *app.RequestContext) {
 		clientName = c.manager.GetDefault()
 	}
 
-	client, err := c.manager.GetClient(clientName)
-	if err != nil {
+	if _, err := c.manager.GetClient(clientName); err != nil {
 		hCtx.JSON(400, map[string]interface{}{
 			"error": "Model not available: " + err.Error(),
 		})
@@ -83,14 +125,20 @@ func (c *AIController) Chat(ctx context.Context, hCtx *app.RequestContext) {
 		Stream:      req.Stream,
 	}
 
+	// 检查额度
+	userID := currentUserID(hCtx)
+	if !c.checkQuota(ctx, hCtx, userID) {
+		return
+	}
+
 	// 处理流式请求
 	if req.Stream {
-		c.handleStreamChat(ctx, hCtx, client, chatReq, clientName)
+		c.handleStreamChat(ctx, hCtx, chatReq, clientName, userID, "chat", nil)
 		return
 	}
 
-	// 执行聊天
-	response, err := client.Chat(ctx, chatReq)
+	// 执行聊天（按路由策略重试/故障转移）
+	response, err := c.manager.Chat(ctx, clientName, chatReq)
 	if err != nil {
 		hlog.CtxErrorf(ctx, "chat failed: %v", err)
 		hCtx.JSON(500, map[string]interface{}{
@@ -98,17 +146,26 @@ func (c *AIController) Chat(ctx context.Context, hCtx *app.RequestContext) {
 		})
 		return
 	}
+	c.recordUsage(ctx, userID, "chat", routedClient(clientName, response.Metadata), response.Usage)
 
 	// 返回响应
-	hCtx.JSON(200, ChatResponse{
+	resp := ChatResponse{
 		Message: response.Message.Content,
 		Usage:   response.Usage,
 		Model:   clientName,
-	})
+		Routing: response.Metadata,
+	}
+	if response.Metadata != nil {
+		resp.Provider = response.Metadata.Client
+	}
+	hCtx.JSON(200, resp)
 }
 
-// handleStreamChat 处理流式聊天
-func (c *AIController) handleStreamChat(ctx context.Context, hCtx *app.RequestContext, client *ai.Client, req *ai.ChatRequest, modelName string) {
+// streamFinalizer 在流式输出结束时根据完整回答生成附加字段，合并到最后一个事件中
+type streamFinalizer func(message string) map[string]interface{}
+
+// handleStreamChat 处理流式聊天，返回完整回答（出错时为空）
+func (c *AIController) handleStreamChat(ctx context.Context, hCtx *app.RequestContext, req *ai.ChatRequest, modelName string, userID uint, endpoint string, finalize streamFinalizer) string {
 	// 设置SSE响应头
 	hCtx.Header("Content-Type", "text/event-stream")
 	hCtx.Header("Cache-Control", "no-cache")
@@ -116,39 +173,75 @@ func (c *AIController) handleStreamChat(ctx context.Context, hCtx *app.RequestCo
 	hCtx.Header("Access-Control-Allow-Origin", "*")
 
 	// 获取流式响应
-	responseCh, errorCh := client.StreamChat(ctx, req)
+	responseCh, errorCh := c.manager.StreamChat(ctx, modelName, req)
+	message := ""
 
 	for {
 		select {
 		case response, ok := <-responseCh:
 			if !ok {
+				// 错误通道已关闭并置为nil时不能再读取，否则会一直阻塞
+				var err error
+				if errorCh != nil {
+					err = <-errorCh
+				}
+				if err != nil {
+					hlog.CtxErrorf(ctx, "stream chat error: %v", err)
+					errorData, _ := json.Marshal(map[string]string{"error": err.Error()})
+					hCtx.Write([]byte(fmt.Sprintf("data: %s\n\n", errorData)))
+					hCtx.Flush()
+					return ""
+				}
 				// 发送结束事件
 				hCtx.Write([]byte("data: [DONE]\n\n"))
 				hCtx.Flush()
-				return
+				return message
 			}
+			message = response.Message
 
 			// 构建流式响应
 			streamResp := map[string]interface{}{
-				"message": response.Message, // response.Message 已经是字符串
+				"delta":   response.Delta,
+				"message": response.Message, // 已累计的完整内容
+				"done":    response.Done,
 				"model":   modelName,
 			}
+			if response.Usage != nil {
+				streamResp["usage"] = response.Usage
+			}
+			if response.Metadata != nil {
+				streamResp["provider"] = response.Metadata.Client
+				streamResp["routing"] = response.Metadata
+			}
+			if response.Done {
+				c.recordUsage(ctx, userID, endpoint, routedClient(modelName, response.Metadata), response.Usage)
+				if finalize != nil {
+					for key, value := range finalize(response.Message) {
+						streamResp[key] = value
+					}
+				}
+			}
 
 			data, _ := json.Marshal(streamResp)
 			hCtx.Write([]byte(fmt.Sprintf("data: %s\n\n", data)))
 			hCtx.Flush()
 
-		case err := <-errorCh:
+		case err, ok := <-errorCh:
+			// 关闭的通道每次都能立即读到零值，置为nil后 select 不再选中该分支
+			if !ok {
+				errorCh = nil
+				continue
+			}
 			if err != nil {
 				hlog.CtxErrorf(ctx, "stream chat error: %v", err)
 				errorData, _ := json.Marshal(map[string]string{"error": err.Error()})
 				hCtx.Write([]byte(fmt.Sprintf("data: %s\n\n", errorData)))
 				hCtx.Flush()
-				return
+				return ""
 			}
 
 		case <-ctx.Done():
-			return
+			return ""
 		}
 	}
 }
@@ -164,8 +257,9 @@ type CompletionRequest struct {
 
 // CompletionResponse 补全响应
 type CompletionResponse struct {
-	Text  string `json:"text"`
-	Model string `json:"model"`
+	Text     string `json:"text"`
+	Model    string `json:"model"`
+	Provider string `json:"provider,omitempty"` // 实际应答的客户端
 }
 
 // Completion 文本补全接口
@@ -184,25 +278,27 @@ func (c *AIController) Completion(ctx context.Context, hCtx *app.RequestContext)
 		clientName = c.manager.GetDefault()
 	}
 
-	client, err := c.manager.GetClient(clientName)
-	if err != nil {
+	if _, err := c.manager.GetClient(clientName); err != nil {
 		hCtx.JSON(400, map[string]interface{}{
 			"error": "Model not available: " + err.Error(),
 		})
 		return
 	}
 
-	// 构建选项
-	options := []ai.Option{}
-	if req.Temperature != nil {
-		options = append(options, ai.WithTemperature(*req.Temperature))
+	// 检查额度
+	userID := currentUserID(hCtx)
+	if !c.checkQuota(ctx, hCtx, userID) {
+		return
 	}
-	if req.MaxTokens != nil {
-		options = append(options, ai.WithMaxTokens(*req.MaxTokens))
+
+	chatReq := &ai.ChatRequest{
+		Messages:    []*ai.Message{{Role: "user", Content: req.Prompt}},
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
 	}
 
-	// 执行补全
-	result, err := client.CreateCompletion(ctx, req.Prompt, options...)
+	// 执行补全（按路由策略重试/故障转移）
+	response, err := c.manager.Chat(ctx, clientName, chatReq)
 	if err != nil {
 		hlog.CtxErrorf(ctx, "completion failed: %v", err)
 		hCtx.JSON(500, map[string]interface{}{
@@ -210,12 +306,17 @@ func (c *AIController) Completion(ctx context.Context, hCtx *app.RequestContext)
 		})
 		return
 	}
+	c.recordUsage(ctx, userID, "completion", routedClient(clientName, response.Metadata), response.Usage)
 
 	// 返回响应
-	hCtx.JSON(200, CompletionResponse{
-		Text:  result,
+	resp := CompletionResponse{
+		Text:  response.Message.Content,
 		Model: clientName,
-	})
+	}
+	if response.Metadata != nil {
+		resp.Provider = response.Metadata.Client
+	}
+	hCtx.JSON(200, resp)
 }
 
 // EmbeddingRequest 嵌入请求
@@ -246,16 +347,21 @@ func (c *AIController) Embedding(ctx context.Context, hCtx *app.RequestContext)
 		clientName = c.manager.GetDefault()
 	}
 
-	client, err := c.manager.GetClient(clientName)
-	if err != nil {
+	if _, err := c.manager.GetClient(clientName); err != nil {
 		hCtx.JSON(400, map[string]interface{}{
 			"error": "Model not available: " + err.Error(),
 		})
 		return
 	}
 
+	// 检查额度
+	userID := currentUserID(hCtx)
+	if !c.checkQuota(ctx, hCtx, userID) {
+		return
+	}
+
 	// 生成嵌入向量
-	embeddings, err := client.CreateEmbedding(ctx, req.Input)
+	embeddings, err := c.manager.CreateEmbedding(ctx, clientName, req.Input)
 	if err != nil {
 		hlog.CtxErrorf(ctx, "embedding failed: %v", err)
 		hCtx.JSON(500, map[string]interface{}{
@@ -264,6 +370,13 @@ func (c *AIController) Embedding(ctx context.Context, hCtx *app.RequestContext)
 		return
 	}
 
+	// 嵌入接口不返回用量，按输入估算
+	tokens := 0
+	for _, input := range req.Input {
+		tokens += ai.EstimateTokens(input)
+	}
+	c.recordUsage(ctx, userID, "embedding", clientName, &ai.Usage{PromptTokens: tokens, TotalTokens: tokens})
+
 	// 返回响应
 	hCtx.JSON(200, EmbeddingResponse{
 		Embeddings: embeddings,
@@ -300,10 +413,16 @@ func (c *AIController) Models(ctx context.Context, hCtx *app.RequestContext) {
 func (c *AIController) Health(ctx context.Context, hCtx *app.RequestContext) {
 	models := c.manager.ListClients()
 
+	circuits := make(map[string]string, len(models))
+	for _, name := range models {
+		circuits[name] = c.manager.CircuitState(name)
+	}
+
 	status := map[string]interface{}{
 		"status":           "healthy",
 		"models":           len(models),
 		"available_models": models,
+		"circuits":         circuits,
 	}
 
 	// 检查默认模型是否可用
@@ -317,6 +436,212 @@ func (c *AIController) Health(ctx context.Context, hCtx *app.RequestContext) {
 	hCtx.JSON(200, status)
 }
 
+// defaultAskTopK 站内问答默认检索的片段数
+const defaultAskTopK = 5
+
+// AskRequest 站内问答请求
+type AskRequest struct {
+	Question  string `json:"question" binding:"required"`
+	SessionID string `json:"session_id,omitempty"` // 为空时不保留上下文
+	Model     string `json:"model,omitempty"`
+	TopK      int    `json:"top_k,omitempty"`
+	Stream    bool   `json:"stream,omitempty"`
+}
+
+// AskSource 回答的参考文章
+type AskSource struct {
+	Index  int     `json:"index"` // 回答中的引用编号 [n]
+	PostID uint    `json:"post_id"`
+	Slug   string  `json:"slug"`
+	Title  string  `json:"title"`
+	Score  float64 `json:"score"`
+	Cited  bool    `json:"cited"` // 回答中是否引用
+}
+
+// AskResponse 站内问答响应
+type AskResponse struct {
+	Answer    string      `json:"answer"`
+	SessionID string      `json:"session_id,omitempty"`
+	Sources   []AskSource `json:"sources"`
+	Model     string      `json:"model"`
+	Usage     *ai.Usage   `json:"usage,omitempty"`
+}
+
+// Ask 站内问答接口：检索已发布文章作为带编号的参考资料，回答中以 [n] 引用来源
+func (c *AIController) Ask(ctx context.Context, hCtx *app.RequestContext) {
+	var req AskRequest
+	if err := hCtx.BindJSON(&req); err != nil || strings.TrimSpace(req.Question) == "" {
+		hCtx.JSON(400, map[string]interface{}{
+			"error": "Invalid request format: question is required",
+		})
+		return
+	}
+	if c.search == nil {
+		hCtx.JSON(503, map[string]interface{}{
+			"error": "Site search is not available",
+		})
+		return
+	}
+
+	// 选择客户端
+	clientName := req.Model
+	if clientName == "" {
+		clientName = c.manager.GetDefault()
+	}
+	config, err := c.manager.GetConfig(clientName)
+	if err != nil {
+		hCtx.JSON(400, map[string]interface{}{
+			"error": "Model not available: " + err.Error(),
+		})
+		return
+	}
+
+	userID := currentUserID(hCtx)
+	if !c.checkQuota(ctx, hCtx, userID) {
+		return
+	}
+
+	// 检索参考资料，最多占用一半的提示词预算
+	topK := req.TopK
+	if topK <= 0 || topK > 20 {
+		topK = defaultAskTopK
+	}
+	passages, err := c.search.Retrieve(ctx, req.Question, topK)
+	if err != nil {
+		hlog.CtxErrorf(ctx, "ask retrieval failed: %v", err)
+		hCtx.JSON(500, map[string]interface{}{
+			"error": "Failed to retrieve site content",
+		})
+		return
+	}
+
+	tokenizer := ai.TokenizerForModel(config.Model)
+	budget := ai.PromptTokenBudget(config)
+	sources := ai.FitSources(tokenizer, ragSources(passages), budget/2)
+	grounding := ai.GroundingMessage("", sources)
+	citations := func(answer string) []AskSource {
+		return askSources(passages, sources, answer)
+	}
+
+	// 需要保留上下文时加载对话
+	var conversation *ai.Conversation
+	var conversationClient *ai.ConversationClient
+	if req.SessionID != "" {
+		conversation, err = c.loadConversation(ctx, req.SessionID, clientName, userID, true)
+		if err != nil {
+			c.conversationError(ctx, hCtx, err)
+			return
+		}
+		conversationClient, err = c.newConversationClient(conversation)
+		if err != nil {
+			hCtx.JSON(500, map[string]interface{}{
+				"error": "Failed to create conversation client: " + err.Error(),
+			})
+			return
+		}
+		conversationClient.SetTokenBudget(budget - ai.CountMessageTokens(tokenizer, []*ai.Message{grounding}))
+	}
+
+	// 流式输出：引用来源在最后一个事件中返回
+	if req.Stream {
+		messages := []*ai.Message{grounding, {Role: "user", Content: req.Question}}
+		if conversationClient != nil {
+			messages = conversationClient.PrepareMessages(ctx, req.Question, []*ai.Message{grounding})
+		}
+
+		answer := c.handleStreamChat(ctx, hCtx, &ai.ChatRequest{Messages: messages, Stream: true}, clientName, userID, "ask",
+			func(message string) map[string]interface{} {
+				return map[string]interface{}{"sources": citations(message), "session_id": req.SessionID}
+			})
+
+		if conversationClient != nil && answer != "" {
+			conversationClient.AddAssistantMessage(ctx, answer)
+			conversation.Messages = conversationClient.GetHistory()
+			if err := c.conversations.Save(ctx, conversation, c.conversationTTL); err != nil {
+				hlog.CtxErrorf(ctx, "failed to save conversation %s: %v", conversation.Key(), err)
+			}
+		}
+		return
+	}
+
+	var answer string
+	var usage *ai.Usage
+	if conversationClient != nil {
+		answer, err = conversationClient.ChatWithContext(ctx, req.Question, []*ai.Message{grounding})
+		usage = conversationClient.LastUsage()
+		c.recordUsage(ctx, userID, "ask", clientName, usage)
+	} else {
+		var response *ai.ChatResponse
+		response, err = c.manager.Chat(ctx, clientName, &ai.ChatRequest{
+			Messages: []*ai.Message{grounding, {Role: "user", Content: req.Question}},
+		})
+		if err == nil {
+			answer = response.Message.Content
+			usage = response.Usage
+			c.recordUsage(ctx, userID, "ask", routedClient(clientName, response.Metadata), usage)
+		}
+	}
+	if err != nil {
+		hlog.CtxErrorf(ctx, "ask failed: %v", err)
+		hCtx.JSON(500, map[string]interface{}{
+			"error": "Ask failed",
+		})
+		return
+	}
+
+	if conversation != nil {
+		conversation.Messages = conversationClient.GetHistory()
+		if err := c.conversations.Save(ctx, conversation, c.conversationTTL); err != nil {
+			hlog.CtxErrorf(ctx, "failed to save conversation %s: %v", conversation.Key(), err)
+		}
+	}
+
+	hCtx.JSON(200, AskResponse{
+		Answer:    answer,
+		SessionID: req.SessionID,
+		Sources:   citations(answer),
+		Model:     clientName,
+		Usage:     usage,
+	})
+}
+
+// ragSources 将文章片段转换为参考资料
+func ragSources(passages []services.PostPassage) []ai.RAGSource {
+	sources := make([]ai.RAGSource, len(passages))
+	for i, passage := range passages {
+		sources[i] = ai.RAGSource{
+			Index:   i + 1,
+			ID:      passage.Slug,
+			Title:   passage.Title,
+			Content: passage.Content,
+			Score:   passage.Score,
+		}
+	}
+	return sources
+}
+
+// askSources 生成响应中的引用列表，标记回答实际引用的来源
+func askSources(passages []services.PostPassage, sources []ai.RAGSource, answer string) []AskSource {
+	cited := make(map[int]bool)
+	for _, index := range ai.CitedIndexes(answer) {
+		cited[index] = true
+	}
+
+	// FitSources 保持顺序，因此 sources[i] 对应 passages[i]
+	result := make([]AskSource, 0, len(sources))
+	for i, source := range sources {
+		result = append(result, AskSource{
+			Index:  source.Index,
+			PostID: passages[i].PostID,
+			Slug:   passages[i].Slug,
+			Title:  source.Title,
+			Score:  source.Score,
+			Cited:  cited[source.Index],
+		})
+	}
+	return result
+}
+
 // ConversationRequest 对话请求
 type ConversationRequest struct {
 	SessionID string `json:"session_id" binding:"required"`
@@ -334,9 +659,6 @@ type ConversationResponse struct {
 	Model     string        `json:"model"`
 }
 
-// conversations 存储对话上下文（实际应该用数据库或缓存）
-var conversations = make(map[string]*ai.ConversationClient)
-
 // Conversation 对话接口（保持上下文）
 func (c *AIController) Conversation(ctx context.Context, hCtx *app.RequestContext) {
 	var req ConversationRequest
@@ -353,18 +675,24 @@ func (c *AIController) Conversation(ctx context.Context, hCtx *app.RequestContex
 		clientName = c.manager.GetDefault()
 	}
 
-	// 获取或创建对话客户端
-	conversationClient, err := c.getOrCreateConversationClient(req.SessionID, clientName, req.MaxLen)
+	userID := currentUserID(hCtx)
+
+	// 加载或创建对话
+	conversation, err := c.loadConversation(ctx, req.SessionID, clientName, userID, true)
 	if err != nil {
-		hCtx.JSON(500, map[string]interface{}{
-			"error": "Failed to create conversation client: " + err.Error(),
-		})
+		c.conversationError(ctx, hCtx, err)
 		return
 	}
+	if req.MaxLen != nil && *req.MaxLen > 0 {
+		conversation.MaxLen = *req.MaxLen
+	}
 
 	// 如果请求清空历史
 	if req.Clear {
-		conversationClient.ClearHistory()
+		if err := c.conversations.Delete(ctx, req.SessionID, clientName); err != nil {
+			c.conversationError(ctx, hCtx, err)
+			return
+		}
 		hCtx.JSON(200, map[string]interface{}{
 			"message":    "History cleared",
 			"session_id": req.SessionID,
@@ -372,8 +700,22 @@ func (c *AIController) Conversation(ctx context.Context, hCtx *app.RequestContex
 		return
 	}
 
+	// 检查额度
+	if !c.checkQuota(ctx, hCtx, userID) {
+		return
+	}
+
+	conversationClient, err := c.newConversationClient(conversation)
+	if err != nil {
+		hCtx.JSON(500, map[string]interface{}{
+			"error": "Failed to create conversation client: " + err.Error(),
+		})
+		return
+	}
+
 	// 执行对话
 	response, err := conversationClient.Chat(ctx, req.Message)
+	c.recordUsage(ctx, userID, "conversation", clientName, conversationClient.LastUsage())
 	if err != nil {
 		hlog.CtxErrorf(ctx, "conversation failed: %v", err)
 		hCtx.JSON(500, map[string]interface{}{
@@ -382,6 +724,12 @@ func (c *AIController) Conversation(ctx context.Context, hCtx *app.RequestContex
 		return
 	}
 
+	// 保存对话历史
+	conversation.Messages = conversationClient.GetHistory()
+	if err := c.conversations.Save(ctx, conversation, c.conversationTTL); err != nil {
+		hlog.CtxErrorf(ctx, "failed to save conversation %s: %v", conversation.Key(), err)
+	}
+
 	// 返回响应
 	resp := ConversationResponse{
 		Message:   response,
@@ -391,40 +739,53 @@ func (c *AIController) Conversation(ctx context.Context, hCtx *app.RequestContex
 
 	// 如果请求包含历史记录
 	if strings.ToLower(hCtx.Query("include_history")) == "true" {
-		resp.History = conversationClient.GetHistory()
+		resp.History = conversation.Messages
 	}
 
 	hCtx.JSON(200, resp)
 }
 
-// getOrCreateConversationClient 获取或创建对话客户端
-func (c *AIController) getOrCreateConversationClient(sessionID, clientName string, maxLen *int) (*ai.ConversationClient, error) {
-	key := fmt.Sprintf("%s:%s", sessionID, clientName)
-
-	if client, exists := conversations[key]; exists {
-		return client, nil
+// loadConversation 从存储加载对话并检查归属，create为true时不存在则新建
+func (c *AIController) loadConversation(ctx context.Context, sessionID, clientName string, userID uint, create bool) (*ai.Conversation, error) {
+	conversation, err := c.conversations.Get(ctx, sessionID, clientName)
+	if errors.Is(err, ai.ErrConversationNotFound) && create {
+		if _, err := c.manager.GetClient(clientName); err != nil {
+			return nil, err
+		}
+		return &ai.Conversation{
+			SessionID: sessionID,
+			Client:    clientName,
+			UserID:    userID,
+			MaxLen:    defaultConversationMaxLen,
+		}, nil
 	}
-
-	// 获取AI客户端
-	manager := c.manager
-	_, err := manager.GetClient(clientName)
 	if err != nil {
 		return nil, err
 	}
 
-	// 创建Eino客户端（这里需要根据实际情况创建）
-	config, err := manager.GetConfig(clientName)
+	if err := conversation.CheckOwner(userID); err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+// newConversationClient 根据存储的对话创建对话客户端
+func (c *AIController) newConversationClient(conversation *ai.Conversation) (*ai.ConversationClient, error) {
+	config, err := c.manager.GetConfig(conversation.Client)
 	if err != nil {
 		return nil, err
 	}
 
 	modelConfig := &ai.ModelConfig{
-		Name:        clientName,
+		Name:        conversation.Client,
 		Provider:    ai.ProviderType(config.Provider),
 		APIKey:      config.APIKey,
+		BaseURL:     config.BaseURL,
 		Model:       config.Model,
 		Temperature: config.Temperature,
 		MaxTokens:   config.MaxTokens,
+		Options:     config.Options,
 	}
 
 	einoClient, err := ai.NewEinoClient(modelConfig)
@@ -433,17 +794,40 @@ func (c *AIController) getOrCreateConversationClient(sessionID, clientName strin
 	}
 
 	// 设置最大上下文长度
-	contextLen := 50 // 默认50条消息
-	if maxLen != nil && *maxLen > 0 {
-		contextLen = *maxLen
+	contextLen := conversation.MaxLen
+	if contextLen <= 0 {
+		contextLen = defaultConversationMaxLen
 	}
 
 	conversationClient := ai.NewConversationClient(einoClient, contextLen)
-	conversations[key] = conversationClient
+	conversationClient.SetTokenBudget(ai.PromptTokenBudget(config))
+	if c.summarize {
+		conversationClient.EnableSummarization()
+	}
+	conversationClient.LoadHistory(conversation.Messages)
 
 	return conversationClient, nil
 }
 
+// conversationError 将对话存储错误转换为HTTP响应
+func (c *AIController) conversationError(ctx context.Context, hCtx *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, ai.ErrConversationNotFound):
+		hCtx.JSON(404, map[string]interface{}{
+			"error": "Conversation not found",
+		})
+	case errors.Is(err, ai.ErrConversationForbidden):
+		hCtx.JSON(403, map[string]interface{}{
+			"error": "Conversation belongs to another user",
+		})
+	default:
+		hlog.CtxErrorf(ctx, "conversation store error: %v", err)
+		hCtx.JSON(500, map[string]interface{}{
+			"error": "Conversation store error: " + err.Error(),
+		})
+	}
+}
+
 // GetConversationHistory 获取对话历史
 func (c *AIController) GetConversationHistory(ctx context.Context, hCtx *app.RequestContext) {
 	sessionID := hCtx.Param("session_id")
@@ -459,21 +843,19 @@ func (c *AIController) GetConversationHistory(ctx context.Context, hCtx *app.Req
 		clientName = c.manager.GetDefault()
 	}
 
-	key := fmt.Sprintf("%s:%s", sessionID, clientName)
-
-	if client, exists := conversations[key]; exists {
-		history := client.GetHistory()
-		hCtx.JSON(200, map[string]interface{}{
-			"session_id": sessionID,
-			"model":      clientName,
-			"history":    history,
-			"count":      len(history),
-		})
-	} else {
-		hCtx.JSON(404, map[string]interface{}{
-			"error": "Conversation not found",
-		})
+	conversation, err := c.loadConversation(ctx, sessionID, clientName, currentUserID(hCtx), false)
+	if err != nil {
+		c.conversationError(ctx, hCtx, err)
+		return
 	}
+
+	hCtx.JSON(200, map[string]interface{}{
+		"session_id": sessionID,
+		"model":      clientName,
+		"history":    conversation.Messages,
+		"count":      len(conversation.Messages),
+		"expires_at": conversation.ExpiresAt,
+	})
 }
 
 // ClearConversationHistory 清空对话历史
@@ -491,17 +873,88 @@ func (c *AIController) ClearConversationHistory(ctx context.Context, hCtx *app.R
 		clientName = c.manager.GetDefault()
 	}
 
-	key := fmt.Sprintf("%s:%s", sessionID, clientName)
+	if _, err := c.loadConversation(ctx, sessionID, clientName, currentUserID(hCtx), false); err != nil {
+		c.conversationError(ctx, hCtx, err)
+		return
+	}
 
-	if client, exists := conversations[key]; exists {
-		client.ClearHistory()
-		hCtx.JSON(200, map[string]interface{}{
-			"message":    "History cleared",
-			"session_id": sessionID,
-		})
-	} else {
-		hCtx.JSON(404, map[string]interface{}{
-			"error": "Conversation not found",
+	if err := c.conversations.Delete(ctx, sessionID, clientName); err != nil {
+		c.conversationError(ctx, hCtx, err)
+		return
+	}
+
+	hCtx.JSON(200, map[string]interface{}{
+		"message":    "History cleared",
+		"session_id": sessionID,
+	})
+}
+
+// currentUserID 获取当前登录用户ID，未登录返回0
+func currentUserID(hCtx *app.RequestContext) uint {
+	if uid, exists := hCtx.Get("user_id"); exists {
+		if id, ok := uid.(uint); ok {
+			return id
+		}
+	}
+	return 0
+}
+
+// checkQuota 检查用户额度，超出时返回429并返回false；账本查询失败时放行
+func (c *AIController) checkQuota(ctx context.Context, hCtx *app.RequestContext, userID uint) bool {
+	if c.usage == nil || c.quotas == nil {
+		return true
+	}
+
+	limit := c.quotas.Resolve(userID, userRoleNames(userID))
+	err := c.usage.CheckQuota(ctx, userID, limit)
+	if err == nil {
+		return true
+	}
+
+	var quotaErr *ai.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		hCtx.JSON(429, map[string]interface{}{
+			"error": "AI usage quota exceeded",
+			"quota": quotaErr,
 		})
+		return false
+	}
+
+	hlog.CtxErrorf(ctx, "failed to check ai quota for user %d: %v", userID, err)
+	return true
+}
+
+// recordUsage 记录用量，clientName 为实际应答的客户端
+func (c *AIController) recordUsage(ctx context.Context, userID uint, endpoint, clientName string, usage *ai.Usage) {
+	if c.usage == nil || usage == nil || (usage.TotalTokens == 0 && usage.PromptTokens == 0 && usage.CompletionTokens == 0) {
+		return
 	}
+
+	config, _ := c.manager.GetConfig(clientName)
+	record := ai.NewUsageRecord(userID, endpoint, clientName, config, usage)
+	if err := c.usage.Record(ctx, record); err != nil {
+		hlog.CtxErrorf(ctx, "failed to record ai usage: %v", err)
+	}
+}
+
+// routedClient 获取实际应答的客户端名称
+func routedClient(requested string, metadata *ai.RoutingMetadata) string {
+	if metadata != nil && metadata.Client != "" {
+		return metadata.Client
+	}
+	return requested
+}
+
+// userRoleNames 获取用户的角色名称
+func userRoleNames(userID uint) []string {
+	db := database.GetDB()
+	if db == nil || userID == 0 {
+		return nil
+	}
+
+	var names []string
+	db.Model(&models.Role{}).
+		Where("id IN (?)", db.Table(database.TableName("user_roles")).Select("role_id").Where("user_id = ?", userID)).
+		Pluck("name", &names)
+	return names
 }
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	middleware "github.com/clarkzhu2020/aidecms/app/Http/Middleware"
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/adapters"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/ai"
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+// 带令牌调用 AI 接口时用量记在当前用户名下，匿名调用记为0
+func TestAIController_UsageRecordedForCaller(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret-with-at-least-32-characters")
+	t.Setenv("JWT_KEYS_FILE", filepath.Join(t.TempDir(), "jwt.json"))
+
+	d := database.NewDatabase(&database.Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "ai.db")})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	if err := d.DB.AutoMigrate(&models.User{}, &auth.RefreshToken{}, &auth.DeniedToken{}, &ai.UsageRecord{}); err != nil {
+		t.Fatal(err)
+	}
+	database.SetDB(d.DB)
+	t.Cleanup(func() { database.SetDB(nil) })
+
+	user := &models.User{Username: "grace", Email: "grace@example.com", Password: "x"}
+	if err := d.DB.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := config.LoadTokenManager()
+	if err != nil {
+		t.Fatalf("LoadTokenManager error: %v", err)
+	}
+	pair, err := tokens.Issue(context.Background(), user.ID, auth.SessionMeta{})
+	if err != nil {
+		t.Fatalf("Issue error: %v", err)
+	}
+
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[{"index":0,"embedding":[0.1,0.2]}],"usage":{"prompt_tokens":2,"total_tokens":2}}`)
+	}))
+	t.Cleanup(provider.Close)
+	manager := ai.NewManager()
+	if err := manager.AddClient("openai", &ai.Config{Provider: "openai", APIKey: "test-key", BaseURL: provider.URL, Model: "gpt-test"}); err != nil {
+		t.Fatal(err)
+	}
+	ledger, err := ai.NewUsageLedger(d.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller := NewAIController(manager)
+	controller.SetUsageLedger(ledger, &ai.QuotaPolicy{})
+
+	h := server.New()
+	router := framework.NewRouter(h)
+	router.POST("/api/ai/embedding", middleware.OptionalAuthMiddleware(), adapters.HertzToFramework(controller.Embedding))
+
+	embed := func(headers ...ut.Header) int {
+		headers = append(headers, ut.Header{Key: "Content-Type", Value: "application/json"})
+		body := &ut.Body{Body: strings.NewReader(`{"input":["hello"]}`), Len: -1}
+		return ut.PerformRequest(h.Engine, "POST", "/api/ai/embedding", body, headers...).Result().StatusCode()
+	}
+
+	if status := embed(ut.Header{Key: "Authorization", Value: "Bearer " + pair.AccessToken}); status != 200 {
+		t.Fatalf("authenticated embedding status = %d", status)
+	}
+	if status := embed(); status != 200 {
+		t.Fatalf("anonymous embedding status = %d", status)
+	}
+	if status := embed(ut.Header{Key: "Authorization", Value: "Bearer invalid"}); status != 401 {
+		t.Fatalf("invalid token status = %d, want 401", status)
+	}
+
+	var records []ai.UsageRecord
+	d.DB.Order("id").Find(&records)
+	if len(records) != 2 || records[0].UserID != user.ID || records[1].UserID != 0 {
+		t.Fatalf("usage records = %+v, want user %d then anonymous", records, user.ID)
+	}
+}
+
+// 流式聊天在上游错误通道先关闭时仍然正常结束
+func TestAIController_StreamChatEndsAfterErrorChannelCloses(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hel\"}}]}\n\ndata: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\ndata: [DONE]\n\n")
+	}))
+	t.Cleanup(provider.Close)
+	manager := ai.NewManager()
+	if err := manager.AddClient("openai", &ai.Config{Provider: "openai", APIKey: "test-key", BaseURL: provider.URL, Model: "gpt-test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	h := server.New()
+	router := framework.NewRouter(h)
+	router.POST("/api/ai/chat", adapters.HertzToFramework(NewAIController(manager).Chat))
+
+	body := &ut.Body{Body: strings.NewReader(`{"message":"hi","stream":true}`), Len: -1}
+	resp := ut.PerformRequest(h.Engine, "POST", "/api/ai/chat", body, ut.Header{Key: "Content-Type", Value: "application/json"}).Result()
+
+	events := string(resp.Body())
+	if !strings.Contains(events, `"message":"hello"`) || !strings.HasSuffix(events, "data: [DONE]\n\n") {
+		t.Fatalf("unexpected stream: %q", events)
+	}
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"gorm.io/gorm"
+)
+
+// AccessTokenController 个人访问令牌管理控制器
+// 令牌管理只接受登录会话（JWT），个人访问令牌不能创建或修改令牌
+type AccessTokenController struct {
+	TokenService *services.AccessTokenService
+}
+
+// NewAccessTokenController 创建个人访问令牌控制器
+func NewAccessTokenController() *AccessTokenController {
+	return &AccessTokenController{
+		TokenService: services.NewAccessTokenService(),
+	}
+}
+
+// 创建令牌请求
+type CreateAccessTokenRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"` // 为空表示不过期
+}
+
+// 更新令牌请求
+type UpdateAccessTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"` // 为空时不修改
+}
+
+// Index 获取当前用户的令牌列表
+func (c *AccessTokenController) Index(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+
+	tokens, err := c.TokenService.List(userID)
+	if err != nil {
+		reqCtx.JSON(500, map[string]interface{}{
+			"error": "获取令牌失败",
+		})
+		return
+	}
+
+	items := make([]map[string]interface{}, 0, len(tokens))
+	for i := range tokens {
+		items = append(items, accessTokenResponse(&tokens[i]))
+	}
+	reqCtx.JSON(200, map[string]interface{}{
+		"tokens": items,
+	})
+}
+
+// Store 创建令牌，明文令牌只在响应中返回一次
+func (c *AccessTokenController) Store(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+
+	var req CreateAccessTokenRequest
+	if err := reqCtx.BindJSON(&req); err != nil || req.Name == "" || len(req.Name) > 100 {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return
+	}
+
+	token, plain, err := c.TokenService.Create(userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	reqCtx.JSON(201, map[string]interface{}{
+		"token":        plain,
+		"access_token": accessTokenResponse(token),
+		"message":      "请立即保存令牌，之后将无法再次查看",
+	})
+}
+
+// Update 修改令牌名称和权限范围
+func (c *AccessTokenController) Update(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+	tokenID, ok := tokenIDParam(reqCtx)
+	if !ok {
+		return
+	}
+
+	var req UpdateAccessTokenRequest
+	if err := reqCtx.BindJSON(&req); err != nil || len(req.Name) > 100 {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return
+	}
+	if len(req.Scopes) == 0 {
+		req.Scopes = nil
+	}
+
+	token, err := c.TokenService.Update(userID, tokenID, req.Name, req.Scopes)
+	if err != nil {
+		accessTokenError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"access_token": accessTokenResponse(token),
+	})
+}
+
+// Destroy 撤销令牌
+func (c *AccessTokenController) Destroy(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+	tokenID, ok := tokenIDParam(reqCtx)
+	if !ok {
+		return
+	}
+
+	if err := c.TokenService.Revoke(userID, tokenID); err != nil {
+		accessTokenError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"message": "令牌已撤销",
+	})
+}
+
+// sessionUserID 获取认证中间件保存的用户ID，不存在时写入401响应
+func sessionUserID(reqCtx *framework.RequestContext) (uint, bool) {
+	value, exists := reqCtx.Get("user_id")
+	userID, ok := value.(uint)
+	if !exists || !ok {
+		reqCtx.JSON(401, map[string]interface{}{
+			"error": "未授权",
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+// tokenIDParam 解析路由参数 :id，失败时写入400响应
+func tokenIDParam(reqCtx *framework.RequestContext) (uint, bool) {
+	id, err := strconv.ParseUint(reqCtx.GetParam("id"), 10, 32)
+	if err != nil || id == 0 {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的令牌ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// accessTokenError 将令牌服务的错误转换为响应
+func accessTokenError(reqCtx *framework.RequestContext, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		reqCtx.JSON(404, map[string]interface{}{
+			"error": "令牌不存在",
+		})
+	case errors.Is(err, services.ErrInvalidScope):
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": err.Error(),
+		})
+	default:
+		reqCtx.JSON(500, map[string]interface{}{
+			"error": "操作失败",
+		})
+	}
+}
+
+// accessTokenResponse 令牌信息，不包含令牌本身
+func accessTokenResponse(token *models.PersonalAccessToken) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           token.ID,
+		"name":         token.Name,
+		"prefix":       token.Prefix,
+		"scopes":       token.ScopeList(),
+		"expires_at":   token.ExpiresAt,
+		"last_used_at": token.LastUsedAt,
+		"last_used_ip": token.LastUsedIP,
+		"created_at":   token.CreatedAt,
+	}
+}
@@ -28,6 +28,22 @@ type CreateCategoryRequest struct {
 	Image           string `json:"image"`
 	MetaTitle       string `json:"meta_title"`
 	MetaDescription string `json:"meta_description"`
+
+	// 访问规则，适用于分类下的所有文章
+	AccessRules models.AccessRules `json:"access_rules"`
+}
+
+// UpdateCategoryRequest 更新分类请求
+type UpdateCategoryRequest struct {
+	Name            string `json:"name" validate:"required,min=2,max=100"`
+	Description     string `json:"description"`
+	ParentID        *uint  `json:"parent_id"`
+	Image           string `json:"image"`
+	MetaTitle       string `json:"meta_title"`
+	MetaDescription string `json:"meta_description"`
+
+	// 访问规则，不传时保持不变，传空数组时取消限制
+	AccessRules *models.AccessRules `json:"access_rules"`
 }
 
 // Create 创建分类
@@ -56,6 +72,10 @@ func (c *CategoryController) Create(ctx context.Context, hCtx *app.RequestContex
 		response.BadRequest(hCtx, err.Error())
 		return
 	}
+	if err := req.AccessRules.Validate(); err != nil {
+		response.BadRequest(hCtx, err.Error())
+		return
+	}
 
 	category := &models.Category{
 		Name:            req.Name,
@@ -65,6 +85,7 @@ func (c *CategoryController) Create(ctx context.Context, hCtx *app.RequestContex
 		Image:           req.Image,
 		MetaTitle:       req.MetaTitle,
 		MetaDescription: req.MetaDescription,
+		AccessRules:     req.AccessRules,
 	}
 
 	db := database.GetDB()
@@ -137,11 +158,17 @@ func (c *CategoryController) Get(ctx context.Context, hCtx *app.RequestContext)
 func (c *CategoryController) Update(ctx context.Context, hCtx *app.RequestContext) {
 	id := hCtx.Param("id")
 
-	var req CreateCategoryRequest
+	var req UpdateCategoryRequest
 	if err := hCtx.BindJSON(&req); err != nil {
 		response.BadRequest(hCtx, "Invalid request data")
 		return
 	}
+	if req.AccessRules != nil {
+		if err := req.AccessRules.Validate(); err != nil {
+			response.BadRequest(hCtx, err.Error())
+			return
+		}
+	}
 
 	db := database.GetDB()
 	var category models.Category
@@ -158,6 +185,9 @@ func (c *CategoryController) Update(ctx context.Context, hCtx *app.RequestContex
 	category.Image = req.Image
 	category.MetaTitle = req.MetaTitle
 	category.MetaDescription = req.MetaDescription
+	if req.AccessRules != nil {
+		category.AccessRules = *req.AccessRules
+	}
 
 	if err := db.Save(&category).Error; err != nil {
 		response.ServerError(hCtx, "Failed to update category")
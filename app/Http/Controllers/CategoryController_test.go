@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/adapters"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+// 更新分类时不传 access_rules 保持原有的访问规则，传空数组取消限制
+func TestCategoryController_UpdateKeepsAccessRules(t *testing.T) {
+	d := database.NewDatabase(&database.Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "category.db")})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	if err := d.DB.AutoMigrate(&models.Category{}); err != nil {
+		t.Fatal(err)
+	}
+	database.SetDB(d.DB)
+	t.Cleanup(func() { database.SetDB(nil) })
+
+	category := &models.Category{
+		Name: "Members",
+		Slug: "members",
+		AccessRules: models.AccessRules{{
+			Type:       models.AccessRuleERC20,
+			ChainID:    1,
+			Contract:   "0x00000000000000000000000000000000000000aa",
+			MinBalance: "100",
+		}},
+	}
+	if err := d.DB.Create(category).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	h := server.New()
+	router := framework.NewRouter(h)
+	router.PUT("/categories/:id", adapters.HertzToFramework(NewCategoryController().Update))
+
+	update := func(body string) models.Category {
+		t.Helper()
+		path := "/categories/" + strconv.FormatUint(uint64(category.ID), 10)
+		resp := ut.PerformRequest(h.Engine, "PUT", path, &ut.Body{Body: strings.NewReader(body), Len: -1},
+			ut.Header{Key: "Content-Type", Value: "application/json"}).Result()
+		if resp.StatusCode() != 200 {
+			t.Fatalf("update status = %d, body = %s", resp.StatusCode(), resp.Body())
+		}
+		var got models.Category
+		if err := d.DB.First(&got, category.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	got := update(`{"name":"Renamed"}`)
+	if got.Name != "Renamed" {
+		t.Fatalf("name = %q, want Renamed", got.Name)
+	}
+	if len(got.AccessRules) != 1 || got.AccessRules[0].Contract != category.AccessRules[0].Contract {
+		t.Fatalf("access rules = %+v, want the original gate", got.AccessRules)
+	}
+
+	if got := update(`{"name":"Renamed","access_rules":[]}`); len(got.AccessRules) != 0 {
+		t.Fatalf("access rules = %+v, want cleared", got.AccessRules)
+	}
+}
@@ -288,6 +288,12 @@ func (c *CommentController) Update(ctx context.Context, hCtx *app.RequestContext
 		return
 	}
 
+	// 修改评论状态需要审核权限，作者只能修改内容
+	if req.Status != "" && req.Status != comment.Status && !userCan(hCtx, "comment", "moderate") {
+		response.Forbidden(hCtx, "You don't have permission to moderate comment")
+		return
+	}
+
 	// 更新字段
 	updates := make(map[string]interface{})
 	if req.Content != "" {
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"github.com/clarkzhu2020/aidecms/pkg/oidc"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// oidcStateCookie 发起授权时保存 state 的 Cookie，回调时必须与查询参数一致，防止登录 CSRF
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/auth/oidc"
+)
+
+// OIDCController OpenID Connect 第三方登录控制器
+type OIDCController struct {
+	OIDC        *services.OIDCService
+	UserService *services.UserService
+}
+
+// NewOIDCController 创建第三方登录控制器，登录成功后通过 users 签发令牌
+func NewOIDCController(users *services.UserService) *OIDCController {
+	return &OIDCController{
+		OIDC:        services.NewOIDCService(),
+		UserService: users,
+	}
+}
+
+// Providers 已配置的身份提供方
+func (c *OIDCController) Providers(ctx context.Context, reqCtx *framework.RequestContext) {
+	reqCtx.JSON(200, map[string]interface{}{
+		"providers": c.OIDC.Providers(),
+	})
+}
+
+// Login 跳转到身份提供方的授权页面
+func (c *OIDCController) Login(ctx context.Context, reqCtx *framework.RequestContext) {
+	request, err := c.OIDC.Begin(ctx, reqCtx.GetParam("provider"), 0)
+	if err != nil {
+		oidcError(reqCtx, err)
+		return
+	}
+
+	setOIDCStateCookie(reqCtx, request)
+	reqCtx.Redirect(302, request.URL)
+}
+
+// Callback 身份提供方的回调，登录时返回与 /login 相同的响应，关联身份时返回关联结果
+func (c *OIDCController) Callback(ctx context.Context, reqCtx *framework.RequestContext) {
+	if errCode := reqCtx.GetQuery("error"); errCode != "" {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error":             "第三方登录已取消或被拒绝",
+			"provider_error":    errCode,
+			"error_description": reqCtx.GetQuery("error_description"),
+		})
+		return
+	}
+
+	state, code := reqCtx.GetQuery("state"), reqCtx.GetQuery("code")
+	cookie := reqCtx.GetCookie(oidcStateCookie)
+	if state == "" || code == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		oidcError(reqCtx, services.ErrOIDCState)
+		return
+	}
+	reqCtx.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", isSecureRequest(reqCtx), true)
+
+	result, err := c.OIDC.Complete(ctx, reqCtx.GetParam("provider"), state, code)
+	if err != nil {
+		oidcError(reqCtx, err)
+		return
+	}
+	if result.Linked {
+		reqCtx.JSON(200, map[string]interface{}{
+			"message":  "已关联第三方账户",
+			"provider": result.Provider,
+		})
+		return
+	}
+
+	user, tokens, err := c.UserService.LoginUser(ctx, result.User, sessionMeta(reqCtx))
+	loginResponse(reqCtx, c.UserService, user, tokens, err)
+}
+
+// Identities 当前用户关联的第三方身份
+func (c *OIDCController) Identities(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+
+	identities, err := c.OIDC.Identities(userID)
+	if err != nil {
+		oidcError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"identities": identities,
+		"providers":  c.OIDC.Providers(),
+	})
+}
+
+// Link 为当前用户关联第三方身份，返回授权地址，由前端跳转
+func (c *OIDCController) Link(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+
+	request, err := c.OIDC.Begin(ctx, reqCtx.GetParam("provider"), userID)
+	if err != nil {
+		oidcError(reqCtx, err)
+		return
+	}
+
+	setOIDCStateCookie(reqCtx, request)
+	reqCtx.JSON(200, map[string]interface{}{
+		"authorization_url": request.URL,
+		"expires_at":        request.ExpiresAt,
+	})
+}
+
+// Unlink 解除当前用户与身份提供方的关联
+func (c *OIDCController) Unlink(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+
+	if err := c.OIDC.Unlink(userID, reqCtx.GetParam("provider")); err != nil {
+		oidcError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"message": "已解除关联",
+	})
+}
+
+// setOIDCStateCookie 保存 state，有效期与服务端记录一致
+func setOIDCStateCookie(reqCtx *framework.RequestContext, request *services.OIDCAuthRequest) {
+	maxAge := int(time.Until(request.ExpiresAt).Seconds())
+	reqCtx.SetCookie(oidcStateCookie, request.State, maxAge, oidcCookiePath, "", isSecureRequest(reqCtx), true)
+}
+
+// isSecureRequest 请求是否通过 HTTPS（包括反向代理终止 TLS 的情况）
+func isSecureRequest(reqCtx *framework.RequestContext) bool {
+	return string(reqCtx.URI().Scheme()) == "https" || reqCtx.GetHeader("X-Forwarded-Proto") == "https"
+}
+
+// oidcError 将第三方登录的错误转换为响应
+func oidcError(reqCtx *framework.RequestContext, err error) {
+	status := 500
+	message := err.Error()
+	var providerErr *oidc.Error
+	switch {
+	case errors.Is(err, services.ErrOIDCProviderNotFound), errors.Is(err, services.ErrIdentityNotFound):
+		status = 404
+	case errors.Is(err, services.ErrOIDCState):
+		status = 400
+	case errors.Is(err, services.ErrOIDCNoAccount), errors.Is(err, services.ErrOIDCEmailRequired),
+		errors.Is(err, services.ErrOIDCDomainNotAllowed):
+		status = 403
+	case errors.Is(err, services.ErrOIDCAccountExists), errors.Is(err, services.ErrIdentityLinked),
+		errors.Is(err, services.ErrProviderLinked):
+		status = 409
+	case errors.Is(err, oidc.ErrInvalidIDToken), errors.Is(err, oidc.ErrNoIDToken), errors.As(err, &providerErr):
+		hlog.Warnf("oidc login failed: %v", err)
+		status = 401
+		message = "第三方登录失败"
+	default:
+		hlog.Errorf("oidc login failed: %v", err)
+		message = "第三方登录失败"
+	}
+	reqCtx.JSON(status, map[string]interface{}{
+		"error": message,
+	})
+}
@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/clarkzhu2020/aidecms/pkg/queue"
+	"github.com/clarkzhu2020/aidecms/pkg/response"
+	"github.com/clarkzhu2020/aidecms/pkg/validator"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// PostAIController 文章AI编辑辅助控制器，生成的内容只作为建议返回，不修改文章
+type PostAIController struct {
+	editorial *services.PostEditorialService
+	queue     *queue.Queue
+}
+
+// NewPostAIController 创建文章AI编辑辅助控制器，queue 为nil时不支持后台任务
+func NewPostAIController(editorial *services.PostEditorialService, q *queue.Queue) *PostAIController {
+	return &PostAIController{
+		editorial: editorial,
+		queue:     q,
+	}
+}
+
+// SuggestRequest 生成建议请求
+type SuggestRequest struct {
+	Language string `json:"language"` // 翻译目标语言
+	Async    bool   `json:"async"`    // 为 true 时放入队列，结果保存为待审核建议
+	Save     bool   `json:"save"`     // 同步生成时是否同时保存为待审核建议
+}
+
+// BackfillRequest 批量生成建议请求
+type BackfillRequest struct {
+	Actions     []string `json:"actions"`
+	PostIDs     []uint   `json:"post_ids"`
+	Status      string   `json:"status" validate:"omitempty,oneof=draft published archived"`
+	OnlyMissing bool     `json:"only_missing"`
+	Language    string   `json:"language"`
+	Limit       int      `json:"limit"`
+}
+
+// UpdateSuggestionRequest 审核建议请求
+type UpdateSuggestionRequest struct {
+	Status string `json:"status" validate:"required,oneof=applied dismissed"`
+}
+
+// Excerpt 生成摘要建议
+// @Summary      生成摘要建议
+// @Description  使用AI为文章生成摘要建议，不修改文章
+// @Tags         Posts AI
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "文章ID"
+// @Param        request body SuggestRequest false "选项"
+// @Success      200 {object} response.Response{data=services.EditorialSuggestion}
+// @Success      202 {object} response.Response
+// @Failure      404 {object} response.Response
+// @Security     BearerAuth
+// @Router       /cms/posts/{id}/ai/excerpt [post]
+func (c *PostAIController) Excerpt(ctx context.Context, hCtx *app.RequestContext) {
+	c.suggest(ctx, hCtx, services.EditorialExcerpt)
+}
+
+// SEO 生成SEO字段建议
+// @Summary      生成SEO字段建议
+// @Description  使用AI为文章生成 meta_title、meta_description、meta_keywords 建议，不修改文章
+// @Tags         Posts AI
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "文章ID"
+// @Param        request body SuggestRequest false "选项"
+// @Success      200 {object} response.Response{data=services.EditorialSuggestion}
+// @Success      202 {object} response.Response
+// @Failure      404 {object} response.Response
+// @Security     BearerAuth
+// @Router       /cms/posts/{id}/ai/seo [post]
+func (c *PostAIController) SEO(ctx context.Context, hCtx *app.RequestContext) {
+	c.suggest(ctx, hCtx, services.EditorialSEO)
+}
+
+// Tags 生成标签建议
+// @Summary      生成标签建议
+// @Description  使用AI从已有标签中为文章挑选标签，不修改文章
+// @Tags         Posts AI
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "文章ID"
+// @Param        request body SuggestRequest false "选项"
+// @Success      200 {object} response.Response{data=services.EditorialSuggestion}
+// @Success      202 {object} response.Response
+// @Failure      404 {object} response.Response
+// @Security     BearerAuth
+// @Router       /cms/posts/{id}/ai/tags [post]
+func (c *PostAIController) Tags(ctx context.Context, hCtx *app.RequestContext) {
+	c.suggest(ctx, hCtx, services.EditorialTags)
+}
+
+// Translate 生成翻译建议
+// @Summary      生成翻译建议
+// @Description  使用AI将文章翻译为目标语言，不修改文章
+// @Tags         Posts AI
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "文章ID"
+// @Param        request body SuggestRequest true "目标语言"
+// @Success      200 {object} response.Response{data=services.EditorialSuggestion}
+// @Success      202 {object} response.Response
+// @Failure      400 {object} response.Response
+// @Failure      404 {object} response.Response
+// @Security     BearerAuth
+// @Router       /cms/posts/{id}/ai/translate [post]
+func (c *PostAIController) Translate(ctx context.Context, hCtx *app.RequestContext) {
+	c.suggest(ctx, hCtx, services.EditorialTranslation)
+}
+
+// suggest 生成单项建议，async 时放入队列
+func (c *PostAIController) suggest(ctx context.Context, hCtx *app.RequestContext, action string) {
+	var req SuggestRequest
+	if len(hCtx.Request.Body()) > 0 {
+		if err := hCtx.BindJSON(&req); err != nil {
+			response.BadRequest(hCtx, "Invalid request data")
+			return
+		}
+	}
+	if action == services.EditorialTranslation && req.Language == "" {
+		response.BadRequest(hCtx, "Target language is required")
+		return
+	}
+
+	var post models.Post
+	if err := database.GetDB().First(&post, hCtx.Param("id")).Error; err != nil {
+		response.NotFound(hCtx, "Post not found")
+		return
+	}
+
+	userID := currentUserID(hCtx)
+	actions := []string{action}
+
+	if req.Async {
+		if c.queue == nil {
+			response.Error(hCtx, 503, "Service Unavailable", "Background jobs are not available")
+			return
+		}
+		job := services.NewPostEditorialJob(post.ID, actions, req.Language, userID)
+		if err := c.queue.Push(job); err != nil {
+			hlog.CtxErrorf(ctx, "failed to queue editorial job for post %d: %v", post.ID, err)
+			response.ServerError(hCtx, "Failed to queue job")
+			return
+		}
+		hCtx.JSON(202, response.Response{
+			Success: true,
+			Data:    map[string]interface{}{"job_id": job.GetID(), "post_id": post.ID, "actions": actions},
+			Message: "Suggestion job queued",
+		})
+		return
+	}
+
+	suggestion, err := c.editorial.Suggest(ctx, &post, actions, req.Language, userID)
+	if err != nil {
+		hlog.CtxErrorf(ctx, "failed to generate %s suggestion for post %d: %v", action, post.ID, err)
+		response.ServerError(hCtx, "Failed to generate suggestion")
+		return
+	}
+
+	if req.Save {
+		if _, err := c.editorial.SaveSuggestion(ctx, suggestion, userID); err != nil {
+			hlog.CtxErrorf(ctx, "failed to save suggestion for post %d: %v", post.ID, err)
+			response.ServerError(hCtx, "Failed to save suggestion")
+			return
+		}
+	}
+
+	response.Success(hCtx, suggestion, "")
+}
+
+// Backfill 批量生成建议
+// @Summary      批量生成建议
+// @Description  为符合条件的文章批量生成建议，以后台任务执行，结果保存为待审核建议
+// @Tags         Posts AI
+// @Accept       json
+// @Produce      json
+// @Param        request body BackfillRequest true "筛选条件"
+// @Success      202 {object} response.Response
+// @Failure      400 {object} response.Response
+// @Failure      503 {object} response.Response
+// @Security     BearerAuth
+// @Router       /cms/ai/posts/backfill [post]
+func (c *PostAIController) Backfill(ctx context.Context, hCtx *app.RequestContext) {
+	if c.queue == nil {
+		response.Error(hCtx, 503, "Service Unavailable", "Background jobs are not available")
+		return
+	}
+
+	var req BackfillRequest
+	if err := hCtx.BindJSON(&req); err != nil {
+		response.BadRequest(hCtx, "Invalid request data")
+		return
+	}
+	if err := validator.Validate(&req); err != nil {
+		if valErr, ok := err.(*validator.ValidationError); ok {
+			response.ValidationError(hCtx, valErr.Errors)
+			return
+		}
+		response.BadRequest(hCtx, err.Error())
+		return
+	}
+
+	actions := req.Actions
+	if len(actions) == 0 {
+		actions, _ = services.ParseEditorialActions("")
+	}
+	for _, action := range actions {
+		if _, err := services.ParseEditorialActions(action); err != nil {
+			response.BadRequest(hCtx, err.Error())
+			return
+		}
+		if action == services.EditorialTranslation && req.Language == "" {
+			response.BadRequest(hCtx, "Target language is required")
+			return
+		}
+	}
+
+	ids, err := c.editorial.BackfillPosts(ctx, actions, services.BackfillFilter{
+		PostIDs:     req.PostIDs,
+		Status:      req.Status,
+		OnlyMissing: req.OnlyMissing,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		response.ServerError(hCtx, "Failed to fetch posts")
+		return
+	}
+
+	userID := currentUserID(hCtx)
+	jobIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		job := services.NewPostEditorialJob(id, actions, req.Language, userID)
+		if err := c.queue.Push(job); err != nil {
+			hlog.CtxErrorf(ctx, "failed to queue editorial job for post %d: %v", id, err)
+			continue
+		}
+		jobIDs = append(jobIDs, job.GetID())
+	}
+
+	hCtx.JSON(202, response.Response{
+		Success: true,
+		Data:    map[string]interface{}{"queued": len(jobIDs), "jobs": jobIDs, "actions": actions},
+		Message: "Suggestion jobs queued",
+	})
+}
+
+// Suggestions 获取文章的待审核建议
+// @Summary      获取文章建议
+// @Description  获取文章已保存的AI建议，默认只返回待审核的建议
+// @Tags         Posts AI
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "文章ID"
+// @Param        status query string false "状态" Enums(pending, applied, dismissed, all) default(pending)
+// @Success      200 {object} response.Response{data=[]models.PostSuggestion}
+// @Security     BearerAuth
+// @Router       /cms/posts/{id}/ai/suggestions [get]
+func (c *PostAIController) Suggestions(ctx context.Context, hCtx *app.RequestContext) {
+	postID, err := strconv.ParseUint(hCtx.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(hCtx, "Invalid post ID")
+		return
+	}
+
+	query := database.GetDB().Where("post_id = ?", postID)
+	switch status := string(hCtx.Query("status")); status {
+	case "":
+		query = query.Where("status = ?", "pending")
+	case "all":
+	default:
+		query = query.Where("status = ?", status)
+	}
+
+	var suggestions []models.PostSuggestion
+	if err := query.Order("created_at DESC").Find(&suggestions).Error; err != nil {
+		response.ServerError(hCtx, "Failed to fetch suggestions")
+		return
+	}
+
+	response.Success(hCtx, suggestions, "")
+}
+
+// UpdateSuggestion 标记建议已采纳或已忽略
+// @Summary      审核建议
+// @Description  将建议标记为已采纳或已忽略（采纳的内容需通过更新文章接口写入）
+// @Tags         Posts AI
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "建议ID"
+// @Param        request body UpdateSuggestionRequest true "状态"
+// @Success      200 {object} response.Response{data=models.PostSuggestion}
+// @Failure      404 {object} response.Response
+// @Security     BearerAuth
+// @Router       /cms/ai/suggestions/{id} [put]
+func (c *PostAIController) UpdateSuggestion(ctx context.Context, hCtx *app.RequestContext) {
+	var req UpdateSuggestionRequest
+	if err := hCtx.BindJSON(&req); err != nil {
+		response.BadRequest(hCtx, "Invalid request data")
+		return
+	}
+	if err := validator.Validate(&req); err != nil {
+		if valErr, ok := err.(*validator.ValidationError); ok {
+			response.ValidationError(hCtx, valErr.Errors)
+			return
+		}
+		response.BadRequest(hCtx, err.Error())
+		return
+	}
+
+	db := database.GetDB()
+	var suggestion models.PostSuggestion
+	if err := db.First(&suggestion, hCtx.Param("id")).Error; err != nil {
+		response.NotFound(hCtx, "Suggestion not found")
+		return
+	}
+
+	if err := db.Model(&suggestion).Update("status", req.Status).Error; err != nil {
+		response.ServerError(hCtx, "Failed to update suggestion")
+		return
+	}
+
+	response.Success(hCtx, suggestion, "Suggestion updated successfully")
+}
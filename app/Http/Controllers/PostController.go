@@ -6,19 +6,34 @@ import (
 	"time"
 
 	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
 	"github.com/clarkzhu2020/aidecms/pkg/database"
 	"github.com/clarkzhu2020/aidecms/pkg/response"
 	"github.com/clarkzhu2020/aidecms/pkg/validator"
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/gosimple/slug"
 )
 
+// postIndexTimeout 单篇文章索引的超时时间
+const postIndexTimeout = 2 * time.Minute
+
 // PostController 文章控制器
-type PostController struct{}
+type PostController struct {
+	search *services.PostSearchService
+	gate   *services.TokenGateService
+}
 
 // NewPostController 创建文章控制器
 func NewPostController() *PostController {
-	return &PostController{}
+	return &PostController{
+		gate: services.NewTokenGateService(),
+	}
+}
+
+// SetSearchService 设置文章检索服务，设置后文章发布、更新、删除时同步维护向量索引
+func (c *PostController) SetSearchService(search *services.PostSearchService) {
+	c.search = search
 }
 
 // CreatePostRequest 创建文章请求
@@ -33,6 +48,9 @@ type CreatePostRequest struct {
 	MetaTitle       string `json:"meta_title"`
 	MetaDescription string `json:"meta_description"`
 	MetaKeywords    string `json:"meta_keywords"`
+
+	// 访问规则，满足其中一条才能阅读正文
+	AccessRules models.AccessRules `json:"access_rules"`
 }
 
 // UpdatePostRequest 更新文章请求
@@ -47,6 +65,22 @@ type UpdatePostRequest struct {
 	MetaTitle       string `json:"meta_title"`
 	MetaDescription string `json:"meta_description"`
 	MetaKeywords    string `json:"meta_keywords"`
+
+	// 访问规则，不传时保持不变，传空数组时取消限制
+	AccessRules *models.AccessRules `json:"access_rules"`
+}
+
+// postView 读者看到的文章，不满足访问规则时隐藏正文
+type postView struct {
+	models.Post
+	Locked bool                     `json:"locked"`
+	Access *services.AccessDecision `json:"access,omitempty"`
+}
+
+// postSearchView 读者看到的检索结果
+type postSearchView struct {
+	services.PostSearchResult
+	Post postView `json:"post"`
 }
 
 // Create 创建文章
@@ -78,10 +112,20 @@ func (c *PostController) Create(ctx context.Context, hCtx *app.RequestContext) {
 		response.BadRequest(hCtx, err.Error())
 		return
 	}
+	if err := req.AccessRules.Validate(); err != nil {
+		response.BadRequest(hCtx, err.Error())
+		return
+	}
 
 	// 获取当前用户ID（从JWT中间件设置）
 	userID, _ := hCtx.Get("user_id")
 
+	// 直接创建已发布的文章需要发布权限
+	if req.Status == "published" && !userCan(hCtx, "post", "publish") {
+		response.Forbidden(hCtx, "You don't have permission to publish post")
+		return
+	}
+
 	// 生成slug
 	postSlug := slug.Make(req.Title)
 
@@ -97,6 +141,7 @@ func (c *PostController) Create(ctx context.Context, hCtx *app.RequestContext) {
 		MetaTitle:       req.MetaTitle,
 		MetaDescription: req.MetaDescription,
 		MetaKeywords:    req.MetaKeywords,
+		AccessRules:     req.AccessRules,
 	}
 
 	// 如果状态为已发布，设置发布时间
@@ -136,6 +181,8 @@ func (c *PostController) Create(ctx context.Context, hCtx *app.RequestContext) {
 	// 预加载关联数据
 	db.Preload("Author").Preload("Category").Preload("Tags").First(post, post.ID)
 
+	c.indexPost(post)
+
 	response.Created(hCtx, post, "Post created successfully")
 }
 
@@ -152,6 +199,7 @@ func (c *PostController) Create(ctx context.Context, hCtx *app.RequestContext) {
 // @Param        author_id query int false "作者ID"
 // @Success      200 {object} response.Response{data=[]models.PostSwagger}
 // @Failure      500 {object} response.Response
+// @Security     BearerAuth
 // @Router       /posts [get]
 func (c *PostController) List(ctx context.Context, hCtx *app.RequestContext) {
 	db := database.GetDB()
@@ -202,19 +250,32 @@ func (c *PostController) List(ctx context.Context, hCtx *app.RequestContext) {
 		return
 	}
 
+	refs := make([]*models.Post, len(posts))
+	for i := range posts {
+		refs[i] = &posts[i]
+	}
+	views, err := c.postViews(ctx, hCtx, refs)
+	if err != nil {
+		hlog.CtxErrorf(ctx, "post access check failed: %v", err)
+		response.ServerError(hCtx, "Failed to fetch posts")
+		return
+	}
+
 	meta := response.NewMeta(page, perPage, total)
-	response.SuccessWithMeta(hCtx, posts, meta, "")
+	response.SuccessWithMeta(hCtx, views, meta, "")
 }
 
 // Get 获取单篇文章
 // @Summary      获取文章详情
-// @Description  根据ID获取文章详细信息
+// @Description  根据ID获取文章详细信息；不满足访问规则时返回403，data 中只有摘要和访问规则
 // @Tags         Posts
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "文章ID"
 // @Success      200 {object} response.Response{data=models.PostSwagger}
+// @Failure      403 {object} response.Response{data=models.PostSwagger}
 // @Failure      404 {object} response.Response
+// @Security     BearerAuth
 // @Router       /posts/{id} [get]
 func (c *PostController) Get(ctx context.Context, hCtx *app.RequestContext) {
 	id := hCtx.Param("id")
@@ -230,11 +291,27 @@ func (c *PostController) Get(ctx context.Context, hCtx *app.RequestContext) {
 		return
 	}
 
+	views, err := c.postViews(ctx, hCtx, []*models.Post{&post})
+	if err != nil {
+		hlog.CtxErrorf(ctx, "post access check failed: %v", err)
+		response.ServerError(hCtx, "Failed to fetch post")
+		return
+	}
+	if views[0].Locked {
+		hCtx.JSON(403, response.Response{
+			Success: false,
+			Data:    views[0],
+			Error:   "Forbidden",
+			Message: "This post requires holding the listed tokens",
+		})
+		return
+	}
+
 	// 增加浏览次数
 	db.Model(&post).UpdateColumn("view_count", post.ViewCount+1)
-	post.ViewCount++
+	views[0].ViewCount++
 
-	response.Success(hCtx, post, "")
+	response.Success(hCtx, views[0], "")
 }
 
 // Update 更新文章
@@ -269,6 +346,12 @@ func (c *PostController) Update(ctx context.Context, hCtx *app.RequestContext) {
 		response.BadRequest(hCtx, err.Error())
 		return
 	}
+	if req.AccessRules != nil {
+		if err := req.AccessRules.Validate(); err != nil {
+			response.BadRequest(hCtx, err.Error())
+			return
+		}
+	}
 
 	db := database.GetDB()
 	var post models.Post
@@ -278,6 +361,12 @@ func (c *PostController) Update(ctx context.Context, hCtx *app.RequestContext) {
 		return
 	}
 
+	// 修改为已发布需要发布权限
+	if req.Status == "published" && post.Status != "published" && !userCan(hCtx, "post", "publish") {
+		response.Forbidden(hCtx, "You don't have permission to publish post")
+		return
+	}
+
 	// 更新字段
 	if req.Title != "" {
 		post.Title = req.Title
@@ -302,6 +391,9 @@ func (c *PostController) Update(ctx context.Context, hCtx *app.RequestContext) {
 	post.MetaTitle = req.MetaTitle
 	post.MetaDescription = req.MetaDescription
 	post.MetaKeywords = req.MetaKeywords
+	if req.AccessRules != nil {
+		post.AccessRules = *req.AccessRules
+	}
 
 	tx := db.Begin()
 
@@ -331,6 +423,8 @@ func (c *PostController) Update(ctx context.Context, hCtx *app.RequestContext) {
 	// 重新加载
 	db.Preload("Author").Preload("Category").Preload("Tags").First(&post, post.ID)
 
+	c.indexPost(&post)
+
 	response.Success(hCtx, post, "Post updated successfully")
 }
 
@@ -361,6 +455,12 @@ func (c *PostController) Delete(ctx context.Context, hCtx *app.RequestContext) {
 		return
 	}
 
+	if c.search != nil {
+		if err := c.search.RemovePost(ctx, post.ID); err != nil {
+			hlog.CtxErrorf(ctx, "failed to remove post %d from search index: %v", post.ID, err)
+		}
+	}
+
 	response.Success(hCtx, nil, "Post deleted successfully")
 }
 
@@ -393,5 +493,131 @@ func (c *PostController) Publish(ctx context.Context, hCtx *app.RequestContext)
 		return
 	}
 
+	c.indexPost(&post)
+
 	response.Success(hCtx, post, "Post published successfully")
 }
+
+// Search 检索文章
+// @Summary      检索文章
+// @Description  检索已发布文章，默认结合关键词与语义向量进行混合排序；未配置AI时退化为关键词检索
+// @Tags         Posts
+// @Accept       json
+// @Produce      json
+// @Param        q query string true "检索关键词"
+// @Param        mode query string false "检索模式" Enums(hybrid, keyword, semantic) default(hybrid)
+// @Param        limit query int false "返回数量" default(10)
+// @Success      200 {object} response.Response{data=[]services.PostSearchResult}
+// @Failure      400 {object} response.Response
+// @Failure      500 {object} response.Response
+// @Security     BearerAuth
+// @Router       /posts/search [get]
+func (c *PostController) Search(ctx context.Context, hCtx *app.RequestContext) {
+	query := string(hCtx.Query("q"))
+	if query == "" {
+		response.BadRequest(hCtx, "Query parameter q is required")
+		return
+	}
+
+	mode := string(hCtx.Query("mode"))
+	switch mode {
+	case "", services.SearchModeHybrid, services.SearchModeKeyword, services.SearchModeSemantic:
+	default:
+		response.BadRequest(hCtx, "Invalid search mode")
+		return
+	}
+	limit, _ := strconv.Atoi(string(hCtx.Query("limit")))
+
+	search := c.search
+	if search == nil {
+		search = services.NewPostSearchService(nil, nil, nil)
+	}
+	if mode == services.SearchModeSemantic && !search.SemanticEnabled() {
+		response.BadRequest(hCtx, "Semantic search is not available")
+		return
+	}
+
+	results, err := search.Search(ctx, query, mode, limit)
+	if err != nil {
+		hlog.CtxErrorf(ctx, "post search failed: %v", err)
+		response.ServerError(hCtx, "Failed to search posts")
+		return
+	}
+
+	refs := make([]*models.Post, len(results))
+	for i := range results {
+		refs[i] = &results[i].Post
+	}
+	views, err := c.postViews(ctx, hCtx, refs)
+	if err != nil {
+		hlog.CtxErrorf(ctx, "post access check failed: %v", err)
+		response.ServerError(hCtx, "Failed to search posts")
+		return
+	}
+
+	output := make([]postSearchView, len(results))
+	for i, result := range results {
+		if views[i].Locked {
+			result.Snippet = ""
+		}
+		output[i] = postSearchView{PostSearchResult: result, Post: views[i]}
+	}
+	response.Success(hCtx, output, "")
+}
+
+// postViews 按读者关联钱包的持仓生成文章视图，不满足访问规则的文章隐藏正文
+// 文章作者和有文章编辑权限的用户不受访问规则限制
+func (c *PostController) postViews(ctx context.Context, hCtx *app.RequestContext, posts []*models.Post) ([]postView, error) {
+	userID, _ := hCtx.Get("user_id")
+	viewer, _ := userID.(uint)
+
+	views := make([]postView, len(posts))
+	var pending []*models.Post
+	var indexes []int
+	for i, post := range posts {
+		views[i].Post = *post
+		if viewer == 0 || post.AuthorID != viewer {
+			pending = append(pending, &views[i].Post)
+			indexes = append(indexes, i)
+		}
+	}
+
+	decisions, err := c.gate.Decide(ctx, viewer, pending)
+	if err != nil {
+		return nil, err
+	}
+
+	checked, editor := false, false
+	for i, decision := range decisions {
+		if !decision.Locked {
+			continue
+		}
+		if viewer != 0 && !checked {
+			editor, checked = userCan(hCtx, "post", "update"), true
+		}
+		if editor {
+			continue
+		}
+		view := &views[indexes[i]]
+		view.Locked = true
+		view.Access = decision
+		view.Content = ""
+	}
+	return views, nil
+}
+
+// indexPost 异步更新文章的向量索引，不阻塞请求
+func (c *PostController) indexPost(post *models.Post) {
+	if c.search == nil || !c.search.SemanticEnabled() {
+		return
+	}
+
+	snapshot := *post
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), postIndexTimeout)
+		defer cancel()
+		if err := c.search.IndexPost(ctx, &snapshot); err != nil {
+			hlog.CtxErrorf(ctx, "failed to index post %d: %v", snapshot.ID, err)
+		}
+	}()
+}
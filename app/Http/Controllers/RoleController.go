@@ -0,0 +1,404 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+	"github.com/clarkzhu2020/aidecms/pkg/response"
+	"github.com/clarkzhu2020/aidecms/pkg/validator"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+// RoleController 角色与权限管理控制器
+type RoleController struct{}
+
+// NewRoleController 创建角色控制器
+func NewRoleController() *RoleController {
+	return &RoleController{}
+}
+
+// CreateRoleRequest 创建角色请求
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	DisplayName string   `json:"display_name" validate:"max=100"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+
+	RequireTwoFactor bool `json:"require_two_factor"`
+}
+
+// UpdateRoleRequest 更新角色请求
+type UpdateRoleRequest struct {
+	DisplayName string `json:"display_name" validate:"max=100"`
+	Description string `json:"description"`
+
+	RequireTwoFactor *bool `json:"require_two_factor"` // 为空时不修改
+}
+
+// SyncPermissionsRequest 同步角色权限请求
+type SyncPermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// CreatePermissionRequest 创建权限请求
+type CreatePermissionRequest struct {
+	Resource    string `json:"resource" validate:"required,max=50"`
+	Action      string `json:"action" validate:"required,max=20"`
+	DisplayName string `json:"display_name" validate:"max=100"`
+	Description string `json:"description"`
+}
+
+// SyncRolesRequest 同步用户角色请求
+type SyncRolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+// ListRoles 获取角色列表
+// @Summary      获取角色列表
+// @Description  获取所有角色及其权限
+// @Tags         Admin
+// @Produce      json
+// @Success      200 {object} response.Response
+// @Security     BearerAuth
+// @Router       /admin/roles [get]
+func (c *RoleController) ListRoles(ctx context.Context, hCtx *app.RequestContext) {
+	roles, err := services.NewPermissionService().ListRoles()
+	if err != nil {
+		response.ServerError(hCtx, "Failed to fetch roles")
+		return
+	}
+
+	response.Success(hCtx, roles, "")
+}
+
+// CreateRole 创建角色
+// @Summary      创建角色
+// @Description  创建自定义角色，可同时指定权限名称
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        role body CreateRoleRequest true "角色信息"
+// @Success      201 {object} response.Response
+// @Failure      400 {object} response.Response
+// @Security     BearerAuth
+// @Router       /admin/roles [post]
+func (c *RoleController) CreateRole(ctx context.Context, hCtx *app.RequestContext) {
+	var req CreateRoleRequest
+	if !bindAndValidate(hCtx, &req) {
+		return
+	}
+
+	permService := services.NewPermissionService()
+	if _, err := permService.GetRoleByName(req.Name); err == nil {
+		response.BadRequest(hCtx, "Role already exists")
+		return
+	}
+
+	role := &models.Role{
+		Name:             req.Name,
+		DisplayName:      req.DisplayName,
+		Description:      req.Description,
+		RequireTwoFactor: req.RequireTwoFactor,
+	}
+	if err := permService.CreateRole(role); err != nil {
+		response.ServerError(hCtx, "Failed to create role")
+		return
+	}
+
+	created, err := permService.SyncRolePermissionsByName(role.ID, req.Permissions)
+	if err != nil {
+		permService.DeleteRole(role.ID)
+		roleError(hCtx, err)
+		return
+	}
+
+	response.Created(hCtx, created, "Role created successfully")
+}
+
+// UpdateRole 更新角色
+// @Summary      更新角色
+// @Description  更新角色的显示名称、描述和是否强制两步验证
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "角色ID"
+// @Param        role body UpdateRoleRequest true "角色信息"
+// @Success      200 {object} response.Response
+// @Failure      404 {object} response.Response
+// @Security     BearerAuth
+// @Router       /admin/roles/{id} [put]
+func (c *RoleController) UpdateRole(ctx context.Context, hCtx *app.RequestContext) {
+	id, ok := paramID(hCtx)
+	if !ok {
+		return
+	}
+
+	var req UpdateRoleRequest
+	if !bindAndValidate(hCtx, &req) {
+		return
+	}
+
+	permService := services.NewPermissionService()
+	role, err := permService.UpdateRole(id, req.DisplayName, req.Description)
+	if err != nil {
+		roleError(hCtx, err)
+		return
+	}
+	if req.RequireTwoFactor != nil {
+		if role, err = permService.SetRoleRequireTwoFactor(id, *req.RequireTwoFactor); err != nil {
+			roleError(hCtx, err)
+			return
+		}
+	}
+
+	response.Success(hCtx, role, "Role updated successfully")
+}
+
+// DeleteRole 删除角色
+// @Summary      删除角色
+// @Description  删除自定义角色，系统角色不能删除
+// @Tags         Admin
+// @Produce      json
+// @Param        id path int true "角色ID"
+// @Success      200 {object} response.Response
+// @Failure      403 {object} response.Response
+// @Security     BearerAuth
+// @Router       /admin/roles/{id} [delete]
+func (c *RoleController) DeleteRole(ctx context.Context, hCtx *app.RequestContext) {
+	id, ok := paramID(hCtx)
+	if !ok {
+		return
+	}
+
+	if err := services.NewPermissionService().DeleteRole(id); err != nil {
+		roleError(hCtx, err)
+		return
+	}
+
+	response.Success(hCtx, nil, "Role deleted successfully")
+}
+
+// SyncPermissions 同步角色权限
+// @Summary      同步角色权限
+// @Description  用给定的权限名称替换角色的所有权限
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "角色ID"
+// @Param        permissions body SyncPermissionsRequest true "权限名称"
+// @Success      200 {object} response.Response
+// @Failure      400 {object} response.Response
+// @Security     BearerAuth
+// @Router       /admin/roles/{id}/permissions [put]
+func (c *RoleController) SyncPermissions(ctx context.Context, hCtx *app.RequestContext) {
+	id, ok := paramID(hCtx)
+	if !ok {
+		return
+	}
+
+	var req SyncPermissionsRequest
+	if !bindAndValidate(hCtx, &req) {
+		return
+	}
+
+	role, err := services.NewPermissionService().SyncRolePermissionsByName(id, req.Permissions)
+	if err != nil {
+		roleError(hCtx, err)
+		return
+	}
+
+	response.Success(hCtx, role, "Role permissions updated successfully")
+}
+
+// ListPermissions 获取权限列表
+// @Summary      获取权限列表
+// @Tags         Admin
+// @Produce      json
+// @Success      200 {object} response.Response
+// @Security     BearerAuth
+// @Router       /admin/permissions [get]
+func (c *RoleController) ListPermissions(ctx context.Context, hCtx *app.RequestContext) {
+	permissions, err := services.NewPermissionService().ListPermissions()
+	if err != nil {
+		response.ServerError(hCtx, "Failed to fetch permissions")
+		return
+	}
+
+	response.Success(hCtx, permissions, "")
+}
+
+// CreatePermission 创建权限
+// @Summary      创建权限
+// @Description  创建自定义权限，名称为 resource.action
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        permission body CreatePermissionRequest true "权限信息"
+// @Success      201 {object} response.Response
+// @Failure      400 {object} response.Response
+// @Security     BearerAuth
+// @Router       /admin/permissions [post]
+func (c *RoleController) CreatePermission(ctx context.Context, hCtx *app.RequestContext) {
+	var req CreatePermissionRequest
+	if !bindAndValidate(hCtx, &req) {
+		return
+	}
+
+	permService := services.NewPermissionService()
+	name := req.Resource + "." + req.Action
+	if _, err := permService.GetPermissionByName(name); err == nil {
+		response.BadRequest(hCtx, "Permission already exists")
+		return
+	}
+
+	permission := &models.Permission{
+		Name:        name,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		Resource:    req.Resource,
+		Action:      req.Action,
+	}
+	if err := permService.CreatePermission(permission); err != nil {
+		response.ServerError(hCtx, "Failed to create permission")
+		return
+	}
+
+	response.Created(hCtx, permission, "Permission created successfully")
+}
+
+// DeletePermission 删除权限
+// @Summary      删除权限
+// @Description  删除自定义权限，系统权限不能删除
+// @Tags         Admin
+// @Produce      json
+// @Param        id path int true "权限ID"
+// @Success      200 {object} response.Response
+// @Failure      403 {object} response.Response
+// @Security     BearerAuth
+// @Router       /admin/permissions/{id} [delete]
+func (c *RoleController) DeletePermission(ctx context.Context, hCtx *app.RequestContext) {
+	id, ok := paramID(hCtx)
+	if !ok {
+		return
+	}
+
+	if err := services.NewPermissionService().DeletePermission(id); err != nil {
+		roleError(hCtx, err)
+		return
+	}
+
+	response.Success(hCtx, nil, "Permission deleted successfully")
+}
+
+// UserRoles 获取用户角色
+// @Summary      获取用户角色
+// @Tags         Admin
+// @Produce      json
+// @Param        id path int true "用户ID"
+// @Success      200 {object} response.Response
+// @Failure      404 {object} response.Response
+// @Security     BearerAuth
+// @Router       /admin/users/{id}/roles [get]
+func (c *RoleController) UserRoles(ctx context.Context, hCtx *app.RequestContext) {
+	id, ok := paramID(hCtx)
+	if !ok {
+		return
+	}
+
+	roles, err := services.NewPermissionService().GetUserRoles(id)
+	if err != nil {
+		roleError(hCtx, err)
+		return
+	}
+
+	response.Success(hCtx, roles, "")
+}
+
+// SyncUserRoles 同步用户角色
+// @Summary      同步用户角色
+// @Description  用给定的角色名称替换用户的所有角色
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "用户ID"
+// @Param        roles body SyncRolesRequest true "角色名称"
+// @Success      200 {object} response.Response
+// @Failure      400 {object} response.Response
+// @Security     BearerAuth
+// @Router       /admin/users/{id}/roles [put]
+func (c *RoleController) SyncUserRoles(ctx context.Context, hCtx *app.RequestContext) {
+	id, ok := paramID(hCtx)
+	if !ok {
+		return
+	}
+
+	var req SyncRolesRequest
+	if !bindAndValidate(hCtx, &req) {
+		return
+	}
+
+	roles, err := services.NewPermissionService().SyncUserRolesByName(id, req.Roles)
+	if err != nil {
+		roleError(hCtx, err)
+		return
+	}
+
+	response.Success(hCtx, roles, "User roles updated successfully")
+}
+
+// bindAndValidate 解析并校验请求体，失败时写入错误响应
+func bindAndValidate(hCtx *app.RequestContext, req interface{}) bool {
+	if err := hCtx.BindJSON(req); err != nil {
+		response.BadRequest(hCtx, "Invalid request data")
+		return false
+	}
+	if err := validator.Validate(req); err != nil {
+		if valErr, ok := err.(*validator.ValidationError); ok {
+			response.ValidationError(hCtx, valErr.Errors)
+			return false
+		}
+		response.BadRequest(hCtx, err.Error())
+		return false
+	}
+	return true
+}
+
+// paramID 解析路由参数 :id，失败时写入错误响应
+func paramID(hCtx *app.RequestContext) (uint, bool) {
+	id, err := strconv.ParseUint(hCtx.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		response.BadRequest(hCtx, "Invalid id")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// roleError 将权限服务的错误转换为响应
+func roleError(hCtx *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.NotFound(hCtx, "Not found")
+	case errors.Is(err, services.ErrSystemRole):
+		response.Forbidden(hCtx, err.Error())
+	case errors.Is(err, services.ErrUnknownPermission):
+		response.BadRequest(hCtx, err.Error())
+	default:
+		response.ServerError(hCtx, err.Error())
+	}
+}
+
+// userCan 当前用户是否拥有 resource.action 权限，用于路由授权之外的字段级检查
+func userCan(hCtx *app.RequestContext, resource, action string) bool {
+	userID, _ := hCtx.Get("user_id")
+	id, _ := userID.(uint)
+	var scopes []string
+	if v, ok := hCtx.Get(services.TokenScopesKey); ok {
+		scopes, _ = v.([]string)
+	}
+	allowed, err := services.NewPermissionService().AuthorizeScoped(id, scopes, resource, action, "", 0)
+	return err == nil && allowed
+}
@@ -21,12 +21,17 @@ func NewSEOController(baseURL string) *SEOController {
 	db := database.GetDB()
 
 	return &SEOController{
-		sitemapGen:  seo.NewSitemapGenerator(baseURL, db),
+		sitemapGen:  seo.NewSitemapGenerator(db),
 		robotsTxt:   seo.DefaultRobotsTxt(baseURL + "/sitemap.xml"),
 		redirectMgr: seo.NewRedirectManager(db),
 	}
 }
 
+// SetURLGenerator 设置链接生成器，sitemap 按路由名称生成链接
+func (c *SEOController) SetURLGenerator(urls seo.URLGenerator) {
+	c.sitemapGen.SetURLGenerator(urls)
+}
+
 // Sitemap 生成sitemap.xml
 // @Summary      生成sitemap
 // @Description  生成站点地图XML
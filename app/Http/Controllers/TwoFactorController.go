@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+)
+
+// TwoFactorController 两步验证管理控制器
+// 只接受登录会话（JWT），个人访问令牌不能修改两步验证设置
+type TwoFactorController struct {
+	TwoFactor *services.TwoFactorService
+}
+
+// NewTwoFactorController 创建两步验证控制器
+func NewTwoFactorController() *TwoFactorController {
+	return &TwoFactorController{
+		TwoFactor: services.NewTwoFactorService(),
+	}
+}
+
+// 两步验证码请求
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"` // TOTP 验证码或恢复码
+}
+
+// Show 获取两步验证状态
+func (c *TwoFactorController) Show(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+
+	enabled, err := c.TwoFactor.Enabled(userID)
+	if err != nil {
+		twoFactorError(reqCtx, err)
+		return
+	}
+	required, err := c.TwoFactor.Required(userID)
+	if err != nil {
+		twoFactorError(reqCtx, err)
+		return
+	}
+	remaining, err := c.TwoFactor.RemainingRecoveryCodes(userID)
+	if err != nil {
+		twoFactorError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"enabled":                  enabled,
+		"required":                 required,
+		"recovery_codes_remaining": remaining,
+	})
+}
+
+// Enable 开始绑定，返回密钥和 otpauth URI，需要再调用 Confirm 提交验证码
+func (c *TwoFactorController) Enable(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+
+	setup, err := c.TwoFactor.Begin(userID)
+	if err != nil {
+		twoFactorError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, setup)
+}
+
+// Confirm 提交验证器中的验证码，开启两步验证并返回只显示一次的恢复码
+func (c *TwoFactorController) Confirm(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+	code, ok := twoFactorCode(reqCtx)
+	if !ok {
+		return
+	}
+
+	codes, err := c.TwoFactor.Confirm(userID, code)
+	if err != nil {
+		twoFactorError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"message":        "两步验证已开启，请妥善保存恢复码",
+		"recovery_codes": codes,
+	})
+}
+
+// Disable 关闭两步验证，角色要求两步验证时不能关闭
+func (c *TwoFactorController) Disable(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+	code, ok := twoFactorCode(reqCtx)
+	if !ok {
+		return
+	}
+
+	if err := c.TwoFactor.Disable(userID, code); err != nil {
+		twoFactorError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"message": "两步验证已关闭",
+	})
+}
+
+// RecoveryCodes 重新生成恢复码，旧的恢复码全部失效
+func (c *TwoFactorController) RecoveryCodes(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+	code, ok := twoFactorCode(reqCtx)
+	if !ok {
+		return
+	}
+
+	codes, err := c.TwoFactor.RegenerateRecoveryCodes(userID, code)
+	if err != nil {
+		twoFactorError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"recovery_codes": codes,
+	})
+}
+
+// twoFactorCode 解析请求中的验证码，失败时写入400响应
+func twoFactorCode(reqCtx *framework.RequestContext) (string, bool) {
+	var req TwoFactorCodeRequest
+	if err := reqCtx.BindJSON(&req); err != nil || req.Code == "" {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return "", false
+	}
+	return req.Code, true
+}
+
+// twoFactorError 将两步验证服务的错误转换为响应，连续输错被锁定时返回 429
+func twoFactorError(reqCtx *framework.RequestContext, err error) {
+	if tooManyAttempts(reqCtx, err) {
+		return
+	}
+
+	status := 500
+	switch {
+	case errors.Is(err, services.ErrTwoFactorCode):
+		status = 422
+	case errors.Is(err, services.ErrTwoFactorEnabled), errors.Is(err, services.ErrTwoFactorNotEnabled):
+		status = 409
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		status = 403
+	}
+	reqCtx.JSON(status, map[string]interface{}{
+		"error": err.Error(),
+	})
+}
@@ -2,9 +2,16 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
 
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
 	"github.com/clarkzhu2020/aidecms/internal/app/services"
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
 	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -35,6 +42,49 @@ type LoginRequest struct {
 	Password        string `json:"password" binding:"required"`
 }
 
+// 刷新令牌请求
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// 两步验证登录请求
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP 验证码或恢复码
+}
+
+// 登录过程中开始绑定两步验证请求
+type LoginMFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// 忘记密码和重新发送验证邮件请求
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// 重置密码请求
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// 验证邮箱请求
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// 退出登录请求
+type LogoutRequest struct {
+	All bool `json:"all"` // 注销所有设备上的会话
+}
+
+// 修改密码请求
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
 // 更新用户资料请求
 type UpdateProfileRequest struct {
 	FirstName string `json:"first_name"`
@@ -61,8 +111,21 @@ func (c *UserController) Register(ctx context.Context, reqCtx *framework.Request
 		return
 	}
 
-	// 生成JWT令牌
-	token, err := c.UserService.GenerateJWT(user.ID)
+	// 发送邮箱验证邮件
+	go c.sendMail("verification", func() error { return c.UserService.SendVerificationEmail(user.Email) })
+
+	// 要求验证邮箱时，验证后才能登录
+	if c.UserService.Auth != nil && c.UserService.Auth.RequireVerifiedEmail {
+		reqCtx.JSON(201, map[string]interface{}{
+			"user":           user.ToProfile(),
+			"email_verified": false,
+			"message":        "注册成功，请查收验证邮件",
+		})
+		return
+	}
+
+	// 签发令牌
+	tokens, err := c.UserService.IssueTokens(ctx, user.ID, sessionMeta(reqCtx))
 	if err != nil {
 		reqCtx.JSON(500, map[string]interface{}{
 			"error": "生成令牌失败",
@@ -71,10 +134,7 @@ func (c *UserController) Register(ctx context.Context, reqCtx *framework.Request
 	}
 
 	// 返回用户信息和令牌
-	reqCtx.JSON(201, map[string]interface{}{
-		"user":  user.ToProfile(),
-		"token": token,
-	})
+	reqCtx.JSON(201, tokenResponse(user, tokens))
 }
 
 // Login 用户登录
@@ -88,18 +148,250 @@ func (c *UserController) Login(ctx context.Context, reqCtx *framework.RequestCon
 	}
 
 	// 调用用户服务登录
-	user, token, err := c.UserService.Login(req.UsernameOrEmail, req.Password)
+	user, tokens, err := c.UserService.Login(ctx, req.UsernameOrEmail, req.Password, sessionMeta(reqCtx))
+	loginResponse(reqCtx, c.UserService, user, tokens, err)
+}
+
+// LoginMFA 提交两步验证码完成登录
+// 登录过程中绑定两步验证的用户，响应中包含只显示一次的恢复码
+func (c *UserController) LoginMFA(ctx context.Context, reqCtx *framework.RequestContext) {
+	var req LoginMFARequest
+	if err := reqCtx.BindJSON(&req); err != nil || req.MFAToken == "" || req.Code == "" {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return
+	}
+
+	user, tokens, recoveryCodes, err := c.UserService.VerifyMFA(ctx, req.MFAToken, req.Code, sessionMeta(reqCtx))
+	if tooManyAttempts(reqCtx, err) {
+		return
+	}
 	if err != nil {
+		status := 500
+		switch {
+		case errors.Is(err, services.ErrTwoFactorCode), errors.Is(err, services.ErrTwoFactorNotEnabled):
+			status = 422
+		case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrTokenRevoked):
+			status = 401
+		}
+		reqCtx.JSON(status, map[string]interface{}{
+			"error": "两步验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	response := tokenResponse(user, tokens)
+	if recoveryCodes != nil {
+		response["recovery_codes"] = recoveryCodes
+	}
+	reqCtx.JSON(200, response)
+}
+
+// LoginMFAEnroll 角色要求两步验证但尚未开启的用户，在登录过程中获取绑定密钥
+func (c *UserController) LoginMFAEnroll(ctx context.Context, reqCtx *framework.RequestContext) {
+	var req LoginMFAEnrollRequest
+	if err := reqCtx.BindJSON(&req); err != nil || req.MFAToken == "" {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return
+	}
+
+	setup, err := c.UserService.BeginMFAEnrollment(ctx, req.MFAToken)
+	if err != nil {
+		status := 500
+		switch {
+		case errors.Is(err, services.ErrTwoFactorEnabled):
+			status = 409
+		case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrTokenRevoked):
+			status = 401
+		}
+		reqCtx.JSON(status, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	reqCtx.JSON(200, setup)
+}
+
+// ForgotPassword 发送密码重置邮件
+// 无论邮箱是否注册都返回相同的响应，避免泄露账户是否存在
+func (c *UserController) ForgotPassword(ctx context.Context, reqCtx *framework.RequestContext) {
+	var req EmailRequest
+	if err := reqCtx.BindJSON(&req); err != nil || req.Email == "" {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return
+	}
+
+	go c.sendMail("password reset", func() error { return c.UserService.SendPasswordReset(req.Email) })
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"message": "如果该邮箱已注册，您将收到重置密码的邮件",
+	})
+}
+
+// ResetPassword 使用邮件中的令牌设置新密码，所有会话随之失效
+func (c *UserController) ResetPassword(ctx context.Context, reqCtx *framework.RequestContext) {
+	var req ResetPasswordRequest
+	if err := reqCtx.BindJSON(&req); err != nil || req.Token == "" || len(req.Password) < 6 {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return
+	}
+
+	if err := c.UserService.ResetPassword(ctx, req.Token, req.Password); err != nil {
+		linkError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"message": "密码已重置，请使用新密码登录",
+	})
+}
+
+// VerifyEmail 使用邮件中的令牌验证邮箱
+func (c *UserController) VerifyEmail(ctx context.Context, reqCtx *framework.RequestContext) {
+	var req VerifyEmailRequest
+	if err := reqCtx.BindJSON(&req); err != nil || req.Token == "" {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return
+	}
+
+	user, err := c.UserService.VerifyEmail(req.Token)
+	if err != nil {
+		linkError(reqCtx, err)
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"message": "邮箱已验证",
+		"user":    user.ToProfile(),
+	})
+}
+
+// ResendVerification 重新发送邮箱验证邮件，未验证邮箱的用户可能无法登录，因此不需要认证
+func (c *UserController) ResendVerification(ctx context.Context, reqCtx *framework.RequestContext) {
+	var req EmailRequest
+	if err := reqCtx.BindJSON(&req); err != nil || req.Email == "" {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return
+	}
+
+	go c.sendMail("verification", func() error { return c.UserService.SendVerificationEmail(req.Email) })
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"message": "如果该邮箱已注册且尚未验证，您将收到验证邮件",
+	})
+}
+
+// Refresh 使用刷新令牌换取新的访问令牌和刷新令牌
+func (c *UserController) Refresh(ctx context.Context, reqCtx *framework.RequestContext) {
+	var req RefreshRequest
+	if err := reqCtx.BindJSON(&req); err != nil || req.RefreshToken == "" {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return
+	}
+
+	tokens, err := c.UserService.RefreshTokens(ctx, req.RefreshToken, sessionMeta(reqCtx))
+	if err != nil {
+		status := 500
+		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrTokenRevoked) || errors.Is(err, auth.ErrRefreshTokenReused) {
+			status = 401
+		}
+		reqCtx.JSON(status, map[string]interface{}{
+			"error": "刷新令牌失败: " + err.Error(),
+		})
+		return
+	}
+
+	reqCtx.JSON(200, tokens)
+}
+
+// JWKS 发布访问令牌的校验公钥（/.well-known/jwks.json）
+func (c *UserController) JWKS(ctx context.Context, reqCtx *framework.RequestContext) {
+	jwks, err := c.UserService.JWKS()
+	if err != nil {
+		reqCtx.JSON(503, map[string]interface{}{
+			"error": "获取密钥失败: " + err.Error(),
+		})
+		return
+	}
+
+	reqCtx.SetHeader("Cache-Control", "public, max-age=300")
+	reqCtx.JSON(200, jwks)
+}
+
+// Logout 退出登录，注销当前会话；all 为true时注销所有会话
+func (c *UserController) Logout(ctx context.Context, reqCtx *framework.RequestContext) {
+	claims, ok := currentClaims(reqCtx)
+	if !ok {
 		reqCtx.JSON(401, map[string]interface{}{
+			"error": "未授权",
+		})
+		return
+	}
+
+	// 请求体可选
+	var req LogoutRequest
+	if len(reqCtx.Request.Body()) > 0 {
+		if err := reqCtx.BindJSON(&req); err != nil {
+			reqCtx.JSON(400, map[string]interface{}{
+				"error": "无效的请求数据",
+			})
+			return
+		}
+	}
+
+	if err := c.UserService.Logout(ctx, claims, req.All); err != nil {
+		reqCtx.JSON(500, map[string]interface{}{
+			"error": "退出登录失败",
+		})
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"message": "已退出登录",
+	})
+}
+
+// ChangePassword 修改密码，所有会话（包括当前会话）随之失效
+func (c *UserController) ChangePassword(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, exists := reqCtx.Get("user_id")
+	if !exists {
+		reqCtx.JSON(401, map[string]interface{}{
+			"error": "未授权",
+		})
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := reqCtx.BindJSON(&req); err != nil || len(req.NewPassword) < 6 {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return
+	}
+
+	if err := c.UserService.ChangePassword(ctx, userID.(uint), req.CurrentPassword, req.NewPassword); err != nil {
+		reqCtx.JSON(400, map[string]interface{}{
 			"error": err.Error(),
 		})
 		return
 	}
 
-	// 返回用户信息和令牌
 	reqCtx.JSON(200, map[string]interface{}{
-		"user":  user.ToProfile(),
-		"token": token,
+		"message": "密码已修改，请重新登录",
 	})
 }
 
@@ -163,6 +455,137 @@ func (c *UserController) UpdateProfile(ctx context.Context, reqCtx *framework.Re
 	})
 }
 
+// sessionMeta 从请求中提取会话的客户端信息
+func sessionMeta(reqCtx *framework.RequestContext) auth.SessionMeta {
+	return auth.SessionMeta{
+		UserAgent: string(reqCtx.UserAgent()),
+		IP:        reqCtx.ClientIP(),
+	}
+}
+
+// currentClaims 获取认证中间件保存的访问令牌声明
+func currentClaims(reqCtx *framework.RequestContext) (*auth.Claims, bool) {
+	value, exists := reqCtx.Get(auth.ClaimsKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*auth.Claims)
+	return claims, ok
+}
+
+// sendMail 在后台发送账户邮件，响应时间不受邮件服务影响，也不会暴露账户是否存在
+func (c *UserController) sendMail(kind string, send func() error) {
+	if err := send(); err != nil {
+		hlog.Errorf("failed to send %s email: %v", kind, err)
+	}
+}
+
+// linkError 将密码重置和邮箱验证令牌的错误转换为响应
+func linkError(reqCtx *framework.RequestContext, err error) {
+	switch {
+	case errors.Is(err, auth.ErrTokenExpired):
+		reqCtx.JSON(410, map[string]interface{}{
+			"error": "链接已过期，请重新申请",
+		})
+	case errors.Is(err, auth.ErrInvalidToken):
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "链接无效或已使用",
+		})
+	default:
+		reqCtx.JSON(500, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// loginResponse 登录结果的响应，密码登录和第三方登录共用
+// 需要两步验证时返回待完成令牌，被限制时返回 429 和 Retry-After
+func loginResponse(reqCtx *framework.RequestContext, users *services.UserService, user *models.User, tokens *auth.TokenPair, err error) {
+	if errors.Is(err, services.ErrMFARequired) || errors.Is(err, services.ErrMFAEnrollRequired) {
+		mfaChallenge(reqCtx, users, user, errors.Is(err, services.ErrMFAEnrollRequired))
+		return
+	}
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		reqCtx.JSON(403, map[string]interface{}{
+			"error":          err.Error(),
+			"email_verified": false,
+		})
+		return
+	}
+	if tooManyAttempts(reqCtx, err) {
+		return
+	}
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		reqCtx.JSON(401, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		reqCtx.JSON(500, map[string]interface{}{
+			"error": "登录失败",
+		})
+		return
+	}
+
+	// 返回用户信息和令牌
+	reqCtx.JSON(200, tokenResponse(user, tokens))
+}
+
+// tooManyAttempts 登录或两步验证被限制时返回 429，Retry-After 头和 retry_after 字段为需要等待的秒数
+func tooManyAttempts(reqCtx *framework.RequestContext, err error) bool {
+	var wait time.Duration
+	var throttled *services.LoginThrottledError
+	var locked *services.TwoFactorLockedError
+	switch {
+	case errors.As(err, &throttled):
+		wait = throttled.RetryAfter
+	case errors.As(err, &locked):
+		wait = locked.RetryAfter
+	default:
+		return false
+	}
+
+	retryAfter := int64(math.Ceil(wait.Seconds()))
+	reqCtx.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	reqCtx.JSON(429, map[string]interface{}{
+		"error":       err.Error(),
+		"retry_after": retryAfter,
+	})
+	return true
+}
+
+// mfaChallenge 身份验证通过但需要两步验证时的响应，不包含访问令牌
+func mfaChallenge(reqCtx *framework.RequestContext, users *services.UserService, user *models.User, enroll bool) {
+	mfaToken, expiresIn, err := users.MFAChallenge(user.ID)
+	if err != nil {
+		reqCtx.JSON(500, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	reqCtx.JSON(200, map[string]interface{}{
+		"mfa_required":    true,
+		"enroll_required": enroll,
+		"mfa_token":       mfaToken,
+		"expires_in":      expiresIn,
+	})
+}
+
+// tokenResponse 登录和注册的响应，token 字段保留给只读取访问令牌的旧客户端
+func tokenResponse(user *models.User, tokens *auth.TokenPair) map[string]interface{} {
+	return map[string]interface{}{
+		"user":               user.ToProfile(),
+		"token":              tokens.AccessToken,
+		"access_token":       tokens.AccessToken,
+		"refresh_token":      tokens.RefreshToken,
+		"token_type":         tokens.TokenType,
+		"expires_in":         tokens.ExpiresIn,
+		"refresh_expires_in": tokens.RefreshExpiresIn,
+	}
+}
+
 func hashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"github.com/clarkzhu2020/aidecms/pkg/web3"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// WalletController 以太坊钱包登录（Sign-In With Ethereum）控制器
+type WalletController struct {
+	SIWE        *services.SIWEService
+	UserService *services.UserService
+}
+
+// NewWalletController 创建钱包登录控制器，登录成功后通过 users 签发令牌
+func NewWalletController(users *services.UserService) *WalletController {
+	return &WalletController{
+		SIWE:        services.NewSIWEService(),
+		UserService: users,
+	}
+}
+
+// 钱包签名请求
+type SIWERequest struct {
+	Message   string `json:"message" binding:"required"`   // 用户签名的 EIP-4361 消息原文
+	Signature string `json:"signature" binding:"required"` // personal_sign 签名，0x 开头的十六进制
+}
+
+// Nonce 签发登录用的 nonce 和组装消息需要的参数
+func (c *WalletController) Nonce(ctx context.Context, reqCtx *framework.RequestContext) {
+	challenge, err := c.SIWE.Nonce(0)
+	if err != nil {
+		siweError(reqCtx, err)
+		return
+	}
+	reqCtx.JSON(200, challenge)
+}
+
+// Verify 校验签名并登录，返回与 /login 相同的响应
+func (c *WalletController) Verify(ctx context.Context, reqCtx *framework.RequestContext) {
+	req, ok := bindSIWERequest(reqCtx)
+	if !ok {
+		return
+	}
+
+	result, err := c.SIWE.Verify(ctx, 0, req.Message, req.Signature)
+	if err != nil {
+		siweError(reqCtx, err)
+		return
+	}
+
+	user, tokens, err := c.UserService.LoginUser(ctx, result.User, sessionMeta(reqCtx))
+	loginResponse(reqCtx, c.UserService, user, tokens, err)
+}
+
+// Wallets 当前用户关联的钱包
+func (c *WalletController) Wallets(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+
+	wallets, err := c.SIWE.Wallets(userID)
+	if err != nil {
+		siweError(reqCtx, err)
+		return
+	}
+	reqCtx.JSON(200, map[string]interface{}{
+		"wallets": wallets,
+	})
+}
+
+// LinkNonce 签发当前用户关联钱包用的 nonce
+func (c *WalletController) LinkNonce(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+
+	challenge, err := c.SIWE.Nonce(userID)
+	if err != nil {
+		siweError(reqCtx, err)
+		return
+	}
+	reqCtx.JSON(200, challenge)
+}
+
+// Link 校验签名并为当前用户关联钱包
+func (c *WalletController) Link(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+	req, ok := bindSIWERequest(reqCtx)
+	if !ok {
+		return
+	}
+
+	result, err := c.SIWE.Verify(ctx, userID, req.Message, req.Signature)
+	if err != nil {
+		siweError(reqCtx, err)
+		return
+	}
+	reqCtx.JSON(200, map[string]interface{}{
+		"message": "已关联钱包",
+		"address": result.Address,
+	})
+}
+
+// Unlink 解除当前用户与钱包的关联
+func (c *WalletController) Unlink(ctx context.Context, reqCtx *framework.RequestContext) {
+	userID, ok := sessionUserID(reqCtx)
+	if !ok {
+		return
+	}
+
+	if err := c.SIWE.Unlink(userID, reqCtx.GetParam("address")); err != nil {
+		siweError(reqCtx, err)
+		return
+	}
+	reqCtx.JSON(200, map[string]interface{}{
+		"message": "已解除关联",
+	})
+}
+
+// bindSIWERequest 解析签名请求，失败时写入400响应
+func bindSIWERequest(reqCtx *framework.RequestContext) (*SIWERequest, bool) {
+	var req SIWERequest
+	if err := reqCtx.BindJSON(&req); err != nil || req.Message == "" || req.Signature == "" {
+		reqCtx.JSON(400, map[string]interface{}{
+			"error": "无效的请求数据",
+		})
+		return nil, false
+	}
+	return &req, true
+}
+
+// siweError 将钱包登录的错误转换为响应
+func siweError(reqCtx *framework.RequestContext, err error) {
+	status := 500
+	message := err.Error()
+	switch {
+	case errors.Is(err, web3.ErrSIWEInvalidMessage), errors.Is(err, services.ErrSIWENonce):
+		status = 400
+	case errors.Is(err, web3.ErrSIWEInvalidSignature), errors.Is(err, web3.ErrSIWEDomainMismatch),
+		errors.Is(err, web3.ErrSIWEChainNotAllowed), errors.Is(err, web3.ErrSIWEExpired):
+		status = 401
+	case errors.Is(err, services.ErrSIWENoAccount):
+		status = 403
+	case errors.Is(err, services.ErrWalletNotFound):
+		status = 404
+	case errors.Is(err, services.ErrWalletLinked), errors.Is(err, services.ErrLastWallet):
+		status = 409
+	case errors.Is(err, services.ErrSIWEUnavailable):
+		status = 503
+	default:
+		hlog.Errorf("wallet login failed: %v", err)
+		message = "钱包登录失败"
+	}
+	reqCtx.JSON(status, map[string]interface{}{
+		"error": message,
+	})
+}
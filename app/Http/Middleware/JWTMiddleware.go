@@ -4,14 +4,15 @@ import (
 	"context"
 	"strings"
 
+	"github.com/clarkzhu2020/aidecms/config"
 	"github.com/clarkzhu2020/aidecms/internal/app/services"
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
 	"github.com/clarkzhu2020/aidecms/pkg/framework"
 )
 
 // JWTMiddleware JWT认证中间件
+// 令牌由全局令牌管理器校验，注销的令牌通过其拒绝列表检查
 func JWTMiddleware() framework.HandlerFunc {
-	userService := services.NewUserService()
-
 	return func(ctx context.Context, reqCtx *framework.RequestContext) {
 		// 获取Authorization头
 		authHeader := reqCtx.GetHeader("Authorization")
@@ -36,8 +37,17 @@ func JWTMiddleware() framework.HandlerFunc {
 		// 提取令牌
 		tokenString := tokenParts[1]
 
-		// 解析令牌
-		userID, err := userService.ParseJWT(tokenString)
+		tokens, err := config.LoadTokenManager()
+		if err != nil {
+			reqCtx.JSON(503, map[string]interface{}{
+				"error": services.ErrTokenServiceUnavailable.Error(),
+			})
+			reqCtx.Abort()
+			return
+		}
+
+		// 解析令牌（包括注销检查）
+		claims, err := tokens.Parse(ctx, tokenString)
 		if err != nil {
 			reqCtx.JSON(401, map[string]interface{}{
 				"error": "无效的令牌: " + err.Error(),
@@ -46,10 +56,55 @@ func JWTMiddleware() framework.HandlerFunc {
 			return
 		}
 
-		// 将用户ID添加到上下文
-		reqCtx.Set("user_id", userID)
+		// 将用户ID和令牌声明添加到上下文
+		reqCtx.Set("user_id", claims.UserID)
+		reqCtx.Set(auth.ClaimsKey, claims)
 
 		// 继续处理请求
 		reqCtx.Next(ctx)
 	}
 }
+
+// AuthMiddleware 同时接受 JWT 和个人访问令牌（acms_ 前缀）的认证中间件
+// 使用个人访问令牌时，上下文中的 services.TokenScopesKey 保存令牌的权限范围，
+// 权限中间件只允许范围内的操作。令牌管理、修改密码等账户操作应继续使用 JWTMiddleware
+func AuthMiddleware() framework.HandlerFunc {
+	jwtAuth := JWTMiddleware()
+	tokenService := services.NewAccessTokenService()
+
+	return func(ctx context.Context, reqCtx *framework.RequestContext) {
+		tokenString, ok := strings.CutPrefix(reqCtx.GetHeader("Authorization"), "Bearer ")
+		if !ok || !strings.HasPrefix(tokenString, services.AccessTokenPrefix) {
+			jwtAuth(ctx, reqCtx)
+			return
+		}
+
+		token, err := tokenService.Authenticate(tokenString, reqCtx.ClientIP())
+		if err != nil {
+			reqCtx.JSON(401, map[string]interface{}{
+				"error": "无效的令牌: " + err.Error(),
+			})
+			reqCtx.Abort()
+			return
+		}
+
+		reqCtx.Set("user_id", token.UserID)
+		reqCtx.Set(services.TokenScopesKey, token.ScopeList())
+
+		reqCtx.Next(ctx)
+	}
+}
+
+// OptionalAuthMiddleware 可选认证中间件，用于公开但内容因人而异的路由
+// 没有 Authorization 头时按未登录处理；带有令牌时按 AuthMiddleware 校验，无效的令牌仍返回401
+func OptionalAuthMiddleware() framework.HandlerFunc {
+	auth := AuthMiddleware()
+
+	return func(ctx context.Context, reqCtx *framework.RequestContext) {
+		if reqCtx.GetHeader("Authorization") == "" {
+			reqCtx.Next(ctx)
+			return
+		}
+		auth(ctx, reqCtx)
+	}
+}
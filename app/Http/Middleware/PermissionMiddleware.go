@@ -65,7 +65,14 @@ func PermissionMiddleware(permissionName string) framework.HandlerFunc {
 }
 
 // ResourcePermissionMiddleware 资源权限检查中间件
+// 没有 resource.action 权限时，按路由参数 :id 交给同名资源策略判断（如作者只能修改自己的文章）
 func ResourcePermissionMiddleware(resource, action string) framework.HandlerFunc {
+	return PolicyMiddleware(resource, action, resource)
+}
+
+// PolicyMiddleware 与 ResourcePermissionMiddleware 相同，但 :id 指向的资源由 policy 策略解析
+// 例如 /ai/suggestions/:id 检查 post.update 权限，所有者是建议所属文章的作者
+func PolicyMiddleware(resource, action, policy string) framework.HandlerFunc {
 	return func(ctx context.Context, reqCtx *framework.RequestContext) {
 		userIDStr, exists := reqCtx.Get("user_id")
 		if !exists {
@@ -88,8 +95,17 @@ func ResourcePermissionMiddleware(resource, action string) framework.HandlerFunc
 			return
 		}
 
+		// 非数字的 id 不参与策略判断
+		resourceID, _ := strconv.ParseUint(reqCtx.GetParam("id"), 10, 32)
+
+		// 个人访问令牌只能使用其权限范围内的权限
+		var scopes []string
+		if v, ok := reqCtx.Get(services.TokenScopesKey); ok {
+			scopes, _ = v.([]string)
+		}
+
 		permService := services.NewPermissionService()
-		hasPermission, err := permService.CheckUserResourcePermission(uint(userID), resource, action)
+		hasPermission, err := permService.AuthorizeScoped(uint(userID), scopes, resource, action, policy, uint(resourceID))
 		if err != nil {
 			reqCtx.JSON(500, map[string]interface{}{
 				"success": false,
@@ -48,6 +48,14 @@ func HandleAICommand(args []string) {
 		cmd.Test(subArgs)
 	case "config":
 		cmd.Config(subArgs)
+	case "usage":
+		cmd.Usage(subArgs)
+	case "reindex":
+		cmd.Reindex(subArgs)
+	case "suggest":
+		cmd.Suggest(subArgs)
+	case "backfill":
+		cmd.Backfill(subArgs)
 	default:
 		fmt.Printf("Unknown AI command: %s\n", subCommand)
 		cmd.showHelp()
@@ -469,12 +477,19 @@ func (c *AICommand) showHelp() {
 	fmt.Println("  ai:models                                         - List available models")
 	fmt.Println("  ai:test [model]                                   - Test AI connection")
 	fmt.Println("  ai:config <action> [args...]                     - Manage configurations")
+	fmt.Println("  ai:usage [--period=today|month|all] [--user=ID]  - Token usage and cost report")
+	fmt.Println("  ai:reindex [--client=name]                       - Rebuild post semantic search index")
+	fmt.Println("  ai:suggest <post_id> [--action=...] [--save]     - Generate editorial suggestions for a post")
+	fmt.Println("  ai:backfill [--action=...] [--missing] [--queue] - Generate suggestions for posts in bulk")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  ai:setup openai sk-xxx gpt-4")
 	fmt.Println("  ai:chat \"Hello, how are you?\" openai")
 	fmt.Println("  ai:completion \"Write a poem about\" openai 0.8 500")
 	fmt.Println("  ai:test openai")
+	fmt.Println("  ai:usage --period=today --group=user,model")
+	fmt.Println("  ai:suggest 12 --action=translation --lang=en --save")
+	fmt.Println("  ai:backfill --action=excerpt,seo --status=published --missing --limit=100")
 }
 
 // parseFloat 解析浮点数
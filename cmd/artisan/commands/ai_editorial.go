@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+)
+
+// Suggest 为单篇文章生成编辑建议
+func (c *AICommand) Suggest(args []string) {
+	if len(args) < 1 || strings.HasPrefix(args[0], "--") {
+		fmt.Println("Usage: ai:suggest <post_id> [--action=excerpt,seo,tags,translation] [--lang=en] [--client=name] [--save]")
+		return
+	}
+	options := parseUsageOptions(args[1:])
+
+	postID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		fmt.Printf("Error: Invalid post ID: %s\n", args[0])
+		return
+	}
+	actions, err := parseEditorialOptions(options)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	editorial, err := newEditorialService(options["client"])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	var post models.Post
+	if err := config.DB.First(&post, postID).Error; err != nil {
+		fmt.Printf("Error: Post %d not found\n", postID)
+		return
+	}
+
+	ctx := context.Background()
+	fmt.Printf("Generating %s suggestions for #%d %s...\n", strings.Join(actions, ", "), post.ID, post.Title)
+	suggestion, err := editorial.Suggest(ctx, &post, actions, options["lang"], 0)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	output, _ := json.MarshalIndent(suggestion, "", "  ")
+	fmt.Println(string(output))
+
+	if _, save := options["save"]; save {
+		saved, err := editorial.SaveSuggestion(ctx, suggestion, 0)
+		if err != nil {
+			fmt.Printf("Error: Failed to save suggestions: %v\n", err)
+			return
+		}
+		fmt.Printf("\nSaved %d suggestions for review\n", len(saved))
+	}
+}
+
+// Backfill 批量为文章生成编辑建议并保存为待审核建议
+func (c *AICommand) Backfill(args []string) {
+	options := parseUsageOptions(args)
+
+	actions, err := parseEditorialOptions(options)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	filter := services.BackfillFilter{Status: options["status"]}
+	if _, missing := options["missing"]; missing {
+		filter.OnlyMissing = true
+	}
+	if value := options["limit"]; value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			fmt.Printf("Error: Invalid limit: %s\n", value)
+			return
+		}
+	}
+
+	editorial, err := newEditorialService(options["client"])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	ctx := context.Background()
+	ids, err := editorial.BackfillPosts(ctx, actions, filter)
+	if err != nil {
+		fmt.Printf("Error: Failed to fetch posts: %v\n", err)
+		return
+	}
+	if len(ids) == 0 {
+		fmt.Println("No posts need suggestions")
+		return
+	}
+
+	// 放入队列，由 queue:work 处理
+	if _, useQueue := options["queue"]; useQueue {
+		if config.QueueDriver() == "memory" {
+			fmt.Println("Error: --queue requires a shared queue driver (QUEUE_DRIVER=redis)")
+			return
+		}
+		q, err := config.LoadQueue()
+		if err != nil {
+			fmt.Printf("Error: Failed to load queue: %v\n", err)
+			return
+		}
+		queued := 0
+		for _, id := range ids {
+			if err := q.Push(services.NewPostEditorialJob(id, actions, options["lang"], 0)); err != nil {
+				fmt.Printf("  ✗ #%d: %v\n", id, err)
+				continue
+			}
+			queued++
+		}
+		fmt.Printf("Queued %d of %d posts, run queue:work to process them\n", queued, len(ids))
+		return
+	}
+
+	fmt.Printf("Generating %s suggestions for %d posts...\n", strings.Join(actions, ", "), len(ids))
+	start := time.Now()
+	failed := 0
+	for _, id := range ids {
+		job := services.NewPostEditorialJob(id, actions, options["lang"], 0)
+		if err := editorial.RunJob(ctx, job); err != nil {
+			failed++
+			fmt.Printf("  ✗ #%d: %v\n", id, err)
+			continue
+		}
+		fmt.Printf("  ✓ #%d\n", id)
+	}
+
+	fmt.Printf("\nProcessed %d posts (%d failed) in %s\n", len(ids)-failed, failed, time.Since(start).Round(time.Millisecond))
+}
+
+// parseEditorialOptions 解析 --action 和 --lang 参数
+func parseEditorialOptions(options map[string]string) ([]string, error) {
+	actions, err := services.ParseEditorialActions(options["action"])
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range actions {
+		if action == services.EditorialTranslation && options["lang"] == "" {
+			return nil, fmt.Errorf("--lang is required for translation")
+		}
+	}
+	return actions, nil
+}
+
+// newEditorialService 创建文章编辑辅助服务，启用用量统计时记录令牌消耗
+func newEditorialService(client string) (*services.PostEditorialService, error) {
+	manager, err := config.LoadAIManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AI manager: %w", err)
+	}
+
+	editorial := services.NewPostEditorialService(manager, client)
+	if ledger, _, err := config.LoadUsageLedger(); err != nil {
+		fmt.Printf("Warning: Failed to load AI usage ledger, usage will not be metered: %v\n", err)
+	} else if ledger != nil {
+		editorial.SetUsageLedger(ledger)
+	}
+	return editorial, nil
+}
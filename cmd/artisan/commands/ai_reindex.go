@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+)
+
+// Reindex 重建文章语义检索索引
+func (c *AICommand) Reindex(args []string) {
+	options := parseUsageOptions(args)
+
+	manager, err := config.LoadAIManager()
+	if err != nil {
+		fmt.Printf("Error: Failed to load AI manager: %v\n", err)
+		return
+	}
+
+	store, searchConfig, err := config.LoadSemanticSearch()
+	if err != nil {
+		fmt.Printf("Error: Failed to load vector store: %v\n", err)
+		return
+	}
+	if store == nil {
+		fmt.Println("Semantic search is disabled (AI_SEMANTIC_SEARCH_ENABLED=false or AI_EMBEDDING_ENABLED=false)")
+		return
+	}
+	if client := options["client"]; client != "" {
+		searchConfig.Client = client
+	}
+
+	search := services.NewPostSearchService(manager, store, searchConfig)
+
+	fmt.Println("Reindexing published posts...")
+	start := time.Now()
+	failed := 0
+	indexed, err := search.Reindex(context.Background(), func(post *models.Post, err error) {
+		if err != nil {
+			failed++
+			fmt.Printf("  ✗ #%d %s: %v\n", post.ID, post.Title, err)
+			return
+		}
+		fmt.Printf("  ✓ #%d %s\n", post.ID, post.Title)
+	})
+	if err != nil {
+		fmt.Printf("Error: Reindex aborted: %v\n", err)
+	}
+
+	fmt.Printf("\nIndexed %d posts (%d failed) in %s\n", indexed, failed, time.Since(start).Round(time.Millisecond))
+}
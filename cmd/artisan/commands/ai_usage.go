@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/pkg/ai"
+)
+
+// Usage AI用量报表
+func (c *AICommand) Usage(args []string) {
+	options := parseUsageOptions(args)
+
+	filter, err := buildUsageFilter(options, time.Now())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		c.usageHelp()
+		return
+	}
+
+	ledger, _, err := config.LoadUsageLedger()
+	if err != nil {
+		fmt.Printf("Error: Failed to load usage ledger: %v\n", err)
+		return
+	}
+	if ledger == nil {
+		fmt.Println("AI usage metering is disabled (AI_USAGE_METERING_ENABLED=false)")
+		return
+	}
+
+	summaries, err := ledger.Report(context.Background(), filter)
+	if err != nil {
+		fmt.Printf("Error: Failed to build usage report: %v\n", err)
+		return
+	}
+
+	fmt.Println("AI Usage Report")
+	fmt.Println("===============")
+	fmt.Printf("Period: %s ~ %s\n", formatUsageTime(filter.Since, "beginning"), formatUsageTime(filter.Until, "now"))
+	fmt.Printf("Group by: %s\n\n", strings.Join(filter.GroupBy, ", "))
+
+	if len(summaries) == 0 {
+		fmt.Println("No usage recorded")
+		return
+	}
+
+	fmt.Printf("%-8s %-12s %-28s %10s %14s %14s %14s %12s\n",
+		"User", "Client", "Model", "Requests", "Prompt", "Completion", "Total", "Cost")
+
+	var total ai.UsageTotals
+	for _, summary := range summaries {
+		user := "-"
+		if contains(filter.GroupBy, "user") {
+			user = strconv.FormatUint(uint64(summary.UserID), 10)
+			if summary.UserID == 0 {
+				user = "anon"
+			}
+		}
+		fmt.Printf("%-8s %-12s %-28s %10d %14d %14d %14d %12.4f\n",
+			user, orDash(summary.Client), orDash(summary.Model), summary.Requests,
+			summary.PromptTokens, summary.CompletionTokens, summary.TotalTokens, summary.Cost)
+
+		total.Requests += summary.Requests
+		total.PromptTokens += summary.PromptTokens
+		total.CompletionTokens += summary.CompletionTokens
+		total.TotalTokens += summary.TotalTokens
+		total.Cost += summary.Cost
+	}
+
+	fmt.Printf("%-50s %10d %14d %14d %14d %12.4f\n",
+		"TOTAL", total.Requests, total.PromptTokens, total.CompletionTokens, total.TotalTokens, total.Cost)
+}
+
+// parseUsageOptions 解析 --key=value 形式的参数
+func parseUsageOptions(args []string) map[string]string {
+	options := make(map[string]string)
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		key, value, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		options[key] = value
+	}
+	return options
+}
+
+// buildUsageFilter 根据参数构建报表筛选条件
+func buildUsageFilter(options map[string]string, now time.Time) (ai.UsageFilter, error) {
+	filter := ai.UsageFilter{
+		GroupBy: []string{"client", "model"},
+	}
+
+	switch period := options["period"]; period {
+	case "today":
+		filter.Since = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	case "", "month":
+		filter.Since = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	case "all":
+	default:
+		return filter, fmt.Errorf("unsupported period: %s", period)
+	}
+
+	if since := options["since"]; since != "" {
+		t, err := time.ParseInLocation("2006-01-02", since, now.Location())
+		if err != nil {
+			return filter, fmt.Errorf("invalid --since date: %s", since)
+		}
+		filter.Since = t
+	}
+	if until := options["until"]; until != "" {
+		t, err := time.ParseInLocation("2006-01-02", until, now.Location())
+		if err != nil {
+			return filter, fmt.Errorf("invalid --until date: %s", until)
+		}
+		filter.Until = t.AddDate(0, 0, 1) // 包含当天
+	}
+
+	if user := options["user"]; user != "" {
+		id, err := strconv.ParseUint(user, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --user id: %s", user)
+		}
+		userID := uint(id)
+		filter.UserID = &userID
+	}
+	filter.Client = options["client"]
+
+	if group := options["group"]; group != "" {
+		filter.GroupBy = strings.Split(group, ",")
+	}
+
+	return filter, nil
+}
+
+// usageHelp 用量报表帮助
+func (c *AICommand) usageHelp() {
+	fmt.Println("Usage: ai:usage [--period=today|month|all] [--since=YYYY-MM-DD] [--until=YYYY-MM-DD]")
+	fmt.Println("                [--user=ID] [--client=name] [--group=user,client,model]")
+}
+
+// formatUsageTime 格式化报表时间，零值显示为 def
+func formatUsageTime(t time.Time, def string) string {
+	if t.IsZero() {
+		return def
+	}
+	return t.Format("2006-01-02")
+}
+
+// orDash 空值显示为 -
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// contains 检查切片是否包含指定值
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/database/seeders"
+)
+
+// DBSeed 填充种子数据
+// 用法: db:seed [--class=UserSeeder]，默认执行 DatabaseSeeder
+func DBSeed(args []string) {
+	class := parseUsageOptions(args)["class"]
+	if class == "" {
+		class = "DatabaseSeeder"
+	}
+
+	fmt.Printf("Seeding: %s\n", class)
+	if err := seeders.Run(config.DB, class); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Printf("Available seeders: %s\n", strings.Join(seeders.Names(), ", "))
+		os.Exit(1)
+	}
+	fmt.Println("Database seeding completed successfully")
+}
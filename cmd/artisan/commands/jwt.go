@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
+)
+
+// KeyGenerate 生成随机的 JWT_SECRET 并写入 .env
+// 用法: key:generate [--show] [--force]
+func KeyGenerate(args []string) {
+	options := parseUsageOptions(args)
+
+	b := make([]byte, 48)
+	if _, err := rand.Read(b); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	if _, ok := options["show"]; ok {
+		fmt.Println(secret)
+		return
+	}
+
+	_, force := options["force"]
+	written, err := setEnvValue(".env", "JWT_SECRET", secret, force)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !written {
+		fmt.Println("JWT_SECRET is already set in .env, use --force to replace it (all issued tokens become invalid)")
+		return
+	}
+	fmt.Println("JWT_SECRET set successfully")
+}
+
+// JWTRotate 轮换 JWT 签名密钥，当前密钥未到轮换周期时跳过，可由 cron 定期执行
+// 用法: jwt:rotate [--alg=RS256] [--force] [--now]
+func JWTRotate(args []string) {
+	options := parseUsageOptions(args)
+	config.InitJWT()
+
+	algorithm := config.JWT.Algorithm
+	if alg := options["alg"]; alg != "" {
+		algorithm = alg
+	}
+	store := config.JWTKeyStore()
+	now := time.Now()
+
+	keys, err := store.Keys()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	current, err := auth.SigningKey(keys, now)
+	_, force := options["force"]
+	if err == nil && !force && current.Algorithm == algorithm {
+		if due := current.NotBefore.Add(config.JWT.KeyRotation); now.Before(due) {
+			fmt.Printf("Signing key %s is not due for rotation until %s, use --force to rotate now\n", current.ID, due.Format(time.RFC3339))
+			return
+		}
+	}
+
+	publish := config.JWT.KeyPublish
+	if _, ok := options["now"]; ok {
+		publish = 0
+	}
+	key, err := store.Rotate(algorithm, now, publish, config.JWT.AccessTTL)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Generated %s key %s in %s\n", key.Algorithm, key.ID, store.Path())
+	if key.NotBefore.After(now) {
+		fmt.Printf("The key is published in JWKS now and will start signing at %s\n", key.NotBefore.Format(time.RFC3339))
+	}
+}
+
+// setEnvValue 设置 .env 中的变量，文件不存在时创建；已有非空值且未指定 force 时不修改
+func setEnvValue(path, key, value string, force bool) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+
+	found := false
+	for i, line := range lines {
+		name, current, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || strings.TrimSpace(name) != key {
+			continue
+		}
+		current = strings.Trim(strings.TrimSpace(current), `"'`)
+		if current != "" && current != "default_secret_key" && !force {
+			return false, nil
+		}
+		lines[i] = key + "=" + value
+		found = true
+	}
+	if !found {
+		lines = append(lines, key+"="+value)
+	}
+
+	return true, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+}
@@ -4,51 +4,64 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"text/template"
 	"time"
 )
 
-func MakeMigration(args []string) {
-	if len(args) < 1 {
-		fmt.Println("Migration name is required")
-		return
-	}
-
-	name := args[0]
-	timestamp := time.Now().Format("20060102150405")
-	fileName := fmt.Sprintf("%s_%s.go", timestamp, strings.ToLower(name))
-	filePath := filepath.Join("database", "migrations", fileName)
-
-	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-		fmt.Printf("Failed to create directory: %v\n", err)
-		return
-	}
+// migrationNamePattern 迁移名称只允许小写字母、数字和下划线
+var migrationNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
 
-	tmpl := `package migrations
+const migrationTemplate = `package migrations
 
 import (
 	"gorm.io/gorm"
 )
 
 func init() {
-	migrationList = append(migrationList, &{{.Name}}{})
+	Register("{{.Name}}", &{{.Type}}{})
 }
 
-type {{.Name}} struct {}
+// {{.Type}} {{.Name}}
+type {{.Type}} struct{}
 
-func (m *{{.Name}}) Up(db *gorm.DB) error {
-	// Migration logic here
+// Up 执行迁移
+func (m *{{.Type}}) Up(tx *gorm.DB) error {
 	return nil
 }
 
-func (m *{{.Name}}) Down(db *gorm.DB) error {
-	// Rollback logic here
+// Down 回滚迁移
+func (m *{{.Type}}) Down(tx *gorm.DB) error {
 	return nil
-}`
+}
+`
+
+// MakeMigration 创建带时间戳的迁移文件
+// 用法: make:migration create_posts_table
+func MakeMigration(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Migration name is required")
+		return
+	}
+
+	name := strings.ToLower(args[0])
+	if !migrationNamePattern.MatchString(name) {
+		fmt.Println("Migration name may only contain lowercase letters, digits and underscores")
+		return
+	}
+
+	timestamp := time.Now().Format("20060102150405")
+	fullName := fmt.Sprintf("%s_%s", timestamp, name)
+	filePath := filepath.Join("database", "migrations", fullName+".go")
 
-	data := struct{ Name string }{Name: name}
-	t, err := template.New("migration").Parse(tmpl)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		fmt.Printf("Failed to create directory: %v\n", err)
+		return
+	}
+
+	data := struct{ Name, Type string }{Name: fullName, Type: migrationTypeName(name)}
+	t, err := template.New("migration").Parse(migrationTemplate)
 	if err != nil {
 		fmt.Printf("Failed to parse template: %v\n", err)
 		return
@@ -68,3 +81,15 @@ func (m *{{.Name}}) Down(db *gorm.DB) error {
 
 	fmt.Printf("Migration created: %s\n", filePath)
 }
+
+// migrationTypeName 将 create_posts_table 转换为 CreatePostsTable
+func migrationTypeName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/clarkzhu2020/aidecms/database/migrations"
+	"github.com/clarkzhu2020/aidecms/database/seeders"
 	"github.com/clarkzhu2020/aidecms/pkg/database"
 	"github.com/spf13/cobra"
 )
@@ -19,8 +20,8 @@ var MenuInitCmd = &cobra.Command{
 		db := database.GetDB()
 
 		// 创建菜单表
-		fmt.Println("Creating menu tables...")
-		if err := migrations.CreateMenusTable(db); err != nil {
+		fmt.Println("Running migrations...")
+		if _, err := migrations.NewMigrator(db).Migrate(); err != nil {
 			fmt.Printf("Error creating menu tables: %v\n", err)
 			return
 		}
@@ -28,7 +29,7 @@ var MenuInitCmd = &cobra.Command{
 
 		// 添加默认菜单
 		fmt.Println("\nSeeding default menus...")
-		if err := migrations.SeedDefaultMenus(db); err != nil {
+		if err := seeders.Run(db, "MenuSeeder"); err != nil {
 			fmt.Printf("Error seeding default menus: %v\n", err)
 			return
 		}
@@ -2,20 +2,119 @@ package commands
 
 import (
 	"fmt"
-	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
 
 	"github.com/clarkzhu2020/aidecms/config"
 	"github.com/clarkzhu2020/aidecms/database/migrations"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
 )
 
+// Migrate 执行所有未执行的迁移
+// 用法: migrate [--seed]
 func Migrate(args []string) {
-	// 使用全局DB实例
-	db := config.DB
+	migrated, err := newMigrator().Migrate()
+	printMigrations("Migrated", migrated)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(migrated) == 0 {
+		fmt.Println("Nothing to migrate.")
+	}
+	seedIfRequested(args)
+}
+
+// MigrateRollback 回滚最近一个批次的迁移
+// 用法: migrate:rollback [--step=N]
+func MigrateRollback(args []string) {
+	steps := 0
+	if value, ok := parseUsageOptions(args)["step"]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			fmt.Println("Error: --step must be a positive integer")
+			os.Exit(1)
+		}
+		steps = n
+	}
+
+	rolledBack, err := newMigrator().Rollback(steps)
+	printMigrations("Rolled back", rolledBack)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(rolledBack) == 0 {
+		fmt.Println("Nothing to rollback.")
+	}
+}
+
+// MigrateReset 回滚所有已执行的迁移
+func MigrateReset(args []string) {
+	rolledBack, err := newMigrator().Reset()
+	printMigrations("Rolled back", rolledBack)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(rolledBack) == 0 {
+		fmt.Println("Nothing to rollback.")
+	}
+}
 
-	// 执行迁移
-	if err := db.AutoMigrate(&migrations.User{}); err != nil {
-		log.Fatalf("Migration failed: %v", err)
+// MigrateFresh 删除所有表后重新执行全部迁移
+// 用法: migrate:fresh [--seed]
+func MigrateFresh(args []string) {
+	fmt.Println("Dropping all tables...")
+	migrated, err := newMigrator().Fresh()
+	printMigrations("Migrated", migrated)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
 	}
+	seedIfRequested(args)
+}
+
+// MigrateStatus 显示每个迁移的执行状态
+func MigrateStatus(args []string) {
+	statuses, err := newMigrator().Status()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(statuses) == 0 {
+		fmt.Println("No migrations found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RAN\tMIGRATION\tBATCH")
+	for _, status := range statuses {
+		ran, batch := "No", ""
+		if status.Ran {
+			ran, batch = "Yes", strconv.Itoa(status.Batch)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", ran, status.Name, batch)
+	}
+	w.Flush()
+}
 
-	fmt.Println("Migration completed successfully")
+// newMigrator 使用全局数据库连接创建迁移执行器
+func newMigrator() *database.Migrator {
+	return migrations.NewMigrator(config.DB)
+}
+
+// seedIfRequested 带 --seed 参数时在迁移后执行 DatabaseSeeder
+func seedIfRequested(args []string) {
+	if _, seed := parseUsageOptions(args)["seed"]; seed {
+		DBSeed(nil)
+	}
+}
+
+// printMigrations 输出已处理的迁移
+func printMigrations(action string, names []string) {
+	for _, name := range names {
+		fmt.Printf("%s: %s\n", action, name)
+	}
 }
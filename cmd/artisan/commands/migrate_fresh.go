@@ -1,22 +0,0 @@
-package commands
-
-import (
-	"fmt"
-	"log"
-)
-
-func MigrateFresh(args []string) {
-	// 1. 删除所有表
-	if err := dropAllTables(); err != nil {
-		log.Fatalf("Failed to drop tables: %v", err)
-	}
-
-	// 2. 重新运行迁移
-	Migrate(args)
-}
-
-func dropAllTables() error {
-	// 实现删除所有表的逻辑
-	fmt.Println("Dropping all tables...")
-	return nil
-}
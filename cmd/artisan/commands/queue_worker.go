@@ -7,15 +7,19 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/clarkzhu2020/aidecms/config"
 	q "github.com/clarkzhu2020/aidecms/pkg/queue"
 )
 
 func QueueWork(args []string) {
 	fmt.Println("Starting queue workers...")
 
-	// 创建队列管理器（使用内存驱动，实际应用中应该使用 Redis）
-	driver := q.NewMemoryDriver()
-	queueMgr := q.NewQueue(driver)
+	// 创建队列管理器（QUEUE_DRIVER 为 memory 时只能处理本进程推送的任务）
+	queueMgr, err := config.LoadQueue()
+	if err != nil {
+		fmt.Printf("Error: Failed to load queue: %v\n", err)
+		return
+	}
 
 	// 配置工作进程
 	queueMgr.SetWorkers(3).SetQueues([]string{"default", "high", "low"})
@@ -69,4 +73,12 @@ func registerJobHandlers(queueMgr *q.Queue) {
 	})
 
 	fmt.Println("✓ Registered 3 job handlers")
+
+	// 文章AI编辑建议任务
+	if editorial, err := newEditorialService(""); err != nil {
+		fmt.Printf("Warning: AI editorial jobs will not be processed: %v\n", err)
+	} else {
+		editorial.RegisterJobs(queueMgr)
+		fmt.Println("✓ Registered AI editorial job handler")
+	}
 }
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"github.com/clarkzhu2020/aidecms/routes"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// RouteList 列出所有已注册的路由
+// 用法: route:list [--method=GET] [--name=posts] [--path=/api/cms] [--json]
+func RouteList(args []string) {
+	options := parseUsageOptions(args)
+
+	app := framework.NewApplication().
+		SetConfigPath("config").
+		SetDebug(false).
+		Boot()
+
+	// Boot 按配置设置日志级别和输出（默认 stdout），注册路由时只把警告写到 stderr，
+	// 标准输出只保留路由列表，--json 的输出可以直接交给其他程序解析
+	hlog.SetLevel(hlog.LevelWarn)
+	hlog.SetOutput(os.Stderr)
+	routes.Register(app)
+
+	list := filterRoutes(app.Router.GetRoutes(), options)
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Path != list[j].Path {
+			return list[i].Path < list[j].Path
+		}
+		return list[i].Method < list[j].Method
+	})
+
+	if _, asJSON := options["json"]; asJSON {
+		output, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(output))
+		return
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No routes matched.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tNAME\tHANDLER\tMIDDLEWARE")
+	for _, route := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			route.Method, route.Path, route.Name, route.Handler, strings.Join(route.Middleware, ", "))
+	}
+	w.Flush()
+
+	fmt.Printf("\nTotal routes: %d\n", len(list))
+}
+
+// filterRoutes 按方法、名称和路径筛选路由
+func filterRoutes(list []framework.RouteInfo, options map[string]string) []framework.RouteInfo {
+	method := strings.ToUpper(options["method"])
+	name := options["name"]
+	path := options["path"]
+
+	filtered := make([]framework.RouteInfo, 0, len(list))
+	for _, route := range list {
+		if method != "" && route.Method != method {
+			continue
+		}
+		if name != "" && !strings.Contains(route.Name, name) {
+			continue
+		}
+		if path != "" && !strings.Contains(route.Path, path) {
+			continue
+		}
+		filtered = append(filtered, route)
+	}
+	return filtered
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+)
+
+// UserTwoFactorReset 为丢失验证器设备的用户重置两步验证，删除密钥和恢复码
+// 角色要求两步验证的用户下次登录时需要重新绑定
+// 用法: user:2fa-reset --user=<ID|用户名|邮箱>
+func UserTwoFactorReset(args []string) {
+	identifier := parseUsageOptions(args)["user"]
+	if identifier == "" {
+		fmt.Println("Usage: user:2fa-reset --user=<id|username|email>")
+		os.Exit(1)
+	}
+
+	var user models.User
+	if err := config.DB.Where("id = ? OR username = ? OR email = ?", identifier, identifier, identifier).First(&user).Error; err != nil {
+		fmt.Printf("Error: user %q not found\n", identifier)
+		os.Exit(1)
+	}
+
+	twoFactor := services.NewTwoFactorService()
+	enabled, err := twoFactor.Enabled(user.ID)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := twoFactor.Reset(user.ID); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !enabled {
+		fmt.Printf("Two-factor authentication was not enabled for %s\n", user.Username)
+		return
+	}
+	fmt.Printf("Two-factor authentication reset for %s (ID %d)\n", user.Username, user.ID)
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+)
+
+// UserUnlock 解除因连续登录失败或两步验证码输错被锁定的账户
+// 各实例内存中的递增延迟和IP限制不受影响，会在统计窗口结束后自动清除
+// 用法: user:unlock --user=<ID|用户名|邮箱>
+func UserUnlock(args []string) {
+	identifier := parseUsageOptions(args)["user"]
+	if identifier == "" {
+		fmt.Println("Usage: user:unlock --user=<id|username|email>")
+		os.Exit(1)
+	}
+
+	var user models.User
+	if err := config.DB.Where("id = ? OR username = ? OR email = ?", identifier, identifier, identifier).First(&user).Error; err != nil {
+		fmt.Printf("Error: user %q not found\n", identifier)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	var credential models.TwoFactorCredential
+	twoFactorLocked := config.DB.Where("user_id = ?", user.ID).First(&credential).Error == nil && credential.IsLocked(now)
+
+	userService := &services.UserService{DB: config.DB}
+	if err := userService.Unlock(user.ID); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !user.IsLocked(now) && !twoFactorLocked {
+		fmt.Printf("User %s was not locked\n", user.Username)
+		return
+	}
+	fmt.Printf("User %s (ID %d) unlocked\n", user.Username, user.ID)
+}
@@ -71,7 +71,7 @@ func runArtisan() {
 	args := cmdArgs[1:]
 
 	switch command {
-	case "ai:setup", "ai:chat", "ai:completion", "ai:models", "ai:test", "ai:config":
+	case "ai:setup", "ai:chat", "ai:completion", "ai:models", "ai:test", "ai:config", "ai:usage", "ai:reindex", "ai:suggest", "ai:backfill":
 		// 将 ai: 前缀的命令传递给AI命令处理器
 		aiArgs := []string{strings.TrimPrefix(command, "ai:")}
 		aiArgs = append(aiArgs, args...)
@@ -84,8 +84,28 @@ func runArtisan() {
 		generator.NewCommand().Handle(args)
 	case "make:middleware":
 		generator.NewCommand().Handle(args)
+	case "make:migration":
+		commands.MakeMigration(args)
 	case "migrate":
 		commands.Migrate(args)
+	case "migrate:rollback":
+		commands.MigrateRollback(args)
+	case "migrate:reset":
+		commands.MigrateReset(args)
+	case "migrate:fresh":
+		commands.MigrateFresh(args)
+	case "migrate:status":
+		commands.MigrateStatus(args)
+	case "db:seed":
+		commands.DBSeed(args)
+	case "key:generate":
+		commands.KeyGenerate(args)
+	case "jwt:rotate":
+		commands.JWTRotate(args)
+	case "user:2fa-reset":
+		commands.UserTwoFactorReset(args)
+	case "user:unlock":
+		commands.UserUnlock(args)
 	case "help":
 		showHelp()
 	case "stats:show":
@@ -122,6 +142,8 @@ func runArtisan() {
 		commands.SetupEmailAlert(args)
 	case "alert:test":
 		commands.SendTestEmail(args)
+	case "queue:work":
+		commands.QueueWork(args)
 	case "queue:process":
 		commands.ProcessQueue()
 	case "queue:status":
@@ -138,6 +160,8 @@ func runArtisan() {
 		commands.ScheduleWork(args)
 	case "schedule:run":
 		commands.ScheduleRun(args)
+	case "route:list":
+		commands.RouteList(args)
 	case "schedule:list":
 		commands.ScheduleList(args)
 	case "event:test":
@@ -189,7 +213,17 @@ func showHelp() {
 	fmt.Println("  make:controller <name>\tCreate a new controller")
 	fmt.Println("  make:model <name>\tCreate a new model")
 	fmt.Println("  make:middleware <name>\tCreate a new middleware")
-	fmt.Println("  migrate\t\tRun database migrations")
+	fmt.Println("  make:migration <name>\tCreate a new migration")
+	fmt.Println("  migrate\t\tRun pending database migrations")
+	fmt.Println("  migrate:rollback\tRollback the last batch (--step=N for the last N migrations)")
+	fmt.Println("  migrate:reset\t\tRollback all migrations")
+	fmt.Println("  migrate:fresh\t\tDrop all tables and re-run all migrations")
+	fmt.Println("  migrate:status\t\tShow the status of each migration")
+	fmt.Println("  db:seed [--class=Name]\tSeed the database (default DatabaseSeeder)")
+	fmt.Println("  key:generate [--show]\tGenerate a random JWT_SECRET in .env")
+	fmt.Println("  jwt:rotate [--alg=RS256]\tRotate JWT signing keys when due (--force to rotate now)")
+	fmt.Println("  user:2fa-reset --user=ID\tReset two-factor authentication for a locked-out user")
+	fmt.Println("  user:unlock --user=ID\tUnlock an account locked after repeated failed logins or 2FA codes")
 	fmt.Println("  help\t\t\tShow this help message")
 	fmt.Println("\nAI commands:")
 	fmt.Println("  ai:setup <provider> <api_key>\tSetup AI configuration")
@@ -198,6 +232,10 @@ func showHelp() {
 	fmt.Println("  ai:models\t\t\tList available models")
 	fmt.Println("  ai:test [model]\t\tTest AI connection")
 	fmt.Println("  ai:config <action>\t\tManage AI configurations")
+	fmt.Println("  ai:usage [--period=month]\tAI token usage and cost report")
+	fmt.Println("  ai:reindex\t\t\tRebuild post semantic search index")
+	fmt.Println("  ai:suggest <post_id>\t\tGenerate AI editorial suggestions for a post")
+	fmt.Println("  ai:backfill\t\t\tGenerate AI editorial suggestions in bulk")
 	fmt.Println("\nStatistics commands:")
 	fmt.Println("  stats:show\t\tShow command usage statistics")
 	fmt.Println("  stats:reset\t\tReset command statistics")
@@ -209,6 +247,7 @@ func showHelp() {
 	fmt.Println("  alert:setup <file>\tSetup email alert configuration")
 	fmt.Println("  alert:test\t\tSend test email")
 	fmt.Println("\nQueue commands:")
+	fmt.Println("  queue:work\t\tStart background job workers")
 	fmt.Println("  queue:process\t\tProcess email queue")
 	fmt.Println("  queue:status\t\tShow queue status")
 	fmt.Println("  queue:retry\t\tRetry failed jobs")
@@ -218,6 +257,7 @@ func showHelp() {
 	fmt.Println("\nSchedule commands:")
 	fmt.Println("  schedule:work\t\tStart scheduler workers")
 	fmt.Println("  schedule:run\t\tRun scheduled tasks once")
+	fmt.Println("  route:list\t\tList registered routes with names and middleware")
 	fmt.Println("  schedule:list\t\tList scheduled tasks")
 	fmt.Println("\nEvent commands:")
 	fmt.Println("  event:test\t\tTest event system")
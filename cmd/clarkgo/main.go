@@ -14,7 +14,8 @@ func main() {
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "migrate":
-			if err := migrations.CreateUsersTable(app.DB); err != nil {
+			app.Boot()
+			if _, err := migrations.NewMigrator(app.DB.DB).Migrate(); err != nil {
 				fmt.Printf("Migration failed: %v\n", err)
 				os.Exit(1)
 			}
@@ -4,21 +4,20 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/clarkzhu2020/aidecms/config"
 	"github.com/clarkzhu2020/aidecms/database/migrations"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
 )
 
 func main() {
-	// 直接创建数据库连接
-	dsn := "root:@tcp(localhost:3306)/aidecms?charset=utf8mb4&parseTime=True&loc=Local"
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
+	// 按 config/database.json 或 DB_* 环境变量连接数据库
+	config.InitDB()
 
 	// 执行迁移
-	if err := db.AutoMigrate(&migrations.User{}); err != nil {
+	migrated, err := migrations.NewMigrator(config.DB).Migrate()
+	for _, name := range migrated {
+		fmt.Printf("Migrated: %s\n", name)
+	}
+	if err != nil {
 		log.Fatalf("Migration failed: %v", err)
 	}
 
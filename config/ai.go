@@ -6,15 +6,21 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/clarkzhu2020/aidecms/pkg/ai"
 	envConfig "github.com/clarkzhu2020/aidecms/pkg/config"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 // AIConfig AI配置结构
 type AIConfig struct {
 	DefaultProvider string                 `json:"default_provider"`
 	Providers       map[string]*ai.Config  `json:"providers"`
+	Fallbacks       map[string][]string    `json:"fallbacks,omitempty"` // 故障转移链，"*" 为默认链
+	Quotas          *ai.QuotaPolicy        `json:"quotas,omitempty"`    // 用量额度，按用户/角色设置
 	GlobalOptions   map[string]interface{} `json:"global_options"`
 }
 
@@ -48,7 +54,18 @@ func LoadAIConfig() (*AIConfig, error) {
 // loadConfigFromEnv 从环境变量加载配置
 func loadConfigFromEnv() *AIConfig {
 	config := &AIConfig{
-		Providers:     make(map[string]*ai.Config),
+		Providers: make(map[string]*ai.Config),
+		Fallbacks: make(map[string][]string),
+		Quotas: &ai.QuotaPolicy{
+			Default: ai.QuotaLimit{
+				DailyTokens:   int64(envConfig.GetEnvInt("AI_QUOTA_DAILY_TOKENS", 0)),
+				MonthlyTokens: int64(envConfig.GetEnvInt("AI_QUOTA_MONTHLY_TOKENS", 0)),
+				DailyCost:     envConfig.GetEnvFloat64("AI_QUOTA_DAILY_COST", 0),
+				MonthlyCost:   envConfig.GetEnvFloat64("AI_QUOTA_MONTHLY_COST", 0),
+			},
+			Roles: make(map[string]ai.QuotaLimit),
+			Users: make(map[uint]ai.QuotaLimit),
+		},
 		GlobalOptions: make(map[string]interface{}),
 	}
 
@@ -67,10 +84,27 @@ func loadConfigFromEnv() *AIConfig {
 	config.GlobalOptions["request_timeout"] = envConfig.GetEnvInt("AI_REQUEST_TIMEOUT", 30)
 	config.GlobalOptions["max_retries"] = envConfig.GetEnvInt("AI_MAX_RETRIES", 3)
 	config.GlobalOptions["retry_delay"] = envConfig.GetEnvInt("AI_RETRY_DELAY", 1)
+	config.GlobalOptions["max_retry_delay"] = envConfig.GetEnvInt("AI_MAX_RETRY_DELAY", 10)
+	config.GlobalOptions["circuit_breaker_threshold"] = envConfig.GetEnvInt("AI_CIRCUIT_BREAKER_THRESHOLD", 5)
+	config.GlobalOptions["circuit_breaker_cooldown"] = envConfig.GetEnvInt("AI_CIRCUIT_BREAKER_COOLDOWN", 30)
+	config.GlobalOptions["load_balance_enabled"] = envConfig.GetEnvBool("AI_LOAD_BALANCE_ENABLED", false)
+
+	// 故障转移链，例如 AI_FALLBACK_CHAIN=openai,qianwen,doubao
+	if chain := envConfig.GetEnv("AI_FALLBACK_CHAIN", ""); chain != "" {
+		config.Fallbacks["*"] = splitList(chain)
+	}
 	config.GlobalOptions["rate_limit_enabled"] = envConfig.GetEnvBool("AI_RATE_LIMIT_ENABLED", true)
 	config.GlobalOptions["rate_limit_rpm"] = envConfig.GetEnvInt("AI_RATE_LIMIT_RPM", 60)
 	config.GlobalOptions["conversation_max_history"] = envConfig.GetEnvInt("AI_CONVERSATION_MAX_HISTORY", 50)
+	config.GlobalOptions["conversation_store"] = envConfig.GetEnv("AI_CONVERSATION_STORE", "memory")
+	config.GlobalOptions["conversation_ttl"] = envConfig.GetEnvInt("AI_CONVERSATION_TTL", 86400)
+	config.GlobalOptions["conversation_max_sessions"] = envConfig.GetEnvInt("AI_CONVERSATION_MAX_SESSIONS", 10000)
+	config.GlobalOptions["conversation_summarize"] = envConfig.GetEnvBool("AI_CONVERSATION_SUMMARIZE", false)
+	config.GlobalOptions["usage_metering_enabled"] = envConfig.GetEnvBool("AI_USAGE_METERING_ENABLED", true)
 	config.GlobalOptions["embedding_batch_size"] = envConfig.GetEnvInt("AI_EMBEDDING_BATCH_SIZE", 100)
+	config.GlobalOptions["embedding_client"] = envConfig.GetEnv("AI_EMBEDDING_CLIENT", "")
+	config.GlobalOptions["semantic_search_enabled"] = envConfig.GetEnvBool("AI_SEMANTIC_SEARCH_ENABLED", true)
+	config.GlobalOptions["search_chunk_tokens"] = envConfig.GetEnvInt("AI_SEARCH_CHUNK_TOKENS", 400)
 
 	// 功能开关
 	config.GlobalOptions["chat_enabled"] = envConfig.GetEnvBool("AI_CHAT_ENABLED", true)
@@ -116,6 +150,33 @@ func loadProviderConfig(provider string) *ai.Config {
 		Options:     make(map[string]interface{}),
 	}
 
+	// 嵌入模型（可选，默认由提供商适配器决定）
+	if embeddingModel := envConfig.GetEnv(upperProvider+"_EMBEDDING_MODEL", ""); embeddingModel != "" {
+		providerConfig.Options["embedding_model"] = embeddingModel
+	}
+
+	// 负载分摊权重与模型家族
+	providerConfig.Options["weight"] = envConfig.GetEnvInt(upperProvider+"_WEIGHT", 1)
+	if family := envConfig.GetEnv(upperProvider+"_MODEL_FAMILY", ""); family != "" {
+		providerConfig.Options["family"] = family
+	}
+
+	// 单价（每百万令牌），用于费用统计
+	if price := envConfig.GetEnvFloat64(upperProvider+"_PROMPT_PRICE", 0); price > 0 {
+		providerConfig.Options["prompt_price"] = price
+	}
+	if price := envConfig.GetEnvFloat64(upperProvider+"_COMPLETION_PRICE", 0); price > 0 {
+		providerConfig.Options["completion_price"] = price
+	}
+
+	// 上下文窗口（可选，默认按模型名称推断）
+	if contextWindow := envConfig.GetEnvInt(upperProvider+"_CONTEXT_WINDOW", 0); contextWindow > 0 {
+		providerConfig.Options["context_window"] = contextWindow
+	}
+
+	// 请求超时（秒）
+	providerConfig.Options["request_timeout"] = envConfig.GetEnvInt("AI_REQUEST_TIMEOUT", 30)
+
 	// 特殊处理 MiniMax 的 API Secret
 	if provider == "minimax" {
 		if apiSecret := envConfig.GetEnv("MINIMAX_API_SECRET", ""); apiSecret != "" {
@@ -153,6 +214,26 @@ func mergeConfigs(envConfig, fileConfig *AIConfig) {
 		}
 	}
 
+	// 合并故障转移链
+	for name, chain := range fileConfig.Fallbacks {
+		if _, exists := envConfig.Fallbacks[name]; !exists {
+			envConfig.Fallbacks[name] = chain
+		}
+	}
+
+	// 合并额度：文件中的角色和用户额度补充环境变量，默认额度仅在环境变量未设置时使用
+	if fileConfig.Quotas != nil {
+		if envConfig.Quotas.Default.Unlimited() {
+			envConfig.Quotas.Default = fileConfig.Quotas.Default
+		}
+		for role, limit := range fileConfig.Quotas.Roles {
+			envConfig.Quotas.Roles[role] = limit
+		}
+		for userID, limit := range fileConfig.Quotas.Users {
+			envConfig.Quotas.Users[userID] = limit
+		}
+	}
+
 	// 合并全局选项
 	for key, value := range fileConfig.GlobalOptions {
 		if _, exists := envConfig.GlobalOptions[key]; !exists {
@@ -196,6 +277,8 @@ func LoadAIManager() (*ai.Manager, error) {
 		}
 	}
 
+	manager.SetRoutingPolicy(buildRoutingPolicy(config))
+
 	// 设置默认提供商
 	if config.DefaultProvider != "" {
 		if err := manager.SetDefault(config.DefaultProvider); err != nil {
@@ -210,6 +293,140 @@ func LoadAIManager() (*ai.Manager, error) {
 	return manager, nil
 }
 
+// buildRoutingPolicy 根据全局选项构建路由策略
+func buildRoutingPolicy(config *AIConfig) *ai.RoutingPolicy {
+	policy := ai.DefaultRoutingPolicy()
+
+	policy.MaxRetries = globalInt(config, "max_retries", policy.MaxRetries)
+	policy.RetryDelay = time.Duration(globalInt(config, "retry_delay", 1)) * time.Second
+	policy.MaxRetryDelay = time.Duration(globalInt(config, "max_retry_delay", 10)) * time.Second
+	policy.BreakerThreshold = globalInt(config, "circuit_breaker_threshold", policy.BreakerThreshold)
+	policy.BreakerCooldown = time.Duration(globalInt(config, "circuit_breaker_cooldown", 30)) * time.Second
+	if enabled, ok := config.GlobalOptions["load_balance_enabled"].(bool); ok {
+		policy.LoadBalance = enabled
+	}
+
+	for name, chain := range config.Fallbacks {
+		policy.Fallbacks[name] = chain
+	}
+
+	return policy
+}
+
+// globalInt 读取整数全局选项（兼容JSON解码得到的float64）
+func globalInt(config *AIConfig, key string, defaultValue int) int {
+	switch value := config.GlobalOptions[key].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	}
+	return defaultValue
+}
+
+// splitList 解析逗号分隔的列表
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// LoadConversationStore 根据配置创建对话存储（memory、database、redis），返回存储与TTL
+func LoadConversationStore() (ai.ConversationStore, time.Duration, error) {
+	config, err := LoadAIConfig()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ttl := time.Duration(globalInt(config, "conversation_ttl", 86400)) * time.Second
+	driver, _ := config.GlobalOptions["conversation_store"].(string)
+
+	switch driver {
+	case "database":
+		store, err := ai.NewGormConversationStore(aiDB())
+		if err != nil {
+			return nil, 0, err
+		}
+		return store, ttl, nil
+	case "redis":
+		client := goredis.NewClient(&goredis.Options{
+			Addr:     envConfig.GetEnv("REDIS_HOST", "127.0.0.1") + ":" + envConfig.GetEnv("REDIS_PORT", "6379"),
+			Password: envConfig.GetEnv("REDIS_PASSWORD", ""),
+			DB:       envConfig.GetEnvInt("REDIS_DB", 0),
+		})
+		prefix := envConfig.GetEnv("REDIS_PREFIX", "") + "ai:conversation"
+		return ai.NewRedisConversationStore(client, prefix), ttl, nil
+	case "", "memory":
+		return ai.NewMemoryConversationStore(globalInt(config, "conversation_max_sessions", 10000)), ttl, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported conversation store: %s", driver)
+	}
+}
+
+// LoadUsageLedger 创建用量账本并返回额度策略，未启用用量统计时返回nil
+func LoadUsageLedger() (*ai.UsageLedger, *ai.QuotaPolicy, error) {
+	config, err := LoadAIConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if enabled, ok := config.GlobalOptions["usage_metering_enabled"].(bool); ok && !enabled {
+		return nil, nil, nil
+	}
+
+	ledger, err := ai.NewUsageLedger(aiDB())
+	if err != nil {
+		return nil, nil, err
+	}
+	return ledger, config.Quotas, nil
+}
+
+// SemanticSearchConfig 语义检索配置
+type SemanticSearchConfig struct {
+	Client      string // 生成嵌入向量的客户端，为空时使用默认客户端
+	ChunkTokens int    // 每个分块的最大令牌数
+	BatchSize   int    // 每次嵌入请求的最大文本数
+}
+
+// LoadSemanticSearch 创建向量存储并返回语义检索配置，未启用语义检索时返回nil
+func LoadSemanticSearch() (ai.VectorStore, *SemanticSearchConfig, error) {
+	config, err := LoadAIConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if enabled, ok := config.GlobalOptions["semantic_search_enabled"].(bool); ok && !enabled {
+		return nil, nil, nil
+	}
+	if enabled, ok := config.GlobalOptions["embedding_enabled"].(bool); ok && !enabled {
+		return nil, nil, nil
+	}
+
+	store, err := ai.NewGormVectorStore(aiDB())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, _ := config.GlobalOptions["embedding_client"].(string)
+	return store, &SemanticSearchConfig{
+		Client:      client,
+		ChunkTokens: globalInt(config, "search_chunk_tokens", 400),
+		BatchSize:   globalInt(config, "embedding_batch_size", 100),
+	}, nil
+}
+
+// aiDB 获取AI功能使用的数据库连接
+func aiDB() *gorm.DB {
+	if db := database.GetDB(); db != nil {
+		return db
+	}
+	return DB
+}
+
 // GetAIGlobalOption 获取全局AI选项
 func GetAIGlobalOption(key string, defaultValue interface{}) interface{} {
 	config, err := LoadAIConfig()
@@ -234,6 +451,9 @@ func IsAIFeatureEnabled(feature string) bool {
 		return GetAIGlobalOption("embedding_enabled", true).(bool)
 	case "streaming":
 		return GetAIGlobalOption("streaming_enabled", true).(bool)
+	case "conversation_summarize":
+		enabled, _ := GetAIGlobalOption("conversation_summarize", false).(bool)
+		return enabled
 	default:
 		return false
 	}
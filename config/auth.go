@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
+	envConfig "github.com/clarkzhu2020/aidecms/pkg/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// AuthConfig 账户相关配置：邮箱验证、密码重置和登录限制
+type AuthConfig struct {
+	RequireVerifiedEmail bool          // 未验证邮箱的用户不能登录
+	PasswordResetTTL     time.Duration // 密码重置链接有效期
+	VerifyEmailTTL       time.Duration // 邮箱验证链接有效期
+	PasswordResetURL     string        // 前端重置密码页面，链接为 <url>?token=...
+	VerifyEmailURL       string        // 前端邮箱验证页面，链接为 <url>?token=...
+
+	LoginMaxAttempts   int           // 同一账户在 LoginLockout 内连续登录失败的次数上限，达到后锁定账户
+	LoginIPMaxAttempts int           // 同一IP在 LoginLockout 内登录失败的次数上限，达到后暂时拒绝该IP登录
+	LoginLockout       time.Duration // 失败次数的统计窗口和锁定时长
+}
+
+var Auth = &AuthConfig{
+	PasswordResetTTL:   60 * time.Minute,
+	VerifyEmailTTL:     24 * time.Hour,
+	LoginMaxAttempts:   5,
+	LoginIPMaxAttempts: 50,
+	LoginLockout:       15 * time.Minute,
+}
+
+// InitAuth 从环境变量读取账户配置
+// AUTH_REQUIRE_VERIFIED_EMAIL、AUTH_PASSWORD_RESET_TTL（分钟）、AUTH_VERIFY_EMAIL_TTL（小时）、
+// AUTH_PASSWORD_RESET_URL、AUTH_VERIFY_EMAIL_URL（默认为 APP_URL 下的 /reset-password 和 /verify-email）、
+// AUTH_LOGIN_MAX_ATTEMPTS、AUTH_LOGIN_IP_MAX_ATTEMPTS、AUTH_LOGIN_LOCKOUT（分钟）
+func InitAuth() {
+	envConfig.LoadEnv(".env")
+
+	appURL := strings.TrimRight(envConfig.GetEnv("APP_URL", "http://localhost:8888"), "/")
+	Auth.RequireVerifiedEmail = envConfig.GetEnvBool("AUTH_REQUIRE_VERIFIED_EMAIL", Auth.RequireVerifiedEmail)
+	Auth.PasswordResetTTL = time.Duration(envConfig.GetEnvInt("AUTH_PASSWORD_RESET_TTL", int(Auth.PasswordResetTTL/time.Minute))) * time.Minute
+	Auth.VerifyEmailTTL = time.Duration(envConfig.GetEnvInt("AUTH_VERIFY_EMAIL_TTL", int(Auth.VerifyEmailTTL/time.Hour))) * time.Hour
+	Auth.PasswordResetURL = envConfig.GetEnv("AUTH_PASSWORD_RESET_URL", appURL+"/reset-password")
+	Auth.VerifyEmailURL = envConfig.GetEnv("AUTH_VERIFY_EMAIL_URL", appURL+"/verify-email")
+	Auth.LoginMaxAttempts = envConfig.GetEnvInt("AUTH_LOGIN_MAX_ATTEMPTS", Auth.LoginMaxAttempts)
+	Auth.LoginIPMaxAttempts = envConfig.GetEnvInt("AUTH_LOGIN_IP_MAX_ATTEMPTS", Auth.LoginIPMaxAttempts)
+	Auth.LoginLockout = time.Duration(envConfig.GetEnvInt("AUTH_LOGIN_LOCKOUT", int(Auth.LoginLockout/time.Minute))) * time.Minute
+}
+
+// LoadSignedTokens 创建密码重置和邮箱验证使用的签名令牌
+// 签名密钥为 APP_KEY，未设置时使用 JWT_SECRET；生产环境下密钥不安全时返回错误
+func LoadSignedTokens() (*auth.SignedTokens, error) {
+	InitJWT()
+
+	secret := envConfig.GetEnv("APP_KEY", JWT.SecretKey)
+	if secret == "" || secret == defaultJWTSecret || len(secret) < 32 {
+		if isProduction() {
+			return nil, fmt.Errorf("APP_KEY or JWT_SECRET must be set to a random value of at least 32 characters in production")
+		}
+		hlog.Warn("APP_KEY and JWT_SECRET are not set or too short, password reset links can be forged")
+	}
+	return auth.NewSignedTokens([]byte(secret)), nil
+}
@@ -1,25 +1,32 @@
 package config
 
 import (
-	"os"
-
-	"gorm.io/driver/sqlite"
+	envConfig "github.com/clarkzhu2020/aidecms/pkg/config"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
+// InitDB 按 config/database.json 或 DB_* 环境变量连接数据库，并设置为全局数据库实例
 func InitDB() {
-	// Ensure data directory exists
-	if err := os.MkdirAll("storage/database", 0755); err != nil {
-		panic("failed to create database directory: " + err.Error())
+	if DB != nil {
+		return
+	}
+
+	envConfig.LoadEnv(".env")
+	cfg := envConfig.NewConfig([]string{"config"})
+	if err := cfg.Load(); err != nil {
+		panic("failed to load config: " + err.Error())
 	}
 
-	// Force SQLite configuration
-	dbPath := "storage/database/data.db"
-	var err error
-	DB, err = gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
-	if err != nil {
+	db := database.NewDatabase(database.LoadConfig(cfg))
+	if err := db.Connect(); err != nil {
 		panic("failed to connect database: " + err.Error())
 	}
+
+	DB = db.DB
+	if database.GetDB() == nil {
+		database.SetDB(DB)
+	}
 }
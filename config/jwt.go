@@ -1,29 +1,184 @@
 package config
 
 import (
-	"os"
-	"strconv"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
+	envConfig "github.com/clarkzhu2020/aidecms/pkg/config"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	goredis "github.com/redis/go-redis/v9"
 )
 
-// JWT holds the JWT configuration
+// defaultJWTSecret 未配置 JWT_SECRET 时使用的密钥，生产环境禁止使用
+const defaultJWTSecret = "default_secret_key"
 
+// JWTConfig JWT配置
 type JWTConfig struct {
-	SecretKey string
-	ExpiresIn int // 过期时间(小时)
+	SecretKey   string
+	Algorithm   string        // 签名算法：HS256、RS256、ES256、EdDSA
+	KeysFile    string        // 密钥文件，存在时优先于 SecretKey
+	KeyRotation time.Duration // 密钥轮换周期
+	KeyPublish  time.Duration // 新密钥先发布公钥、延迟签名的时间
+	Issuer      string
+	AccessTTL   time.Duration // 访问令牌有效期
+	RefreshTTL  time.Duration // 刷新令牌有效期
+	MFATTL      time.Duration // 两步验证待完成令牌有效期
+	Denylist    string        // 注销列表驱动：database、redis
 }
 
 var JWT = &JWTConfig{
-	SecretKey: "default_secret_key",
-	ExpiresIn: 24,
+	SecretKey:   defaultJWTSecret,
+	Algorithm:   auth.AlgHS256,
+	KeysFile:    "storage/keys/jwt.json",
+	KeyRotation: 90 * 24 * time.Hour,
+	KeyPublish:  10 * time.Minute,
+	AccessTTL:   15 * time.Minute,
+	RefreshTTL:  30 * 24 * time.Hour,
+	MFATTL:      5 * time.Minute,
+	Denylist:    "database",
 }
 
+var (
+	tokenManager     *auth.TokenManager
+	tokenManagerErr  error
+	tokenManagerOnce sync.Once
+)
+
+// InitJWT 从环境变量读取JWT配置
+// JWT_SECRET、JWT_ALGORITHM、JWT_KEYS_FILE、JWT_KEY_ROTATION（天）、JWT_KEY_PUBLISH（分钟）、
+// JWT_ISSUER、JWT_ACCESS_TTL（分钟）、JWT_REFRESH_TTL（小时）、JWT_MFA_TTL（分钟）、JWT_DENYLIST
 func InitJWT() {
-	if secret := os.Getenv("JWT_SECRET"); secret != "" {
-		JWT.SecretKey = secret
+	envConfig.LoadEnv(".env")
+
+	JWT.SecretKey = envConfig.GetEnv("JWT_SECRET", JWT.SecretKey)
+	JWT.Algorithm = envConfig.GetEnv("JWT_ALGORITHM", JWT.Algorithm)
+	JWT.KeysFile = envConfig.GetEnv("JWT_KEYS_FILE", JWT.KeysFile)
+	JWT.KeyRotation = time.Duration(envConfig.GetEnvInt("JWT_KEY_ROTATION", int(JWT.KeyRotation/(24*time.Hour)))) * 24 * time.Hour
+	JWT.KeyPublish = time.Duration(envConfig.GetEnvInt("JWT_KEY_PUBLISH", int(JWT.KeyPublish/time.Minute))) * time.Minute
+	JWT.Issuer = envConfig.GetEnv("JWT_ISSUER", JWT.Issuer)
+	JWT.AccessTTL = time.Duration(envConfig.GetEnvInt("JWT_ACCESS_TTL", int(JWT.AccessTTL/time.Minute))) * time.Minute
+	JWT.RefreshTTL = time.Duration(envConfig.GetEnvInt("JWT_REFRESH_TTL", int(JWT.RefreshTTL/time.Hour))) * time.Hour
+	JWT.MFATTL = time.Duration(envConfig.GetEnvInt("JWT_MFA_TTL", int(JWT.MFATTL/time.Minute))) * time.Minute
+	JWT.Denylist = envConfig.GetEnv("JWT_DENYLIST", JWT.Denylist)
+}
+
+// LoadTokenManager 返回全局令牌管理器，首次调用时按配置创建
+func LoadTokenManager() (*auth.TokenManager, error) {
+	tokenManagerOnce.Do(func() {
+		InitJWT()
+		tokenManager, tokenManagerErr = newTokenManager()
+	})
+	return tokenManager, tokenManagerErr
+}
+
+// JWTKeyStore 返回配置的密钥文件存储
+func JWTKeyStore() *auth.FileKeyStore {
+	return auth.NewFileKeyStore(JWT.KeysFile)
+}
+
+// appEnv 应用启动时确定的运行环境，未启动应用（如部分 artisan 命令）时为空，使用 APP_ENV
+var appEnv string
+
+func init() {
+	framework.RegisterBootCheck(checkSecrets)
+}
+
+// checkSecrets 启动时检查令牌签名密钥，生产环境使用默认或过短的密钥时拒绝启动
+// 运行环境以应用配置为准（config/app.json 的 env 优先于 APP_ENV）
+func checkSecrets(app *framework.Application) error {
+	appEnv = app.Env
+	if !app.IsProduction() {
+		return nil
+	}
+	InitJWT()
+	if _, err := jwtKeys(); err != nil {
+		return err
 	}
-	if expires := os.Getenv("JWT_EXPIRES"); expires != "" {
-		if exp, err := strconv.Atoi(expires); err == nil {
-			JWT.ExpiresIn = exp
+	_, err := LoadSignedTokens()
+	return err
+}
+
+// isProduction 是否为生产环境
+func isProduction() bool {
+	if appEnv != "" {
+		return appEnv == "production"
+	}
+	return envConfig.GetEnv("APP_ENV", "") == "production"
+}
+
+// jwtKeys 返回令牌签名密钥
+// 密钥文件存在时使用文件中的密钥；否则 HS256 使用 JWT_SECRET，
+// 非对称算法在开发环境自动生成密钥文件，生产环境需要先执行 artisan jwt:rotate
+func jwtKeys() (auth.KeySource, error) {
+	store := JWTKeyStore()
+	if store.Exists() {
+		return store, nil
+	}
+
+	if JWT.Algorithm == auth.AlgHS256 {
+		if JWT.SecretKey == "" || JWT.SecretKey == defaultJWTSecret || len(JWT.SecretKey) < 32 {
+			if isProduction() {
+				return nil, fmt.Errorf("JWT_SECRET must be set to a random value of at least 32 characters in production, run `artisan key:generate`")
+			}
+			hlog.Warn("JWT_SECRET is not set or too short, run `artisan key:generate` before deploying")
 		}
+		return auth.SecretKey([]byte(JWT.SecretKey)), nil
+	}
+
+	if isProduction() {
+		return nil, fmt.Errorf("JWT key file %s not found, run `artisan jwt:rotate` to create it", JWT.KeysFile)
+	}
+	key, err := store.Rotate(JWT.Algorithm, time.Now(), 0, JWT.AccessTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate JWT key: %w", err)
+	}
+	hlog.Warnf("Generated %s JWT signing key %s in %s", key.Algorithm, key.ID, JWT.KeysFile)
+	return store, nil
+}
+
+// newTokenManager 根据配置创建令牌管理器
+func newTokenManager() (*auth.TokenManager, error) {
+	keys, err := jwtKeys()
+	if err != nil {
+		return nil, err
 	}
+
+	db := database.GetDB()
+	if db == nil {
+		db = DB
+	}
+
+	store, err := auth.NewRefreshTokenStore(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var denylist auth.Denylist
+	switch JWT.Denylist {
+	case "database":
+		if denylist, err = auth.NewGormDenylist(db); err != nil {
+			return nil, err
+		}
+	case "redis":
+		client := goredis.NewClient(&goredis.Options{
+			Addr:     envConfig.GetEnv("REDIS_HOST", "127.0.0.1") + ":" + envConfig.GetEnv("REDIS_PORT", "6379"),
+			Password: envConfig.GetEnv("REDIS_PASSWORD", ""),
+			DB:       envConfig.GetEnvInt("REDIS_DB", 0),
+		})
+		denylist = auth.NewRedisDenylist(client, envConfig.GetEnv("REDIS_PREFIX", "")+"auth:denied")
+	default:
+		return nil, fmt.Errorf("unsupported token denylist: %s", JWT.Denylist)
+	}
+
+	return auth.NewTokenManager(auth.Config{
+		Keys:       keys,
+		Issuer:     JWT.Issuer,
+		AccessTTL:  JWT.AccessTTL,
+		RefreshTTL: JWT.RefreshTTL,
+		MFATTL:     JWT.MFATTL,
+	}, store, denylist), nil
 }
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+)
+
+// 生产环境以应用的运行环境为准，未设置 APP_ENV 时同样拒绝默认密钥
+func TestCheckSecrets(t *testing.T) {
+	saved := *JWT
+	t.Cleanup(func() {
+		*JWT = saved
+		appEnv = ""
+	})
+	t.Setenv("APP_ENV", "")
+	t.Setenv("APP_KEY", "")
+	t.Setenv("JWT_ALGORITHM", "HS256")
+	t.Setenv("JWT_KEYS_FILE", filepath.Join(t.TempDir(), "jwt.json"))
+	t.Setenv("JWT_SECRET", defaultJWTSecret)
+
+	production := framework.NewApplication().SetEnv("production")
+	if err := checkSecrets(production); err == nil {
+		t.Fatal("default JWT_SECRET should be rejected in production")
+	}
+	if !isProduction() {
+		t.Error("application env should take precedence over APP_ENV")
+	}
+
+	t.Setenv("JWT_SECRET", strings.Repeat("s", 32))
+	if err := checkSecrets(production); err != nil {
+		t.Fatalf("checkSecrets with a strong secret: %v", err)
+	}
+
+	t.Setenv("JWT_SECRET", defaultJWTSecret)
+	if err := checkSecrets(framework.NewApplication()); err != nil {
+		t.Fatalf("default secret should only warn in development, got %v", err)
+	}
+	if isProduction() {
+		t.Error("development application should not be production")
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	envConfig "github.com/clarkzhu2020/aidecms/pkg/config"
+)
+
+// OIDCProviderConfig OpenID Connect 身份提供方配置
+type OIDCProviderConfig struct {
+	Name         string   `json:"name"`         // 路由中的标识，如 /auth/oidc/google/login
+	DisplayName  string   `json:"display_name"` // 登录按钮上显示的名称
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	RedirectURL  string   `json:"redirect_url"` // 默认为 APP_URL/auth/oidc/<name>/callback
+	Scopes       []string `json:"scopes"`       // 默认为 openid email profile
+
+	// 账户关联和自动注册规则
+	AutoProvision  bool     `json:"auto_provision"`  // 没有关联账户时自动创建用户
+	LinkByEmail    bool     `json:"link_by_email"`   // 身份提供方已验证的邮箱与本站已验证邮箱的用户相同时自动关联
+	AllowedDomains []string `json:"allowed_domains"` // 自动注册和按邮箱关联时要求的邮箱域名，为空不限制
+	DefaultRole    string   `json:"default_role"`    // 自动注册用户的角色，默认为 user
+}
+
+// OIDCConfig 第三方登录配置
+type OIDCConfig struct {
+	Providers map[string]*OIDCProviderConfig `json:"providers"`
+	StateTTL  time.Duration                  `json:"-"` // 发起登录到回调的最长时间
+}
+
+// LoadOIDCConfig 加载身份提供方配置
+// OIDC_PROVIDERS 为逗号分隔的提供方名称，每个提供方读取 OIDC_<NAME>_ISSUER、_CLIENT_ID、_CLIENT_SECRET、
+// _REDIRECT_URL、_SCOPES、_DISPLAY_NAME、_AUTO_PROVISION、_LINK_BY_EMAIL、_ALLOWED_DOMAINS、_DEFAULT_ROLE；
+// config/oidc.json 存在时其中的提供方覆盖同名的环境变量配置
+func LoadOIDCConfig() (*OIDCConfig, error) {
+	envConfig.LoadEnv(".env")
+
+	config := &OIDCConfig{
+		Providers: make(map[string]*OIDCProviderConfig),
+		StateTTL:  time.Duration(envConfig.GetEnvInt("OIDC_STATE_TTL", 10)) * time.Minute,
+	}
+	for _, name := range splitList(envConfig.GetEnv("OIDC_PROVIDERS", "")) {
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		config.Providers[name] = &OIDCProviderConfig{
+			Name:           name,
+			DisplayName:    envConfig.GetEnv(prefix+"DISPLAY_NAME", ""),
+			Issuer:         envConfig.GetEnv(prefix+"ISSUER", ""),
+			ClientID:       envConfig.GetEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret:   envConfig.GetEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:    envConfig.GetEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:         strings.Fields(envConfig.GetEnv(prefix+"SCOPES", "")),
+			AutoProvision:  envConfig.GetEnvBool(prefix+"AUTO_PROVISION", false),
+			LinkByEmail:    envConfig.GetEnvBool(prefix+"LINK_BY_EMAIL", false),
+			AllowedDomains: splitList(envConfig.GetEnv(prefix+"ALLOWED_DOMAINS", "")),
+			DefaultRole:    envConfig.GetEnv(prefix+"DEFAULT_ROLE", ""),
+		}
+	}
+
+	configPath := "config/oidc.json"
+	if data, err := os.ReadFile(configPath); err == nil {
+		var fileConfig OIDCConfig
+		if err := json.Unmarshal(data, &fileConfig); err != nil {
+			return nil, fmt.Errorf("failed to load OIDC config file: %w", err)
+		}
+		for name, provider := range fileConfig.Providers {
+			provider.Name = name
+			config.Providers[name] = provider
+		}
+	}
+
+	appURL := strings.TrimRight(envConfig.GetEnv("APP_URL", "http://localhost:8888"), "/")
+	for name, provider := range config.Providers {
+		if provider.Issuer == "" || provider.ClientID == "" {
+			return nil, fmt.Errorf("OIDC provider %q requires an issuer and a client id", name)
+		}
+		if provider.DisplayName == "" {
+			provider.DisplayName = name
+		}
+		if provider.RedirectURL == "" {
+			provider.RedirectURL = appURL + "/auth/oidc/" + name + "/callback"
+		}
+		if provider.DefaultRole == "" {
+			provider.DefaultRole = "user"
+		}
+	}
+	return config, nil
+}
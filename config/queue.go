@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+
+	envConfig "github.com/clarkzhu2020/aidecms/pkg/config"
+	"github.com/clarkzhu2020/aidecms/pkg/queue"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// QueueDriver 获取队列驱动名称（memory、redis）
+func QueueDriver() string {
+	return envConfig.GetEnv("QUEUE_DRIVER", "memory")
+}
+
+// LoadQueue 根据配置创建队列管理器
+// memory 驱动只在当前进程内有效，任务需由同一进程中的工作进程处理
+func LoadQueue() (*queue.Queue, error) {
+	switch driver := QueueDriver(); driver {
+	case "redis":
+		client := goredis.NewClient(&goredis.Options{
+			Addr:     envConfig.GetEnv("REDIS_HOST", "127.0.0.1") + ":" + envConfig.GetEnv("REDIS_PORT", "6379"),
+			Password: envConfig.GetEnv("REDIS_PASSWORD", ""),
+			DB:       envConfig.GetEnvInt("REDIS_DB", 0),
+		})
+		prefix := envConfig.GetEnv("REDIS_PREFIX", "") + "queue"
+		return queue.NewQueue(queue.NewRedisDriver(client, prefix)), nil
+	case "memory":
+		return queue.NewQueue(queue.NewMemoryDriver()), nil
+	default:
+		return nil, fmt.Errorf("unsupported queue driver: %s", driver)
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	envConfig "github.com/clarkzhu2020/aidecms/pkg/config"
+)
+
+// SIWEConfig 以太坊钱包登录（Sign-In With Ethereum）配置
+type SIWEConfig struct {
+	Domain        string        // 消息中的 domain 和 URI 必须为该站点，默认为 APP_URL 的 host
+	URI           string        // 建议客户端在消息中使用的 URI，默认为 APP_URL
+	Statement     string        // 建议客户端在消息中显示的说明
+	ChainIDs      []int64       // 允许的 Chain ID
+	NonceTTL      time.Duration // nonce 的有效期
+	AutoProvision bool          // 未关联账户的钱包登录时自动创建用户
+	DefaultRole   string        // 自动注册用户的角色
+}
+
+// LoadSIWEConfig 从环境变量读取钱包登录配置
+// SIWE_DOMAIN、SIWE_STATEMENT、SIWE_CHAIN_IDS（逗号分隔，默认为1）、SIWE_NONCE_TTL（分钟）、
+// SIWE_AUTO_PROVISION、SIWE_DEFAULT_ROLE
+func LoadSIWEConfig() (*SIWEConfig, error) {
+	envConfig.LoadEnv(".env")
+
+	appURL := strings.TrimRight(envConfig.GetEnv("APP_URL", "http://localhost:8888"), "/")
+	parsed, err := url.Parse(appURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APP_URL: %w", err)
+	}
+
+	config := &SIWEConfig{
+		Domain:        envConfig.GetEnv("SIWE_DOMAIN", parsed.Host),
+		URI:           appURL,
+		Statement:     envConfig.GetEnv("SIWE_STATEMENT", "Sign in to "+envConfig.GetEnv("APP_NAME", "AideCMS")),
+		NonceTTL:      time.Duration(envConfig.GetEnvInt("SIWE_NONCE_TTL", 10)) * time.Minute,
+		AutoProvision: envConfig.GetEnvBool("SIWE_AUTO_PROVISION", true),
+		DefaultRole:   envConfig.GetEnv("SIWE_DEFAULT_ROLE", "user"),
+	}
+	for _, value := range splitList(envConfig.GetEnv("SIWE_CHAIN_IDS", "1")) {
+		chainID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || chainID <= 0 {
+			return nil, fmt.Errorf("invalid chain id %q in SIWE_CHAIN_IDS", value)
+		}
+		config.ChainIDs = append(config.ChainIDs, chainID)
+	}
+	if config.Domain == "" {
+		return nil, fmt.Errorf("SIWE_DOMAIN or APP_URL must be set")
+	}
+	if config.Domain != parsed.Host {
+		config.URI = "https://" + config.Domain
+	}
+	return config, nil
+}
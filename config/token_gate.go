@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	envConfig "github.com/clarkzhu2020/aidecms/pkg/config"
+)
+
+// TokenGateConfig 按链上持仓限制内容访问的配置
+type TokenGateConfig struct {
+	RPCs     map[int64]string // Chain ID 对应的 JSON-RPC 地址
+	CacheTTL time.Duration    // 余额查询结果的缓存时间
+}
+
+// LoadTokenGateConfig 读取各链的 RPC 地址和缓存时间
+// 以太坊主网（1）默认使用 WEB3_ETHEREUM_RPC，BSC（56）默认使用 WEB3_BSC_RPC，
+// 其他链通过 TOKEN_GATE_RPC_<CHAIN_ID> 配置（也可以覆盖前两者）；TOKEN_GATE_CACHE_TTL 单位为秒
+func LoadTokenGateConfig() *TokenGateConfig {
+	envConfig.LoadEnv(".env")
+
+	config := &TokenGateConfig{
+		RPCs:     make(map[int64]string),
+		CacheTTL: time.Duration(envConfig.GetEnvInt("TOKEN_GATE_CACHE_TTL", 300)) * time.Second,
+	}
+	if rpc := envConfig.GetEnv("WEB3_ETHEREUM_RPC", ""); rpc != "" {
+		config.RPCs[1] = rpc
+	}
+	if rpc := envConfig.GetEnv("WEB3_BSC_RPC", ""); rpc != "" {
+		config.RPCs[56] = rpc
+	}
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		suffix, ok := strings.CutPrefix(key, "TOKEN_GATE_RPC_")
+		if !ok || value == "" {
+			continue
+		}
+		if chainID, err := strconv.ParseInt(suffix, 10, 64); err == nil && chainID > 0 {
+			config.RPCs[chainID] = value
+		}
+	}
+	return config
+}
@@ -0,0 +1,217 @@
+package factories
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// DefaultPassword 工厂生成的用户的明文密码
+const DefaultPassword = "password"
+
+var (
+	fake = database.NewFaker(0)
+
+	passwordOnce sync.Once
+	passwordHash string
+)
+
+// Seed 重置数据生成器，相同的种子生成相同的数据，用于测试
+func Seed(seed int64) {
+	fake = database.NewFaker(seed)
+}
+
+// Faker 返回工厂共用的数据生成器
+func Faker() *database.Faker {
+	return fake
+}
+
+// hashedPassword 返回 DefaultPassword 的哈希，只计算一次
+func hashedPassword() string {
+	passwordOnce.Do(func() {
+		hash, err := bcrypt.GenerateFromPassword([]byte(DefaultPassword), bcrypt.DefaultCost)
+		if err != nil {
+			panic(err)
+		}
+		passwordHash = string(hash)
+	})
+	return passwordHash
+}
+
+// User 用户工厂，密码为 DefaultPassword
+func User() *database.Factory[models.User] {
+	return database.NewFactory(fake, func(fake *database.Faker) models.User {
+		return models.User{
+			Username:  fake.Username(),
+			Email:     fake.Email(),
+			Password:  hashedPassword(),
+			FirstName: fake.FirstName(),
+			LastName:  fake.LastName(),
+			Avatar:    fake.ImageURL(200, 200),
+			Status:    "active",
+		}
+	})
+}
+
+// Role 角色工厂
+func Role() *database.Factory[models.Role] {
+	return database.NewFactory(fake, func(fake *database.Faker) models.Role {
+		name := fake.Unique("role", func() string { return strings.Join(fake.Words(2), "_") })
+		return models.Role{
+			Name:        name,
+			DisplayName: fake.Title(2),
+			Description: fake.Sentence(8),
+		}
+	})
+}
+
+// Category 分类工厂
+func Category() *database.Factory[models.Category] {
+	return database.NewFactory(fake, func(fake *database.Faker) models.Category {
+		name := fake.Title(fake.IntBetween(1, 2))
+		return models.Category{
+			Name:            name,
+			Slug:            fake.Slug(2),
+			Description:     fake.Sentence(12),
+			Sort:            fake.IntBetween(0, 100),
+			MetaTitle:       name,
+			MetaDescription: fake.Sentence(16),
+		}
+	})
+}
+
+// Tag 标签工厂
+func Tag() *database.Factory[models.Tag] {
+	return database.NewFactory(fake, func(fake *database.Faker) models.Tag {
+		name := fake.Unique("tag", func() string { return strings.Join(fake.Words(2), " ") })
+		return models.Tag{
+			Name: name,
+			Slug: fake.Slug(1),
+		}
+	})
+}
+
+// Post 文章工厂，未指定作者和分类时自动创建
+func Post() *database.Factory[models.Post] {
+	return database.NewFactory(fake, func(fake *database.Faker) models.Post {
+		title := fake.Title(fake.IntBetween(3, 8))
+		return models.Post{
+			Title:           title,
+			Slug:            fake.Slug(4),
+			Content:         fake.Paragraphs(fake.IntBetween(3, 8)),
+			Excerpt:         fake.Paragraph(2),
+			FeaturedImage:   fake.ImageURL(1200, 630),
+			Status:          "draft",
+			ViewCount:       fake.IntBetween(0, 5000),
+			LikeCount:       fake.IntBetween(0, 300),
+			MetaTitle:       title,
+			MetaDescription: fake.Sentence(20),
+			MetaKeywords:    strings.Join(fake.Words(4), ","),
+		}
+	}).BeforeCreating(func(tx *gorm.DB, post *models.Post) error {
+		if post.AuthorID == 0 {
+			author, err := User().Create(tx)
+			if err != nil {
+				return err
+			}
+			post.AuthorID = author.ID
+		}
+		if post.CategoryID == 0 {
+			category, err := Category().Create(tx)
+			if err != nil {
+				return err
+			}
+			post.CategoryID = category.ID
+		}
+		return nil
+	})
+}
+
+// Published 文章状态：最近一年内发布
+func Published(post *models.Post) {
+	publishedAt := fake.TimeBetween(time.Now().AddDate(-1, 0, 0), time.Now())
+	post.Status = "published"
+	post.PublishedAt = &publishedAt
+}
+
+// Comment 评论工厂，未指定文章时自动创建；UserID 为0时为游客评论
+func Comment() *database.Factory[models.Comment] {
+	return database.NewFactory(fake, func(fake *database.Faker) models.Comment {
+		return models.Comment{
+			Content:     fake.Paragraph(fake.IntBetween(1, 3)),
+			AuthorName:  fake.FirstName() + " " + fake.LastName(),
+			AuthorEmail: fake.Email(),
+			AuthorURL:   fake.URL(),
+			AuthorIP:    fake.IPv4(),
+			UserAgent:   "Mozilla/5.0 (compatible; AideCMS Seeder)",
+			Status:      fake.Pick("approved", "approved", "approved", "pending", "spam"),
+			Rating:      fake.IntBetween(0, 5),
+		}
+	}).BeforeCreating(func(tx *gorm.DB, comment *models.Comment) error {
+		if comment.PostID == 0 {
+			post, err := Post().State(Published).Create(tx)
+			if err != nil {
+				return err
+			}
+			comment.PostID = post.ID
+		}
+		return nil
+	})
+}
+
+// Media 媒体工厂，未指定上传用户时自动创建
+func Media() *database.Factory[models.Media] {
+	return database.NewFactory(fake, func(fake *database.Faker) models.Media {
+		hash := fake.Hex(64)
+		width, height := fake.IntBetween(400, 2400), fake.IntBetween(300, 1600)
+		fileName := hash[:16] + ".jpg"
+		path := fmt.Sprintf("uploads/%s/%s", time.Now().Format("2006/01"), fileName)
+		return models.Media{
+			FileName:     fileName,
+			OriginalName: strings.Join(fake.Words(2), "-") + ".jpg",
+			FilePath:     path,
+			FileURL:      "/" + path,
+			FileSize:     int64(fake.IntBetween(20_000, 5_000_000)),
+			FileType:     "image",
+			MimeType:     "image/jpeg",
+			Extension:    ".jpg",
+			Hash:         hash,
+			Width:        width,
+			Height:       height,
+			Description:  fake.Sentence(10),
+			Alt:          fake.Sentence(5),
+			Title:        fake.Title(3),
+		}
+	}).BeforeCreating(func(tx *gorm.DB, media *models.Media) error {
+		if media.UserID == 0 {
+			user, err := User().Create(tx)
+			if err != nil {
+				return err
+			}
+			media.UserID = user.ID
+		}
+		return nil
+	})
+}
+
+// Menu 菜单工厂
+func Menu() *database.Factory[models.Menu] {
+	return database.NewFactory(fake, func(fake *database.Faker) models.Menu {
+		word := fake.Word()
+		return models.Menu{
+			Name:     fake.Unique("menu", func() string { return word + "-" + fake.Hex(4) }),
+			Title:    strings.ToUpper(word[:1]) + word[1:],
+			URL:      "/" + word,
+			Target:   "_self",
+			Position: fake.Pick("header", "footer", "sidebar"),
+			Sort:     fake.IntBetween(1, 20),
+			IsActive: true,
+		}
+	})
+}
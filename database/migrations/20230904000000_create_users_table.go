@@ -1,18 +1,43 @@
 package migrations
 
 import (
+	"time"
+
 	"github.com/clarkzhu2020/aidecms/pkg/database"
 	"gorm.io/gorm"
 )
 
-type User struct {
+func init() {
+	Register("20230904000000_create_users_table", &CreateUsersTable{})
+}
+
+// usersTable 创建时的用户表
+type usersTable struct {
 	gorm.Model
-	Id       uint   `gorm:"primaryKey;autoIncrement;not null;index"`
-	Name     string `gorm:"size:255;not null"`
-	Email    string `gorm:"size:255;uniqueIndex"`
-	Password string `gorm:"size:255"`
+	Username  string `gorm:"size:100;not null;unique"`
+	Email     string `gorm:"size:100;not null;unique"`
+	Password  string `gorm:"size:100;not null"`
+	FirstName string `gorm:"size:100"`
+	LastName  string `gorm:"size:100"`
+	Avatar    string `gorm:"size:255"`
+	LastLogin *time.Time
+	Status    string `gorm:"size:20;default:'active'"`
+}
+
+// TableName 指定表名
+func (usersTable) TableName() string {
+	return database.TableName("users")
+}
+
+// CreateUsersTable 创建用户表
+type CreateUsersTable struct{}
+
+// Up 执行迁移
+func (m *CreateUsersTable) Up(tx *gorm.DB) error {
+	return createTables(tx, &usersTable{})
 }
 
-func CreateUsersTable(db *database.Database) error {
-	return db.DB.AutoMigrate(&User{})
+// Down 回滚迁移
+func (m *CreateUsersTable) Down(tx *gorm.DB) error {
+	return dropTables(tx, &usersTable{})
 }
@@ -0,0 +1,194 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240101000000_create_cms_tables", &CreateCMSTables{})
+}
+
+// rolesTable 创建时的角色表
+type rolesTable struct {
+	gorm.Model
+	Name        string `gorm:"size:50;uniqueIndex;not null"`
+	DisplayName string `gorm:"size:100"`
+	Description string `gorm:"type:text"`
+	IsSystem    bool   `gorm:"default:false"`
+}
+
+// TableName 指定表名
+func (rolesTable) TableName() string {
+	return database.TableName("roles")
+}
+
+// permissionsTable 创建时的权限表
+type permissionsTable struct {
+	gorm.Model
+	Name        string `gorm:"size:100;uniqueIndex;not null"`
+	DisplayName string `gorm:"size:100"`
+	Description string `gorm:"type:text"`
+	Resource    string `gorm:"size:50"`
+	Action      string `gorm:"size:20"`
+	IsSystem    bool   `gorm:"default:false"`
+}
+
+// TableName 指定表名
+func (permissionsTable) TableName() string {
+	return database.TableName("permissions")
+}
+
+// rolePermissionsTable 角色权限关联表
+type rolePermissionsTable struct {
+	RoleID       uint `gorm:"primaryKey"`
+	PermissionID uint `gorm:"primaryKey"`
+}
+
+// TableName 指定表名
+func (rolePermissionsTable) TableName() string {
+	return database.TableName("role_permissions")
+}
+
+// userRolesTable 用户角色关联表
+type userRolesTable struct {
+	UserID uint `gorm:"primaryKey"`
+	RoleID uint `gorm:"primaryKey"`
+}
+
+// TableName 指定表名
+func (userRolesTable) TableName() string {
+	return database.TableName("user_roles")
+}
+
+// mediaTable 创建时的媒体表
+type mediaTable struct {
+	gorm.Model
+	UserID       uint   `gorm:"index"`
+	FileName     string `gorm:"size:255;not null"`
+	OriginalName string `gorm:"size:255"`
+	FilePath     string `gorm:"size:500;not null"`
+	FileURL      string `gorm:"size:500"`
+	FileSize     int64
+	FileType     string `gorm:"size:50"`
+	MimeType     string `gorm:"size:100"`
+	Extension    string `gorm:"size:20"`
+	Hash         string `gorm:"size:64;index"`
+	Width        int
+	Height       int
+	Thumbnails   string `gorm:"type:text"`
+	Description  string `gorm:"type:text"`
+	Alt          string `gorm:"size:255"`
+	Title        string `gorm:"size:255"`
+	Status       string `gorm:"size:20;default:'active'"`
+	UploadedAt   time.Time
+
+	User *usersTable `gorm:"foreignKey:UserID"`
+}
+
+// TableName 指定表名
+func (mediaTable) TableName() string {
+	return database.TableName("media")
+}
+
+// categoriesTable 创建时的分类表
+type categoriesTable struct {
+	gorm.Model
+	Name            string `gorm:"size:100;not null"`
+	Slug            string `gorm:"size:100;uniqueIndex;not null"`
+	Description     string `gorm:"type:text"`
+	ParentID        *uint  `gorm:"index"`
+	Sort            int    `gorm:"default:0"`
+	Image           string `gorm:"size:500"`
+	MetaTitle       string `gorm:"size:200"`
+	MetaDescription string `gorm:"type:text"`
+
+	Children []categoriesTable `gorm:"foreignKey:ParentID"`
+	Posts    []postsTable      `gorm:"foreignKey:CategoryID"`
+}
+
+// TableName 指定表名
+func (categoriesTable) TableName() string {
+	return database.TableName("categories")
+}
+
+// tagsTable 创建时的标签表
+type tagsTable struct {
+	gorm.Model
+	Name  string `gorm:"size:50;uniqueIndex;not null"`
+	Slug  string `gorm:"size:50;uniqueIndex;not null"`
+	Count int    `gorm:"default:0"`
+}
+
+// TableName 指定表名
+func (tagsTable) TableName() string {
+	return database.TableName("tags")
+}
+
+// postsTable 创建时的文章表
+type postsTable struct {
+	gorm.Model
+	Title           string `gorm:"size:200;not null"`
+	Slug            string `gorm:"size:200;uniqueIndex;not null"`
+	Content         string `gorm:"type:longtext"`
+	Excerpt         string `gorm:"type:text"`
+	FeaturedImage   string `gorm:"size:500"`
+	Status          string `gorm:"size:20;default:'draft';index"`
+	AuthorID        uint   `gorm:"index;not null"`
+	CategoryID      uint   `gorm:"index"`
+	ViewCount       int    `gorm:"default:0"`
+	LikeCount       int    `gorm:"default:0"`
+	CommentCount    int    `gorm:"default:0"`
+	PublishedAt     *time.Time
+	MetaTitle       string `gorm:"size:200"`
+	MetaDescription string `gorm:"type:text"`
+	MetaKeywords    string `gorm:"size:500"`
+
+	Author *usersTable `gorm:"foreignKey:AuthorID"`
+}
+
+// TableName 指定表名
+func (postsTable) TableName() string {
+	return database.TableName("posts")
+}
+
+// postTagsTable 文章标签关联表
+type postTagsTable struct {
+	PostID uint `gorm:"primaryKey"`
+	TagID  uint `gorm:"primaryKey"`
+}
+
+// TableName 指定表名
+func (postTagsTable) TableName() string {
+	return database.TableName("post_tags")
+}
+
+// CreateCMSTables 创建角色权限、媒体、分类、标签和文章表
+type CreateCMSTables struct{}
+
+// tables 按依赖顺序排列的数据表
+func (m *CreateCMSTables) tables() []interface{} {
+	return []interface{}{
+		&rolesTable{},
+		&permissionsTable{},
+		&rolePermissionsTable{},
+		&userRolesTable{},
+		&mediaTable{},
+		&categoriesTable{},
+		&tagsTable{},
+		&postsTable{},
+		&postTagsTable{},
+	}
+}
+
+// Up 执行迁移
+func (m *CreateCMSTables) Up(tx *gorm.DB) error {
+	return createTables(tx, m.tables()...)
+}
+
+// Down 回滚迁移
+func (m *CreateCMSTables) Down(tx *gorm.DB) error {
+	return dropTables(tx, m.tables()...)
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240102000000_create_menus_table", &CreateMenusTable{})
+}
+
+// menusTable 创建时的菜单表
+type menusTable struct {
+	gorm.Model
+	Name        string `gorm:"size:100;not null"`
+	Title       string `gorm:"size:200"`
+	URL         string `gorm:"size:500"`
+	Icon        string `gorm:"size:100"`
+	Target      string `gorm:"size:20;default:_self"`
+	Position    string `gorm:"size:50;not null"`
+	ParentID    uint   `gorm:"default:0"`
+	Sort        int    `gorm:"default:0"`
+	IsActive    bool   `gorm:"default:true"`
+	CSSClass    string `gorm:"size:100"`
+	Description string `gorm:"size:500"`
+
+	Children []menusTable `gorm:"foreignKey:ParentID"`
+}
+
+// TableName 指定表名
+func (menusTable) TableName() string {
+	return database.TableName("menus")
+}
+
+// CreateMenusTable 创建菜单表
+type CreateMenusTable struct{}
+
+// Up 执行迁移
+func (m *CreateMenusTable) Up(tx *gorm.DB) error {
+	return createTables(tx, &menusTable{})
+}
+
+// Down 回滚迁移
+func (m *CreateMenusTable) Down(tx *gorm.DB) error {
+	return dropTables(tx, &menusTable{})
+}
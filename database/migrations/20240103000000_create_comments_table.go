@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240103000000_create_comments_table", &CreateCommentsTable{})
+}
+
+// commentsTable 创建时的评论表
+type commentsTable struct {
+	gorm.Model
+	PostID      uint   `gorm:"index;not null"`
+	UserID      uint   `gorm:"index"`
+	ParentID    uint   `gorm:"index;default:0"`
+	Content     string `gorm:"type:text;not null"`
+	AuthorName  string `gorm:"size:100"`
+	AuthorEmail string `gorm:"size:100"`
+	AuthorURL   string `gorm:"size:200"`
+	AuthorIP    string `gorm:"size:45"`
+	UserAgent   string `gorm:"size:500"`
+	Status      string `gorm:"size:20;default:pending"`
+	Rating      int    `gorm:"default:0"`
+	IsAnonymous bool   `gorm:"default:false"`
+
+	Children []commentsTable `gorm:"foreignKey:ParentID"`
+	User     *usersTable     `gorm:"foreignKey:UserID"`
+	Post     *postsTable     `gorm:"foreignKey:PostID"`
+}
+
+// TableName 指定表名
+func (commentsTable) TableName() string {
+	return database.TableName("comments")
+}
+
+// CreateCommentsTable 创建评论表
+type CreateCommentsTable struct{}
+
+// Up 执行迁移
+func (m *CreateCommentsTable) Up(tx *gorm.DB) error {
+	return createTables(tx, &commentsTable{})
+}
+
+// Down 回滚迁移
+func (m *CreateCommentsTable) Down(tx *gorm.DB) error {
+	return dropTables(tx, &commentsTable{})
+}
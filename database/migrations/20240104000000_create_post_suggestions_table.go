@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240104000000_create_post_suggestions_table", &CreatePostSuggestionsTable{})
+}
+
+// postSuggestionsTable 创建时的文章编辑建议表
+type postSuggestionsTable struct {
+	gorm.Model
+	PostID      uint   `gorm:"index;not null"`
+	Action      string `gorm:"size:32;index;not null"`
+	Language    string `gorm:"size:16"`
+	Payload     string `gorm:"type:text"`
+	Status      string `gorm:"size:20;default:'pending';index"`
+	Client      string `gorm:"size:64"`
+	RequestedBy uint   `gorm:"index"`
+
+	Post *postsTable `gorm:"foreignKey:PostID"`
+}
+
+// TableName 指定表名
+func (postSuggestionsTable) TableName() string {
+	return database.TableName("post_suggestions")
+}
+
+// CreatePostSuggestionsTable 创建文章编辑建议表
+type CreatePostSuggestionsTable struct{}
+
+// Up 执行迁移
+func (m *CreatePostSuggestionsTable) Up(tx *gorm.DB) error {
+	return createTables(tx, &postSuggestionsTable{})
+}
+
+// Down 回滚迁移
+func (m *CreatePostSuggestionsTable) Down(tx *gorm.DB) error {
+	return dropTables(tx, &postSuggestionsTable{})
+}
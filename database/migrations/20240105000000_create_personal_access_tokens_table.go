@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240105000000_create_personal_access_tokens_table", &CreatePersonalAccessTokensTable{})
+}
+
+// personalAccessTokensTable 创建时的个人访问令牌表
+type personalAccessTokensTable struct {
+	gorm.Model
+	UserID     uint       `gorm:"index;not null"`
+	Name       string     `gorm:"size:100;not null"`
+	TokenHash  string     `gorm:"size:64;uniqueIndex;not null"`
+	Prefix     string     `gorm:"size:16"`
+	Scopes     string     `gorm:"type:text"`
+	ExpiresAt  *time.Time `gorm:"index"`
+	LastUsedAt *time.Time
+	LastUsedIP string `gorm:"size:45"`
+
+	User *usersTable `gorm:"foreignKey:UserID"`
+}
+
+// TableName 指定表名
+func (personalAccessTokensTable) TableName() string {
+	return database.TableName("personal_access_tokens")
+}
+
+// CreatePersonalAccessTokensTable 创建个人访问令牌表
+type CreatePersonalAccessTokensTable struct{}
+
+// Up 执行迁移
+func (m *CreatePersonalAccessTokensTable) Up(tx *gorm.DB) error {
+	return createTables(tx, &personalAccessTokensTable{})
+}
+
+// Down 回滚迁移
+func (m *CreatePersonalAccessTokensTable) Down(tx *gorm.DB) error {
+	return dropTables(tx, &personalAccessTokensTable{})
+}
@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240106000000_create_two_factor_tables", &CreateTwoFactorTables{})
+}
+
+// twoFactorCredentialsTable 创建时的两步验证凭据表
+type twoFactorCredentialsTable struct {
+	gorm.Model
+	UserID         uint   `gorm:"uniqueIndex;not null"`
+	Secret         string `gorm:"size:64;not null"`
+	ConfirmedAt    *time.Time
+	LastUsedStep   int64
+	FailedAttempts int `gorm:"default:0"`
+
+	User *usersTable `gorm:"foreignKey:UserID"`
+}
+
+// TableName 指定表名
+func (twoFactorCredentialsTable) TableName() string {
+	return database.TableName("two_factor_credentials")
+}
+
+// recoveryCodesTable 创建时的恢复码表
+type recoveryCodesTable struct {
+	gorm.Model
+	UserID   uint   `gorm:"index;not null"`
+	CodeHash string `gorm:"size:64;not null"`
+	UsedAt   *time.Time
+}
+
+// TableName 指定表名
+func (recoveryCodesTable) TableName() string {
+	return database.TableName("two_factor_recovery_codes")
+}
+
+// rolesRequireTwoFactorColumn 角色表新增的强制两步验证字段
+type rolesRequireTwoFactorColumn struct {
+	RequireTwoFactor bool `gorm:"default:false"`
+}
+
+// TableName 指定表名
+func (rolesRequireTwoFactorColumn) TableName() string {
+	return database.TableName("roles")
+}
+
+// CreateTwoFactorTables 创建两步验证凭据和恢复码表，并为角色添加强制两步验证字段
+type CreateTwoFactorTables struct{}
+
+// Up 执行迁移
+func (m *CreateTwoFactorTables) Up(tx *gorm.DB) error {
+	if err := createTables(tx, &twoFactorCredentialsTable{}, &recoveryCodesTable{}); err != nil {
+		return err
+	}
+	return addColumns(tx, &rolesRequireTwoFactorColumn{}, "RequireTwoFactor")
+}
+
+// Down 回滚迁移
+func (m *CreateTwoFactorTables) Down(tx *gorm.DB) error {
+	if err := dropColumns(tx, &rolesRequireTwoFactorColumn{}, "RequireTwoFactor"); err != nil {
+		return err
+	}
+	return dropTables(tx, &twoFactorCredentialsTable{}, &recoveryCodesTable{})
+}
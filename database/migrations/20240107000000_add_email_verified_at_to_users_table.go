@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240107000000_add_email_verified_at_to_users_table", &AddEmailVerifiedAtToUsersTable{})
+}
+
+// usersEmailVerifiedAtColumn 用户表新增的邮箱验证时间
+type usersEmailVerifiedAtColumn struct {
+	EmailVerifiedAt *time.Time
+}
+
+// TableName 指定表名
+func (usersEmailVerifiedAtColumn) TableName() string {
+	return database.TableName("users")
+}
+
+// AddEmailVerifiedAtToUsersTable 为用户表添加邮箱验证时间
+// 已有用户视为已验证，避免开启 AUTH_REQUIRE_VERIFIED_EMAIL 后无法登录
+type AddEmailVerifiedAtToUsersTable struct{}
+
+// Up 执行迁移
+func (m *AddEmailVerifiedAtToUsersTable) Up(tx *gorm.DB) error {
+	if err := addColumns(tx, &usersEmailVerifiedAtColumn{}, "EmailVerifiedAt"); err != nil {
+		return err
+	}
+	return tx.Model(&usersEmailVerifiedAtColumn{}).Where("email_verified_at IS NULL").
+		Update("email_verified_at", gorm.Expr("created_at")).Error
+}
+
+// Down 回滚迁移
+func (m *AddEmailVerifiedAtToUsersTable) Down(tx *gorm.DB) error {
+	return dropColumns(tx, &usersEmailVerifiedAtColumn{}, "EmailVerifiedAt")
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240108000000_add_locked_until_to_users_table", &AddLockedUntilToUsersTable{})
+}
+
+// usersLockedUntilColumn 用户表新增的登录锁定时间
+type usersLockedUntilColumn struct {
+	LockedUntil *time.Time
+}
+
+// TableName 指定表名
+func (usersLockedUntilColumn) TableName() string {
+	return database.TableName("users")
+}
+
+// AddLockedUntilToUsersTable 为用户表添加登录锁定时间
+type AddLockedUntilToUsersTable struct{}
+
+// Up 执行迁移
+func (m *AddLockedUntilToUsersTable) Up(tx *gorm.DB) error {
+	return addColumns(tx, &usersLockedUntilColumn{}, "LockedUntil")
+}
+
+// Down 回滚迁移
+func (m *AddLockedUntilToUsersTable) Down(tx *gorm.DB) error {
+	return dropColumns(tx, &usersLockedUntilColumn{}, "LockedUntil")
+}
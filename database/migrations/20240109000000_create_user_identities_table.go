@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240109000000_create_user_identities_table", &CreateUserIdentitiesTable{})
+}
+
+// userIdentitiesTable 创建时的第三方身份关联表
+type userIdentitiesTable struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_user_identities_user_provider"`
+	Provider    string `gorm:"size:50;not null;uniqueIndex:idx_user_identities_user_provider;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject     string `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email       string `gorm:"size:100"`
+	LastLoginAt *time.Time
+
+	User *usersTable `gorm:"foreignKey:UserID"`
+}
+
+// TableName 指定表名
+func (userIdentitiesTable) TableName() string {
+	return database.TableName("user_identities")
+}
+
+// oidcStatesTable 创建时的登录 state 表
+type oidcStatesTable struct {
+	ID           uint      `gorm:"primarykey"`
+	StateHash    string    `gorm:"size:64;not null;uniqueIndex"`
+	Provider     string    `gorm:"size:50;not null"`
+	Nonce        string    `gorm:"size:64;not null"`
+	CodeVerifier string    `gorm:"size:64;not null"`
+	UserID       uint      `gorm:"default:0"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time
+}
+
+// TableName 指定表名
+func (oidcStatesTable) TableName() string {
+	return database.TableName("oidc_states")
+}
+
+// CreateUserIdentitiesTable 创建第三方身份关联表和登录 state 表
+type CreateUserIdentitiesTable struct{}
+
+// Up 执行迁移
+func (m *CreateUserIdentitiesTable) Up(tx *gorm.DB) error {
+	return createTables(tx, &userIdentitiesTable{}, &oidcStatesTable{})
+}
+
+// Down 回滚迁移
+func (m *CreateUserIdentitiesTable) Down(tx *gorm.DB) error {
+	return dropTables(tx, &userIdentitiesTable{}, &oidcStatesTable{})
+}
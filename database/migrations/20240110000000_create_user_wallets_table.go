@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240110000000_create_user_wallets_table", &CreateUserWalletsTable{})
+}
+
+// userWalletsTable 创建时的钱包地址关联表
+type userWalletsTable struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index"`
+	Address     string `gorm:"size:42;not null;uniqueIndex"`
+	ChainID     int64
+	LastLoginAt *time.Time
+
+	User *usersTable `gorm:"foreignKey:UserID"`
+}
+
+// TableName 指定表名
+func (userWalletsTable) TableName() string {
+	return database.TableName("user_wallets")
+}
+
+// siweNoncesTable 创建时的钱包登录 nonce 表
+type siweNoncesTable struct {
+	ID        uint      `gorm:"primarykey"`
+	Nonce     string    `gorm:"size:64;not null;uniqueIndex"`
+	UserID    uint      `gorm:"default:0"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (siweNoncesTable) TableName() string {
+	return database.TableName("siwe_nonces")
+}
+
+// CreateUserWalletsTable 创建钱包地址关联表和钱包登录 nonce 表
+type CreateUserWalletsTable struct{}
+
+// Up 执行迁移
+func (m *CreateUserWalletsTable) Up(tx *gorm.DB) error {
+	return createTables(tx, &userWalletsTable{}, &siweNoncesTable{})
+}
+
+// Down 回滚迁移
+func (m *CreateUserWalletsTable) Down(tx *gorm.DB) error {
+	return dropTables(tx, &userWalletsTable{}, &siweNoncesTable{})
+}
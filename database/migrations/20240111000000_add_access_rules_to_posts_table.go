@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240111000000_add_access_rules_to_posts_table", &AddAccessRulesToPostsTable{})
+}
+
+// postsAccessRulesColumn 文章表新增的访问规则，以 JSON 文本保存
+type postsAccessRulesColumn struct {
+	AccessRules string `gorm:"type:text"`
+}
+
+// TableName 指定表名
+func (postsAccessRulesColumn) TableName() string {
+	return database.TableName("posts")
+}
+
+// categoriesAccessRulesColumn 分类表新增的访问规则
+type categoriesAccessRulesColumn struct {
+	AccessRules string `gorm:"type:text"`
+}
+
+// TableName 指定表名
+func (categoriesAccessRulesColumn) TableName() string {
+	return database.TableName("categories")
+}
+
+// AddAccessRulesToPostsTable 为文章表和分类表添加访问规则
+type AddAccessRulesToPostsTable struct{}
+
+// Up 执行迁移
+func (m *AddAccessRulesToPostsTable) Up(tx *gorm.DB) error {
+	if err := addColumns(tx, &postsAccessRulesColumn{}, "AccessRules"); err != nil {
+		return err
+	}
+	return addColumns(tx, &categoriesAccessRulesColumn{}, "AccessRules")
+}
+
+// Down 回滚迁移
+func (m *AddAccessRulesToPostsTable) Down(tx *gorm.DB) error {
+	if err := dropColumns(tx, &postsAccessRulesColumn{}, "AccessRules"); err != nil {
+		return err
+	}
+	return dropColumns(tx, &categoriesAccessRulesColumn{}, "AccessRules")
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240112000000_create_refresh_tokens_and_denied_tokens_tables", &CreateRefreshTokensAndDeniedTokensTables{})
+}
+
+// refreshTokensTable 创建时的刷新令牌表
+type refreshTokensTable struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null"`
+	UserID    uint      `gorm:"index;not null"`
+	SessionID string    `gorm:"size:36;index;not null"`
+	UserAgent string    `gorm:"size:255"`
+	IP        string    `gorm:"size:45"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (refreshTokensTable) TableName() string {
+	return database.TableName("refresh_tokens")
+}
+
+// deniedTokensTable 创建时的令牌注销列表
+type deniedTokensTable struct {
+	Key       string    `gorm:"column:token_key;primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
+
+// TableName 指定表名
+func (deniedTokensTable) TableName() string {
+	return database.TableName("denied_tokens")
+}
+
+// CreateRefreshTokensAndDeniedTokensTables 创建刷新令牌表和令牌注销列表
+// 早期版本在创建令牌存储时通过 AutoMigrate 建表，已存在的表保持不变
+type CreateRefreshTokensAndDeniedTokensTables struct{}
+
+// Up 执行迁移
+func (m *CreateRefreshTokensAndDeniedTokensTables) Up(tx *gorm.DB) error {
+	return createTables(tx, &refreshTokensTable{}, &deniedTokensTable{})
+}
+
+// Down 回滚迁移
+func (m *CreateRefreshTokensAndDeniedTokensTables) Down(tx *gorm.DB) error {
+	return dropTables(tx, &refreshTokensTable{}, &deniedTokensTable{})
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240113000000_add_locked_until_to_two_factor_credentials_table", &AddLockedUntilToTwoFactorCredentialsTable{})
+}
+
+// twoFactorCredentialsLockedUntilColumn 两步验证凭据表新增的锁定时间
+type twoFactorCredentialsLockedUntilColumn struct {
+	LockedUntil *time.Time
+}
+
+// TableName 指定表名
+func (twoFactorCredentialsLockedUntilColumn) TableName() string {
+	return database.TableName("two_factor_credentials")
+}
+
+// AddLockedUntilToTwoFactorCredentialsTable 为两步验证凭据表添加锁定时间
+type AddLockedUntilToTwoFactorCredentialsTable struct{}
+
+// Up 执行迁移
+func (m *AddLockedUntilToTwoFactorCredentialsTable) Up(tx *gorm.DB) error {
+	return addColumns(tx, &twoFactorCredentialsLockedUntilColumn{}, "LockedUntil")
+}
+
+// Down 回滚迁移
+func (m *AddLockedUntilToTwoFactorCredentialsTable) Down(tx *gorm.DB) error {
+	return dropColumns(tx, &twoFactorCredentialsLockedUntilColumn{}, "LockedUntil")
+}
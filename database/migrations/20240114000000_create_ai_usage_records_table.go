@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240114000000_create_ai_usage_records_table", &CreateAIUsageRecordsTable{})
+}
+
+// aiUsageRecordsTable 创建时的 AI 用量记录表
+type aiUsageRecordsTable struct {
+	ID               uint   `gorm:"primaryKey"`
+	UserID           uint   `gorm:"index;not null;default:0"`
+	Client           string `gorm:"size:64;index;not null"`
+	Provider         string `gorm:"size:32"`
+	Model            string `gorm:"size:128;index"`
+	Endpoint         string `gorm:"size:32"`
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Cost             float64
+	CreatedAt        time.Time `gorm:"index"`
+}
+
+// TableName 指定表名
+func (aiUsageRecordsTable) TableName() string {
+	return database.TableName("ai_usage_records")
+}
+
+// CreateAIUsageRecordsTable 创建 AI 用量记录表
+// 早期版本在创建用量账本时通过 AutoMigrate 建表，已存在的表保持不变
+type CreateAIUsageRecordsTable struct{}
+
+// Up 执行迁移
+func (m *CreateAIUsageRecordsTable) Up(tx *gorm.DB) error {
+	return createTables(tx, &aiUsageRecordsTable{})
+}
+
+// Down 回滚迁移
+func (m *CreateAIUsageRecordsTable) Down(tx *gorm.DB) error {
+	return dropTables(tx, &aiUsageRecordsTable{})
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240115000000_create_ai_conversations_table", &CreateAIConversationsTable{})
+}
+
+// aiConversationsTable 创建时的 AI 对话表
+type aiConversationsTable struct {
+	ID        uint       `gorm:"primaryKey"`
+	Key       string     `gorm:"column:conversation_key;size:255;uniqueIndex;not null"`
+	SessionID string     `gorm:"size:128;not null"`
+	Client    string     `gorm:"size:64;not null"`
+	UserID    uint       `gorm:"index;not null;default:0"`
+	MaxLen    int        `gorm:"not null;default:0"`
+	Messages  string     `gorm:"type:text"`
+	ExpiresAt *time.Time `gorm:"index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName 指定表名
+func (aiConversationsTable) TableName() string {
+	return database.TableName("ai_conversations")
+}
+
+// CreateAIConversationsTable 创建 AI 对话表
+// 早期版本在创建对话存储时通过 AutoMigrate 建表，已存在的表保持不变
+type CreateAIConversationsTable struct{}
+
+// Up 执行迁移
+func (m *CreateAIConversationsTable) Up(tx *gorm.DB) error {
+	return createTables(tx, &aiConversationsTable{})
+}
+
+// Down 回滚迁移
+func (m *CreateAIConversationsTable) Down(tx *gorm.DB) error {
+	return dropTables(tx, &aiConversationsTable{})
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("20240116000000_create_ai_embeddings_table", &CreateAIEmbeddingsTable{})
+}
+
+// aiEmbeddingsTable 创建时的向量表
+type aiEmbeddingsTable struct {
+	ID         uint   `gorm:"primaryKey"`
+	Namespace  string `gorm:"size:64;not null;index:idx_ai_embeddings_document"`
+	DocumentID string `gorm:"size:128;not null;index:idx_ai_embeddings_document"`
+	Chunk      int    `gorm:"not null;default:0"`
+	Content    string `gorm:"type:text"`
+	Model      string `gorm:"size:128"`
+	Dimensions int    `gorm:"not null;default:0"`
+	Vector     []byte
+	CreatedAt  time.Time
+}
+
+// TableName 指定表名
+func (aiEmbeddingsTable) TableName() string {
+	return database.TableName("ai_embeddings")
+}
+
+// CreateAIEmbeddingsTable 创建语义检索的向量表
+// 早期版本在创建向量存储时通过 AutoMigrate 建表，已存在的表保持不变
+type CreateAIEmbeddingsTable struct{}
+
+// Up 执行迁移
+func (m *CreateAIEmbeddingsTable) Up(tx *gorm.DB) error {
+	return createTables(tx, &aiEmbeddingsTable{})
+}
+
+// Down 回滚迁移
+func (m *CreateAIEmbeddingsTable) Down(tx *gorm.DB) error {
+	return dropTables(tx, &aiEmbeddingsTable{})
+}
@@ -0,0 +1,173 @@
+package migrations
+
+import (
+	"errors"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func init() {
+	Register("20240117000000_add_comment_menu_and_own_permissions", &AddCommentMenuAndOwnPermissions{})
+}
+
+// permissionRow 写入权限时使用的权限表字段
+type permissionRow struct {
+	gorm.Model
+	Name        string
+	DisplayName string
+	Resource    string
+	Action      string
+	IsSystem    bool
+}
+
+// TableName 指定表名
+func (permissionRow) TableName() string {
+	return database.TableName("permissions")
+}
+
+// roleRow 查找系统角色时使用的角色表字段
+type roleRow struct {
+	gorm.Model
+	Name string
+}
+
+// TableName 指定表名
+func (roleRow) TableName() string {
+	return database.TableName("roles")
+}
+
+// rolePermissionRow 角色权限关联
+type rolePermissionRow struct {
+	RoleID       uint
+	PermissionID uint
+}
+
+// TableName 指定表名
+func (rolePermissionRow) TableName() string {
+	return database.TableName("role_permissions")
+}
+
+// ownPermissions 新增的评论、菜单权限和只能操作自己资源的 xxx_own 权限
+var ownPermissions = []permissionRow{
+	{Name: "post.update_own", DisplayName: "Update Own Post", Resource: "post", Action: "update_own", IsSystem: true},
+	{Name: "post.delete_own", DisplayName: "Delete Own Post", Resource: "post", Action: "delete_own", IsSystem: true},
+	{Name: "media.update_own", DisplayName: "Update Own Media", Resource: "media", Action: "update_own", IsSystem: true},
+	{Name: "media.delete_own", DisplayName: "Delete Own Media", Resource: "media", Action: "delete_own", IsSystem: true},
+	{Name: "comment.update", DisplayName: "Update Comment", Resource: "comment", Action: "update", IsSystem: true},
+	{Name: "comment.delete", DisplayName: "Delete Comment", Resource: "comment", Action: "delete", IsSystem: true},
+	{Name: "comment.moderate", DisplayName: "Moderate Comments", Resource: "comment", Action: "moderate", IsSystem: true},
+	{Name: "comment.update_own", DisplayName: "Update Own Comment", Resource: "comment", Action: "update_own", IsSystem: true},
+	{Name: "comment.delete_own", DisplayName: "Delete Own Comment", Resource: "comment", Action: "delete_own", IsSystem: true},
+	{Name: "menu.create", DisplayName: "Create Menu", Resource: "menu", Action: "create", IsSystem: true},
+	{Name: "menu.update", DisplayName: "Update Menu", Resource: "menu", Action: "update", IsSystem: true},
+	{Name: "menu.delete", DisplayName: "Delete Menu", Resource: "menu", Action: "delete", IsSystem: true},
+}
+
+// ownPermissionGrants 已有系统角色新增的权限，super_admin 获得全部新权限
+var ownPermissionGrants = map[string][]string{
+	"admin":  {"comment.update", "comment.delete", "comment.moderate", "menu.create", "menu.update", "menu.delete"},
+	"editor": {"media.delete_own", "comment.update", "comment.delete", "comment.moderate"},
+	"author": {"post.update_own", "post.delete_own", "media.update_own", "media.delete_own", "comment.update_own", "comment.delete_own"},
+	"user":   {"comment.update_own", "comment.delete_own"},
+}
+
+// AddCommentMenuAndOwnPermissions 为已有数据库添加评论、菜单和 xxx_own 权限并分配给系统角色
+// post.update 现在表示可以编辑所有文章，author 角色改为 post.update_own。
+// 新安装的数据库还没有角色时只写入权限，角色由 db:seed 创建
+type AddCommentMenuAndOwnPermissions struct{}
+
+// Up 执行迁移
+func (m *AddCommentMenuAndOwnPermissions) Up(tx *gorm.DB) error {
+	ids := make(map[string]uint, len(ownPermissions))
+	all := make([]string, 0, len(ownPermissions))
+	for _, permission := range ownPermissions {
+		if err := tx.Where(permissionRow{Name: permission.Name}).FirstOrCreate(&permission).Error; err != nil {
+			return err
+		}
+		ids[permission.Name] = permission.ID
+		all = append(all, permission.Name)
+	}
+
+	grants := map[string][]string{"super_admin": all}
+	for role, names := range ownPermissionGrants {
+		grants[role] = names
+	}
+	for roleName, names := range grants {
+		role, err := findRole(tx, roleName)
+		if err != nil {
+			return err
+		}
+		if role == nil {
+			continue
+		}
+		rows := make([]rolePermissionRow, 0, len(names))
+		for _, name := range names {
+			rows = append(rows, rolePermissionRow{RoleID: role.ID, PermissionID: ids[name]})
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+
+	return setAuthorPostUpdate(tx, false)
+}
+
+// Down 回滚迁移
+func (m *AddCommentMenuAndOwnPermissions) Down(tx *gorm.DB) error {
+	names := make([]string, 0, len(ownPermissions))
+	for _, permission := range ownPermissions {
+		names = append(names, permission.Name)
+	}
+
+	var ids []uint
+	if err := tx.Model(&permissionRow{}).Unscoped().Where("name IN ?", names).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		if err := tx.Where("permission_id IN ?", ids).Delete(&rolePermissionRow{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&permissionRow{}, ids).Error; err != nil {
+			return err
+		}
+	}
+
+	return setAuthorPostUpdate(tx, true)
+}
+
+// findRole 按名称查找角色，不存在时返回nil
+func findRole(tx *gorm.DB, name string) (*roleRow, error) {
+	var role roleRow
+	err := tx.Where("name = ?", name).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// setAuthorPostUpdate 授予或收回 author 角色的 post.update 权限
+func setAuthorPostUpdate(tx *gorm.DB, granted bool) error {
+	role, err := findRole(tx, "author")
+	if err != nil || role == nil {
+		return err
+	}
+	var permission permissionRow
+	err = tx.Where("name = ?", "post.update").First(&permission).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	row := rolePermissionRow{RoleID: role.ID, PermissionID: permission.ID}
+	if granted {
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error
+	}
+	return tx.Where(&row).Delete(&rolePermissionRow{}).Error
+}
@@ -0,0 +1,79 @@
+package migrations
+
+import (
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// registry 已注册的迁移，按名称中的时间戳顺序执行
+var registry = make(map[string]database.Migration)
+
+// Register 注册迁移，在迁移文件的 init 中调用
+func Register(name string, migration database.Migration) {
+	if _, exists := registry[name]; exists {
+		panic("migrations: duplicate migration " + name)
+	}
+	registry[name] = migration
+}
+
+// NewMigrator 创建包含所有已注册迁移的迁移执行器
+func NewMigrator(db *gorm.DB) *database.Migrator {
+	migrator := database.NewMigrator(db)
+	for name, migration := range registry {
+		migrator.Register(name, migration)
+	}
+	return migrator
+}
+
+// 每个迁移在自己的文件中定义创建或修改时的表结构，不引用 models 中的模型，
+// 这样迁移描述的是固定的结构变更，不会随模型的修改而变化
+
+// createTables 按顺序创建数据表，被引用的表需要排在前面
+// 已存在的表（早期版本通过 AutoMigrate 创建）保持不变，以便已有数据库直接纳入迁移管理
+func createTables(tx *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		if tx.Migrator().HasTable(model) {
+			continue
+		}
+		if err := tx.Migrator().CreateTable(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropTables 按创建的相反顺序删除数据表
+func dropTables(tx *gorm.DB, models ...interface{}) error {
+	for i := len(models) - 1; i >= 0; i-- {
+		if err := tx.Migrator().DropTable(models[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumns 为已有数据表添加字段，已存在的字段跳过
+func addColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumns 删除数据表的字段
+func dropColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if !tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().DropColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package migrations
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func setupDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	d := database.NewDatabase(&database.Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "migrate.db")})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d.DB
+}
+
+// 新增字段的迁移回滚后，创建表的迁移不应包含这些字段
+func TestMigrationsAddColumnsStepByStep(t *testing.T) {
+	db := setupDB(t)
+	migrator := NewMigrator(db)
+	if _, err := migrator.Migrate(); err != nil {
+		t.Fatalf("Migrate error: %v", err)
+	}
+
+	added := map[string][]string{
+		"users":      {"email_verified_at", "locked_until"},
+		"roles":      {"require_two_factor"},
+		"posts":      {"access_rules"},
+		"categories": {"access_rules"},
+	}
+	for table, columns := range added {
+		for _, column := range columns {
+			if !db.Migrator().HasColumn(table, column) {
+				t.Fatalf("%s.%s missing after migrate", table, column)
+			}
+		}
+	}
+
+	// 回滚到 20240106 之前，只保留基础表
+	steps := 0
+	for _, name := range migrator.Names() {
+		if name >= "20240106" {
+			steps++
+		}
+	}
+	if _, err := migrator.Rollback(steps); err != nil {
+		t.Fatalf("Rollback error: %v", err)
+	}
+	for table, columns := range added {
+		if !db.Migrator().HasTable(table) {
+			t.Fatalf("table %s should remain", table)
+		}
+		for _, column := range columns {
+			if db.Migrator().HasColumn(table, column) {
+				t.Errorf("%s.%s should be added by a later migration", table, column)
+			}
+		}
+	}
+
+	if _, err := migrator.Migrate(); err != nil {
+		t.Fatalf("re-Migrate error: %v", err)
+	}
+	if !db.Migrator().HasColumn("users", "locked_until") {
+		t.Fatal("users.locked_until missing after re-migrate")
+	}
+
+	// 两步验证凭据表的锁定时间同样由单独的迁移添加
+	if !db.Migrator().HasColumn("two_factor_credentials", "locked_until") {
+		t.Fatal("two_factor_credentials.locked_until missing after migrate")
+	}
+	steps = 0
+	for _, name := range migrator.Names() {
+		if name >= "20240113" {
+			steps++
+		}
+	}
+	if _, err := migrator.Rollback(steps); err != nil {
+		t.Fatalf("Rollback error: %v", err)
+	}
+	if !db.Migrator().HasTable("two_factor_credentials") || db.Migrator().HasColumn("two_factor_credentials", "locked_until") {
+		t.Error("rolling back 20240113 should only drop two_factor_credentials.locked_until")
+	}
+}
+
+// 已有数据库升级时系统角色获得新权限，author 只保留编辑自己文章的权限
+func TestMigrationsOwnPermissionsUpgrade(t *testing.T) {
+	db := setupDB(t)
+	migrator := NewMigrator(db)
+	if _, err := migrator.Migrate(); err != nil {
+		t.Fatalf("Migrate error: %v", err)
+	}
+	steps := 0
+	for _, name := range migrator.Names() {
+		if name >= "20240117" {
+			steps++
+		}
+	}
+	if _, err := migrator.Rollback(steps); err != nil {
+		t.Fatalf("Rollback error: %v", err)
+	}
+	var remaining int64
+	db.Model(&permissionRow{}).Where("name = ?", "menu.create").Count(&remaining)
+	if remaining != 0 {
+		t.Fatal("rollback should remove the added permissions")
+	}
+
+	// 早期版本 db:seed 创建的角色和权限
+	postUpdate := permissionRow{Name: "post.update", Resource: "post", Action: "update", IsSystem: true}
+	db.Create(&postUpdate)
+	for _, name := range []string{"super_admin", "admin", "author"} {
+		role := roleRow{Name: name}
+		db.Create(&role)
+		if name == "author" {
+			db.Create(&rolePermissionRow{RoleID: role.ID, PermissionID: postUpdate.ID})
+		}
+	}
+
+	if _, err := migrator.Migrate(); err != nil {
+		t.Fatalf("re-Migrate error: %v", err)
+	}
+
+	has := func(role, permission string) bool {
+		var count int64
+		db.Model(&rolePermissionRow{}).
+			Joins("JOIN roles ON roles.id = role_permissions.role_id").
+			Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+			Where("roles.name = ? AND permissions.name = ?", role, permission).
+			Count(&count)
+		return count > 0
+	}
+	for _, grant := range [][2]string{
+		{"super_admin", "comment.moderate"},
+		{"admin", "menu.create"},
+		{"author", "post.update_own"},
+		{"author", "comment.delete_own"},
+	} {
+		if !has(grant[0], grant[1]) {
+			t.Errorf("%s should have %s after migrate", grant[0], grant[1])
+		}
+	}
+	if has("author", "post.update") {
+		t.Error("author should no longer be able to update every post")
+	}
+
+	if _, err := migrator.Rollback(steps); err != nil {
+		t.Fatalf("Rollback error: %v", err)
+	}
+	if !has("author", "post.update") || has("author", "post.update_own") {
+		t.Error("rollback should restore author's post.update")
+	}
+}
+
+func TestMigrationsReset(t *testing.T) {
+	db := setupDB(t)
+	migrator := NewMigrator(db)
+	if _, err := migrator.Migrate(); err != nil {
+		t.Fatalf("Migrate error: %v", err)
+	}
+	if _, err := migrator.Reset(); err != nil {
+		t.Fatalf("Reset error: %v", err)
+	}
+
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		if table != "migrations" && table != "sqlite_sequence" {
+			t.Errorf("table %s left after reset", table)
+		}
+	}
+}
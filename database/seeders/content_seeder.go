@@ -0,0 +1,142 @@
+package seeders
+
+import (
+	"github.com/clarkzhu2020/aidecms/database/factories"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("ContentSeeder", &ContentSeeder{})
+}
+
+// ContentSeeder 示例分类、标签、文章、评论和媒体，作者从已有用户中选取
+type ContentSeeder struct{}
+
+// Run 执行种子
+func (s *ContentSeeder) Run(tx *gorm.DB) error {
+	fake := factories.Faker()
+
+	var authors []models.User
+	if err := tx.Limit(20).Find(&authors).Error; err != nil {
+		return err
+	}
+	if len(authors) == 0 {
+		created, err := factories.User().Count(3).CreateMany(tx)
+		if err != nil {
+			return err
+		}
+		authors = created
+	}
+	author := func() uint {
+		return authors[fake.IntBetween(0, len(authors)-1)].ID
+	}
+
+	// 分类：5个顶级分类，第一个分类下有2个子分类
+	categories, err := factories.Category().Count(5).CreateMany(tx)
+	if err != nil {
+		return err
+	}
+	parentID := categories[0].ID
+	children, err := factories.Category().Count(2).State(func(category *models.Category) {
+		category.ParentID = &parentID
+	}).CreateMany(tx)
+	if err != nil {
+		return err
+	}
+	categories = append(categories, children...)
+
+	tags, err := factories.Tag().Count(15).CreateMany(tx)
+	if err != nil {
+		return err
+	}
+
+	// 文章：大部分已发布，每篇关联1-4个标签和若干评论
+	for i := 0; i < 30; i++ {
+		postFactory := factories.Post().State(func(post *models.Post) {
+			post.AuthorID = author()
+			post.CategoryID = categories[fake.IntBetween(0, len(categories)-1)].ID
+			for _, index := range pickIndexes(fake.IntBetween(1, 4), len(tags)) {
+				post.Tags = append(post.Tags, tags[index])
+			}
+		})
+		if fake.Bool(80) {
+			postFactory = postFactory.State(factories.Published)
+		}
+		post, err := postFactory.Create(tx)
+		if err != nil {
+			return err
+		}
+		if !post.IsPublished() {
+			continue
+		}
+
+		comments, err := factories.Comment().Count(fake.IntBetween(0, 5)).State(func(comment *models.Comment) {
+			comment.PostID = post.ID
+		}).CreateMany(tx)
+		if err != nil {
+			return err
+		}
+		if len(comments) > 1 {
+			// 第二条评论回复第一条
+			if err := tx.Model(&comments[1]).Update("parent_id", comments[0].ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := factories.Media().Count(10).State(func(media *models.Media) {
+		media.UserID = author()
+	}).CreateMany(tx); err != nil {
+		return err
+	}
+
+	return refreshCounts(tx)
+}
+
+// pickIndexes 从 [0, total) 中随机选取 n 个不重复的下标
+func pickIndexes(n, total int) []int {
+	fake := factories.Faker()
+	if n > total {
+		n = total
+	}
+	picked := make(map[int]bool, n)
+	indexes := make([]int, 0, n)
+	for len(indexes) < n {
+		index := fake.IntBetween(0, total-1)
+		if !picked[index] {
+			picked[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+// refreshCounts 重新统计标签文章数和文章已通过评论数
+func refreshCounts(tx *gorm.DB) error {
+	var tags []models.Tag
+	if err := tx.Find(&tags).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		count := tx.Model(&tag).Association("Posts").Count()
+		if err := tx.Model(&tag).Update("count", count).Error; err != nil {
+			return err
+		}
+	}
+
+	var posts []models.Post
+	if err := tx.Select("id").Find(&posts).Error; err != nil {
+		return err
+	}
+	for _, post := range posts {
+		var count int64
+		if err := tx.Model(&models.Comment{}).Where("post_id = ? AND status = ?", post.ID, "approved").Count(&count).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&post).Update("comment_count", count).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package seeders
+
+import (
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("DatabaseSeeder", &DatabaseSeeder{})
+}
+
+// DatabaseSeeder 默认种子，依次填充角色权限、用户、菜单和示例内容
+type DatabaseSeeder struct{}
+
+// Run 执行种子
+func (s *DatabaseSeeder) Run(tx *gorm.DB) error {
+	for _, seeder := range []database.Seeder{
+		&RoleSeeder{},
+		&UserSeeder{},
+		&MenuSeeder{},
+		&ContentSeeder{},
+	} {
+		if err := seeder.Run(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,25 +1,26 @@
-package migrations
+package seeders
 
 import (
 	"github.com/clarkzhu2020/aidecms/internal/app/models"
 	"gorm.io/gorm"
 )
 
-// CreateMenusTable 创建菜单表
-func CreateMenusTable(db *gorm.DB) error {
-	return db.AutoMigrate(&models.Menu{})
+func init() {
+	Register("MenuSeeder", &MenuSeeder{})
 }
 
-// SeedDefaultMenus 添加默认菜单
-func SeedDefaultMenus(db *gorm.DB) error {
+// MenuSeeder 默认页眉和页脚菜单，已有菜单时跳过
+type MenuSeeder struct{}
+
+// Run 执行种子
+func (s *MenuSeeder) Run(tx *gorm.DB) error {
 	// 检查是否已有菜单
 	var count int64
-	if err := db.Model(&models.Menu{}).Count(&count).Error; err != nil {
+	if err := tx.Model(&models.Menu{}).Count(&count).Error; err != nil {
 		return err
 	}
-
 	if count > 0 {
-		return nil // 已有菜单，跳过
+		return nil
 	}
 
 	// Header 菜单
@@ -99,11 +100,5 @@ func SeedDefaultMenus(db *gorm.DB) error {
 
 	// 插入所有菜单
 	allMenus := append(headerMenus, footerMenus...)
-	for _, menu := range allMenus {
-		if err := db.Create(&menu).Error; err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return tx.Create(&allMenus).Error
 }
@@ -0,0 +1,138 @@
+package seeders
+
+import (
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("RoleSeeder", &RoleSeeder{})
+}
+
+// RoleSeeder 默认角色和权限，已存在的角色按名称更新权限
+type RoleSeeder struct{}
+
+// defaultRoles 系统角色
+var defaultRoles = []models.Role{
+	{Name: "super_admin", DisplayName: "Super Administrator", Description: "Full system access with all permissions", IsSystem: true},
+	{Name: "admin", DisplayName: "Administrator", Description: "Administrative access to manage content and users", IsSystem: true},
+	{Name: "editor", DisplayName: "Editor", Description: "Can manage all content", IsSystem: true},
+	{Name: "author", DisplayName: "Author", Description: "Can create and manage own posts", IsSystem: true},
+	{Name: "user", DisplayName: "User", Description: "Basic user with read access", IsSystem: true},
+}
+
+// defaultPermissions 系统权限
+var defaultPermissions = []models.Permission{
+	// 文章权限
+	{Name: "post.create", DisplayName: "Create Post", Resource: "post", Action: "create", IsSystem: true},
+	{Name: "post.read", DisplayName: "Read Post", Resource: "post", Action: "read", IsSystem: true},
+	{Name: "post.update", DisplayName: "Update Post", Resource: "post", Action: "update", IsSystem: true},
+	{Name: "post.delete", DisplayName: "Delete Post", Resource: "post", Action: "delete", IsSystem: true},
+	{Name: "post.publish", DisplayName: "Publish Post", Resource: "post", Action: "publish", IsSystem: true},
+	{Name: "post.update_own", DisplayName: "Update Own Post", Resource: "post", Action: "update_own", IsSystem: true},
+	{Name: "post.delete_own", DisplayName: "Delete Own Post", Resource: "post", Action: "delete_own", IsSystem: true},
+
+	// 分类权限
+	{Name: "category.create", DisplayName: "Create Category", Resource: "category", Action: "create", IsSystem: true},
+	{Name: "category.read", DisplayName: "Read Category", Resource: "category", Action: "read", IsSystem: true},
+	{Name: "category.update", DisplayName: "Update Category", Resource: "category", Action: "update", IsSystem: true},
+	{Name: "category.delete", DisplayName: "Delete Category", Resource: "category", Action: "delete", IsSystem: true},
+
+	// 标签权限
+	{Name: "tag.create", DisplayName: "Create Tag", Resource: "tag", Action: "create", IsSystem: true},
+	{Name: "tag.read", DisplayName: "Read Tag", Resource: "tag", Action: "read", IsSystem: true},
+	{Name: "tag.update", DisplayName: "Update Tag", Resource: "tag", Action: "update", IsSystem: true},
+	{Name: "tag.delete", DisplayName: "Delete Tag", Resource: "tag", Action: "delete", IsSystem: true},
+
+	// 媒体权限
+	{Name: "media.upload", DisplayName: "Upload Media", Resource: "media", Action: "upload", IsSystem: true},
+	{Name: "media.read", DisplayName: "Read Media", Resource: "media", Action: "read", IsSystem: true},
+	{Name: "media.update", DisplayName: "Update Media", Resource: "media", Action: "update", IsSystem: true},
+	{Name: "media.delete", DisplayName: "Delete Media", Resource: "media", Action: "delete", IsSystem: true},
+	{Name: "media.update_own", DisplayName: "Update Own Media", Resource: "media", Action: "update_own", IsSystem: true},
+	{Name: "media.delete_own", DisplayName: "Delete Own Media", Resource: "media", Action: "delete_own", IsSystem: true},
+
+	// 评论权限
+	{Name: "comment.update", DisplayName: "Update Comment", Resource: "comment", Action: "update", IsSystem: true},
+	{Name: "comment.delete", DisplayName: "Delete Comment", Resource: "comment", Action: "delete", IsSystem: true},
+	{Name: "comment.moderate", DisplayName: "Moderate Comments", Resource: "comment", Action: "moderate", IsSystem: true},
+	{Name: "comment.update_own", DisplayName: "Update Own Comment", Resource: "comment", Action: "update_own", IsSystem: true},
+	{Name: "comment.delete_own", DisplayName: "Delete Own Comment", Resource: "comment", Action: "delete_own", IsSystem: true},
+
+	// 菜单权限
+	{Name: "menu.create", DisplayName: "Create Menu", Resource: "menu", Action: "create", IsSystem: true},
+	{Name: "menu.update", DisplayName: "Update Menu", Resource: "menu", Action: "update", IsSystem: true},
+	{Name: "menu.delete", DisplayName: "Delete Menu", Resource: "menu", Action: "delete", IsSystem: true},
+
+	// 用户管理权限
+	{Name: "user.create", DisplayName: "Create User", Resource: "user", Action: "create", IsSystem: true},
+	{Name: "user.read", DisplayName: "Read User", Resource: "user", Action: "read", IsSystem: true},
+	{Name: "user.update", DisplayName: "Update User", Resource: "user", Action: "update", IsSystem: true},
+	{Name: "user.delete", DisplayName: "Delete User", Resource: "user", Action: "delete", IsSystem: true},
+
+	// 角色权限管理
+	{Name: "role.manage", DisplayName: "Manage Roles", Resource: "role", Action: "manage", IsSystem: true},
+	{Name: "permission.manage", DisplayName: "Manage Permissions", Resource: "permission", Action: "manage", IsSystem: true},
+}
+
+// rolePermissions 各角色拥有的权限，super_admin 拥有全部权限
+// xxx.update 可以操作所有资源，xxx.update_own 只能操作自己的资源（见 services.OwnerPolicy）
+var rolePermissions = map[string][]string{
+	"admin": {
+		"post.create", "post.read", "post.update", "post.delete", "post.publish",
+		"category.create", "category.read", "category.update", "category.delete",
+		"tag.create", "tag.read", "tag.update", "tag.delete",
+		"media.upload", "media.read", "media.update", "media.delete",
+		"comment.update", "comment.delete", "comment.moderate",
+		"menu.create", "menu.update", "menu.delete",
+		"user.read", "user.update",
+	},
+	"editor": {
+		"post.create", "post.read", "post.update", "post.delete", "post.publish",
+		"category.create", "category.read", "category.update",
+		"tag.create", "tag.read", "tag.update",
+		"media.upload", "media.read", "media.update", "media.delete_own",
+		"comment.update", "comment.delete", "comment.moderate",
+	},
+	"author": {
+		"post.create", "post.read", "post.update_own", "post.delete_own",
+		"category.read", "tag.read", "tag.create",
+		"media.upload", "media.read", "media.update_own", "media.delete_own",
+		"comment.update_own", "comment.delete_own",
+	},
+	"user": {
+		"post.read", "category.read", "tag.read", "media.read",
+		"comment.update_own", "comment.delete_own",
+	},
+}
+
+// Run 执行种子
+func (s *RoleSeeder) Run(tx *gorm.DB) error {
+	permissions := make(map[string]models.Permission, len(defaultPermissions))
+	all := make([]models.Permission, 0, len(defaultPermissions))
+	for _, permission := range defaultPermissions {
+		if err := tx.Where(models.Permission{Name: permission.Name}).FirstOrCreate(&permission).Error; err != nil {
+			return err
+		}
+		permissions[permission.Name] = permission
+		all = append(all, permission)
+	}
+
+	for _, role := range defaultRoles {
+		if err := tx.Where(models.Role{Name: role.Name}).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+
+		granted := all
+		if role.Name != "super_admin" {
+			granted = make([]models.Permission, 0, len(rolePermissions[role.Name]))
+			for _, name := range rolePermissions[role.Name] {
+				granted = append(granted, permissions[name])
+			}
+		}
+		if err := tx.Model(&role).Association("Permissions").Replace(granted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
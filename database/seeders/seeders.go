@@ -0,0 +1,44 @@
+package seeders
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// registry 已注册的种子，按名称查找，如 db:seed --class=UserSeeder
+var registry = make(map[string]database.Seeder)
+
+// Register 注册种子，在种子文件的 init 中调用
+func Register(name string, seeder database.Seeder) {
+	if _, exists := registry[name]; exists {
+		panic("seeders: duplicate seeder " + name)
+	}
+	registry[name] = seeder
+}
+
+// Names 返回已注册的种子名称
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run 按顺序执行种子，每个种子在独立的事务中执行
+func Run(db *gorm.DB, names ...string) error {
+	for _, name := range names {
+		seeder, ok := registry[name]
+		if !ok {
+			return fmt.Errorf("seeder %s not found", name)
+		}
+		if err := db.Transaction(seeder.Run); err != nil {
+			return fmt.Errorf("seeder %s failed: %w", name, err)
+		}
+	}
+	return nil
+}
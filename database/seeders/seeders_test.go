@@ -0,0 +1,98 @@
+package seeders
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/clarkzhu2020/aidecms/database/factories"
+	"github.com/clarkzhu2020/aidecms/database/migrations"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func setupDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	d := database.NewDatabase(&database.Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "seed.db")})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+
+	if _, err := migrations.NewMigrator(d.DB).Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return d.DB
+}
+
+func count(t *testing.T, db *gorm.DB, model interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(model).Count(&n).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	return n
+}
+
+func TestDatabaseSeeder(t *testing.T) {
+	factories.Seed(1)
+	db := setupDB(t)
+
+	if err := Run(db, "DatabaseSeeder"); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	if n := count(t, db, &models.Post{}); n != 30 {
+		t.Errorf("expected 30 posts, got %d", n)
+	}
+	if n := count(t, db, &models.Menu{}); n != 7 {
+		t.Errorf("expected 7 menus, got %d", n)
+	}
+
+	var admin models.User
+	db.Preload("Roles.Permissions").Where("email = ?", "admin@example.com").First(&admin)
+	if !admin.HasRole("super_admin") || !admin.HasPermission("permission.manage") {
+		t.Errorf("admin should be super_admin with all permissions: %+v", admin.Roles)
+	}
+
+	var post models.Post
+	db.Preload("Tags").Where("status = ?", "published").First(&post)
+	if post.AuthorID == 0 || post.CategoryID == 0 || len(post.Tags) == 0 {
+		t.Errorf("post relations not seeded: %+v", post)
+	}
+
+	// 角色和菜单种子可以重复执行
+	if err := Run(db, "RoleSeeder", "MenuSeeder"); err != nil {
+		t.Fatalf("rerun error: %v", err)
+	}
+	if n := count(t, db, &models.Role{}); n != 5 {
+		t.Errorf("expected 5 roles after rerun, got %d", n)
+	}
+
+	if err := Run(db, "MissingSeeder"); err == nil {
+		t.Error("expected error for unknown seeder")
+	}
+}
+
+func TestFactories_Relations(t *testing.T) {
+	db := setupDB(t)
+
+	comment, err := factories.Comment().Create(db)
+	if err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+
+	var post models.Post
+	if err := db.Preload("Author").Preload("Category").First(&post, comment.PostID).Error; err != nil {
+		t.Fatalf("comment post not created: %v", err)
+	}
+	if !post.IsPublished() || post.Author == nil || post.Category == nil {
+		t.Errorf("post relations not created: %+v", post)
+	}
+
+	media, err := factories.Media().Create(db)
+	if err != nil || media.UserID == 0 {
+		t.Errorf("media uploader not created: %+v, %v", media, err)
+	}
+}
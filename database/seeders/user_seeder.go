@@ -0,0 +1,55 @@
+package seeders
+
+import (
+	"github.com/clarkzhu2020/aidecms/database/factories"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("UserSeeder", &UserSeeder{})
+}
+
+// UserSeeder 管理员账号和各角色的示例用户，密码均为 factories.DefaultPassword
+// 需要先执行 RoleSeeder，角色不存在时不分配角色
+type UserSeeder struct{}
+
+// Run 执行种子
+func (s *UserSeeder) Run(tx *gorm.DB) error {
+	admin := factories.User().State(func(user *models.User) {
+		user.Username = "admin"
+		user.Email = "admin@example.com"
+		user.FirstName, user.LastName = "Site", "Admin"
+	}).Make()
+	if err := tx.Where(models.User{Email: admin.Email}).FirstOrCreate(&admin).Error; err != nil {
+		return err
+	}
+	if err := assignRole(tx, &admin, "super_admin"); err != nil {
+		return err
+	}
+
+	for _, group := range []struct {
+		role  string
+		count int
+	}{{"editor", 2}, {"author", 4}, {"user", 10}} {
+		users, err := factories.User().Count(group.count).CreateMany(tx)
+		if err != nil {
+			return err
+		}
+		for i := range users {
+			if err := assignRole(tx, &users[i], group.role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// assignRole 为用户分配角色
+func assignRole(tx *gorm.DB, user *models.User, roleName string) error {
+	var role models.Role
+	if err := tx.Where("name = ?", roleName).Limit(1).Find(&role).Error; err != nil || role.ID == 0 {
+		return err
+	}
+	return tx.Model(user).Association("Roles").Append(&role)
+}
@@ -9,10 +9,10 @@ import (
 
 // HertzToFramework 将Hertz的处理函数转换为framework的处理函数
 func HertzToFramework(handler app.HandlerFunc) framework.HandlerFunc {
-	return func(ctx context.Context, c *framework.RequestContext) {
+	return framework.LabelHandler(func(ctx context.Context, c *framework.RequestContext) {
 		// framework.RequestContext 包含了 *app.RequestContext
 		handler(ctx, c.RequestContext)
-	}
+	}, handler)
 }
 
 // ControllerToFramework 将控制器方法转换为framework的处理函数
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 访问规则类型
+const (
+	AccessRuleNative = "native" // 持有链上原生币（如 ETH），按 wei 计
+	AccessRuleERC20  = "erc20"  // 持有 ERC-20 代币，按代币最小单位计
+	AccessRuleERC721 = "erc721" // 持有 NFT，指定 token_id 时要求持有该 NFT
+)
+
+// AccessRule 内容访问规则：关联的钱包在指定链上持有不少于 MinBalance 的资产
+type AccessRule struct {
+	Type       string `json:"type"`
+	ChainID    int64  `json:"chain_id"`
+	Contract   string `json:"contract,omitempty"`    // erc20、erc721 的合约地址
+	MinBalance string `json:"min_balance,omitempty"` // 最小单位的整数，为空时为1
+	TokenID    string `json:"token_id,omitempty"`    // erc721 指定的 NFT
+	Label      string `json:"label,omitempty"`       // 显示给读者的说明，如 "持有 100 AIDE"
+}
+
+// Validate 检查规则是否完整
+func (r AccessRule) Validate() error {
+	switch r.Type {
+	case AccessRuleNative:
+	case AccessRuleERC20, AccessRuleERC721:
+		if !common.IsHexAddress(r.Contract) {
+			return fmt.Errorf("%s rule requires a contract address", r.Type)
+		}
+	default:
+		return fmt.Errorf("unsupported access rule type %q", r.Type)
+	}
+	if r.ChainID <= 0 {
+		return errors.New("access rule requires a chain id")
+	}
+	if r.MinBalance != "" {
+		if n, ok := new(big.Int).SetString(r.MinBalance, 10); !ok || n.Sign() <= 0 {
+			return fmt.Errorf("invalid min balance %q", r.MinBalance)
+		}
+	}
+	if r.TokenID != "" {
+		if r.Type != AccessRuleERC721 {
+			return errors.New("token id is only supported by erc721 rules")
+		}
+		if n, ok := new(big.Int).SetString(r.TokenID, 10); !ok || n.Sign() < 0 {
+			return fmt.Errorf("invalid token id %q", r.TokenID)
+		}
+	}
+	return nil
+}
+
+// Threshold 需要持有的最小数量
+func (r AccessRule) Threshold() *big.Int {
+	if n, ok := new(big.Int).SetString(r.MinBalance, 10); ok {
+		return n
+	}
+	return big.NewInt(1)
+}
+
+// AccessRules 文章或分类的访问规则，满足其中任意一条即可访问，以 JSON 保存
+type AccessRules []AccessRule
+
+// Validate 检查所有规则
+func (rules AccessRules) Validate() error {
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("access_rules[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Value 实现 driver.Valuer，没有规则时保存为 NULL
+func (rules AccessRules) Value() (driver.Value, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(rules)
+	return string(data), err
+}
+
+// Scan 实现 sql.Scanner
+func (rules *AccessRules) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*rules = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into AccessRules", value)
+	}
+	if len(data) == 0 {
+		*rules = nil
+		return nil
+	}
+	return json.Unmarshal(data, rules)
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"github.com/clarkzhu2020/aidecms/pkg/database"
 	"gorm.io/gorm"
 )
 
@@ -27,7 +28,7 @@ type Comment struct {
 
 // TableName 指定表名
 func (Comment) TableName() string {
-	return "comments"
+	return database.TableName("comments")
 }
 
 // IsApproved 检查评论是否已批准
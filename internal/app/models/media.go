@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/clarkzhu2020/aidecms/pkg/database"
 	"gorm.io/gorm"
 )
 
@@ -34,7 +35,7 @@ type Media struct {
 
 // TableName 指定表名
 func (Media) TableName() string {
-	return "media"
+	return database.TableName("media")
 }
 
 // BeforeCreate 创建前钩子
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"github.com/clarkzhu2020/aidecms/pkg/database"
 	"gorm.io/gorm"
 )
 
@@ -24,7 +25,7 @@ type Menu struct {
 
 // TableName 指定表名
 func (Menu) TableName() string {
-	return "menus"
+	return database.TableName("menus")
 }
 
 // MenuSwagger 菜单Swagger模型
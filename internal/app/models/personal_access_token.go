@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessToken 个人访问令牌，供构建流水线等非交互式集成使用
+// 只保存令牌的哈希值，权限范围为用户权限名称的子集
+type PersonalAccessToken struct {
+	gorm.Model
+	UserID     uint       `gorm:"index;not null" json:"user_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	TokenHash  string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Prefix     string     `gorm:"size:16" json:"prefix"`   // 令牌前几位，用于辨认
+	Scopes     string     `gorm:"type:text" json:"-"`      // 逗号分隔的权限名称
+	ExpiresAt  *time.Time `gorm:"index" json:"expires_at"` // 为空表示不过期
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `gorm:"size:45" json:"last_used_ip"`
+
+	// 关联
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName 指定表名
+func (PersonalAccessToken) TableName() string {
+	return database.TableName("personal_access_tokens")
+}
+
+// ScopeList 返回令牌的权限范围
+func (t *PersonalAccessToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// SetScopes 设置令牌的权限范围
+func (t *PersonalAccessToken) SetScopes(scopes []string) {
+	t.Scopes = strings.Join(scopes, ",")
+}
+
+// IsExpired 检查令牌是否已过期
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/clarkzhu2020/aidecms/pkg/database"
 	"gorm.io/gorm"
 )
 
@@ -27,6 +28,9 @@ type Post struct {
 	MetaDescription string `gorm:"type:text" json:"meta_description"`
 	MetaKeywords    string `gorm:"size:500" json:"meta_keywords"`
 
+	// 访问规则，读者关联的钱包满足其中一条才能阅读正文
+	AccessRules AccessRules `gorm:"type:text" json:"access_rules,omitempty"`
+
 	// 关联
 	Author   *User     `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
 	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
@@ -35,7 +39,7 @@ type Post struct {
 
 // TableName 指定表名
 func (Post) TableName() string {
-	return "posts"
+	return database.TableName("posts")
 }
 
 // BeforeCreate 创建前钩子
@@ -72,6 +76,9 @@ type Category struct {
 	MetaTitle       string `gorm:"size:200" json:"meta_title"`
 	MetaDescription string `gorm:"type:text" json:"meta_description"`
 
+	// 访问规则，适用于分类下的所有文章
+	AccessRules AccessRules `gorm:"type:text" json:"access_rules,omitempty"`
+
 	// 关联
 	Parent   *Category  `gorm:"foreignKey:ParentID" json:"parent,omitempty"`
 	Children []Category `gorm:"foreignKey:ParentID" json:"children,omitempty"`
@@ -80,7 +87,7 @@ type Category struct {
 
 // TableName 指定表名
 func (Category) TableName() string {
-	return "categories"
+	return database.TableName("categories")
 }
 
 // Tag 标签模型
@@ -96,7 +103,7 @@ type Tag struct {
 
 // TableName 指定表名
 func (Tag) TableName() string {
-	return "tags"
+	return database.TableName("tags")
 }
 
 // PostTag 文章标签关联表
@@ -107,5 +114,5 @@ type PostTag struct {
 
 // TableName 指定表名
 func (PostTag) TableName() string {
-	return "post_tags"
+	return database.TableName("post_tags")
 }
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// PostSuggestion AI生成的文章编辑建议，由编辑审核后手动采纳，不直接修改文章
+type PostSuggestion struct {
+	gorm.Model
+	PostID      uint   `gorm:"index;not null" json:"post_id"`
+	Action      string `gorm:"size:32;index;not null" json:"action"`          // excerpt, seo, tags, translation
+	Language    string `gorm:"size:16" json:"language,omitempty"`             // 翻译目标语言
+	Payload     string `gorm:"type:text" json:"payload"`                      // 建议内容（JSON格式）
+	Status      string `gorm:"size:20;default:'pending';index" json:"status"` // pending, applied, dismissed
+	Client      string `gorm:"size:64" json:"client"`
+	RequestedBy uint   `gorm:"index" json:"requested_by"`
+
+	// 关联
+	Post *Post `gorm:"foreignKey:PostID" json:"post,omitempty"`
+}
+
+// TableName 指定表名
+func (PostSuggestion) TableName() string {
+	return database.TableName("post_suggestions")
+}
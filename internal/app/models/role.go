@@ -1,6 +1,7 @@
 package models
 
 import (
+	"github.com/clarkzhu2020/aidecms/pkg/database"
 	"gorm.io/gorm"
 )
 
@@ -12,6 +13,8 @@ type Role struct {
 	Description string `gorm:"type:text" json:"description"`
 	IsSystem    bool   `gorm:"default:false" json:"is_system"` // 是否为系统角色（不可删除）
 
+	RequireTwoFactor bool `gorm:"default:false" json:"require_two_factor"` // 拥有该角色的用户必须开启两步验证
+
 	// 关联
 	Permissions []Permission `gorm:"many2many:role_permissions;" json:"permissions,omitempty"`
 	Users       []User       `gorm:"many2many:user_roles;" json:"users,omitempty"`
@@ -19,7 +22,7 @@ type Role struct {
 
 // TableName 指定表名
 func (Role) TableName() string {
-	return "roles"
+	return database.TableName("roles")
 }
 
 // Permission 权限模型
@@ -38,7 +41,7 @@ type Permission struct {
 
 // TableName 指定表名
 func (Permission) TableName() string {
-	return "permissions"
+	return database.TableName("permissions")
 }
 
 // RolePermission 角色权限关联表
@@ -49,7 +52,7 @@ type RolePermission struct {
 
 // TableName 指定表名
 func (RolePermission) TableName() string {
-	return "role_permissions"
+	return database.TableName("role_permissions")
 }
 
 // UserRole 用户角色关联表
@@ -60,7 +63,7 @@ type UserRole struct {
 
 // TableName 指定表名
 func (UserRole) TableName() string {
-	return "user_roles"
+	return database.TableName("user_roles")
 }
 
 // HasPermission 检查角色是否有指定权限
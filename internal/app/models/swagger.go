@@ -27,6 +27,8 @@ type PostSwagger struct {
 	MetaTitle       string           `json:"meta_title" example:"SEO标题"`
 	MetaDescription string           `json:"meta_description" example:"SEO描述"`
 	MetaKeywords    string           `json:"meta_keywords" example:"关键词1,关键词2"`
+	AccessRules     []AccessRule     `json:"access_rules,omitempty"`
+	Locked          bool             `json:"locked" example:"false"`
 	Author          *UserSwagger     `json:"author,omitempty"`
 	Category        *CategorySwagger `json:"category,omitempty"`
 	Tags            []TagSwagger     `json:"tags,omitempty"`
@@ -42,6 +44,7 @@ type CategorySwagger struct {
 	ParentID        uint              `json:"parent_id" example:"0"`
 	MetaTitle       string            `json:"meta_title" example:"技术分类"`
 	MetaDescription string            `json:"meta_description" example:"技术相关的所有文章"`
+	AccessRules     []AccessRule      `json:"access_rules,omitempty"`
 	Children        []CategorySwagger `json:"children,omitempty"`
 }
 
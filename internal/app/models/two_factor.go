@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// TwoFactorCredential 用户的 TOTP 两步验证凭据
+// 开始绑定时创建，ConfirmedAt 为空表示尚未用验证码确认，此时登录不要求两步验证
+type TwoFactorCredential struct {
+	gorm.Model
+	UserID         uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	Secret         string     `gorm:"size:64;not null" json:"-"` // base32 编码的 TOTP 密钥
+	ConfirmedAt    *time.Time `json:"confirmed_at"`
+	LastUsedStep   int64      `json:"-"`                  // 最近一次通过的时间窗口，防止验证码重放
+	FailedAttempts int        `gorm:"default:0" json:"-"` // 连续输错的次数，验证通过后清零
+	LockedUntil    *time.Time `json:"-"`                  // 连续输错后锁定到的时间，期间拒绝验证
+
+	// 关联
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName 指定表名
+func (TwoFactorCredential) TableName() string {
+	return database.TableName("two_factor_credentials")
+}
+
+// IsConfirmed 凭据是否已确认启用
+func (c *TwoFactorCredential) IsConfirmed() bool {
+	return c.ConfirmedAt != nil
+}
+
+// IsLocked 凭据在 now 时是否因连续输错而锁定
+func (c *TwoFactorCredential) IsLocked(now time.Time) bool {
+	return c.LockedUntil != nil && c.LockedUntil.After(now)
+}
+
+// RecoveryCode 两步验证一次性恢复码，只保存哈希值
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash string     `gorm:"size:64;not null" json:"-"`
+	UsedAt   *time.Time `json:"used_at"`
+}
+
+// TableName 指定表名
+func (RecoveryCode) TableName() string {
+	return database.TableName("two_factor_recovery_codes")
+}
@@ -18,6 +18,9 @@ type User struct {
 	LastLogin *time.Time `json:"last_login"`
 	Status    string     `gorm:"size:20;default:'active'" json:"status"` // active, inactive, banned
 
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"` // 连续登录失败后锁定到该时间
+
 	// 关联
 	Roles []Role `gorm:"many2many:user_roles;" json:"roles,omitempty"`
 }
@@ -33,6 +36,8 @@ type UserProfile struct {
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	LastLogin *time.Time `json:"last_login"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
 }
 
 // ToProfile 将用户模型转换为用户资料
@@ -47,9 +52,21 @@ func (u *User) ToProfile() UserProfile {
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 		LastLogin: u.LastLogin,
+
+		EmailVerifiedAt: u.EmailVerifiedAt,
 	}
 }
 
+// IsEmailVerified 检查用户是否已验证邮箱
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsLocked 检查用户是否因连续登录失败被锁定
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && u.LockedUntil.After(now)
+}
+
 // HasRole 检查用户是否有指定角色
 func (u *User) HasRole(roleName string) bool {
 	for _, role := range u.Roles {
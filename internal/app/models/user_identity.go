@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// UserIdentity 用户关联的第三方身份（OpenID Connect），同一提供方的 sub 只能关联一个用户
+type UserIdentity struct {
+	gorm.Model
+	UserID      uint       `gorm:"not null;uniqueIndex:idx_user_identities_user_provider" json:"user_id"`
+	Provider    string     `gorm:"size:50;not null;uniqueIndex:idx_user_identities_user_provider;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject" json:"-"`
+	Email       string     `gorm:"size:100" json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+
+	// 关联
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return database.TableName("user_identities")
+}
+
+// OIDCState 发起第三方登录时保存的 state、nonce 和 PKCE code_verifier，回调时使用一次后删除
+// UserID 不为0时表示已登录用户关联新身份，而不是登录
+type OIDCState struct {
+	ID           uint      `gorm:"primarykey"`
+	StateHash    string    `gorm:"size:64;not null;uniqueIndex"`
+	Provider     string    `gorm:"size:50;not null"`
+	Nonce        string    `gorm:"size:64;not null"`
+	CodeVerifier string    `gorm:"size:64;not null"`
+	UserID       uint      `gorm:"default:0"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time
+}
+
+// TableName 指定表名
+func (OIDCState) TableName() string {
+	return database.TableName("oidc_states")
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// UserWallet 用户关联的以太坊钱包地址，同一地址只能关联一个用户，一个用户可以关联多个地址
+type UserWallet struct {
+	gorm.Model
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Address     string     `gorm:"size:42;not null;uniqueIndex" json:"address"` // EIP-55 校验和格式
+	ChainID     int64      `json:"chain_id"`                                    // 最近一次签名使用的 Chain ID
+	LastLoginAt *time.Time `json:"last_login_at"`
+
+	// 关联
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName 指定表名
+func (UserWallet) TableName() string {
+	return database.TableName("user_wallets")
+}
+
+// SIWENonce 钱包登录的 nonce，签名校验时使用一次后删除，防止签名被重放
+// UserID 不为0时表示已登录用户关联新钱包，而不是登录
+type SIWENonce struct {
+	ID        uint      `gorm:"primarykey"`
+	Nonce     string    `gorm:"size:64;not null;uniqueIndex"`
+	UserID    uint      `gorm:"default:0"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (SIWENonce) TableName() string {
+	return database.TableName("siwe_nonces")
+}
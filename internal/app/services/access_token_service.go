@@ -0,0 +1,181 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// AccessTokenPrefix 个人访问令牌前缀，用于和 JWT 区分
+const AccessTokenPrefix = "acms_"
+
+// TokenScopesKey 使用个人访问令牌认证时，请求上下文中保存权限范围（[]string）的键
+const TokenScopesKey = "token_scopes"
+
+var (
+	// ErrInvalidAccessToken 令牌不存在、已删除或已过期
+	ErrInvalidAccessToken = errors.New("invalid or expired access token")
+
+	// ErrInvalidScope 权限范围不存在或超出用户当前的权限
+	ErrInvalidScope = errors.New("invalid token scope")
+)
+
+// lastUsedInterval 最近使用时间的最小更新间隔，避免每个请求都写库
+const lastUsedInterval = time.Minute
+
+// AccessTokenService 个人访问令牌服务
+type AccessTokenService struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+// NewAccessTokenService 创建个人访问令牌服务
+func NewAccessTokenService() *AccessTokenService {
+	return &AccessTokenService{db: database.GetDB(), now: time.Now}
+}
+
+// Create 为用户创建令牌，返回只显示一次的明文令牌
+// scopes 必须是用户当前拥有的权限名称
+func (s *AccessTokenService) Create(userID uint, name string, scopes []string, expiresAt *time.Time) (*models.PersonalAccessToken, string, error) {
+	scopes, err := s.validateScopes(userID, scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if expiresAt != nil && !expiresAt.After(s.now()) {
+		return nil, "", fmt.Errorf("expiry must be in the future")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	plain := AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	token := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashAccessToken(plain),
+		Prefix:    plain[:len(AccessTokenPrefix)+6],
+		ExpiresAt: expiresAt,
+	}
+	token.SetScopes(scopes)
+	if err := s.db.Create(token).Error; err != nil {
+		return nil, "", err
+	}
+	return token, plain, nil
+}
+
+// List 获取用户的所有令牌
+func (s *AccessTokenService) List(userID uint) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := s.db.Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// Get 获取用户的令牌
+func (s *AccessTokenService) Get(userID, tokenID uint) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	if err := s.db.Where("user_id = ?", userID).First(&token, tokenID).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Update 修改令牌名称和权限范围，scopes 为 nil 时不修改权限范围
+func (s *AccessTokenService) Update(userID, tokenID uint, name string, scopes []string) (*models.PersonalAccessToken, error) {
+	token, err := s.Get(userID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		token.Name = name
+	}
+	if scopes != nil {
+		if scopes, err = s.validateScopes(userID, scopes); err != nil {
+			return nil, err
+		}
+		token.SetScopes(scopes)
+	}
+	if err := s.db.Model(token).Select("Name", "Scopes").Updates(token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Revoke 删除用户的令牌，立即失效
+func (s *AccessTokenService) Revoke(userID, tokenID uint) error {
+	result := s.db.Where("user_id = ?", userID).Delete(&models.PersonalAccessToken{}, tokenID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Authenticate 校验明文令牌并记录最近使用时间和IP
+func (s *AccessTokenService) Authenticate(plain, ip string) (*models.PersonalAccessToken, error) {
+	if !strings.HasPrefix(plain, AccessTokenPrefix) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	var token models.PersonalAccessToken
+	err := s.db.Where("token_hash = ?", hashAccessToken(plain)).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAccessToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if token.IsExpired(now) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedInterval || token.LastUsedIP != ip {
+		token.LastUsedAt = &now
+		token.LastUsedIP = ip
+		s.db.Model(&token).UpdateColumns(map[string]interface{}{"last_used_at": now, "last_used_ip": ip})
+	}
+	return &token, nil
+}
+
+// validateScopes 检查权限范围都是用户当前拥有的权限，返回去重排序后的列表
+func (s *AccessTokenService) validateScopes(userID uint, scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidScope)
+	}
+
+	var user models.User
+	if err := s.db.Preload("Roles.Permissions").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	unique := make([]string, 0, len(scopes))
+	for name := range uniqueNames(scopes) {
+		if !user.HasPermission(name) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidScope, name)
+		}
+		unique = append(unique, name)
+	}
+	sort.Strings(unique)
+	return unique, nil
+}
+
+// hashAccessToken 令牌只保存 SHA-256 哈希值
+func hashAccessToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"gorm.io/gorm"
+)
+
+func TestAccessTokenService_Lifecycle(t *testing.T) {
+	perms, db := newTestPermissionService(t)
+	s := &AccessTokenService{db: db, now: time.Now}
+
+	editor := createUserWithRole(t, perms, "editor", "editor")
+	other := createUserWithRole(t, perms, "other", "editor")
+
+	if _, _, err := s.Create(editor.ID, "ci", []string{"post.create", "role.manage"}, nil); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("scope outside user permissions should fail, got %v", err)
+	}
+	if _, _, err := s.Create(editor.ID, "ci", nil, nil); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("empty scopes should fail, got %v", err)
+	}
+
+	token, plain, err := s.Create(editor.ID, "ci", []string{"post.update", "post.create", "post.create"}, nil)
+	if err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	if !strings.HasPrefix(plain, AccessTokenPrefix) || !strings.HasPrefix(plain, token.Prefix) {
+		t.Fatalf("unexpected token %q (prefix %q)", plain, token.Prefix)
+	}
+	if token.TokenHash == plain || strings.Contains(token.TokenHash, plain) {
+		t.Fatal("plaintext token must not be stored")
+	}
+	if got := strings.Join(token.ScopeList(), ","); got != "post.create,post.update" {
+		t.Errorf("scopes = %s", got)
+	}
+
+	authed, err := s.Authenticate(plain, "10.0.0.1")
+	if err != nil || authed.UserID != editor.ID {
+		t.Fatalf("Authenticate = %+v, %v", authed, err)
+	}
+	stored, _ := s.Get(editor.ID, token.ID)
+	if stored.LastUsedAt == nil || stored.LastUsedIP != "10.0.0.1" {
+		t.Errorf("last use not recorded: %+v", stored)
+	}
+	if _, err := s.Authenticate(plain+"x", ""); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("tampered token should fail, got %v", err)
+	}
+
+	// 其他用户不能修改或撤销
+	if _, err := s.Update(other.ID, token.ID, "stolen", nil); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("other user update should be not found, got %v", err)
+	}
+	if err := s.Revoke(other.ID, token.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("other user revoke should be not found, got %v", err)
+	}
+
+	updated, err := s.Update(editor.ID, token.ID, "deploy", []string{"post.read"})
+	if err != nil || updated.Name != "deploy" || updated.Scopes != "post.read" {
+		t.Fatalf("Update = %+v, %v", updated, err)
+	}
+
+	if err := s.Revoke(editor.ID, token.ID); err != nil {
+		t.Fatalf("Revoke error: %v", err)
+	}
+	if _, err := s.Authenticate(plain, ""); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("revoked token should fail, got %v", err)
+	}
+}
+
+func TestAccessTokenService_Expiry(t *testing.T) {
+	perms, db := newTestPermissionService(t)
+	now := time.Now()
+	s := &AccessTokenService{db: db, now: func() time.Time { return now }}
+
+	user := createUserWithRole(t, perms, "editor", "editor")
+
+	past := now.Add(-time.Minute)
+	if _, _, err := s.Create(user.ID, "old", []string{"post.read"}, &past); err == nil {
+		t.Fatal("expiry in the past should fail")
+	}
+
+	expires := now.Add(time.Hour)
+	_, plain, err := s.Create(user.ID, "short", []string{"post.read"}, &expires)
+	if err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	if _, err := s.Authenticate(plain, ""); err != nil {
+		t.Fatalf("token should be valid before expiry: %v", err)
+	}
+
+	now = expires
+	if _, err := s.Authenticate(plain, ""); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("expired token should fail, got %v", err)
+	}
+}
+
+func TestPermissionService_AuthorizeScoped(t *testing.T) {
+	s, db := newTestPermissionService(t)
+
+	author := createUserWithRole(t, s, "author", "author")
+	editor := createUserWithRole(t, s, "editor", "editor")
+
+	category := &models.Category{Name: "News", Slug: "news"}
+	db.Create(category)
+	post := &models.Post{Title: "Hello", Slug: "hello", Content: "content", AuthorID: author.ID, CategoryID: category.ID}
+	db.Create(post)
+
+	cases := []struct {
+		name   string
+		user   *models.User
+		scopes []string
+		action string
+		id     uint
+		want   bool
+	}{
+		{"unrestricted", editor, nil, "update", post.ID, true},
+		{"scope allows", editor, []string{"post.update"}, "update", post.ID, true},
+		{"scope excludes action", editor, []string{"post.create"}, "update", post.ID, false},
+		{"empty scopes allow nothing", editor, []string{}, "create", 0, false},
+		{"own scope keeps ownership policy", author, []string{"post.update_own"}, "update", post.ID, true},
+		{"scope cannot exceed role", author, []string{"post.update"}, "update", post.ID, false},
+	}
+	for _, tc := range cases {
+		got, err := s.AuthorizeScoped(tc.user.ID, tc.scopes, "post", tc.action, "post", tc.id)
+		if err != nil {
+			t.Fatalf("%s: AuthorizeScoped error: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: AuthorizeScoped = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
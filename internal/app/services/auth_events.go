@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/clarkzhu2020/aidecms/pkg/event"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+var logAuthEventsOnce sync.Once
+
+// LogAuthEvents 注册认证事件的日志监听器，将登录成功、登录失败和账户锁定写入应用日志
+// 监听器异步执行，不影响登录响应时间；重复调用只注册一次
+func LogAuthEvents() {
+	logAuthEventsOnce.Do(func() {
+		dispatcher := event.GetDispatcher()
+		dispatcher.ListenWithOptions("user.logged_in", "auth.log", func(ctx context.Context, e event.Event) error {
+			if ev, ok := e.(*event.UserLoggedIn); ok {
+				hlog.Infof("auth: user %d logged in from %s", ev.UserID, ev.IP)
+			}
+			return nil
+		}, 0, true)
+		dispatcher.ListenWithOptions("user.login_failed", "auth.log", func(ctx context.Context, e event.Event) error {
+			if ev, ok := e.(*event.LoginFailed); ok {
+				hlog.Warnf("auth: login failed for %q (user %d) from %s: %s", ev.Identifier, ev.UserID, ev.IP, ev.Reason)
+			}
+			return nil
+		}, 0, true)
+		dispatcher.ListenWithOptions("user.locked_out", "auth.log", func(ctx context.Context, e event.Event) error {
+			if ev, ok := e.(*event.UserLockedOut); ok {
+				hlog.Warnf("auth: user %d locked until %s after failed logins from %s", ev.UserID, ev.Until.Format("2006-01-02 15:04:05"), ev.IP)
+			}
+			return nil
+		}, 0, true)
+	})
+}
+
+// dispatchEvent 分发认证事件，监听器出错只记录日志，不影响登录流程
+func dispatchEvent(e event.Event) {
+	if err := event.Dispatch(e); err != nil {
+		hlog.Warnf("auth event %s: %v", e.EventName(), err)
+	}
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/pkg/ratelimit"
+)
+
+// 登录失败后的递增延迟：第2次失败后等待1秒，之后每次翻倍，最长30秒
+const (
+	loginBaseDelay = time.Second
+	loginMaxDelay  = 30 * time.Second
+)
+
+// LoginThrottle 记录每个账户和每个IP的登录失败次数，失败后要求等待递增的时间再重试
+// 计数保存在进程内存中（pkg/ratelimit 滑动窗口），多实例部署时每个实例分别计数；
+// 已存在用户的账户锁定由 UserService 保存到数据库，可以通过 user:unlock 命令解除
+// 所有方法都可以在 nil 上调用，此时不做任何限制
+type LoginThrottle struct {
+	accounts      *ratelimit.SlidingWindow
+	ips           *ratelimit.SlidingWindow
+	maxAttempts   int
+	ipMaxAttempts int
+	lockout       time.Duration
+	now           func() time.Time
+
+	mu        sync.Mutex
+	blocked   map[string]time.Time // 键 -> 允许再次尝试的时间
+	lastSweep time.Time
+}
+
+// NewLoginThrottle 根据账户配置创建登录限制，LoginMaxAttempts 不大于0时返回 nil（不限制）
+func NewLoginThrottle(cfg *config.AuthConfig) *LoginThrottle {
+	if cfg == nil || cfg.LoginMaxAttempts <= 0 || cfg.LoginLockout <= 0 {
+		return nil
+	}
+
+	t := &LoginThrottle{
+		accounts:      ratelimit.NewSlidingWindow(cfg.LoginMaxAttempts, cfg.LoginLockout),
+		maxAttempts:   cfg.LoginMaxAttempts,
+		ipMaxAttempts: cfg.LoginIPMaxAttempts,
+		lockout:       cfg.LoginLockout,
+		now:           time.Now,
+		blocked:       make(map[string]time.Time),
+	}
+	if cfg.LoginIPMaxAttempts > 0 {
+		t.ips = ratelimit.NewSlidingWindow(cfg.LoginIPMaxAttempts, cfg.LoginLockout)
+	}
+	return t
+}
+
+// Wait 返回账户或IP还需要等待的时间，为0时允许尝试登录
+func (t *LoginThrottle) Wait(account, ip string) time.Duration {
+	if t == nil {
+		return 0
+	}
+
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var wait time.Duration
+	for _, key := range []string{account, ipKey(ip)} {
+		until, ok := t.blocked[key]
+		if !ok {
+			continue
+		}
+		if d := until.Sub(now); d > 0 {
+			wait = max(wait, d)
+		} else {
+			delete(t.blocked, key)
+		}
+	}
+	return wait
+}
+
+// Failure 记录一次登录失败并设置下次允许尝试的时间
+// 账户失败次数达到上限时清零计数并返回 true，调用方负责锁定账户
+func (t *LoginThrottle) Failure(account, ip string) bool {
+	if t == nil {
+		return false
+	}
+
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sweep(now)
+
+	if t.ips != nil && ip != "" {
+		key := ipKey(ip)
+		t.ips.Allow(key)
+		if failures(t.ips, key) >= t.ipMaxAttempts {
+			t.ips.Reset(key)
+			t.blocked[key] = now.Add(t.lockout)
+		}
+	}
+
+	t.accounts.Allow(account)
+	n := failures(t.accounts, account)
+	if n >= t.maxAttempts {
+		t.accounts.Reset(account)
+		delete(t.blocked, account)
+		return true
+	}
+	if n >= 2 {
+		delay := loginBaseDelay << (n - 2)
+		if delay > loginMaxDelay || delay <= 0 {
+			delay = loginMaxDelay
+		}
+		t.blocked[account] = now.Add(delay)
+	}
+	return false
+}
+
+// Lock 在内存中锁定账户，用于不存在的用户名，使其与真实账户的锁定表现一致
+func (t *LoginThrottle) Lock(account string) {
+	if t == nil {
+		return
+	}
+
+	until := t.now().Add(t.lockout)
+	t.mu.Lock()
+	t.blocked[account] = until
+	t.mu.Unlock()
+}
+
+// Success 登录成功后清除账户的失败记录，IP的失败记录保留
+func (t *LoginThrottle) Success(account string) {
+	if t == nil {
+		return
+	}
+
+	t.accounts.Reset(account)
+	t.mu.Lock()
+	delete(t.blocked, account)
+	t.mu.Unlock()
+}
+
+// Close 停止滑动窗口的后台清理
+func (t *LoginThrottle) Close() {
+	if t == nil {
+		return
+	}
+
+	t.accounts.Close()
+	if t.ips != nil {
+		t.ips.Close()
+	}
+}
+
+// sweep 每分钟清理一次已过期的等待记录，调用方需持有锁
+func (t *LoginThrottle) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < time.Minute {
+		return
+	}
+	t.lastSweep = now
+	for key, until := range t.blocked {
+		if !until.After(now) {
+			delete(t.blocked, key)
+		}
+	}
+}
+
+// failures 滑动窗口内记录的失败次数
+func failures(window *ratelimit.SlidingWindow, key string) int {
+	count, _ := window.GetStats(key)["requests"].(int)
+	return count
+}
+
+// loginAccountKey 登录限制的账户键，已存在的用户按ID计数，
+// 用户名和邮箱交替尝试时共用同一个计数
+func loginAccountKey(identifier string, userID uint) string {
+	if userID != 0 {
+		return ratelimit.CompositeKeyGenerator("login", ratelimit.UserKeyGenerator(strconv.FormatUint(uint64(userID), 10)))
+	}
+	return ratelimit.CompositeKeyGenerator("login", "name", strings.ToLower(strings.TrimSpace(identifier)))
+}
+
+// ipKey 登录限制的IP键
+func ipKey(ip string) string {
+	return ratelimit.CompositeKeyGenerator("login", ratelimit.IPKeyGenerator(ip))
+}
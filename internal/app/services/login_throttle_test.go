@@ -0,0 +1,103 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+)
+
+func newTestLoginThrottle(t *testing.T, cfg *config.AuthConfig, now *time.Time) *LoginThrottle {
+	t.Helper()
+	throttle := NewLoginThrottle(cfg)
+	throttle.now = func() time.Time { return *now }
+	t.Cleanup(throttle.Close)
+	return throttle
+}
+
+func TestLoginThrottle_ProgressiveDelayAndLock(t *testing.T) {
+	now := time.Now()
+	throttle := newTestLoginThrottle(t, &config.AuthConfig{LoginMaxAttempts: 5, LoginLockout: 15 * time.Minute}, &now)
+	account := loginAccountKey("", 1)
+
+	if throttle.Failure(account, "10.0.0.1") {
+		t.Fatal("first failure should not lock")
+	}
+	if wait := throttle.Wait(account, "10.0.0.1"); wait != 0 {
+		t.Fatalf("wait after first failure = %v, want 0", wait)
+	}
+
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		if throttle.Failure(account, "10.0.0.1") {
+			t.Fatalf("failure %d should not lock", i+2)
+		}
+		if wait := throttle.Wait(account, "10.0.0.2"); wait != want {
+			t.Fatalf("wait after failure %d = %v, want %v", i+2, wait, want)
+		}
+		now = now.Add(want)
+		if wait := throttle.Wait(account, "10.0.0.2"); wait != 0 {
+			t.Fatalf("wait after delay = %v, want 0", wait)
+		}
+	}
+
+	if !throttle.Failure(account, "10.0.0.1") {
+		t.Fatal("fifth failure should lock")
+	}
+	// 锁定后计数清零，由调用方保存锁定状态
+	if throttle.Failure(account, "10.0.0.1") {
+		t.Fatal("counter should restart after lock")
+	}
+
+	throttle.Success(account)
+	throttle.Failure(account, "10.0.0.1")
+	if wait := throttle.Wait(account, "10.0.0.1"); wait != 0 {
+		t.Fatalf("wait after success = %v, want 0", wait)
+	}
+}
+
+func TestLoginThrottle_IPLimit(t *testing.T) {
+	now := time.Now()
+	throttle := newTestLoginThrottle(t, &config.AuthConfig{LoginMaxAttempts: 5, LoginIPMaxAttempts: 3, LoginLockout: 15 * time.Minute}, &now)
+
+	// 每次尝试不同的用户名，账户计数不会触发
+	for _, name := range []string{"a", "b", "c"} {
+		throttle.Failure(loginAccountKey(name, 0), "10.0.0.1")
+	}
+
+	if wait := throttle.Wait(loginAccountKey("d", 0), "10.0.0.1"); wait != 15*time.Minute {
+		t.Fatalf("wait for blocked IP = %v, want 15m", wait)
+	}
+	if wait := throttle.Wait(loginAccountKey("d", 0), "10.0.0.2"); wait != 0 {
+		t.Fatalf("wait for other IP = %v, want 0", wait)
+	}
+
+	// 登录成功不清除IP的失败记录
+	throttle.Success(loginAccountKey("d", 0))
+	if wait := throttle.Wait(loginAccountKey("d", 0), "10.0.0.1"); wait == 0 {
+		t.Fatal("IP block should survive a successful login")
+	}
+
+	now = now.Add(15 * time.Minute)
+	if wait := throttle.Wait(loginAccountKey("d", 0), "10.0.0.1"); wait != 0 {
+		t.Fatalf("wait after lockout = %v, want 0", wait)
+	}
+}
+
+func TestLoginThrottle_Disabled(t *testing.T) {
+	throttle := NewLoginThrottle(&config.AuthConfig{})
+	if throttle != nil {
+		t.Fatal("throttle should be disabled without LoginMaxAttempts")
+	}
+	if throttle.Failure("login:user:1", "10.0.0.1") || throttle.Wait("login:user:1", "10.0.0.1") != 0 {
+		t.Fatal("nil throttle should not limit")
+	}
+}
+
+func TestLoginAccountKey(t *testing.T) {
+	if loginAccountKey(" Alice ", 0) != loginAccountKey("alice", 0) {
+		t.Fatal("unknown identifiers should be case and space insensitive")
+	}
+	if loginAccountKey("alice", 7) != loginAccountKey("alice@example.com", 7) {
+		t.Fatal("known users should share one key for username and email")
+	}
+}
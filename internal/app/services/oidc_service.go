@@ -0,0 +1,440 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/clarkzhu2020/aidecms/pkg/oidc"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOIDCProviderNotFound 身份提供方未配置
+	ErrOIDCProviderNotFound = errors.New("身份提供方不存在")
+
+	// ErrOIDCState 回调中的 state 无效、已使用或已过期
+	ErrOIDCState = errors.New("登录请求无效或已过期，请重新登录")
+
+	// ErrOIDCNoAccount 身份未关联账户，且身份提供方不允许自动注册
+	ErrOIDCNoAccount = errors.New("该身份尚未关联账户")
+
+	// ErrOIDCAccountExists 自动注册时邮箱已被现有用户使用，需要登录后手动关联
+	ErrOIDCAccountExists = errors.New("邮箱已被其他账户使用，请登录后在账户设置中关联")
+
+	// ErrOIDCEmailRequired 自动注册需要身份提供方返回已验证的邮箱
+	ErrOIDCEmailRequired = errors.New("身份提供方没有返回已验证的邮箱")
+
+	// ErrOIDCDomainNotAllowed 邮箱域名不在身份提供方的允许列表中
+	ErrOIDCDomainNotAllowed = errors.New("该邮箱域名不允许登录")
+
+	// ErrIdentityLinked 该第三方身份已关联其他用户
+	ErrIdentityLinked = errors.New("该身份已关联其他账户")
+
+	// ErrProviderLinked 用户已关联该身份提供方的另一个身份
+	ErrProviderLinked = errors.New("已关联该身份提供方的其他身份，请先解除关联")
+
+	// ErrIdentityNotFound 用户未关联该身份提供方
+	ErrIdentityNotFound = errors.New("未关联该身份提供方")
+)
+
+// usernameInvalidChars 自动注册时从用户名中去掉的字符
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// OIDCProviderInfo 登录页面显示的身份提供方
+type OIDCProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OIDCAuthRequest 发起登录或关联时生成的授权请求
+type OIDCAuthRequest struct {
+	URL       string
+	State     string
+	ExpiresAt time.Time
+}
+
+// OIDCResult 第三方登录回调的结果
+type OIDCResult struct {
+	User     *models.User
+	Provider string
+	Linked   bool // 已登录用户关联新身份，调用方不应签发令牌
+	Created  bool // 自动注册的新用户
+}
+
+// OIDCService OpenID Connect 第三方登录服务
+// 身份提供方在第一次使用时读取服务发现文档，之后复用
+type OIDCService struct {
+	db         *gorm.DB
+	config     *config.OIDCConfig
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	providers map[string]*oidc.Provider
+}
+
+// NewOIDCService 创建第三方登录服务，配置错误时记录日志并禁用第三方登录
+func NewOIDCService() *OIDCService {
+	cfg, err := config.LoadOIDCConfig()
+	if err != nil {
+		hlog.Errorf("failed to load OIDC config: %v", err)
+		cfg = &config.OIDCConfig{StateTTL: 10 * time.Minute}
+	}
+	return &OIDCService{
+		db:        database.GetDB(),
+		config:    cfg,
+		now:       time.Now,
+		providers: make(map[string]*oidc.Provider),
+	}
+}
+
+// Providers 已配置的身份提供方，按名称排序
+func (s *OIDCService) Providers() []OIDCProviderInfo {
+	infos := make([]OIDCProviderInfo, 0, len(s.config.Providers))
+	for name, provider := range s.config.Providers {
+		infos = append(infos, OIDCProviderInfo{Name: name, DisplayName: provider.DisplayName})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Begin 发起授权，保存 state、nonce 和 PKCE code_verifier，返回跳转到身份提供方的地址
+// userID 不为0时为已登录用户关联新身份
+func (s *OIDCService) Begin(ctx context.Context, name string, userID uint) (*OIDCAuthRequest, error) {
+	provider, _, err := s.provider(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var values [3]string
+	for i := range values {
+		if values[i], err = oidc.RandomToken(); err != nil {
+			return nil, err
+		}
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	now := s.now()
+	// 顺便清理过期的 state
+	if err := s.db.Where("expires_at < ?", now).Delete(&models.OIDCState{}).Error; err != nil {
+		return nil, err
+	}
+	record := &models.OIDCState{
+		StateHash:    hashOIDCState(state),
+		Provider:     name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		UserID:       userID,
+		ExpiresAt:    now.Add(s.config.StateTTL),
+	}
+	if err := s.db.Create(record).Error; err != nil {
+		return nil, err
+	}
+
+	return &OIDCAuthRequest{
+		URL:       provider.AuthCodeURL(state, nonce, verifier),
+		State:     state,
+		ExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+// Complete 处理身份提供方的回调：校验 state，用授权码换取令牌，校验 ID 令牌并读取 userinfo，
+// 然后按关联规则找到或创建用户。登录流程由调用方用 UserService.LoginUser 签发令牌
+func (s *OIDCService) Complete(ctx context.Context, name, state, code string) (*OIDCResult, error) {
+	provider, cfg, err := s.provider(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// state 只能使用一次，并发回调时只有一个请求能删除成功
+	var record models.OIDCState
+	if err := s.db.Where("state_hash = ? AND provider = ?", hashOIDCState(state), name).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOIDCState
+		}
+		return nil, err
+	}
+	result := s.db.Delete(&models.OIDCState{}, record.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || !record.ExpiresAt.After(s.now()) {
+		return nil, ErrOIDCState
+	}
+
+	token, err := provider.Exchange(ctx, code, record.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := provider.VerifyIDToken(ctx, token.IDToken, record.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	claims := idToken.Claims
+	info, err := provider.UserInfo(ctx, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if info != nil {
+		if info.Subject != idToken.Subject {
+			return nil, fmt.Errorf("%w: userinfo sub does not match id token", oidc.ErrInvalidIDToken)
+		}
+		claims.Merge(info.Claims)
+	}
+
+	var res *OIDCResult
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		res, err = s.resolveUser(tx, cfg, idToken.Subject, claims, record.UserID)
+		if err != nil {
+			return err
+		}
+		now := s.now()
+		return tx.Model(&models.UserIdentity{}).
+			Where("provider = ? AND subject = ?", name, idToken.Subject).
+			Updates(map[string]interface{}{"email": claims.Email, "last_login_at": &now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.Provider = name
+	res.Linked = record.UserID != 0
+	return res, nil
+}
+
+// Identities 用户关联的第三方身份
+func (s *OIDCService) Identities(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := s.db.Where("user_id = ?", userID).Order("provider").Find(&identities).Error
+	return identities, err
+}
+
+// Unlink 解除用户与身份提供方的关联
+func (s *OIDCService) Unlink(userID uint, name string) error {
+	result := s.db.Unscoped().Where("user_id = ? AND provider = ?", userID, name).Delete(&models.UserIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrIdentityNotFound
+	}
+	return nil
+}
+
+// provider 获取身份提供方，第一次使用时读取服务发现文档；读取失败时下次请求会重试
+func (s *OIDCService) provider(ctx context.Context, name string) (*oidc.Provider, *config.OIDCProviderConfig, error) {
+	cfg, ok := s.config.Providers[name]
+	if !ok {
+		return nil, nil, ErrOIDCProviderNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if provider, ok := s.providers[name]; ok {
+		return provider, cfg, nil
+	}
+	provider, err := oidc.Discover(ctx, oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+		HTTPClient:   s.httpClient,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	s.providers[name] = provider
+	return provider, cfg, nil
+}
+
+// resolveUser 按关联规则确定第三方身份对应的用户：
+//  1. 已关联的身份直接登录
+//  2. 关联流程中关联到当前用户
+//  3. LinkByEmail 时关联到已验证邮箱相同的现有用户
+//  4. AutoProvision 时创建新用户
+func (s *OIDCService) resolveUser(tx *gorm.DB, cfg *config.OIDCProviderConfig, subject string, claims oidc.Claims, linkUserID uint) (*OIDCResult, error) {
+	var identity models.UserIdentity
+	err := tx.Where("provider = ? AND subject = ?", cfg.Name, subject).First(&identity).Error
+	if err == nil {
+		if linkUserID != 0 && identity.UserID != linkUserID {
+			return nil, ErrIdentityLinked
+		}
+		var user models.User
+		if err := tx.First(&user, identity.UserID).Error; err != nil {
+			return nil, err
+		}
+		return &OIDCResult{User: &user}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if linkUserID != 0 {
+		var user models.User
+		if err := tx.First(&user, linkUserID).Error; err != nil {
+			return nil, err
+		}
+		if err := linkIdentity(tx, &user, cfg.Name, subject, claims.Email); err != nil {
+			return nil, err
+		}
+		return &OIDCResult{User: &user}, nil
+	}
+
+	allowed := emailDomainAllowed(claims.Email, cfg.AllowedDomains)
+	if cfg.LinkByEmail && claims.Email != "" && claims.EmailVerified && allowed {
+		var user models.User
+		err := tx.Where("email = ?", claims.Email).First(&user).Error
+		if err == nil {
+			// 未验证邮箱的账户可能是他人抢先注册的，不能自动关联
+			if !user.IsEmailVerified() {
+				return nil, ErrOIDCAccountExists
+			}
+			if err := linkIdentity(tx, &user, cfg.Name, subject, claims.Email); err != nil {
+				return nil, err
+			}
+			return &OIDCResult{User: &user}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if !cfg.AutoProvision {
+		return nil, ErrOIDCNoAccount
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailRequired
+	}
+	if !allowed {
+		return nil, ErrOIDCDomainNotAllowed
+	}
+	var count int64
+	if err := tx.Model(&models.User{}).Where("email = ?", claims.Email).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrOIDCAccountExists
+	}
+
+	user, err := s.provisionUser(tx, cfg, claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := linkIdentity(tx, user, cfg.Name, subject, claims.Email); err != nil {
+		return nil, err
+	}
+	return &OIDCResult{User: user, Created: true}, nil
+}
+
+// provisionUser 为第三方身份创建新用户，密码为随机值，用户可以通过重置密码设置
+func (s *OIDCService) provisionUser(tx *gorm.DB, cfg *config.OIDCProviderConfig, claims oidc.Claims) (*models.User, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	username, err := availableUsername(tx, base)
+	if err != nil {
+		return nil, err
+	}
+	password, err := oidc.RandomToken()
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	user := &models.User{
+		Username:        username,
+		Email:           claims.Email,
+		Password:        string(hashed),
+		FirstName:       claims.GivenName,
+		LastName:        claims.FamilyName,
+		Avatar:          claims.Picture,
+		EmailVerifiedAt: &now,
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return nil, err
+	}
+
+	var role models.Role
+	if err := tx.Where("name = ?", cfg.DefaultRole).First(&role).Error; err == nil {
+		if err := tx.Model(user).Association("Roles").Append(&role); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// linkIdentity 为用户创建第三方身份关联，每个身份提供方只能关联一个身份
+func linkIdentity(tx *gorm.DB, user *models.User, provider, subject, email string) error {
+	var count int64
+	if err := tx.Model(&models.UserIdentity{}).Where("user_id = ? AND provider = ?", user.ID, provider).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrProviderLinked
+	}
+	return tx.Create(&models.UserIdentity{UserID: user.ID, Provider: provider, Subject: subject, Email: email}).Error
+}
+
+// availableUsername 自动注册时根据 base（如 preferred_username 或邮箱前缀）生成未被使用的用户名
+func availableUsername(tx *gorm.DB, base string) (string, error) {
+	base = strings.Trim(usernameInvalidChars.ReplaceAllString(strings.ToLower(base), ""), ".-")
+	if len(base) > 50 {
+		base = base[:50]
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	for i := 0; i < 100; i++ {
+		candidate := base
+		if i > 0 {
+			candidate = fmt.Sprintf("%s%d", base, i+1)
+		}
+		var count int64
+		if err := tx.Model(&models.User{}).Unscoped().Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no available username for %q", base)
+}
+
+// emailDomainAllowed 邮箱域名是否在允许列表中，列表为空时不限制
+func emailDomainAllowed(email string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	_, domain, ok := strings.Cut(email, "@")
+	return ok && slices.ContainsFunc(domains, func(d string) bool {
+		return strings.EqualFold(d, domain)
+	})
+}
+
+// hashOIDCState state 只保存哈希值
+func hashOIDCState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
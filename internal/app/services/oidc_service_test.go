@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/oidc"
+	"github.com/clarkzhu2020/aidecms/pkg/oidc/oidctest"
+	"gorm.io/gorm"
+)
+
+func newTestOIDCService(t *testing.T, configure func(*config.OIDCProviderConfig)) (*OIDCService, *oidctest.Server, *gorm.DB) {
+	t.Helper()
+
+	server := oidctest.NewServer("cms", "cms-secret")
+	t.Cleanup(server.Close)
+	_, db := newTestPermissionService(t)
+
+	provider := &config.OIDCProviderConfig{
+		Name:         "mock",
+		DisplayName:  "Mock",
+		Issuer:       server.Issuer(),
+		ClientID:     "cms",
+		ClientSecret: "cms-secret",
+		RedirectURL:  "https://cms.example.com/auth/oidc/mock/callback",
+		DefaultRole:  "user",
+	}
+	if configure != nil {
+		configure(provider)
+	}
+
+	return &OIDCService{
+		db:        db,
+		config:    &config.OIDCConfig{Providers: map[string]*config.OIDCProviderConfig{"mock": provider}, StateTTL: 10 * time.Minute},
+		now:       time.Now,
+		providers: make(map[string]*oidc.Provider),
+	}, server, db
+}
+
+// oidcLogin 完成一次授权并处理回调
+func oidcLogin(t *testing.T, s *OIDCService, server *oidctest.Server, userID uint) (*OIDCResult, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	request, err := s.Begin(ctx, "mock", userID)
+	if err != nil {
+		t.Fatalf("Begin error: %v", err)
+	}
+	code, state, err := server.Authorize(request.URL)
+	if err != nil {
+		t.Fatalf("Authorize error: %v", err)
+	}
+	return s.Complete(ctx, "mock", state, code)
+}
+
+func TestOIDCService_AutoProvision(t *testing.T) {
+	s, server, db := newTestOIDCService(t, func(p *config.OIDCProviderConfig) { p.AutoProvision = true })
+	server.SetUser(oidctest.User{Subject: "sub-1", Email: "grace@example.com", EmailVerified: true, PreferredUsername: "Grace H"})
+
+	result, err := oidcLogin(t, s, server, 0)
+	if err != nil {
+		t.Fatalf("Complete error: %v", err)
+	}
+	if !result.Created || result.Linked || result.User.Username != "graceh" || !result.User.IsEmailVerified() {
+		t.Fatalf("unexpected result: %+v, user %+v", result, result.User)
+	}
+
+	var user models.User
+	db.Preload("Roles").First(&user, result.User.ID)
+	if !user.HasRole("user") {
+		t.Errorf("provisioned user roles = %v, want user", user.Roles)
+	}
+
+	// 再次登录使用已关联的身份
+	again, err := oidcLogin(t, s, server, 0)
+	if err != nil {
+		t.Fatalf("second Complete error: %v", err)
+	}
+	if again.Created || again.User.ID != result.User.ID {
+		t.Fatalf("second login = %+v, want existing user %d", again, result.User.ID)
+	}
+	identities, _ := s.Identities(result.User.ID)
+	if len(identities) != 1 || identities[0].LastLoginAt == nil || identities[0].Email != "grace@example.com" {
+		t.Errorf("unexpected identities: %+v", identities)
+	}
+}
+
+func TestOIDCService_ProvisioningRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*config.OIDCProviderConfig)
+		user      oidctest.User
+		want      error
+	}{
+		{
+			name: "no auto provision",
+			user: oidctest.User{Subject: "s", Email: "new@example.com", EmailVerified: true},
+			want: ErrOIDCNoAccount,
+		},
+		{
+			name:      "unverified email",
+			configure: func(p *config.OIDCProviderConfig) { p.AutoProvision = true },
+			user:      oidctest.User{Subject: "s", Email: "new@example.com"},
+			want:      ErrOIDCEmailRequired,
+		},
+		{
+			name: "domain not allowed",
+			configure: func(p *config.OIDCProviderConfig) {
+				p.AutoProvision = true
+				p.AllowedDomains = []string{"corp.example.com"}
+			},
+			user: oidctest.User{Subject: "s", Email: "new@example.com", EmailVerified: true},
+			want: ErrOIDCDomainNotAllowed,
+		},
+		{
+			name:      "email belongs to existing user",
+			configure: func(p *config.OIDCProviderConfig) { p.AutoProvision = true },
+			user:      oidctest.User{Subject: "s", Email: "existing@example.com", EmailVerified: true},
+			want:      ErrOIDCAccountExists,
+		},
+		{
+			name: "link by email requires verified email",
+			configure: func(p *config.OIDCProviderConfig) {
+				p.LinkByEmail = true
+			},
+			user: oidctest.User{Subject: "s", Email: "existing@example.com"},
+			want: ErrOIDCNoAccount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, server, db := newTestOIDCService(t, tt.configure)
+			db.Create(&models.User{Username: "existing", Email: "existing@example.com", Password: "x"})
+			server.SetUser(tt.user)
+
+			if _, err := oidcLogin(t, s, server, 0); !errors.Is(err, tt.want) {
+				t.Fatalf("Complete error = %v, want %v", err, tt.want)
+			}
+			var count int64
+			db.Model(&models.UserIdentity{}).Count(&count)
+			if count != 0 {
+				t.Errorf("identities = %d, want 0", count)
+			}
+		})
+	}
+}
+
+func TestOIDCService_LinkByEmail(t *testing.T) {
+	s, server, db := newTestOIDCService(t, func(p *config.OIDCProviderConfig) { p.LinkByEmail = true })
+	verifiedAt := time.Now()
+	existing := &models.User{Username: "heidi", Email: "heidi@example.com", Password: "x", EmailVerifiedAt: &verifiedAt}
+	db.Create(existing)
+	server.SetUser(oidctest.User{Subject: "sub-h", Email: "heidi@example.com", EmailVerified: true})
+
+	result, err := oidcLogin(t, s, server, 0)
+	if err != nil {
+		t.Fatalf("Complete error: %v", err)
+	}
+	if result.Created || result.User.ID != existing.ID {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestOIDCService_LinkByEmailRequiresVerifiedAccount(t *testing.T) {
+	s, server, db := newTestOIDCService(t, func(p *config.OIDCProviderConfig) { p.LinkByEmail = true })
+	// 抢先用受害者邮箱注册、未验证邮箱的账户
+	squatter := &models.User{Username: "mallory", Email: "olivia@example.com", Password: "x"}
+	db.Create(squatter)
+	server.SetUser(oidctest.User{Subject: "sub-o", Email: "olivia@example.com", EmailVerified: true})
+
+	if _, err := oidcLogin(t, s, server, 0); !errors.Is(err, ErrOIDCAccountExists) {
+		t.Fatalf("Complete error = %v, want ErrOIDCAccountExists", err)
+	}
+
+	var count int64
+	db.Model(&models.UserIdentity{}).Count(&count)
+	if count != 0 {
+		t.Errorf("identities = %d, want 0", count)
+	}
+	var user models.User
+	db.First(&user, squatter.ID)
+	if user.IsEmailVerified() {
+		t.Error("unverified account should not be marked verified")
+	}
+}
+
+func TestOIDCService_LinkAndUnlink(t *testing.T) {
+	s, server, db := newTestOIDCService(t, nil)
+	ivan := &models.User{Username: "ivan", Email: "ivan@example.com", Password: "x"}
+	judy := &models.User{Username: "judy", Email: "judy@example.com", Password: "x"}
+	db.Create(ivan)
+	db.Create(judy)
+	server.SetUser(oidctest.User{Subject: "sub-i", Email: "ivan@corp.example.com", EmailVerified: true})
+
+	// 已登录用户关联身份，不受自动注册规则限制
+	result, err := oidcLogin(t, s, server, ivan.ID)
+	if err != nil {
+		t.Fatalf("link error: %v", err)
+	}
+	if !result.Linked || result.User.ID != ivan.ID {
+		t.Fatalf("unexpected link result: %+v", result)
+	}
+
+	// 之后可以用该身份登录
+	if result, err := oidcLogin(t, s, server, 0); err != nil || result.User.ID != ivan.ID || result.Linked {
+		t.Fatalf("login = %+v, %v", result, err)
+	}
+
+	// 同一身份不能关联到其他用户
+	if _, err := oidcLogin(t, s, server, judy.ID); !errors.Is(err, ErrIdentityLinked) {
+		t.Fatalf("link to other user error = %v, want ErrIdentityLinked", err)
+	}
+	// 同一提供方不能关联第二个身份
+	server.SetUser(oidctest.User{Subject: "sub-i2", Email: "ivan2@example.com", EmailVerified: true})
+	if _, err := oidcLogin(t, s, server, ivan.ID); !errors.Is(err, ErrProviderLinked) {
+		t.Fatalf("second identity error = %v, want ErrProviderLinked", err)
+	}
+
+	if err := s.Unlink(ivan.ID, "mock"); err != nil {
+		t.Fatalf("Unlink error: %v", err)
+	}
+	if err := s.Unlink(ivan.ID, "mock"); !errors.Is(err, ErrIdentityNotFound) {
+		t.Fatalf("second Unlink error = %v, want ErrIdentityNotFound", err)
+	}
+	if _, err := oidcLogin(t, s, server, judy.ID); err != nil {
+		t.Fatalf("link after unlink error: %v", err)
+	}
+}
+
+func TestOIDCService_StateIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	s, server, _ := newTestOIDCService(t, func(p *config.OIDCProviderConfig) { p.AutoProvision = true })
+
+	request, err := s.Begin(ctx, "mock", 0)
+	if err != nil {
+		t.Fatalf("Begin error: %v", err)
+	}
+	code, state, _ := server.Authorize(request.URL)
+
+	if _, err := s.Complete(ctx, "mock", "forged-state", code); !errors.Is(err, ErrOIDCState) {
+		t.Fatalf("forged state error = %v, want ErrOIDCState", err)
+	}
+	if _, err := s.Complete(ctx, "mock", state, code); err != nil {
+		t.Fatalf("Complete error: %v", err)
+	}
+	if _, err := s.Complete(ctx, "mock", state, code); !errors.Is(err, ErrOIDCState) {
+		t.Fatalf("reused state error = %v, want ErrOIDCState", err)
+	}
+
+	// 过期的 state
+	request, _ = s.Begin(ctx, "mock", 0)
+	code, state, _ = server.Authorize(request.URL)
+	s.now = func() time.Time { return time.Now().Add(11 * time.Minute) }
+	if _, err := s.Complete(ctx, "mock", state, code); !errors.Is(err, ErrOIDCState) {
+		t.Fatalf("expired state error = %v, want ErrOIDCState", err)
+	}
+
+	if _, err := s.Begin(ctx, "unknown", 0); !errors.Is(err, ErrOIDCProviderNotFound) {
+		t.Fatalf("unknown provider error = %v, want ErrOIDCProviderNotFound", err)
+	}
+}
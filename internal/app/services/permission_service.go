@@ -9,6 +9,14 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrSystemRole 系统角色和权限不能删除
+	ErrSystemRole = errors.New("system roles and permissions cannot be deleted")
+
+	// ErrUnknownPermission 权限或角色名称不存在
+	ErrUnknownPermission = errors.New("unknown role or permission")
+)
+
 // PermissionService 权限服务
 type PermissionService struct {
 	db *gorm.DB
@@ -147,6 +155,39 @@ func (s *PermissionService) CheckUserResourcePermission(userID uint, resource, a
 	return user.HasResourcePermission(resource, action), nil
 }
 
+// Authorize 检查用户能否对资源执行操作
+// 拥有 resource.action 权限时允许；否则 id 不为0时交给名为 policy 的资源策略判断（如所有者策略）
+func (s *PermissionService) Authorize(userID uint, resource, action, policy string, id uint) (bool, error) {
+	return s.AuthorizeScoped(userID, nil, resource, action, policy, id)
+}
+
+// AuthorizeScoped 与 Authorize 相同，但用户的权限先限制在 scopes 范围内（个人访问令牌）
+// scopes 为 nil 表示不限制
+func (s *PermissionService) AuthorizeScoped(userID uint, scopes []string, resource, action, policy string, id uint) (bool, error) {
+	var user models.User
+	if err := s.db.Preload("Roles.Permissions").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if scopes != nil {
+		restrictPermissions(&user, scopes)
+	}
+
+	if user.HasResourcePermission(resource, action) {
+		return true, nil
+	}
+	if id == 0 {
+		return false, nil
+	}
+	p, ok := policyFor(policy)
+	if !ok {
+		return false, nil
+	}
+	return p.Allow(s.db, &user, resource, action, id)
+}
+
 // CreateRole 创建角色
 func (s *PermissionService) CreateRole(role *models.Role) error {
 	return s.db.Create(role).Error
@@ -212,3 +253,169 @@ func (s *PermissionService) SyncRolePermissions(roleID uint, permissionIDs []uin
 
 	return nil
 }
+
+// ListRoles 获取所有角色及其权限
+func (s *PermissionService) ListRoles() ([]models.Role, error) {
+	var roles []models.Role
+	err := s.db.Preload("Permissions").Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+// GetRole 根据ID获取角色及其权限
+func (s *PermissionService) GetRole(roleID uint) (*models.Role, error) {
+	var role models.Role
+	if err := s.db.Preload("Permissions").First(&role, roleID).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// UpdateRole 更新角色的显示名称和描述，名称不可修改
+func (s *PermissionService) UpdateRole(roleID uint, displayName, description string) (*models.Role, error) {
+	role, err := s.GetRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	role.DisplayName = displayName
+	role.Description = description
+	if err := s.db.Model(role).Select("DisplayName", "Description").Updates(role).Error; err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// SetRoleRequireTwoFactor 设置拥有该角色的用户是否必须开启两步验证
+// 未开启的用户下次登录时需要先完成绑定
+func (s *PermissionService) SetRoleRequireTwoFactor(roleID uint, required bool) (*models.Role, error) {
+	role, err := s.GetRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	role.RequireTwoFactor = required
+	if err := s.db.Model(role).Update("require_two_factor", required).Error; err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// DeleteRole 删除非系统角色及其关联，名称可以重新使用
+func (s *PermissionService) DeleteRole(roleID uint) error {
+	role, err := s.GetRole(roleID)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return ErrSystemRole
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(role).Error
+	})
+}
+
+// ListPermissions 获取所有权限，按资源和操作排序
+func (s *PermissionService) ListPermissions() ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := s.db.Order("resource ASC, action ASC").Find(&permissions).Error
+	return permissions, err
+}
+
+// DeletePermission 删除非系统权限及其关联
+func (s *PermissionService) DeletePermission(permissionID uint) error {
+	var permission models.Permission
+	if err := s.db.First(&permission, permissionID).Error; err != nil {
+		return err
+	}
+	if permission.IsSystem {
+		return ErrSystemRole
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&permission).Association("Roles").Clear(); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&permission).Error
+	})
+}
+
+// SyncRolePermissionsByName 按权限名称同步角色权限，任一名称不存在时不做修改
+func (s *PermissionService) SyncRolePermissionsByName(roleID uint, names []string) (*models.Role, error) {
+	role, err := s.GetRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []models.Permission
+	if len(names) > 0 {
+		if err := s.db.Where("name IN ?", names).Find(&permissions).Error; err != nil {
+			return nil, err
+		}
+		if len(permissions) != len(uniqueNames(names)) {
+			return nil, ErrUnknownPermission
+		}
+	}
+
+	if err := s.db.Model(role).Association("Permissions").Replace(permissions); err != nil {
+		return nil, fmt.Errorf("failed to sync permissions: %w", err)
+	}
+	return s.GetRole(roleID)
+}
+
+// GetUserRoles 获取用户的角色
+func (s *PermissionService) GetUserRoles(userID uint) ([]models.Role, error) {
+	var user models.User
+	if err := s.db.Preload("Roles").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return user.Roles, nil
+}
+
+// SyncUserRolesByName 按角色名称同步用户角色，任一名称不存在时不做修改
+func (s *PermissionService) SyncUserRolesByName(userID uint, names []string) ([]models.Role, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	var roles []models.Role
+	if len(names) > 0 {
+		if err := s.db.Where("name IN ?", names).Find(&roles).Error; err != nil {
+			return nil, err
+		}
+		if len(roles) != len(uniqueNames(names)) {
+			return nil, ErrUnknownPermission
+		}
+	}
+
+	if err := s.db.Model(&user).Association("Roles").Replace(roles); err != nil {
+		return nil, fmt.Errorf("failed to sync roles: %w", err)
+	}
+	return s.GetUserRoles(userID)
+}
+
+// restrictPermissions 只保留用户角色中属于 scopes 的权限
+func restrictPermissions(user *models.User, scopes []string) {
+	allowed := uniqueNames(scopes)
+	for i := range user.Roles {
+		kept := user.Roles[i].Permissions[:0]
+		for _, perm := range user.Roles[i].Permissions {
+			if _, ok := allowed[perm.Name]; ok {
+				kept = append(kept, perm)
+			}
+		}
+		user.Roles[i].Permissions = kept
+	}
+}
+
+// uniqueNames 去重后的名称
+func uniqueNames(names []string) map[string]struct{} {
+	unique := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		unique[name] = struct{}{}
+	}
+	return unique
+}
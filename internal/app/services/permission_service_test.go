@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/clarkzhu2020/aidecms/database/migrations"
+	"github.com/clarkzhu2020/aidecms/database/seeders"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+func newTestPermissionService(t *testing.T) (*PermissionService, *gorm.DB) {
+	t.Helper()
+
+	d := database.NewDatabase(&database.Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "rbac.db")})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+
+	if _, err := migrations.NewMigrator(d.DB).Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := seeders.Run(d.DB, "RoleSeeder"); err != nil {
+		t.Fatalf("seed roles: %v", err)
+	}
+	return &PermissionService{db: d.DB}, d.DB
+}
+
+func createUserWithRole(t *testing.T, s *PermissionService, username, role string) *models.User {
+	t.Helper()
+
+	user := &models.User{Username: username, Email: username + "@example.com", Password: "x"}
+	if err := s.db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if _, err := s.SyncUserRolesByName(user.ID, []string{role}); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+	return user
+}
+
+func TestPermissionService_AuthorizeOwnership(t *testing.T) {
+	s, db := newTestPermissionService(t)
+
+	author := createUserWithRole(t, s, "author", "author")
+	other := createUserWithRole(t, s, "other", "author")
+	editor := createUserWithRole(t, s, "editor", "editor")
+	reader := createUserWithRole(t, s, "reader", "user")
+
+	category := &models.Category{Name: "News", Slug: "news"}
+	db.Create(category)
+	post := &models.Post{Title: "Hello", Slug: "hello", Content: "content", AuthorID: author.ID, CategoryID: category.ID}
+	db.Create(post)
+	suggestion := &models.PostSuggestion{PostID: post.ID, Action: "excerpt", Payload: "{}"}
+	db.Create(suggestion)
+
+	cases := []struct {
+		name     string
+		user     *models.User
+		resource string
+		action   string
+		policy   string
+		id       uint
+		want     bool
+	}{
+		{"author updates own post", author, "post", "update", "post", post.ID, true},
+		{"author deletes own post", author, "post", "delete", "post", post.ID, true},
+		{"author cannot publish", author, "post", "publish", "post", post.ID, false},
+		{"author cannot update others post", other, "post", "update", "post", post.ID, false},
+		{"author needs an id for own checks", author, "post", "update", "post", 0, false},
+		{"editor updates any post", editor, "post", "update", "post", post.ID, true},
+		{"editor cannot delete categories", editor, "category", "delete", "category", 0, false},
+		{"user cannot create posts", reader, "post", "create", "post", 0, false},
+		{"author updates suggestion on own post", author, "post", "update", "post_suggestion", suggestion.ID, true},
+		{"other cannot update suggestion", other, "post", "update", "post_suggestion", suggestion.ID, false},
+		{"missing post", author, "post", "update", "post", 9999, false},
+	}
+	for _, tc := range cases {
+		got, err := s.Authorize(tc.user.ID, tc.resource, tc.action, tc.policy, tc.id)
+		if err != nil {
+			t.Fatalf("%s: Authorize error: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: Authorize = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// 自定义策略
+	RegisterPolicy("always", PolicyFunc(func(*gorm.DB, *models.User, string, string, uint) (bool, error) {
+		return true, nil
+	}))
+	t.Cleanup(func() {
+		policiesMu.Lock()
+		delete(policies, "always")
+		policiesMu.Unlock()
+	})
+	if ok, _ := s.Authorize(reader.ID, "tag", "delete", "always", 1); !ok {
+		t.Error("custom policy should allow")
+	}
+}
+
+func TestPermissionService_ManageRoles(t *testing.T) {
+	s, _ := newTestPermissionService(t)
+
+	role := &models.Role{Name: "reviewer"}
+	if err := s.CreateRole(role); err != nil {
+		t.Fatalf("CreateRole error: %v", err)
+	}
+	updated, err := s.SyncRolePermissionsByName(role.ID, []string{"post.read", "comment.moderate"})
+	if err != nil || len(updated.Permissions) != 2 {
+		t.Fatalf("SyncRolePermissionsByName = %+v, %v", updated, err)
+	}
+	if _, err := s.SyncRolePermissionsByName(role.ID, []string{"post.read", "missing"}); !errors.Is(err, ErrUnknownPermission) {
+		t.Errorf("expected unknown permission, got %v", err)
+	}
+
+	user := createUserWithRole(t, s, "reviewer", "reviewer")
+	if ok, _ := s.Authorize(user.ID, "comment", "moderate", "comment", 0); !ok {
+		t.Error("reviewer should moderate comments")
+	}
+
+	if err := s.DeleteRole(role.ID); err != nil {
+		t.Fatalf("DeleteRole error: %v", err)
+	}
+	if roles, _ := s.GetUserRoles(user.ID); len(roles) != 0 {
+		t.Errorf("deleted role should be detached, got %+v", roles)
+	}
+	if err := s.CreateRole(&models.Role{Name: "reviewer"}); err != nil {
+		t.Errorf("role name should be reusable after delete: %v", err)
+	}
+
+	system, _ := s.GetRoleByName("editor")
+	if err := s.DeleteRole(system.ID); !errors.Is(err, ErrSystemRole) {
+		t.Errorf("expected system role error, got %v", err)
+	}
+}
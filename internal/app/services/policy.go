@@ -0,0 +1,86 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"gorm.io/gorm"
+)
+
+// Policy 资源策略，用户没有 resource.action 权限时按具体资源判断是否允许
+type Policy interface {
+	Allow(db *gorm.DB, user *models.User, resource, action string, id uint) (bool, error)
+}
+
+// PolicyFunc 函数形式的资源策略
+type PolicyFunc func(db *gorm.DB, user *models.User, resource, action string, id uint) (bool, error)
+
+// Allow 调用策略函数
+func (f PolicyFunc) Allow(db *gorm.DB, user *models.User, resource, action string, id uint) (bool, error) {
+	return f(db, user, resource, action, id)
+}
+
+// OwnerPolicy 资源所有者拥有 <resource>.<action>_own 权限时允许操作自己的资源
+// 例如作者拥有 post.update_own 时只能修改自己的文章，编辑拥有 post.update 时可以修改所有文章
+type OwnerPolicy struct {
+	// Owner 返回资源所有者ID
+	Owner func(db *gorm.DB, id uint) (uint, error)
+}
+
+// Allow 判断用户是否为资源所有者且拥有 _own 权限
+func (p OwnerPolicy) Allow(db *gorm.DB, user *models.User, resource, action string, id uint) (bool, error) {
+	if id == 0 || !user.HasResourcePermission(resource, action+"_own") {
+		return false, nil
+	}
+	ownerID, err := p.Owner(db, id)
+	if err != nil {
+		return false, err
+	}
+	return ownerID != 0 && ownerID == user.ID, nil
+}
+
+// ownerColumn 按模型的所有者字段查询资源所有者
+func ownerColumn(model interface{}, column string) func(db *gorm.DB, id uint) (uint, error) {
+	return func(db *gorm.DB, id uint) (uint, error) {
+		var ownerIDs []uint
+		err := db.Model(model).Where("id = ?", id).Limit(1).Pluck(column, &ownerIDs).Error
+		if err != nil || len(ownerIDs) == 0 {
+			return 0, err
+		}
+		return ownerIDs[0], nil
+	}
+}
+
+var (
+	policiesMu sync.RWMutex
+	policies   = map[string]Policy{
+		"post":            OwnerPolicy{Owner: ownerColumn(&models.Post{}, "author_id")},
+		"media":           OwnerPolicy{Owner: ownerColumn(&models.Media{}, "user_id")},
+		"comment":         OwnerPolicy{Owner: ownerColumn(&models.Comment{}, "user_id")},
+		"post_suggestion": OwnerPolicy{Owner: suggestionOwner},
+	}
+)
+
+// suggestionOwner AI编辑建议的所有者为文章作者
+func suggestionOwner(db *gorm.DB, id uint) (uint, error) {
+	postID, err := ownerColumn(&models.PostSuggestion{}, "post_id")(db, id)
+	if err != nil || postID == 0 {
+		return 0, err
+	}
+	return ownerColumn(&models.Post{}, "author_id")(db, postID)
+}
+
+// RegisterPolicy 注册资源策略，同名策略会被替换
+func RegisterPolicy(name string, policy Policy) {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+	policies[name] = policy
+}
+
+// policyFor 返回资源策略
+func policyFor(name string) (Policy, bool) {
+	policiesMu.RLock()
+	defer policiesMu.RUnlock()
+	policy, ok := policies[name]
+	return policy, ok
+}
@@ -0,0 +1,503 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/ai"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/clarkzhu2020/aidecms/pkg/queue"
+	"gorm.io/gorm"
+)
+
+// 编辑建议类型
+const (
+	EditorialExcerpt     = "excerpt"
+	EditorialSEO         = "seo"
+	EditorialTags        = "tags"
+	EditorialTranslation = "translation"
+)
+
+// EditorialActions 支持的编辑建议类型
+var EditorialActions = []string{EditorialExcerpt, EditorialSEO, EditorialTags, EditorialTranslation}
+
+// editorialContentTokens 发送给模型的正文最大令牌数
+const editorialContentTokens = 3000
+
+// editorialMaxTags 参与匹配的已有标签数量上限
+const editorialMaxTags = 300
+
+// ErrUnsupportedEditorialAction 不支持的编辑建议类型
+var ErrUnsupportedEditorialAction = errors.New("unsupported editorial action")
+
+// SEOSuggestion SEO字段建议
+type SEOSuggestion struct {
+	MetaTitle       string `json:"meta_title"`
+	MetaDescription string `json:"meta_description"`
+	MetaKeywords    string `json:"meta_keywords"`
+}
+
+// TagSuggestion 标签建议，只包含已存在的标签
+type TagSuggestion struct {
+	TagID uint   `json:"tag_id"`
+	Name  string `json:"name"`
+	Slug  string `json:"slug"`
+}
+
+// TranslationSuggestion 翻译建议
+type TranslationSuggestion struct {
+	Language string `json:"language"`
+	Title    string `json:"title"`
+	Excerpt  string `json:"excerpt"`
+	Content  string `json:"content"`
+}
+
+// EditorialSuggestion 一次请求生成的编辑建议
+type EditorialSuggestion struct {
+	PostID      uint                   `json:"post_id"`
+	Excerpt     string                 `json:"excerpt,omitempty"`
+	SEO         *SEOSuggestion         `json:"seo,omitempty"`
+	Tags        []TagSuggestion        `json:"tags,omitempty"`
+	Translation *TranslationSuggestion `json:"translation,omitempty"`
+	Client      string                 `json:"client"`
+}
+
+// PostEditorialService 文章编辑辅助服务：生成摘要、SEO字段、标签和翻译建议，不修改文章本身
+type PostEditorialService struct {
+	db      *gorm.DB
+	manager *ai.Manager
+	client  string
+	usage   *ai.UsageLedger
+}
+
+// NewPostEditorialService 创建文章编辑辅助服务，client 为空时使用默认客户端
+func NewPostEditorialService(manager *ai.Manager, client string) *PostEditorialService {
+	db := database.GetDB()
+	if db == nil {
+		db = config.DB
+	}
+	return &PostEditorialService{
+		db:      db,
+		manager: manager,
+		client:  client,
+	}
+}
+
+// SetDB 设置数据库连接
+func (s *PostEditorialService) SetDB(db *gorm.DB) {
+	s.db = db
+}
+
+// SetUsageLedger 设置用量账本，生成建议消耗的令牌按 editorial 端点记录
+func (s *PostEditorialService) SetUsageLedger(ledger *ai.UsageLedger) {
+	s.usage = ledger
+}
+
+// ParseEditorialActions 解析逗号分隔的建议类型，为空时返回除翻译外的全部类型
+func ParseEditorialActions(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return []string{EditorialExcerpt, EditorialSEO, EditorialTags}, nil
+	}
+
+	var actions []string
+	for _, action := range strings.Split(value, ",") {
+		action = strings.TrimSpace(action)
+		if !isEditorialAction(action) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedEditorialAction, action)
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// isEditorialAction 检查建议类型是否受支持
+func isEditorialAction(action string) bool {
+	for _, supported := range EditorialActions {
+		if action == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggest 为文章生成指定类型的建议，language 为翻译目标语言
+func (s *PostEditorialService) Suggest(ctx context.Context, post *models.Post, actions []string, language string, userID uint) (*EditorialSuggestion, error) {
+	suggestion := &EditorialSuggestion{PostID: post.ID, Client: s.clientName()}
+
+	for _, action := range actions {
+		var err error
+		switch action {
+		case EditorialExcerpt:
+			suggestion.Excerpt, err = s.SuggestExcerpt(ctx, post, userID)
+		case EditorialSEO:
+			suggestion.SEO, err = s.SuggestSEO(ctx, post, userID)
+		case EditorialTags:
+			suggestion.Tags, err = s.SuggestTags(ctx, post, userID)
+		case EditorialTranslation:
+			suggestion.Translation, err = s.Translate(ctx, post, language, userID)
+		default:
+			err = fmt.Errorf("%w: %s", ErrUnsupportedEditorialAction, action)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", action, err)
+		}
+	}
+
+	return suggestion, nil
+}
+
+// SuggestExcerpt 生成文章摘要
+func (s *PostEditorialService) SuggestExcerpt(ctx context.Context, post *models.Post, userID uint) (string, error) {
+	answer, err := s.complete(ctx, userID,
+		"You are an editor for a content site. Write a compelling excerpt of at most 2 sentences "+
+			"(under 300 characters) for the article below, in the article's language. Reply with the excerpt only.",
+		articleText(post))
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(strings.TrimSpace(answer), `"`), nil
+}
+
+// SuggestSEO 生成SEO标题、描述和关键词
+func (s *PostEditorialService) SuggestSEO(ctx context.Context, post *models.Post, userID uint) (*SEOSuggestion, error) {
+	answer, err := s.complete(ctx, userID,
+		"You are an SEO editor. For the article below, reply with a JSON object with the keys "+
+			`"meta_title" (under 60 characters), "meta_description" (under 160 characters) and `+
+			`"meta_keywords" (5-8 comma separated keywords), written in the article's language. Reply with JSON only.`,
+		articleText(post))
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestion SEOSuggestion
+	if err := decodeJSONAnswer(answer, &suggestion); err != nil {
+		return nil, err
+	}
+	suggestion.MetaTitle = truncateRunes(strings.TrimSpace(suggestion.MetaTitle), 200)
+	suggestion.MetaDescription = strings.TrimSpace(suggestion.MetaDescription)
+	suggestion.MetaKeywords = truncateRunes(strings.TrimSpace(suggestion.MetaKeywords), 500)
+	return &suggestion, nil
+}
+
+// SuggestTags 从已有标签中挑选适合文章的标签
+func (s *PostEditorialService) SuggestTags(ctx context.Context, post *models.Post, userID uint) ([]TagSuggestion, error) {
+	var tags []models.Tag
+	if err := s.db.WithContext(ctx).Order("count DESC").Limit(editorialMaxTags).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return []TagSuggestion{}, nil
+	}
+
+	names := make([]string, len(tags))
+	byName := make(map[string]models.Tag, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+		byName[strings.ToLower(tag.Name)] = tag
+		byName[strings.ToLower(tag.Slug)] = tag
+	}
+
+	answer, err := s.complete(ctx, userID,
+		"You classify articles. Choose up to 5 tags for the article below, only from this list: "+
+			strings.Join(names, ", ")+". Reply with a JSON array of tag names only, most relevant first.",
+		articleText(post))
+	if err != nil {
+		return nil, err
+	}
+
+	var chosen []string
+	if err := decodeJSONAnswer(answer, &chosen); err != nil {
+		return nil, err
+	}
+
+	// 模型可能返回列表之外的名称，只保留能对应到已有标签的结果
+	seen := make(map[uint]bool)
+	suggestions := make([]TagSuggestion, 0, len(chosen))
+	for _, name := range chosen {
+		tag, exists := byName[strings.ToLower(strings.TrimSpace(name))]
+		if !exists || seen[tag.ID] {
+			continue
+		}
+		seen[tag.ID] = true
+		suggestions = append(suggestions, TagSuggestion{TagID: tag.ID, Name: tag.Name, Slug: tag.Slug})
+	}
+	return suggestions, nil
+}
+
+// Translate 将文章标题、摘要和正文翻译为目标语言，正文较长时分块翻译
+func (s *PostEditorialService) Translate(ctx context.Context, post *models.Post, language string, userID uint) (*TranslationSuggestion, error) {
+	language = strings.TrimSpace(language)
+	if language == "" {
+		return nil, errors.New("target language is required")
+	}
+
+	answer, err := s.complete(ctx, userID,
+		fmt.Sprintf("Translate the title and excerpt below into %s. Reply with a JSON object with the keys "+
+			`"title" and "excerpt". Reply with JSON only.`, language),
+		fmt.Sprintf("Title: %s\nExcerpt: %s", post.Title, post.Excerpt))
+	if err != nil {
+		return nil, err
+	}
+
+	translation := &TranslationSuggestion{Language: language}
+	if err := decodeJSONAnswer(answer, translation); err != nil {
+		return nil, err
+	}
+	translation.Language = language
+
+	prompt := fmt.Sprintf("Translate the following part of an article into %s. Preserve HTML and Markdown "+
+		"formatting, links and code exactly. Reply with the translation only.", language)
+	var parts []string
+	for _, chunk := range ai.ChunkText(post.Content, editorialContentTokens) {
+		part, err := s.complete(ctx, userID, prompt, chunk)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, strings.TrimSpace(part))
+	}
+	translation.Content = strings.Join(parts, "\n\n")
+
+	return translation, nil
+}
+
+// SaveSuggestion 将建议保存为待审核记录，每种类型一条
+func (s *PostEditorialService) SaveSuggestion(ctx context.Context, suggestion *EditorialSuggestion, userID uint) ([]models.PostSuggestion, error) {
+	var records []models.PostSuggestion
+	add := func(action, language string, payload interface{}) error {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		records = append(records, models.PostSuggestion{
+			PostID:      suggestion.PostID,
+			Action:      action,
+			Language:    language,
+			Payload:     string(data),
+			Status:      "pending",
+			Client:      suggestion.Client,
+			RequestedBy: userID,
+		})
+		return nil
+	}
+
+	if suggestion.Excerpt != "" {
+		if err := add(EditorialExcerpt, "", map[string]string{"excerpt": suggestion.Excerpt}); err != nil {
+			return nil, err
+		}
+	}
+	if suggestion.SEO != nil {
+		if err := add(EditorialSEO, "", suggestion.SEO); err != nil {
+			return nil, err
+		}
+	}
+	if suggestion.Tags != nil {
+		if err := add(EditorialTags, "", suggestion.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if suggestion.Translation != nil {
+		if err := add(EditorialTranslation, suggestion.Translation.Language, suggestion.Translation); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(records) == 0 {
+		return records, nil
+	}
+	if err := s.db.WithContext(ctx).Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// complete 调用模型生成文本并记录用量
+func (s *PostEditorialService) complete(ctx context.Context, userID uint, instruction, input string) (string, error) {
+	temperature := 0.3
+	response, err := s.manager.Chat(ctx, s.client, &ai.ChatRequest{
+		Messages: []*ai.Message{
+			{Role: "system", Content: instruction},
+			{Role: "user", Content: input},
+		},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if s.usage != nil {
+		clientName := s.clientName()
+		if response.Metadata != nil && response.Metadata.Client != "" {
+			clientName = response.Metadata.Client
+		}
+		clientConfig, _ := s.manager.GetConfig(clientName)
+		record := ai.NewUsageRecord(userID, "editorial", clientName, clientConfig, response.Usage)
+		if err := s.usage.Record(ctx, record); err != nil {
+			// 用量记录失败不影响建议生成
+			fmt.Printf("Warning: failed to record editorial usage: %v\n", err)
+		}
+	}
+
+	return response.Message.Content, nil
+}
+
+// clientName 获取实际使用的客户端名称
+func (s *PostEditorialService) clientName() string {
+	if s.client != "" {
+		return s.client
+	}
+	return s.manager.GetDefault()
+}
+
+// articleText 组装发送给模型的文章内容，正文超长时截断
+func articleText(post *models.Post) string {
+	content := stripHTML(post.Content)
+	if chunks := ai.ChunkText(content, editorialContentTokens); len(chunks) > 0 {
+		content = chunks[0]
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Title: %s\n", post.Title)
+	if post.Excerpt != "" {
+		fmt.Fprintf(&text, "Excerpt: %s\n", post.Excerpt)
+	}
+	fmt.Fprintf(&text, "\n%s", content)
+	return text.String()
+}
+
+// decodeJSONAnswer 解析模型回答中的JSON，兼容代码块和前后说明文字
+func decodeJSONAnswer(answer string, target interface{}) error {
+	answer = strings.TrimSpace(answer)
+	start := strings.IndexAny(answer, "[{")
+	if start < 0 {
+		return fmt.Errorf("model did not return JSON: %q", truncateRunes(answer, 200))
+	}
+	closing := "}"
+	if answer[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(answer, closing)
+	if end < start {
+		return fmt.Errorf("model did not return JSON: %q", truncateRunes(answer, 200))
+	}
+
+	if err := json.Unmarshal([]byte(answer[start:end+1]), target); err != nil {
+		return fmt.Errorf("failed to parse model JSON: %w", err)
+	}
+	return nil
+}
+
+// truncateRunes 按字符数截断
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit])
+}
+
+// PostEditorialJob 后台生成文章编辑建议的队列任务，结果保存为待审核建议
+type PostEditorialJob struct {
+	queue.BaseJob
+	PostID      uint     `json:"post_id"`
+	Actions     []string `json:"actions"`
+	Language    string   `json:"language,omitempty"`
+	RequestedBy uint     `json:"requested_by"`
+}
+
+// Handle 实现 queue.Job 接口，实际处理由 RegisterJobs 注册的处理器完成
+func (j *PostEditorialJob) Handle() error {
+	return errors.New("PostEditorialJob must be processed by a queue worker with PostEditorialService.RegisterJobs")
+}
+
+// PostEditorialJobType 队列中的任务类型名称
+var PostEditorialJobType = fmt.Sprintf("%T", &PostEditorialJob{})
+
+// NewPostEditorialJob 创建编辑建议任务
+func NewPostEditorialJob(postID uint, actions []string, language string, userID uint) *PostEditorialJob {
+	return &PostEditorialJob{
+		BaseJob: queue.BaseJob{
+			ID:        fmt.Sprintf("post_editorial_%d_%d", postID, time.Now().UnixNano()),
+			Queue:     "default",
+			Timeout:   10 * time.Minute,
+			CreatedAt: time.Now(),
+		},
+		PostID:      postID,
+		Actions:     actions,
+		Language:    language,
+		RequestedBy: userID,
+	}
+}
+
+// RegisterJobs 注册编辑建议任务处理器
+func (s *PostEditorialService) RegisterJobs(q *queue.Queue) {
+	q.Register(PostEditorialJobType, func(payload []byte) error {
+		var job PostEditorialJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return err
+		}
+		return s.RunJob(context.Background(), &job)
+	})
+}
+
+// RunJob 执行编辑建议任务
+func (s *PostEditorialService) RunJob(ctx context.Context, job *PostEditorialJob) error {
+	var post models.Post
+	if err := s.db.WithContext(ctx).First(&post, job.PostID).Error; err != nil {
+		return fmt.Errorf("post %d not found: %w", job.PostID, err)
+	}
+
+	suggestion, err := s.Suggest(ctx, &post, job.Actions, job.Language, job.RequestedBy)
+	if err != nil {
+		return err
+	}
+	_, err = s.SaveSuggestion(ctx, suggestion, job.RequestedBy)
+	return err
+}
+
+// BackfillFilter 批量生成建议的筛选条件
+type BackfillFilter struct {
+	PostIDs     []uint
+	Status      string // 为空时不限制
+	OnlyMissing bool   // 只处理相应字段为空的文章（对摘要和SEO有效）
+	Limit       int
+}
+
+// BackfillPosts 查询需要批量生成建议的文章ID
+func (s *PostEditorialService) BackfillPosts(ctx context.Context, actions []string, filter BackfillFilter) ([]uint, error) {
+	query := s.db.WithContext(ctx).Model(&models.Post{})
+	if len(filter.PostIDs) > 0 {
+		query = query.Where("id IN ?", filter.PostIDs)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.OnlyMissing {
+		var conditions []string
+		for _, action := range actions {
+			switch action {
+			case EditorialExcerpt:
+				conditions = append(conditions, "excerpt IS NULL OR excerpt = ''")
+			case EditorialSEO:
+				conditions = append(conditions, "meta_title IS NULL OR meta_title = '' OR meta_description IS NULL OR meta_description = ''")
+			}
+		}
+		if len(conditions) > 0 {
+			query = query.Where("(" + strings.Join(conditions, " OR ") + ")")
+		}
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var ids []uint
+	if err := query.Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/ai"
+	"github.com/clarkzhu2020/aidecms/pkg/queue"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newEditorialChatServer 根据系统提示返回对应格式的回答
+func newEditorialChatServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		instruction := body.Messages[0].Content
+
+		var answer string
+		switch {
+		case strings.Contains(instruction, "excerpt of at most"):
+			answer = `"A short guide to raising a puppy."`
+		case strings.Contains(instruction, "SEO editor"):
+			answer = "```json\n{\"meta_title\":\"Puppy care\",\"meta_description\":\"How to raise a puppy.\",\"meta_keywords\":\"puppy,dog\"}\n```"
+		case strings.Contains(instruction, "classify articles"):
+			answer = `Here you go: ["Dogs", "Unknown", "pets", "dogs"]`
+		case strings.Contains(instruction, "title and excerpt"):
+			answer = `{"title":"Chiot","excerpt":"Guide"}`
+		default:
+			answer = "Traduit: " + body.Messages[1].Content
+		}
+
+		data, _ := json.Marshal(answer)
+		fmt.Fprintf(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":%s},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`, data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestEditorialService(t *testing.T) (*PostEditorialService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Category{}, &models.Tag{}, &models.Post{}, &models.PostSuggestion{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	manager := ai.NewManager()
+	if err := manager.AddClient("openai", &ai.Config{
+		Provider: "openai",
+		APIKey:   "key",
+		BaseURL:  newEditorialChatServer(t).URL,
+		Model:    "gpt-4o-mini",
+	}); err != nil {
+		t.Fatalf("AddClient error: %v", err)
+	}
+
+	service := NewPostEditorialService(manager, "openai")
+	service.SetDB(db)
+	return service, db
+}
+
+func TestPostEditorialService_Suggest(t *testing.T) {
+	service, db := newTestEditorialService(t)
+	ctx := context.Background()
+
+	db.Create(&models.Tag{Name: "Dogs", Slug: "dogs", Count: 3})
+	db.Create(&models.Tag{Name: "Pets", Slug: "pets", Count: 1})
+	post := &models.Post{Title: "Raising a puppy", Slug: "puppy", Content: "<p>Puppies need sleep.</p>"}
+	db.Create(post)
+
+	suggestion, err := service.Suggest(ctx, post, EditorialActions, "French", 7)
+	if err != nil {
+		t.Fatalf("Suggest error: %v", err)
+	}
+	if suggestion.Excerpt != "A short guide to raising a puppy." {
+		t.Errorf("unexpected excerpt: %q", suggestion.Excerpt)
+	}
+	if suggestion.SEO == nil || suggestion.SEO.MetaTitle != "Puppy care" || suggestion.SEO.MetaKeywords != "puppy,dog" {
+		t.Errorf("unexpected SEO suggestion: %+v", suggestion.SEO)
+	}
+	// 只保留已有标签，忽略未知和重复的名称
+	if len(suggestion.Tags) != 2 || suggestion.Tags[0].Slug != "dogs" || suggestion.Tags[1].Slug != "pets" {
+		t.Errorf("unexpected tags: %+v", suggestion.Tags)
+	}
+	if suggestion.Translation == nil || suggestion.Translation.Title != "Chiot" ||
+		!strings.Contains(suggestion.Translation.Content, "Puppies need sleep.") {
+		t.Errorf("unexpected translation: %+v", suggestion.Translation)
+	}
+
+	records, err := service.SaveSuggestion(ctx, suggestion, 7)
+	if err != nil || len(records) != 4 {
+		t.Fatalf("expected 4 saved suggestions, got %d (%v)", len(records), err)
+	}
+
+	// 文章本身不被修改
+	var stored models.Post
+	db.First(&stored, post.ID)
+	if stored.Excerpt != "" || stored.MetaTitle != "" {
+		t.Errorf("post should not be modified: %+v", stored)
+	}
+}
+
+func TestPostEditorialService_Backfill(t *testing.T) {
+	service, db := newTestEditorialService(t)
+	ctx := context.Background()
+
+	posts := []*models.Post{
+		{Title: "Missing excerpt", Slug: "a", Content: "Text", Status: "published"},
+		{Title: "Has excerpt", Slug: "b", Content: "Text", Excerpt: "Done", Status: "published"},
+		{Title: "Draft", Slug: "c", Content: "Text", Status: "draft"},
+	}
+	for _, post := range posts {
+		db.Create(post)
+	}
+
+	ids, err := service.BackfillPosts(ctx, []string{EditorialExcerpt}, BackfillFilter{Status: "published", OnlyMissing: true})
+	if err != nil || len(ids) != 1 || ids[0] != posts[0].ID {
+		t.Fatalf("unexpected backfill posts: %v (%v)", ids, err)
+	}
+
+	// 通过队列执行任务，结果保存为待审核建议
+	q := queue.NewQueue(queue.NewMemoryDriver())
+	service.RegisterJobs(q)
+	job := NewPostEditorialJob(ids[0], []string{EditorialExcerpt}, "", 1)
+	payload, _ := json.Marshal(job)
+
+	var parsed PostEditorialJob
+	if err := json.Unmarshal(payload, &parsed); err != nil {
+		t.Fatalf("unmarshal job: %v", err)
+	}
+	if err := service.RunJob(ctx, &parsed); err != nil {
+		t.Fatalf("RunJob error: %v", err)
+	}
+
+	var saved []models.PostSuggestion
+	db.Where("post_id = ?", posts[0].ID).Find(&saved)
+	if len(saved) != 1 || saved[0].Action != EditorialExcerpt || saved[0].Status != "pending" {
+		t.Errorf("unexpected saved suggestions: %+v", saved)
+	}
+}
+
+func TestParseEditorialActions(t *testing.T) {
+	actions, err := ParseEditorialActions("")
+	if err != nil || len(actions) != 3 {
+		t.Errorf("expected default actions, got %v (%v)", actions, err)
+	}
+	if _, err := ParseEditorialActions("excerpt,summary"); err == nil {
+		t.Error("expected error for unsupported action")
+	}
+}
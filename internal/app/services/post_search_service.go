@@ -0,0 +1,575 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/ai"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// PostSearchNamespace 文章向量的命名空间
+const PostSearchNamespace = "posts"
+
+// 检索模式
+const (
+	SearchModeHybrid   = "hybrid"
+	SearchModeKeyword  = "keyword"
+	SearchModeSemantic = "semantic"
+)
+
+// rrfK 倒数排名融合（RRF）的平滑常数
+const rrfK = 60
+
+// ErrSemanticSearchUnavailable 未配置AI或向量存储时无法进行语义检索
+var ErrSemanticSearchUnavailable = errors.New("semantic search is not available")
+
+// PostSearchResult 文章检索结果
+type PostSearchResult struct {
+	Post         models.Post `json:"post"`
+	Score        float64     `json:"score"`
+	KeywordScore float64     `json:"keyword_score"`
+	VectorScore  float64     `json:"vector_score"`
+	Snippet      string      `json:"snippet,omitempty"`
+}
+
+// PostSearchService 文章检索服务：维护文章向量索引并提供关键词、语义和混合检索
+type PostSearchService struct {
+	db          *gorm.DB
+	manager     *ai.Manager
+	store       ai.VectorStore
+	client      string
+	chunkTokens int
+	batchSize   int
+}
+
+// NewPostSearchService 创建文章检索服务，manager 或 store 为nil时只支持关键词检索
+func NewPostSearchService(manager *ai.Manager, store ai.VectorStore, searchConfig *config.SemanticSearchConfig) *PostSearchService {
+	db := database.GetDB()
+	if db == nil {
+		db = config.DB
+	}
+
+	service := &PostSearchService{
+		db:          db,
+		manager:     manager,
+		store:       store,
+		chunkTokens: 400,
+		batchSize:   100,
+	}
+	if searchConfig != nil {
+		service.client = searchConfig.Client
+		if searchConfig.ChunkTokens > 0 {
+			service.chunkTokens = searchConfig.ChunkTokens
+		}
+		if searchConfig.BatchSize > 0 {
+			service.batchSize = searchConfig.BatchSize
+		}
+	}
+	return service
+}
+
+// SetDB 设置数据库连接
+func (s *PostSearchService) SetDB(db *gorm.DB) {
+	s.db = db
+}
+
+// SemanticEnabled 是否支持语义检索
+func (s *PostSearchService) SemanticEnabled() bool {
+	return s.manager != nil && s.store != nil
+}
+
+// IndexPost 为已发布的文章生成向量，未发布或已删除的文章从索引中移除
+func (s *PostSearchService) IndexPost(ctx context.Context, post *models.Post) error {
+	if !s.SemanticEnabled() {
+		return nil
+	}
+	if post.Status != "published" || post.DeletedAt.Valid {
+		return s.RemovePost(ctx, post.ID)
+	}
+
+	texts := PostChunks(post, s.chunkTokens)
+	if len(texts) == 0 {
+		return s.RemovePost(ctx, post.ID)
+	}
+
+	vectors, err := s.embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed post %d: %w", post.ID, err)
+	}
+
+	chunks := make([]ai.VectorChunk, len(texts))
+	for i, text := range texts {
+		chunks[i] = ai.VectorChunk{Content: text, Vector: vectors[i]}
+	}
+	return s.store.Upsert(ctx, PostSearchNamespace, documentID(post.ID), s.embeddingModel(), chunks)
+}
+
+// RemovePost 从索引中移除文章
+func (s *PostSearchService) RemovePost(ctx context.Context, postID uint) error {
+	if s.store == nil {
+		return nil
+	}
+	return s.store.Delete(ctx, PostSearchNamespace, documentID(postID))
+}
+
+// Reindex 清空索引并重新索引所有已发布文章，progress 在每篇文章处理后调用
+func (s *PostSearchService) Reindex(ctx context.Context, progress func(post *models.Post, err error)) (int, error) {
+	if !s.SemanticEnabled() {
+		return 0, ErrSemanticSearchUnavailable
+	}
+	if err := s.store.Clear(ctx, PostSearchNamespace); err != nil {
+		return 0, fmt.Errorf("failed to clear index: %w", err)
+	}
+
+	indexed := 0
+	var posts []models.Post
+	err := s.db.WithContext(ctx).
+		Where("status = ?", "published").
+		Order("id").
+		FindInBatches(&posts, 50, func(tx *gorm.DB, batch int) error {
+			for i := range posts {
+				err := s.IndexPost(ctx, &posts[i])
+				if err == nil {
+					indexed++
+				}
+				if progress != nil {
+					progress(&posts[i], err)
+				}
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+			}
+			return nil
+		}).Error
+
+	return indexed, err
+}
+
+// Search 检索已发布文章，mode 为 hybrid、keyword、semantic
+// 混合模式下语义检索失败时退化为关键词检索
+func (s *PostSearchService) Search(ctx context.Context, query, mode string, limit int) ([]PostSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []PostSearchResult{}, nil
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	switch mode {
+	case "":
+		mode = SearchModeHybrid
+	case SearchModeHybrid, SearchModeKeyword, SearchModeSemantic:
+	default:
+		return nil, fmt.Errorf("unsupported search mode: %s", mode)
+	}
+
+	candidates := limit * 5
+	results := make(map[uint]*PostSearchResult)
+	var keywordRanking, vectorRanking []uint
+
+	if mode == SearchModeHybrid || mode == SearchModeKeyword {
+		ranking, scores, err := s.keywordSearch(ctx, query, candidates)
+		if err != nil {
+			return nil, err
+		}
+		keywordRanking = ranking
+		for _, id := range ranking {
+			results[id] = &PostSearchResult{KeywordScore: scores[id]}
+		}
+	}
+
+	if mode == SearchModeHybrid || mode == SearchModeSemantic {
+		ranking, matches, err := s.vectorSearch(ctx, query, candidates)
+		if err != nil && mode == SearchModeSemantic {
+			return nil, err
+		}
+		vectorRanking = ranking
+		for _, id := range ranking {
+			result, exists := results[id]
+			if !exists {
+				result = &PostSearchResult{}
+				results[id] = result
+			}
+			result.VectorScore = matches[id].Score
+			result.Snippet = matches[id].Content
+		}
+	}
+
+	// 倒数排名融合：两路排名互补，不需要对分值做归一化
+	for rank, id := range keywordRanking {
+		results[id].Score += 1.0 / float64(rrfK+rank+1)
+	}
+	for rank, id := range vectorRanking {
+		results[id].Score += 1.0 / float64(rrfK+rank+1)
+	}
+
+	ids := make([]uint, 0, len(results))
+	for id := range results {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if results[ids[i]].Score != results[ids[j]].Score {
+			return results[ids[i]].Score > results[ids[j]].Score
+		}
+		return ids[i] > ids[j]
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	return s.loadResults(ctx, ids, results)
+}
+
+// PostPassage 检索得到的文章片段
+type PostPassage struct {
+	PostID  uint    `json:"post_id"`
+	Slug    string  `json:"slug"`
+	Title   string  `json:"title"`
+	Content string  `json:"content"`
+	Score   float64 `json:"score"`
+}
+
+// Retrieve 检索与问题最相关的已发布文章片段，用于检索增强生成
+// 优先使用语义检索，不可用或失败时按关键词检索并选取命中最多的分块；设置了访问规则的文章不参与
+func (s *PostSearchService) Retrieve(ctx context.Context, query string, limit int) ([]PostPassage, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []PostPassage{}, nil
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+
+	if s.SemanticEnabled() {
+		passages, err := s.retrieveSemantic(ctx, query, limit)
+		if err == nil && len(passages) > 0 {
+			return passages, nil
+		}
+	}
+	return s.retrieveKeyword(ctx, query, limit)
+}
+
+// retrieveSemantic 按向量相似度检索分块
+func (s *PostSearchService) retrieveSemantic(ctx context.Context, query string, limit int) ([]PostPassage, error) {
+	vectors, err := s.embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	// 多取一些，过滤已下线的文章后仍有足够结果
+	matches, err := s.store.Search(ctx, PostSearchNamespace, vectors[0], limit*2)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(matches))
+	for _, match := range matches {
+		if id, err := strconv.ParseUint(match.DocumentID, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	posts, err := s.publishedPosts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	passages := make([]PostPassage, 0, limit)
+	for _, match := range matches {
+		id, _ := strconv.ParseUint(match.DocumentID, 10, 64)
+		post, exists := posts[uint(id)]
+		if !exists {
+			continue
+		}
+		passages = append(passages, PostPassage{
+			PostID:  post.ID,
+			Slug:    post.Slug,
+			Title:   post.Title,
+			Content: match.Content,
+			Score:   match.Score,
+		})
+		if len(passages) >= limit {
+			break
+		}
+	}
+	return passages, nil
+}
+
+// retrieveKeyword 按关键词检索文章，每篇文章取命中关键词最多的分块
+func (s *PostSearchService) retrieveKeyword(ctx context.Context, query string, limit int) ([]PostPassage, error) {
+	ranking, scores, err := s.keywordSearch(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []models.Post
+	if len(ranking) > 0 {
+		if err := s.db.WithContext(ctx).Scopes(ungatedPosts).Where("id IN ?", ranking).Find(&posts).Error; err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[uint]*models.Post, len(posts))
+	for i := range posts {
+		byID[posts[i].ID] = &posts[i]
+	}
+
+	terms := searchTerms(query)
+	passages := make([]PostPassage, 0, len(ranking))
+	for _, id := range ranking {
+		post, exists := byID[id]
+		if !exists {
+			continue
+		}
+
+		best, bestHits := "", -1
+		for _, chunk := range PostChunks(post, s.chunkTokens) {
+			lower := strings.ToLower(chunk)
+			hits := 0
+			for _, term := range terms {
+				hits += strings.Count(lower, term)
+			}
+			if hits > bestHits {
+				best, bestHits = chunk, hits
+			}
+		}
+		passages = append(passages, PostPassage{
+			PostID:  post.ID,
+			Slug:    post.Slug,
+			Title:   post.Title,
+			Content: best,
+			Score:   scores[id],
+		})
+	}
+	return passages, nil
+}
+
+// publishedPosts 按ID加载已发布且没有访问规则的文章
+func (s *PostSearchService) publishedPosts(ctx context.Context, ids []uint) (map[uint]models.Post, error) {
+	posts := make(map[uint]models.Post, len(ids))
+	if len(ids) == 0 {
+		return posts, nil
+	}
+
+	var records []models.Post
+	if err := s.db.WithContext(ctx).
+		Select("id", "slug", "title").
+		Scopes(ungatedPosts).
+		Where("id IN ? AND status = ?", ids, "published").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, post := range records {
+		posts[post.ID] = post
+	}
+	return posts, nil
+}
+
+// ungatedPosts 排除文章或其分类设置了访问规则的文章，受限内容不能作为问答的依据
+func ungatedPosts(db *gorm.DB) *gorm.DB {
+	gated := db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.Category{}).
+		Select("id").
+		Where("access_rules IS NOT NULL")
+	return db.Where("access_rules IS NULL").Where("category_id NOT IN (?)", gated)
+}
+
+// keywordSearch 关键词检索，标题命中权重高于摘要和正文
+func (s *PostSearchService) keywordSearch(ctx context.Context, query string, limit int) ([]uint, map[uint]float64, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil, nil
+	}
+
+	conditions := make([]string, 0, len(terms))
+	args := make([]interface{}, 0, len(terms)*3)
+	for _, term := range terms {
+		pattern := "%" + term + "%"
+		conditions = append(conditions, "(LOWER(title) LIKE ? OR LOWER(excerpt) LIKE ? OR LOWER(content) LIKE ?)")
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	var posts []models.Post
+	err := s.db.WithContext(ctx).
+		Select("id", "title", "excerpt", "content").
+		Where("status = ?", "published").
+		Where(strings.Join(conditions, " OR "), args...).
+		Order("published_at DESC").
+		Limit(limit * 4).
+		Find(&posts).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scores := make(map[uint]float64, len(posts))
+	ranking := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		title := strings.ToLower(post.Title)
+		excerpt := strings.ToLower(post.Excerpt)
+		content := strings.ToLower(stripHTML(post.Content))
+
+		score := 0.0
+		for _, term := range terms {
+			score += 3*float64(strings.Count(title, term)) +
+				2*float64(strings.Count(excerpt, term)) +
+				float64(min(strings.Count(content, term), 10))
+		}
+		if score > 0 {
+			scores[post.ID] = score
+			ranking = append(ranking, post.ID)
+		}
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool { return scores[ranking[i]] > scores[ranking[j]] })
+	if len(ranking) > limit {
+		ranking = ranking[:limit]
+	}
+	return ranking, scores, nil
+}
+
+// vectorSearch 语义检索，每篇文章取最相似的分块
+func (s *PostSearchService) vectorSearch(ctx context.Context, query string, limit int) ([]uint, map[uint]ai.VectorMatch, error) {
+	if !s.SemanticEnabled() {
+		return nil, nil, ErrSemanticSearchUnavailable
+	}
+
+	vectors, err := s.embed(ctx, []string{query})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	matches, err := s.store.Search(ctx, PostSearchNamespace, vectors[0], limit*3)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	best := make(map[uint]ai.VectorMatch)
+	var ranking []uint
+	for _, match := range matches {
+		id, err := strconv.ParseUint(match.DocumentID, 10, 64)
+		if err != nil {
+			continue
+		}
+		postID := uint(id)
+		if _, exists := best[postID]; exists {
+			continue // matches 已按相似度降序
+		}
+		best[postID] = match
+		ranking = append(ranking, postID)
+		if len(ranking) >= limit {
+			break
+		}
+	}
+	return ranking, best, nil
+}
+
+// loadResults 按排序加载文章，过滤已下线的文章
+func (s *PostSearchService) loadResults(ctx context.Context, ids []uint, results map[uint]*PostSearchResult) ([]PostSearchResult, error) {
+	output := make([]PostSearchResult, 0, len(ids))
+	if len(ids) == 0 {
+		return output, nil
+	}
+
+	var posts []models.Post
+	if err := s.db.WithContext(ctx).
+		Preload("Author").
+		Preload("Category").
+		Preload("Tags").
+		Where("id IN ? AND status = ?", ids, "published").
+		Find(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+	for _, id := range ids {
+		post, exists := byID[id]
+		if !exists {
+			continue
+		}
+		result := *results[id]
+		result.Post = post
+		output = append(output, result)
+	}
+	return output, nil
+}
+
+// embed 分批生成嵌入向量
+func (s *PostSearchService) embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += s.batchSize {
+		end := min(start+s.batchSize, len(texts))
+		batch, err := s.manager.CreateEmbedding(ctx, s.client, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("embedding count mismatch: expected %d, got %d", end-start, len(batch))
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// embeddingModel 获取嵌入模型名称，用于记录向量来源
+func (s *PostSearchService) embeddingModel() string {
+	clientConfig, err := s.manager.GetConfig(s.client)
+	if err != nil {
+		return ""
+	}
+	if model, ok := clientConfig.Options["embedding_model"].(string); ok && model != "" {
+		return model
+	}
+	return clientConfig.Model
+}
+
+// PostChunks 将文章拆分为待嵌入的文本：第一块为标题和摘要，其余为正文分块
+func PostChunks(post *models.Post, maxTokens int) []string {
+	var chunks []string
+	head := strings.TrimSpace(post.Title + "\n" + post.Excerpt)
+	if head != "" {
+		chunks = append(chunks, head)
+	}
+	return append(chunks, ai.ChunkText(stripHTML(post.Content), maxTokens)...)
+}
+
+var (
+	htmlBlockPattern = regexp.MustCompile(`(?i)</?(p|div|br|h[1-6]|li|ul|ol|blockquote|pre|tr|table)[^>]*>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]*>`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// stripHTML 去除HTML标签，块级元素转换为段落分隔
+func stripHTML(content string) string {
+	content = htmlBlockPattern.ReplaceAllString(content, "\n\n")
+	content = htmlTagPattern.ReplaceAllString(content, "")
+	content = html.UnescapeString(content)
+	return strings.TrimSpace(blankLinePattern.ReplaceAllString(content, "\n\n"))
+}
+
+// searchTerms 将查询拆分为去重的小写关键词
+func searchTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// documentID 文章在向量存储中的文档ID
+func documentID(postID uint) string {
+	return strconv.FormatUint(uint64(postID), 10)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/ai"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newEmbeddingServer 按关键词生成三维向量：[猫, 狗, 其他]
+func newEmbeddingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+
+		data := make([]string, len(body.Input))
+		for i, text := range body.Input {
+			text = strings.ToLower(text)
+			vector := [3]float64{0, 0, 0.1}
+			if strings.Contains(text, "cat") || strings.Contains(text, "kitten") {
+				vector[0] = 1
+			}
+			if strings.Contains(text, "dog") || strings.Contains(text, "puppy") {
+				vector[1] = 1
+			}
+			data[i] = fmt.Sprintf(`{"index":%d,"embedding":[%g,%g,%g]}`, i, vector[0], vector[1], vector[2])
+		}
+		fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(data, ","))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestPostSearchService(t *testing.T) (*PostSearchService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Category{}, &models.Tag{}, &models.Post{}, &ai.EmbeddingRecord{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	store, err := ai.NewGormVectorStore(db)
+	if err != nil {
+		t.Fatalf("NewGormVectorStore error: %v", err)
+	}
+
+	manager := ai.NewManager()
+	if err := manager.AddClient("openai", &ai.Config{
+		Provider: "openai",
+		APIKey:   "key",
+		BaseURL:  newEmbeddingServer(t).URL,
+		Model:    "text-embedding-3-small",
+	}); err != nil {
+		t.Fatalf("AddClient error: %v", err)
+	}
+
+	service := NewPostSearchService(manager, store, &config.SemanticSearchConfig{Client: "openai", ChunkTokens: 50})
+	service.SetDB(db)
+	return service, db
+}
+
+func TestPostSearchService_HybridSearch(t *testing.T) {
+	service, db := newTestPostSearchService(t)
+	ctx := context.Background()
+
+	posts := []*models.Post{
+		{Title: "Caring for a kitten", Slug: "kitten", Content: "Feed your kitten small meals.", Status: "draft"},
+		{Title: "Training tips", Slug: "training", Content: "Reward your puppy after every walk.", Status: "draft"},
+		{Title: "Dog food reviews", Slug: "dog-food", Content: "We compared ten brands of dog food.", Status: "draft"},
+		{Title: "Unpublished dog draft", Slug: "draft", Content: "Dog content that is not live.", Status: "draft"},
+	}
+	for _, post := range posts[:3] {
+		post.Publish()
+	}
+	for _, post := range posts {
+		if err := db.Create(post).Error; err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+		if err := service.IndexPost(ctx, post); err != nil {
+			t.Fatalf("IndexPost error: %v", err)
+		}
+	}
+
+	// 关键词只能命中标题和正文包含 dog 的文章
+	results, err := service.Search(ctx, "dog", SearchModeKeyword, 10)
+	if err != nil {
+		t.Fatalf("keyword search error: %v", err)
+	}
+	if len(results) != 1 || results[0].Post.Slug != "dog-food" {
+		t.Errorf("unexpected keyword results: %+v", results)
+	}
+
+	// 语义检索能找到只提到 puppy 的文章
+	results, err = service.Search(ctx, "dog", SearchModeSemantic, 2)
+	if err != nil {
+		t.Fatalf("semantic search error: %v", err)
+	}
+	if len(results) != 2 || results[0].VectorScore < 0.9 {
+		t.Errorf("unexpected semantic results: %+v", results)
+	}
+
+	// 混合检索：两路都命中的文章排在最前，草稿不会出现
+	results, err = service.Search(ctx, "dog", SearchModeHybrid, 10)
+	if err != nil {
+		t.Fatalf("hybrid search error: %v", err)
+	}
+	if len(results) < 2 || results[0].Post.Slug != "dog-food" || results[1].Post.Slug != "training" {
+		t.Errorf("unexpected hybrid ranking: %+v", results)
+	}
+	for _, result := range results {
+		if result.Post.Status != "published" {
+			t.Errorf("unpublished post returned: %s", result.Post.Slug)
+		}
+	}
+
+	// 下线后从索引移除
+	posts[2].Status = "archived"
+	db.Save(posts[2])
+	if err := service.IndexPost(ctx, posts[2]); err != nil {
+		t.Fatalf("IndexPost error: %v", err)
+	}
+	results, _ = service.Search(ctx, "dog", SearchModeSemantic, 10)
+	for _, result := range results {
+		if result.Post.Slug == "dog-food" {
+			t.Error("archived post should be removed from the index")
+		}
+	}
+
+	indexed, err := service.Reindex(ctx, nil)
+	if err != nil || indexed != 2 {
+		t.Errorf("expected 2 posts reindexed, got %d (%v)", indexed, err)
+	}
+}
+
+func TestPostSearchService_KeywordOnly(t *testing.T) {
+	_, db := newTestPostSearchService(t)
+	service := NewPostSearchService(nil, nil, nil)
+	service.SetDB(db)
+	ctx := context.Background()
+
+	post := &models.Post{Title: "Hello <b>world</b>", Slug: "hello", Content: "<p>Hello there</p>"}
+	post.Publish()
+	db.Create(post)
+
+	results, err := service.Search(ctx, "hello", "", 10)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("expected keyword fallback result, got %+v (%v)", results, err)
+	}
+	if _, err := service.Search(ctx, "hello", SearchModeSemantic, 10); err == nil {
+		t.Error("expected semantic search to fail without AI")
+	}
+	if _, err := service.Reindex(ctx, nil); err != ErrSemanticSearchUnavailable {
+		t.Errorf("expected ErrSemanticSearchUnavailable, got %v", err)
+	}
+}
+
+func TestPostSearchService_Retrieve(t *testing.T) {
+	service, db := newTestPostSearchService(t)
+	ctx := context.Background()
+
+	post := &models.Post{Title: "Adopting a puppy", Slug: "puppy", Content: "Puppies need vaccinations.\n\nDogs love walks."}
+	post.Publish()
+	db.Create(post)
+	service.IndexPost(ctx, post)
+
+	passages, err := service.Retrieve(ctx, "dog care", 2)
+	if err != nil {
+		t.Fatalf("Retrieve error: %v", err)
+	}
+	if len(passages) == 0 || passages[0].Slug != "puppy" || passages[0].Content == "" {
+		t.Errorf("unexpected passages: %+v", passages)
+	}
+
+	// 无AI时按关键词检索
+	keyword := NewPostSearchService(nil, nil, nil)
+	keyword.SetDB(db)
+	passages, err = keyword.Retrieve(ctx, "walks", 2)
+	if err != nil || len(passages) != 1 || !strings.Contains(passages[0].Content, "walks") {
+		t.Errorf("unexpected keyword passages: %+v (%v)", passages, err)
+	}
+}
+
+func TestPostSearchService_RetrieveSkipsGatedPosts(t *testing.T) {
+	service, db := newTestPostSearchService(t)
+	ctx := context.Background()
+	rule := models.AccessRule{Type: models.AccessRuleNative, ChainID: 1}
+
+	category := &models.Category{Name: "Members", Slug: "members", AccessRules: models.AccessRules{rule}}
+	db.Create(category)
+	gated := &models.Post{Title: "Kitten secrets", Slug: "gated", Content: "Cats purr for members.", AccessRules: models.AccessRules{rule}}
+	inCategory := &models.Post{Title: "Kitten diet", Slug: "members-diet", Content: "Cats eat fish.", CategoryID: category.ID}
+	open := &models.Post{Title: "Kitten care", Slug: "open", Content: "Cats sleep a lot."}
+	for _, post := range []*models.Post{gated, inCategory, open} {
+		post.Publish()
+		db.Create(post)
+		service.IndexPost(ctx, post)
+	}
+
+	passages, err := service.Retrieve(ctx, "cats", 5)
+	if err != nil || len(passages) == 0 {
+		t.Fatalf("unexpected passages: %+v (%v)", passages, err)
+	}
+	for _, passage := range passages {
+		if passage.Slug != "open" {
+			t.Errorf("gated post retrieved: %+v", passage)
+		}
+	}
+
+	keyword := NewPostSearchService(nil, nil, nil)
+	keyword.SetDB(db)
+	passages, err = keyword.Retrieve(ctx, "cats", 5)
+	if err != nil || len(passages) != 1 || passages[0].Slug != "open" {
+		t.Errorf("unexpected keyword passages: %+v (%v)", passages, err)
+	}
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/clarkzhu2020/aidecms/pkg/web3"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSIWEUnavailable 钱包登录配置错误，已禁用
+	ErrSIWEUnavailable = errors.New("钱包登录不可用")
+
+	// ErrSIWENonce 消息中的 nonce 不是本站签发的、已使用或已过期
+	ErrSIWENonce = errors.New("签名请求无效或已过期，请重新获取 nonce")
+
+	// ErrSIWENoAccount 钱包未关联账户，且不允许自动注册
+	ErrSIWENoAccount = errors.New("该钱包尚未关联账户")
+
+	// ErrWalletLinked 该钱包已关联其他用户
+	ErrWalletLinked = errors.New("该钱包已关联其他账户")
+
+	// ErrWalletNotFound 用户未关联该钱包
+	ErrWalletNotFound = errors.New("未关联该钱包")
+
+	// ErrLastWallet 钱包注册的用户没有其他登录方式，不能解除最后一个钱包
+	ErrLastWallet = errors.New("这是账户唯一的登录方式，不能解除关联")
+)
+
+// walletEmailDomain 钱包自动注册用户的占位邮箱域名，.invalid 为保留域名，不会发送邮件
+const walletEmailDomain = "@wallet.invalid"
+
+// SIWEChallenge 客户端组装 EIP-4361 消息需要的参数
+type SIWEChallenge struct {
+	Nonce     string    `json:"nonce"`
+	Domain    string    `json:"domain"`
+	URI       string    `json:"uri"`
+	Statement string    `json:"statement"`
+	Version   string    `json:"version"`
+	ChainIDs  []int64   `json:"chain_ids"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expiration_time"`
+}
+
+// SIWEResult 钱包签名校验的结果
+type SIWEResult struct {
+	User    *models.User
+	Address string
+	Linked  bool // 已登录用户关联新钱包，调用方不应签发令牌
+	Created bool // 自动注册的新用户
+}
+
+// SIWEService 以太坊钱包登录（Sign-In With Ethereum，EIP-4361）服务
+type SIWEService struct {
+	db     *gorm.DB
+	config *config.SIWEConfig
+	now    func() time.Time
+}
+
+// NewSIWEService 创建钱包登录服务，配置错误时记录日志并禁用钱包登录
+func NewSIWEService() *SIWEService {
+	cfg, err := config.LoadSIWEConfig()
+	if err != nil {
+		hlog.Errorf("failed to load SIWE config: %v", err)
+		cfg = nil
+	}
+	// 钱包注册的用户没有可验证的邮箱，要求验证邮箱时无法登录
+	config.InitAuth()
+	if cfg != nil && cfg.AutoProvision && config.Auth.RequireVerifiedEmail {
+		hlog.Warn("SIWE auto provisioning is disabled because AUTH_REQUIRE_VERIFIED_EMAIL is enabled")
+		cfg.AutoProvision = false
+	}
+	return &SIWEService{
+		db:     database.GetDB(),
+		config: cfg,
+		now:    time.Now,
+	}
+}
+
+// Nonce 签发 nonce，userID 不为0时为已登录用户关联新钱包
+func (s *SIWEService) Nonce(userID uint) (*SIWEChallenge, error) {
+	if s.config == nil {
+		return nil, ErrSIWEUnavailable
+	}
+	nonce, err := web3.NewSIWENonce()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	// 顺便清理过期的 nonce
+	if err := s.db.Where("expires_at < ?", now).Delete(&models.SIWENonce{}).Error; err != nil {
+		return nil, err
+	}
+	record := &models.SIWENonce{Nonce: nonce, UserID: userID, ExpiresAt: now.Add(s.config.NonceTTL)}
+	if err := s.db.Create(record).Error; err != nil {
+		return nil, err
+	}
+
+	return &SIWEChallenge{
+		Nonce:     nonce,
+		Domain:    s.config.Domain,
+		URI:       s.config.URI,
+		Statement: s.config.Statement,
+		Version:   "1",
+		ChainIDs:  s.config.ChainIDs,
+		IssuedAt:  now.UTC().Truncate(time.Second),
+		ExpiresAt: record.ExpiresAt.UTC().Truncate(time.Second),
+	}, nil
+}
+
+// Verify 校验钱包签名的 EIP-4361 消息，然后找到、关联或创建钱包对应的用户
+// nonce 只能使用一次，且必须与签发时的用途一致：userID 为0时为登录，否则为该用户关联钱包。
+// 登录流程由调用方用 UserService.LoginUser 签发令牌
+func (s *SIWEService) Verify(ctx context.Context, userID uint, message, signature string) (*SIWEResult, error) {
+	if s.config == nil {
+		return nil, ErrSIWEUnavailable
+	}
+	msg, err := web3.ParseSIWEMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	// 并发提交同一签名时只有一个请求能删除成功
+	var record models.SIWENonce
+	if err := s.db.Where("nonce = ?", msg.Nonce).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSIWENonce
+		}
+		return nil, err
+	}
+	result := s.db.Delete(&models.SIWENonce{}, record.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	now := s.now()
+	if result.RowsAffected == 0 || !record.ExpiresAt.After(now) || record.UserID != userID {
+		return nil, ErrSIWENonce
+	}
+
+	err = msg.Verify(message, signature, web3.SIWEVerifyOptions{
+		Domain:   s.config.Domain,
+		ChainIDs: s.config.ChainIDs,
+		Now:      now,
+		Leeway:   time.Minute,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res *SIWEResult
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		res, err = s.resolveUser(tx, msg.Address, userID)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.UserWallet{}).Where("address = ?", msg.Address).
+			Updates(map[string]interface{}{"chain_id": msg.ChainID, "last_login_at": &now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.Address = msg.Address
+	res.Linked = userID != 0
+	return res, nil
+}
+
+// Wallets 用户关联的钱包
+func (s *SIWEService) Wallets(userID uint) ([]models.UserWallet, error) {
+	var wallets []models.UserWallet
+	err := s.db.Where("user_id = ?", userID).Order("id").Find(&wallets).Error
+	return wallets, err
+}
+
+// Unlink 解除用户与钱包的关联
+func (s *SIWEService) Unlink(userID uint, address string) error {
+	if !common.IsHexAddress(address) {
+		return ErrWalletNotFound
+	}
+	address = common.HexToAddress(address).Hex()
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.UserWallet{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 1 && strings.HasSuffix(user.Email, walletEmailDomain) {
+			var wallet models.UserWallet
+			if err := tx.Where("user_id = ? AND address = ?", userID, address).First(&wallet).Error; err == nil {
+				return ErrLastWallet
+			}
+		}
+
+		result := tx.Unscoped().Where("user_id = ? AND address = ?", userID, address).Delete(&models.UserWallet{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWalletNotFound
+		}
+		return nil
+	})
+}
+
+// resolveUser 确定钱包对应的用户：已关联的钱包直接登录，关联流程中关联到当前用户，
+// 否则在允许时创建新用户
+func (s *SIWEService) resolveUser(tx *gorm.DB, address string, linkUserID uint) (*SIWEResult, error) {
+	var wallet models.UserWallet
+	err := tx.Where("address = ?", address).First(&wallet).Error
+	if err == nil {
+		if linkUserID != 0 && wallet.UserID != linkUserID {
+			return nil, ErrWalletLinked
+		}
+		var user models.User
+		if err := tx.First(&user, wallet.UserID).Error; err != nil {
+			return nil, err
+		}
+		return &SIWEResult{User: &user}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if linkUserID != 0 {
+		var user models.User
+		if err := tx.First(&user, linkUserID).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Create(&models.UserWallet{UserID: user.ID, Address: address}).Error; err != nil {
+			return nil, err
+		}
+		return &SIWEResult{User: &user}, nil
+	}
+
+	if !s.config.AutoProvision {
+		return nil, ErrSIWENoAccount
+	}
+	user, err := s.provisionUser(tx, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&models.UserWallet{UserID: user.ID, Address: address}).Error; err != nil {
+		return nil, err
+	}
+	return &SIWEResult{User: user, Created: true}, nil
+}
+
+// provisionUser 为钱包创建新用户，用户名取地址前缀，邮箱为占位地址，密码为随机值
+func (s *SIWEService) provisionUser(tx *gorm.DB, address string) (*models.User, error) {
+	lower := strings.ToLower(address)
+	username, err := availableUsername(tx, "wallet_"+lower[2:10])
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(b)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username: username,
+		Email:    lower + walletEmailDomain,
+		Password: string(hashed),
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return nil, err
+	}
+
+	var role models.Role
+	if err := tx.Where("name = ?", s.config.DefaultRole).First(&role).Error; err == nil {
+		if err := tx.Model(user).Association("Roles").Append(&role); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
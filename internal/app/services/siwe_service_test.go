@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/web3"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gorm.io/gorm"
+)
+
+func newTestSIWEService(t *testing.T) (*SIWEService, *gorm.DB) {
+	t.Helper()
+	_, db := newTestPermissionService(t)
+	return &SIWEService{
+		db: db,
+		config: &config.SIWEConfig{
+			Domain:        "cms.example.com",
+			URI:           "https://cms.example.com",
+			ChainIDs:      []int64{1, 137},
+			NonceTTL:      10 * time.Minute,
+			AutoProvision: true,
+			DefaultRole:   "user",
+		},
+		now: time.Now,
+	}, db
+}
+
+// signInWithEthereum 按 challenge 组装消息并用钱包签名
+func signInWithEthereum(t *testing.T, key *ecdsa.PrivateKey, challenge *SIWEChallenge, modify func(*web3.SIWEMessage)) (string, string) {
+	t.Helper()
+	msg := &web3.SIWEMessage{
+		Domain:         challenge.Domain,
+		Address:        crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		Statement:      challenge.Statement,
+		URI:            challenge.URI,
+		Version:        challenge.Version,
+		ChainID:        challenge.ChainIDs[0],
+		Nonce:          challenge.Nonce,
+		IssuedAt:       challenge.IssuedAt,
+		ExpirationTime: &challenge.ExpiresAt,
+	}
+	if modify != nil {
+		modify(msg)
+	}
+	message := msg.String()
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return message, hexutil.Encode(sig)
+}
+
+func TestSIWEService_LoginAndReplay(t *testing.T) {
+	ctx := context.Background()
+	s, db := newTestSIWEService(t)
+	key, _ := crypto.GenerateKey()
+
+	challenge, err := s.Nonce(0)
+	if err != nil {
+		t.Fatalf("Nonce error: %v", err)
+	}
+	message, signature := signInWithEthereum(t, key, challenge, nil)
+
+	result, err := s.Verify(ctx, 0, message, signature)
+	if err != nil {
+		t.Fatalf("Verify error: %v", err)
+	}
+	if !result.Created || result.Linked || result.Address != crypto.PubkeyToAddress(key.PublicKey).Hex() {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	var user models.User
+	db.Preload("Roles").First(&user, result.User.ID)
+	if !user.HasRole("user") || user.IsEmailVerified() {
+		t.Errorf("unexpected provisioned user: %+v", user)
+	}
+
+	// 同一签名不能重复使用
+	if _, err := s.Verify(ctx, 0, message, signature); !errors.Is(err, ErrSIWENonce) {
+		t.Fatalf("replay error = %v, want ErrSIWENonce", err)
+	}
+
+	// 再次登录使用已关联的钱包
+	challenge, _ = s.Nonce(0)
+	message, signature = signInWithEthereum(t, key, challenge, func(m *web3.SIWEMessage) { m.ChainID = 137 })
+	again, err := s.Verify(ctx, 0, message, signature)
+	if err != nil {
+		t.Fatalf("second Verify error: %v", err)
+	}
+	if again.Created || again.User.ID != result.User.ID {
+		t.Fatalf("second login = %+v, want existing user %d", again, result.User.ID)
+	}
+	wallets, _ := s.Wallets(result.User.ID)
+	if len(wallets) != 1 || wallets[0].ChainID != 137 || wallets[0].LastLoginAt == nil {
+		t.Errorf("unexpected wallets: %+v", wallets)
+	}
+
+	// 钱包注册的用户不能解除唯一的钱包
+	if err := s.Unlink(result.User.ID, result.Address); !errors.Is(err, ErrLastWallet) {
+		t.Fatalf("Unlink error = %v, want ErrLastWallet", err)
+	}
+}
+
+func TestSIWEService_RejectsInvalidMessages(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*web3.SIWEMessage)
+		want   error
+	}{
+		{"other domain", func(m *web3.SIWEMessage) { m.Domain = "evil.example.com" }, web3.ErrSIWEDomainMismatch},
+		{"other uri", func(m *web3.SIWEMessage) { m.URI = "https://evil.example.com" }, web3.ErrSIWEDomainMismatch},
+		{"chain not allowed", func(m *web3.SIWEMessage) { m.ChainID = 56 }, web3.ErrSIWEChainNotAllowed},
+		{"unknown nonce", func(m *web3.SIWEMessage) { m.Nonce = "0123456789abcdef" }, ErrSIWENonce},
+		{"expired", func(m *web3.SIWEMessage) {
+			expired := time.Now().Add(-time.Hour)
+			m.IssuedAt = expired.Add(-time.Minute)
+			m.ExpirationTime = &expired
+		}, web3.ErrSIWEExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestSIWEService(t)
+			key, _ := crypto.GenerateKey()
+			challenge, _ := s.Nonce(0)
+			message, signature := signInWithEthereum(t, key, challenge, tt.modify)
+
+			if _, err := s.Verify(context.Background(), 0, message, signature); !errors.Is(err, tt.want) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.want)
+			}
+			var count int64
+			db.Model(&models.UserWallet{}).Count(&count)
+			if count != 0 {
+				t.Errorf("wallets = %d, want 0", count)
+			}
+		})
+	}
+
+	// 未开启自动注册时不创建用户
+	s, _ := newTestSIWEService(t)
+	s.config.AutoProvision = false
+	key, _ := crypto.GenerateKey()
+	challenge, _ := s.Nonce(0)
+	message, signature := signInWithEthereum(t, key, challenge, nil)
+	if _, err := s.Verify(context.Background(), 0, message, signature); !errors.Is(err, ErrSIWENoAccount) {
+		t.Fatalf("Verify error = %v, want ErrSIWENoAccount", err)
+	}
+}
+
+func TestSIWEService_LinkAndUnlink(t *testing.T) {
+	ctx := context.Background()
+	s, db := newTestSIWEService(t)
+	kim := &models.User{Username: "kim", Email: "kim@example.com", Password: "x"}
+	leo := &models.User{Username: "leo", Email: "leo@example.com", Password: "x"}
+	db.Create(kim)
+	db.Create(leo)
+	key, _ := crypto.GenerateKey()
+
+	// 关联用的 nonce 不能用来登录，反之亦然
+	challenge, _ := s.Nonce(kim.ID)
+	message, signature := signInWithEthereum(t, key, challenge, nil)
+	if _, err := s.Verify(ctx, 0, message, signature); !errors.Is(err, ErrSIWENonce) {
+		t.Fatalf("login with link nonce error = %v, want ErrSIWENonce", err)
+	}
+
+	challenge, _ = s.Nonce(kim.ID)
+	message, signature = signInWithEthereum(t, key, challenge, nil)
+	result, err := s.Verify(ctx, kim.ID, message, signature)
+	if err != nil {
+		t.Fatalf("link error: %v", err)
+	}
+	if !result.Linked || result.Created || result.User.ID != kim.ID {
+		t.Fatalf("unexpected link result: %+v", result)
+	}
+
+	// 同一钱包不能关联到其他用户
+	challenge, _ = s.Nonce(leo.ID)
+	message, signature = signInWithEthereum(t, key, challenge, nil)
+	if _, err := s.Verify(ctx, leo.ID, message, signature); !errors.Is(err, ErrWalletLinked) {
+		t.Fatalf("link to other user error = %v, want ErrWalletLinked", err)
+	}
+
+	// 关联后可以用钱包登录
+	challenge, _ = s.Nonce(0)
+	message, signature = signInWithEthereum(t, key, challenge, nil)
+	if result, err := s.Verify(ctx, 0, message, signature); err != nil || result.User.ID != kim.ID {
+		t.Fatalf("login = %+v, %v", result, err)
+	}
+
+	if err := s.Unlink(kim.ID, result.Address); err != nil {
+		t.Fatalf("Unlink error: %v", err)
+	}
+	if err := s.Unlink(kim.ID, result.Address); !errors.Is(err, ErrWalletNotFound) {
+		t.Fatalf("second Unlink error = %v, want ErrWalletNotFound", err)
+	}
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/cache"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/clarkzhu2020/aidecms/pkg/web3"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// 读者不能阅读受限文章的原因
+const (
+	AccessLoginRequired       = "login_required"       // 未登录
+	AccessWalletRequired      = "wallet_required"      // 未关联钱包
+	AccessInsufficientHolding = "insufficient_holding" // 关联的钱包不满足规则
+	AccessUnavailable         = "unavailable"          // 链上余额查询失败
+)
+
+// AccessDecision 读者对文章的访问结果，Locked 时 Rules 为未满足的规则
+type AccessDecision struct {
+	Locked bool               `json:"-"`
+	Reason string             `json:"reason,omitempty"`
+	Rules  models.AccessRules `json:"rules,omitempty"`
+}
+
+// TokenBalanceReader 查询钱包满足规则的链上持仓（原生币或代币的最小单位，NFT 的数量）
+type TokenBalanceReader interface {
+	Balance(ctx context.Context, rule models.AccessRule, address string) (*big.Int, error)
+}
+
+// TokenGateService 按读者关联钱包的链上持仓限制文章访问
+// 文章和其分类的规则都需要满足，每组规则满足其中一条即可；余额查询结果缓存 TTL 时间
+type TokenGateService struct {
+	db     *gorm.DB
+	reader TokenBalanceReader
+	cache  *cache.Cache
+	ttl    time.Duration
+}
+
+// NewTokenGateService 创建内容访问控制服务
+func NewTokenGateService() *TokenGateService {
+	cfg := config.LoadTokenGateConfig()
+	return &TokenGateService{
+		db:     database.GetDB(),
+		reader: NewRPCBalanceReader(cfg.RPCs),
+		cache:  cache.NewCache(cache.NewMemoryDriver()),
+		ttl:    cfg.CacheTTL,
+	}
+}
+
+// IsGated 文章或其分类是否设置了访问规则，分类需要已加载
+func IsGated(post *models.Post) bool {
+	return len(post.AccessRules) > 0 || (post.Category != nil && len(post.Category.AccessRules) > 0)
+}
+
+// Decide 判断用户能否阅读文章，返回的结果与 posts 一一对应；userID 为0表示未登录
+// 分类未加载的文章会先加载分类。用户的钱包只在有受限文章时查询一次
+func (s *TokenGateService) Decide(ctx context.Context, userID uint, posts []*models.Post) ([]*AccessDecision, error) {
+	if err := s.loadCategories(posts); err != nil {
+		return nil, err
+	}
+
+	decisions := make([]*AccessDecision, len(posts))
+	var wallets []string
+	walletsLoaded := false
+	for i, post := range posts {
+		decisions[i] = &AccessDecision{}
+		if !IsGated(post) {
+			continue
+		}
+		if userID == 0 {
+			decisions[i] = lockedDecision(AccessLoginRequired, post)
+			continue
+		}
+		if !walletsLoaded {
+			if err := s.db.Model(&models.UserWallet{}).Where("user_id = ?", userID).Pluck("address", &wallets).Error; err != nil {
+				return nil, err
+			}
+			walletsLoaded = true
+		}
+		if len(wallets) == 0 {
+			decisions[i] = lockedDecision(AccessWalletRequired, post)
+			continue
+		}
+
+		decision := decisions[i]
+		for _, rules := range ruleGroups(post) {
+			satisfied, failed := s.satisfies(ctx, rules, wallets)
+			if satisfied {
+				continue
+			}
+			decision.Locked = true
+			decision.Rules = append(decision.Rules, rules...)
+			if failed && decision.Reason != AccessInsufficientHolding {
+				decision.Reason = AccessUnavailable
+			} else {
+				decision.Reason = AccessInsufficientHolding
+			}
+		}
+	}
+	return decisions, nil
+}
+
+// satisfies 任意钱包满足任意一条规则；failed 表示有余额查询失败
+func (s *TokenGateService) satisfies(ctx context.Context, rules models.AccessRules, wallets []string) (satisfied, failed bool) {
+	for _, rule := range rules {
+		for _, wallet := range wallets {
+			balance, err := s.balance(ctx, rule, wallet)
+			if err != nil {
+				hlog.CtxWarnf(ctx, "token gate balance check failed for %s on chain %d: %v", wallet, rule.ChainID, err)
+				failed = true
+				continue
+			}
+			if balance.Cmp(rule.Threshold()) >= 0 {
+				return true, false
+			}
+		}
+	}
+	return false, failed
+}
+
+// balance 查询余额，成功的结果缓存 TTL 时间
+func (s *TokenGateService) balance(ctx context.Context, rule models.AccessRule, address string) (*big.Int, error) {
+	key := fmt.Sprintf("token_gate:%d:%s:%s:%s:%s", rule.ChainID, rule.Type,
+		strings.ToLower(rule.Contract), rule.TokenID, strings.ToLower(address))
+	if cached, err := s.cache.GetString(key); err == nil {
+		if balance, ok := new(big.Int).SetString(cached, 10); ok {
+			return balance, nil
+		}
+	}
+
+	balance, err := s.reader.Balance(ctx, rule, address)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(key, balance.String(), s.ttl)
+	return balance, nil
+}
+
+// loadCategories 为未加载分类的文章加载分类
+func (s *TokenGateService) loadCategories(posts []*models.Post) error {
+	var ids []uint
+	for _, post := range posts {
+		if post.Category == nil && post.CategoryID != 0 {
+			ids = append(ids, post.CategoryID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var categories []models.Category
+	if err := s.db.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]*models.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+	for _, post := range posts {
+		if post.Category == nil {
+			post.Category = byID[post.CategoryID]
+		}
+	}
+	return nil
+}
+
+// ruleGroups 文章需要同时满足的规则组
+func ruleGroups(post *models.Post) []models.AccessRules {
+	var groups []models.AccessRules
+	if post.Category != nil && len(post.Category.AccessRules) > 0 {
+		groups = append(groups, post.Category.AccessRules)
+	}
+	if len(post.AccessRules) > 0 {
+		groups = append(groups, post.AccessRules)
+	}
+	return groups
+}
+
+// lockedDecision 未检查持仓时的结果，返回全部规则
+func lockedDecision(reason string, post *models.Post) *AccessDecision {
+	decision := &AccessDecision{Locked: true, Reason: reason}
+	for _, rules := range ruleGroups(post) {
+		decision.Rules = append(decision.Rules, rules...)
+	}
+	return decision
+}
+
+// RPCBalanceReader 通过各链的 JSON-RPC 节点查询余额，连接在第一次使用时建立
+type RPCBalanceReader struct {
+	rpcs    map[int64]string
+	mu      sync.Mutex
+	clients map[int64]*web3.EthereumClient
+}
+
+// NewRPCBalanceReader 创建余额查询，rpcs 为 Chain ID 对应的 JSON-RPC 地址
+func NewRPCBalanceReader(rpcs map[int64]string) *RPCBalanceReader {
+	return &RPCBalanceReader{
+		rpcs:    rpcs,
+		clients: make(map[int64]*web3.EthereumClient),
+	}
+}
+
+// Balance 查询钱包满足规则的持仓
+func (r *RPCBalanceReader) Balance(ctx context.Context, rule models.AccessRule, address string) (*big.Int, error) {
+	client, err := r.client(ctx, rule.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch rule.Type {
+	case models.AccessRuleNative:
+		balance, err := client.GetBalance(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := new(big.Int).SetString(balance, 10)
+		return n, nil
+	case models.AccessRuleERC20:
+		return web3.NewERC20Token(client, rule.Contract).BalanceOf(ctx, address)
+	case models.AccessRuleERC721:
+		nft := web3.NewNFT(client, rule.Contract)
+		if rule.TokenID == "" {
+			return nft.BalanceOf(ctx, address)
+		}
+		owner, err := nft.GetOwner(ctx, rule.TokenID)
+		if err != nil {
+			// 不存在的 NFT 调用 ownerOf 会回滚
+			if strings.Contains(err.Error(), "execution reverted") {
+				return big.NewInt(0), nil
+			}
+			return nil, err
+		}
+		if common.HexToAddress(owner) == common.HexToAddress(address) {
+			return big.NewInt(1), nil
+		}
+		return big.NewInt(0), nil
+	}
+	return nil, fmt.Errorf("unsupported access rule type %q", rule.Type)
+}
+
+// client 获取链的客户端，第一次连接时确认节点的 Chain ID 与配置一致
+func (r *RPCBalanceReader) client(ctx context.Context, chainID int64) (*web3.EthereumClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if client, ok := r.clients[chainID]; ok {
+		return client, nil
+	}
+
+	rpc, ok := r.rpcs[chainID]
+	if !ok {
+		return nil, fmt.Errorf("no RPC endpoint configured for chain %d", chainID)
+	}
+	client, err := web3.NewEthereumClient(rpc)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := client.GetChainID(ctx)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	if actual.Int64() != chainID {
+		client.Close()
+		return nil, fmt.Errorf("RPC endpoint for chain %d serves chain %s", chainID, actual)
+	}
+	r.clients[chainID] = client
+	return client, nil
+}
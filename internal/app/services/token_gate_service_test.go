@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/cache"
+	"gorm.io/gorm"
+)
+
+const (
+	gateToken  = "0x0000000000000000000000000000000000000aBc"
+	gateWallet = "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+	gateOther  = "0x0000000000000000000000000000000000000001"
+)
+
+// fakeBalanceReader 按 "合约:地址" 返回余额，记录查询次数
+type fakeBalanceReader struct {
+	balances map[string]int64
+	err      error
+	calls    int
+}
+
+func (r *fakeBalanceReader) Balance(ctx context.Context, rule models.AccessRule, address string) (*big.Int, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return big.NewInt(r.balances[strings.ToLower(rule.Contract+":"+address)]), nil
+}
+
+func newTestTokenGateService(t *testing.T, reader TokenBalanceReader) (*TokenGateService, *gorm.DB) {
+	t.Helper()
+	_, db := newTestPermissionService(t)
+	return &TokenGateService{
+		db:     db,
+		reader: reader,
+		cache:  cache.NewCache(cache.NewMemoryDriver()),
+		ttl:    time.Minute,
+	}, db
+}
+
+func erc20Rule(min string) models.AccessRule {
+	return models.AccessRule{Type: models.AccessRuleERC20, ChainID: 1, Contract: gateToken, MinBalance: min}
+}
+
+func TestTokenGateDecide(t *testing.T) {
+	reader := &fakeBalanceReader{balances: map[string]int64{
+		strings.ToLower(gateToken + ":" + gateWallet): 100,
+	}}
+	gate, db := newTestTokenGateService(t, reader)
+	db.Create(&models.UserWallet{UserID: 1, Address: gateOther, ChainID: 1})
+	db.Create(&models.UserWallet{UserID: 1, Address: gateWallet, ChainID: 1})
+
+	public := &models.Post{Title: "public"}
+	enough := &models.Post{Title: "enough", AccessRules: models.AccessRules{erc20Rule("100")}}
+	tooMuch := &models.Post{Title: "too much", AccessRules: models.AccessRules{erc20Rule("101")}}
+	posts := []*models.Post{public, enough, tooMuch}
+	ctx := context.Background()
+
+	decisions, err := gate.Decide(ctx, 0, posts)
+	if err != nil {
+		t.Fatalf("Decide error: %v", err)
+	}
+	if decisions[0].Locked || !decisions[1].Locked || decisions[1].Reason != AccessLoginRequired {
+		t.Fatalf("anonymous decisions = %+v %+v", decisions[0], decisions[1])
+	}
+
+	decisions, err = gate.Decide(ctx, 2, posts)
+	if err != nil || decisions[1].Reason != AccessWalletRequired {
+		t.Fatalf("no wallet decision = %+v, %v", decisions[1], err)
+	}
+
+	decisions, err = gate.Decide(ctx, 1, posts)
+	if err != nil {
+		t.Fatalf("Decide error: %v", err)
+	}
+	if decisions[0].Locked || decisions[1].Locked {
+		t.Fatalf("expected unlocked, got %+v %+v", decisions[0], decisions[1])
+	}
+	if !decisions[2].Locked || decisions[2].Reason != AccessInsufficientHolding || len(decisions[2].Rules) != 1 {
+		t.Fatalf("insufficient decision = %+v", decisions[2])
+	}
+
+	// 余额已缓存
+	calls := reader.calls
+	if _, err := gate.Decide(ctx, 1, posts); err != nil || reader.calls != calls {
+		t.Fatalf("expected cached balances, calls %d -> %d", calls, reader.calls)
+	}
+}
+
+func TestTokenGateCategoryRules(t *testing.T) {
+	reader := &fakeBalanceReader{balances: map[string]int64{
+		strings.ToLower(gateToken + ":" + gateWallet): 5,
+	}}
+	gate, db := newTestTokenGateService(t, reader)
+	db.Create(&models.UserWallet{UserID: 1, Address: gateWallet, ChainID: 1})
+	category := &models.Category{Name: "members", Slug: "members", AccessRules: models.AccessRules{erc20Rule("10")}}
+	if err := db.Create(category).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 文章的规则满足，但分类的规则不满足
+	post := &models.Post{Title: "members only", CategoryID: category.ID, AccessRules: models.AccessRules{erc20Rule("1")}}
+	decisions, err := gate.Decide(context.Background(), 1, []*models.Post{post})
+	if err != nil {
+		t.Fatalf("Decide error: %v", err)
+	}
+	if post.Category == nil || post.Category.ID != category.ID {
+		t.Fatal("expected category to be loaded")
+	}
+	if !decisions[0].Locked || decisions[0].Rules[0].MinBalance != "10" {
+		t.Fatalf("decision = %+v", decisions[0])
+	}
+	if !IsGated(&models.Post{Category: category}) {
+		t.Fatal("expected category rules to gate posts")
+	}
+}
+
+func TestTokenGateUnavailable(t *testing.T) {
+	reader := &fakeBalanceReader{err: errors.New("rpc down")}
+	gate, db := newTestTokenGateService(t, reader)
+	db.Create(&models.UserWallet{UserID: 1, Address: gateWallet, ChainID: 1})
+
+	post := &models.Post{AccessRules: models.AccessRules{erc20Rule("")}}
+	decisions, err := gate.Decide(context.Background(), 1, []*models.Post{post})
+	if err != nil {
+		t.Fatalf("Decide error: %v", err)
+	}
+	if !decisions[0].Locked || decisions[0].Reason != AccessUnavailable {
+		t.Fatalf("decision = %+v", decisions[0])
+	}
+
+	// 失败的查询不缓存
+	reader.err = nil
+	reader.balances = map[string]int64{strings.ToLower(gateToken + ":" + gateWallet): 1}
+	if decisions, _ := gate.Decide(context.Background(), 1, []*models.Post{post}); decisions[0].Locked {
+		t.Fatalf("expected unlocked after recovery, got %+v", decisions[0])
+	}
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
+	envConfig "github.com/clarkzhu2020/aidecms/pkg/config"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// 连续输错 maxTwoFactorAttempts 次后锁定两步验证 twoFactorLockout，锁定期间拒绝所有验证码；
+// 锁定结束后在验证通过前每再输错一次都会重新锁定，时间翻倍，最长 maxTwoFactorLockout
+const (
+	maxTwoFactorAttempts = 5
+	twoFactorLockout     = 15 * time.Minute
+	maxTwoFactorLockout  = 24 * time.Hour
+)
+
+var (
+	// ErrTwoFactorCode 验证码或恢复码错误
+	ErrTwoFactorCode = errors.New("invalid two-factor code")
+
+	// ErrTwoFactorEnabled 已开启两步验证
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+
+	// ErrTwoFactorNotEnabled 未开启两步验证
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+
+	// ErrTwoFactorRequired 用户的角色要求开启两步验证，不能关闭
+	ErrTwoFactorRequired = errors.New("two-factor authentication is required by your role")
+
+	// ErrTwoFactorLocked 连续输错验证码次数过多，具体等待时间见 TwoFactorLockedError
+	ErrTwoFactorLocked = errors.New("too many invalid two-factor codes")
+)
+
+// TwoFactorLockedError 两步验证被锁定时返回的错误，RetryAfter 为还需等待的时间
+type TwoFactorLockedError struct {
+	RetryAfter time.Duration
+}
+
+// Error 实现 error 接口
+func (e *TwoFactorLockedError) Error() string {
+	return ErrTwoFactorLocked.Error()
+}
+
+// Unwrap 支持 errors.Is(err, ErrTwoFactorLocked)
+func (e *TwoFactorLockedError) Unwrap() error {
+	return ErrTwoFactorLocked
+}
+
+// TwoFactorSetup 开始绑定时返回给客户端的密钥，otpauth URI 可生成二维码
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorService TOTP 两步验证服务
+type TwoFactorService struct {
+	db     *gorm.DB
+	issuer string
+	now    func() time.Time
+}
+
+// NewTwoFactorService 创建两步验证服务，验证器中显示的发行方为 APP_NAME
+func NewTwoFactorService() *TwoFactorService {
+	return &TwoFactorService{
+		db:     database.GetDB(),
+		issuer: envConfig.GetEnv("APP_NAME", "AideCMS"),
+		now:    time.Now,
+	}
+}
+
+// Enabled 用户是否已开启两步验证
+func (s *TwoFactorService) Enabled(userID uint) (bool, error) {
+	credential, err := s.credential(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return credential.IsConfirmed(), nil
+}
+
+// Required 用户是否有要求两步验证的角色
+func (s *TwoFactorService) Required(userID uint) (bool, error) {
+	var user models.User
+	if err := s.db.Preload("Roles").First(&user, userID).Error; err != nil {
+		return false, err
+	}
+	for _, role := range user.Roles {
+		if role.RequireTwoFactor {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Begin 开始绑定，生成新的密钥；已开启两步验证时返回 ErrTwoFactorEnabled
+// 未确认的旧密钥会被替换
+func (s *TwoFactorService) Begin(userID uint) (*TwoFactorSetup, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	credential, err := s.credential(userID)
+	switch {
+	case err == nil && credential.IsConfirmed():
+		return nil, ErrTwoFactorEnabled
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		credential = &models.TwoFactorCredential{UserID: userID}
+	case err != nil:
+		return nil, err
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	credential.Secret = secret
+	credential.LastUsedStep = 0
+	if err := s.db.Save(credential).Error; err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorSetup{
+		Secret: secret,
+		URI:    auth.TOTPURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm 用验证器中的验证码确认绑定，开启两步验证并返回恢复码
+// 输错的次数与 Verify 共用，锁定期间返回 *TwoFactorLockedError
+func (s *TwoFactorService) Confirm(userID uint, code string) ([]string, error) {
+	credential, err := s.credential(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if credential.IsConfirmed() {
+		return nil, ErrTwoFactorEnabled
+	}
+	if err := s.checkLocked(credential); err != nil {
+		return nil, err
+	}
+	if err := s.checkTOTP(credential, normalizeTwoFactorCode(code)); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := s.now()
+		if err := tx.Model(credential).Update("confirmed_at", &now).Error; err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+// Verify 校验登录时提交的验证码或恢复码，恢复码使用后失效
+// 验证码中的空格和连字符会被忽略；连续输错后锁定，锁定期间返回 *TwoFactorLockedError
+func (s *TwoFactorService) Verify(userID uint, code string) error {
+	credential, err := s.credential(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return err
+	}
+	if !credential.IsConfirmed() {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := s.checkLocked(credential); err != nil {
+		return err
+	}
+	code = normalizeTwoFactorCode(code)
+	if isTOTPCode(code) {
+		return s.checkTOTP(credential, code)
+	}
+
+	result := s.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, auth.HashRecoveryCode(code)).
+		Update("used_at", s.now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return s.recordFailure(credential)
+	}
+	return s.db.Model(credential).Updates(map[string]interface{}{"failed_attempts": 0, "locked_until": nil}).Error
+}
+
+// Locked 返回两步验证还需锁定的时间，为0时未锁定；未绑定的用户返回0
+func (s *TwoFactorService) Locked(userID uint) (time.Duration, error) {
+	credential, err := s.credential(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if now := s.now(); credential.IsLocked(now) {
+		return credential.LockedUntil.Sub(now), nil
+	}
+	return 0, nil
+}
+
+// Disable 关闭两步验证，需要提交有效的验证码或恢复码
+func (s *TwoFactorService) Disable(userID uint, code string) error {
+	required, err := s.Required(userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+	if err := s.Verify(userID, code); err != nil {
+		return err
+	}
+	return s.Reset(userID)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧的恢复码全部失效
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	if err := s.Verify(userID, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+// RemainingRecoveryCodes 未使用的恢复码数量
+func (s *TwoFactorService) RemainingRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// Reset 删除用户的两步验证凭据和恢复码，用于关闭两步验证或管理员为丢失设备的用户重置，
+// 连续输错造成的锁定随凭据一起清除
+func (s *TwoFactorService) Reset(userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.TwoFactorCredential{}).Error
+	})
+}
+
+// credential 获取用户的两步验证凭据
+func (s *TwoFactorService) credential(userID uint) (*models.TwoFactorCredential, error) {
+	var credential models.TwoFactorCredential
+	if err := s.db.Where("user_id = ?", userID).First(&credential).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// checkLocked 凭据锁定期间返回 *TwoFactorLockedError
+func (s *TwoFactorService) checkLocked(credential *models.TwoFactorCredential) error {
+	if now := s.now(); credential.IsLocked(now) {
+		return &TwoFactorLockedError{RetryAfter: credential.LockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// checkTOTP 校验 TOTP 验证码，已使用过的时间窗口不能再次使用
+func (s *TwoFactorService) checkTOTP(credential *models.TwoFactorCredential, code string) error {
+	step, ok := auth.ValidateTOTP(credential.Secret, code, s.now())
+	if !ok || step <= credential.LastUsedStep {
+		return s.recordFailure(credential)
+	}
+
+	// 条件更新，并发提交同一验证码时只有一个请求成功
+	result := s.db.Model(&models.TwoFactorCredential{}).
+		Where("id = ? AND last_used_step < ?", credential.ID, step).
+		Updates(map[string]interface{}{"last_used_step": step, "failed_attempts": 0, "locked_until": nil})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTwoFactorCode
+	}
+	credential.LastUsedStep = step
+	credential.FailedAttempts = 0
+	credential.LockedUntil = nil
+	return nil
+}
+
+// recordFailure 记录一次验证失败，返回 ErrTwoFactorCode；
+// 连续失败达到上限时锁定凭据并返回 *TwoFactorLockedError，调用方应使当前的待完成令牌失效
+func (s *TwoFactorService) recordFailure(credential *models.TwoFactorCredential) error {
+	// 原子递增后读回计数，并发提交的错误验证码不会漏掉锁定
+	if err := s.db.Model(credential).UpdateColumn("failed_attempts", gorm.Expr("failed_attempts + 1")).Error; err != nil {
+		return err
+	}
+	if err := s.db.Model(&models.TwoFactorCredential{}).Where("id = ?", credential.ID).
+		Select("failed_attempts").Scan(&credential.FailedAttempts).Error; err != nil {
+		return err
+	}
+	if credential.FailedAttempts < maxTwoFactorAttempts {
+		return ErrTwoFactorCode
+	}
+
+	lockout := twoFactorLockoutFor(credential.FailedAttempts)
+	until := s.now().Add(lockout)
+	if err := s.db.Model(credential).UpdateColumn("locked_until", until).Error; err != nil {
+		return err
+	}
+	credential.LockedUntil = &until
+	return &TwoFactorLockedError{RetryAfter: lockout}
+}
+
+// twoFactorLockoutFor 连续失败 attempts 次后的锁定时间
+func twoFactorLockoutFor(attempts int) time.Duration {
+	lockout := twoFactorLockout
+	for i := maxTwoFactorAttempts; i < attempts && lockout < maxTwoFactorLockout; i++ {
+		lockout *= 2
+	}
+	return min(lockout, maxTwoFactorLockout)
+}
+
+// normalizeTwoFactorCode 去掉验证码中的空格和连字符，如 "123 456"、"123-456"
+func normalizeTwoFactorCode(code string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+}
+
+// isTOTPCode 是否为 TOTP 验证码（固定位数的数字），否则按恢复码处理
+func isTOTPCode(code string) bool {
+	if len(code) != auth.TOTPDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceRecoveryCodes 删除旧的恢复码并生成新的恢复码
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([]models.RecoveryCode, len(codes))
+	for i, code := range codes {
+		records[i] = models.RecoveryCode{UserID: userID, CodeHash: auth.HashRecoveryCode(code)}
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// totpAt 计算 now 所在时间窗口往后 offset 个窗口的验证码
+func totpAt(t *testing.T, secret string, now time.Time, offset int64) string {
+	t.Helper()
+	code, err := auth.TOTPCode(secret, auth.TOTPStep(now)+offset)
+	if err != nil {
+		t.Fatalf("TOTPCode error: %v", err)
+	}
+	return code
+}
+
+func newTestTwoFactorService(db *gorm.DB, now *time.Time) *TwoFactorService {
+	return &TwoFactorService{db: db, issuer: "AideCMS", now: func() time.Time { return *now }}
+}
+
+func TestTwoFactorService_EnrollAndVerify(t *testing.T) {
+	perms, db := newTestPermissionService(t)
+	now := time.Now()
+	s := newTestTwoFactorService(db, &now)
+
+	user := createUserWithRole(t, perms, "alice", "author")
+
+	setup, err := s.Begin(user.ID)
+	if err != nil {
+		t.Fatalf("Begin error: %v", err)
+	}
+	if enabled, _ := s.Enabled(user.ID); enabled {
+		t.Fatal("2fa should not be enabled before confirmation")
+	}
+
+	if _, err := s.Confirm(user.ID, "000000"); !errors.Is(err, ErrTwoFactorCode) {
+		t.Fatalf("wrong code should fail, got %v", err)
+	}
+	codes, err := s.Confirm(user.ID, totpAt(t, setup.Secret, now, 0))
+	if err != nil || len(codes) != recoveryCodeCount {
+		t.Fatalf("Confirm = %v, %v", codes, err)
+	}
+	if enabled, _ := s.Enabled(user.ID); !enabled {
+		t.Fatal("2fa should be enabled")
+	}
+	if _, err := s.Begin(user.ID); !errors.Is(err, ErrTwoFactorEnabled) {
+		t.Errorf("Begin after enabling should fail, got %v", err)
+	}
+
+	// 同一时间窗口的验证码不能重放
+	if err := s.Verify(user.ID, totpAt(t, setup.Secret, now, 0)); !errors.Is(err, ErrTwoFactorCode) {
+		t.Errorf("replayed code should fail, got %v", err)
+	}
+	now = now.Add(auth.TOTPPeriod)
+	if err := s.Verify(user.ID, totpAt(t, setup.Secret, now, 0)); err != nil {
+		t.Errorf("next window code should pass: %v", err)
+	}
+
+	// 恢复码只能使用一次
+	if err := s.Verify(user.ID, codes[0]); err != nil {
+		t.Errorf("recovery code should pass: %v", err)
+	}
+	if err := s.Verify(user.ID, codes[0]); !errors.Is(err, ErrTwoFactorCode) {
+		t.Errorf("used recovery code should fail, got %v", err)
+	}
+	if remaining, _ := s.RemainingRecoveryCodes(user.ID); remaining != recoveryCodeCount-1 {
+		t.Errorf("remaining = %d", remaining)
+	}
+
+	now = now.Add(auth.TOTPPeriod)
+	fresh, err := s.RegenerateRecoveryCodes(user.ID, totpAt(t, setup.Secret, now, 0))
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes error: %v", err)
+	}
+	if err := s.Verify(user.ID, codes[1]); !errors.Is(err, ErrTwoFactorCode) {
+		t.Errorf("old recovery code should be invalidated, got %v", err)
+	}
+
+	if err := s.Disable(user.ID, fresh[0]); err != nil {
+		t.Fatalf("Disable error: %v", err)
+	}
+	if enabled, _ := s.Enabled(user.ID); enabled {
+		t.Error("2fa should be disabled")
+	}
+}
+
+func TestTwoFactorService_LockoutAndRequired(t *testing.T) {
+	perms, db := newTestPermissionService(t)
+	now := time.Now()
+	s := newTestTwoFactorService(db, &now)
+
+	user := createUserWithRole(t, perms, "bob", "editor")
+	setup, _ := s.Begin(user.ID)
+	if _, err := s.Confirm(user.ID, totpAt(t, setup.Secret, now, 0)); err != nil {
+		t.Fatalf("Confirm error: %v", err)
+	}
+
+	for i := 1; i < maxTwoFactorAttempts; i++ {
+		if err := s.Verify(user.ID, "000000"); !errors.Is(err, ErrTwoFactorCode) {
+			t.Fatalf("attempt %d: got %v", i, err)
+		}
+	}
+	if err := s.Verify(user.ID, "000000"); !errors.Is(err, ErrTwoFactorLocked) {
+		t.Fatalf("expected lock after %d attempts, got %v", maxTwoFactorAttempts, err)
+	}
+
+	// 锁定期间正确的验证码也被拒绝
+	now = now.Add(auth.TOTPPeriod)
+	var locked *TwoFactorLockedError
+	if err := s.Verify(user.ID, totpAt(t, setup.Secret, now, 0)); !errors.As(err, &locked) || locked.RetryAfter <= 0 {
+		t.Fatalf("Verify while locked = %v, want TwoFactorLockedError", err)
+	}
+	if wait, _ := s.Locked(user.ID); wait <= 0 || wait > twoFactorLockout {
+		t.Fatalf("Locked = %v, want up to %v", wait, twoFactorLockout)
+	}
+
+	// 锁定结束后再输错一次立即重新锁定，时间翻倍
+	now = now.Add(twoFactorLockout)
+	if err := s.Verify(user.ID, "000000"); !errors.As(err, &locked) || locked.RetryAfter != 2*twoFactorLockout {
+		t.Fatalf("failure after lockout = %v, want lock for %v", err, 2*twoFactorLockout)
+	}
+	now = now.Add(2 * twoFactorLockout)
+	if err := s.Verify(user.ID, totpAt(t, setup.Secret, now, 0)); err != nil {
+		t.Fatalf("Verify after lockout error: %v", err)
+	}
+	var credential models.TwoFactorCredential
+	db.Where("user_id = ?", user.ID).First(&credential)
+	if credential.FailedAttempts != 0 || credential.LockedUntil != nil {
+		t.Fatalf("success should clear lock, got %d attempts until %v", credential.FailedAttempts, credential.LockedUntil)
+	}
+
+	// 角色强制两步验证时不能关闭
+	editor, _ := perms.GetRoleByName("editor")
+	if _, err := perms.SetRoleRequireTwoFactor(editor.ID, true); err != nil {
+		t.Fatalf("SetRoleRequireTwoFactor error: %v", err)
+	}
+	if required, _ := s.Required(user.ID); !required {
+		t.Error("editor role should require 2fa")
+	}
+	now = now.Add(auth.TOTPPeriod)
+	if err := s.Disable(user.ID, totpAt(t, setup.Secret, now, 0)); !errors.Is(err, ErrTwoFactorRequired) {
+		t.Errorf("expected required error, got %v", err)
+	}
+
+	if err := s.Reset(user.ID); err != nil {
+		t.Fatalf("Reset error: %v", err)
+	}
+	if enabled, _ := s.Enabled(user.ID); enabled {
+		t.Error("reset should disable 2fa")
+	}
+}
+
+func TestTwoFactorService_NormalizesCode(t *testing.T) {
+	perms, db := newTestPermissionService(t)
+	now := time.Now()
+	s := newTestTwoFactorService(db, &now)
+
+	user := createUserWithRole(t, perms, "dave", "author")
+	setup, _ := s.Begin(user.ID)
+	code := totpAt(t, setup.Secret, now, 0)
+	codes, err := s.Confirm(user.ID, " "+code[:3]+" "+code[3:]+" ")
+	if err != nil {
+		t.Fatalf("Confirm with spaces error: %v", err)
+	}
+
+	now = now.Add(auth.TOTPPeriod)
+	code = totpAt(t, setup.Secret, now, 0)
+	if err := s.Verify(user.ID, code[:3]+"-"+code[3:]); err != nil {
+		t.Errorf("Verify with dash error: %v", err)
+	}
+	now = now.Add(auth.TOTPPeriod)
+	code = totpAt(t, setup.Secret, now, 0)
+	if err := s.Verify(user.ID, code[:3]+" "+code[3:]); err != nil {
+		t.Errorf("Verify with space error: %v", err)
+	}
+
+	// 恢复码不区分大小写，连字符可以省略
+	if err := s.Verify(user.ID, strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))); err != nil {
+		t.Errorf("Verify recovery code error: %v", err)
+	}
+}
+
+func TestTwoFactorLockoutFor(t *testing.T) {
+	tests := map[int]time.Duration{
+		maxTwoFactorAttempts:      twoFactorLockout,
+		maxTwoFactorAttempts + 1:  2 * twoFactorLockout,
+		maxTwoFactorAttempts + 2:  4 * twoFactorLockout,
+		maxTwoFactorAttempts + 50: maxTwoFactorLockout,
+	}
+	for attempts, want := range tests {
+		if got := twoFactorLockoutFor(attempts); got != want {
+			t.Errorf("twoFactorLockoutFor(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestUserService_TwoStepLogin(t *testing.T) {
+	ctx := context.Background()
+	perms, db := newTestPermissionService(t)
+	now := time.Now()
+
+	store, _ := auth.NewRefreshTokenStore(db)
+	denylist, _ := auth.NewGormDenylist(db)
+	s := &UserService{
+		DB:        db,
+		Tokens:    auth.NewTokenManager(auth.Config{Secret: []byte("secret")}, store, denylist),
+		TwoFactor: newTestTwoFactorService(db, &now),
+	}
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	user := &models.User{Username: "carol", Email: "carol@example.com", Password: string(hashed)}
+	db.Create(user)
+
+	if _, tokens, err := s.Login(ctx, "carol", "password", auth.SessionMeta{}); err != nil || tokens == nil {
+		t.Fatalf("login without 2fa = %v, %v", tokens, err)
+	}
+
+	// 角色强制两步验证，登录过程中完成绑定
+	if _, err := perms.SyncUserRolesByName(user.ID, []string{"admin"}); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+	admin, _ := perms.GetRoleByName("admin")
+	perms.SetRoleRequireTwoFactor(admin.ID, true)
+
+	_, tokens, err := s.Login(ctx, "carol", "password", auth.SessionMeta{})
+	if !errors.Is(err, ErrMFAEnrollRequired) || tokens != nil {
+		t.Fatalf("expected enroll required, got %v, %v", tokens, err)
+	}
+	mfaToken, _, err := s.MFAChallenge(user.ID)
+	if err != nil {
+		t.Fatalf("MFAChallenge error: %v", err)
+	}
+	setup, err := s.BeginMFAEnrollment(ctx, mfaToken)
+	if err != nil {
+		t.Fatalf("BeginMFAEnrollment error: %v", err)
+	}
+	_, tokens, codes, err := s.VerifyMFA(ctx, mfaToken, totpAt(t, setup.Secret, now, 0), auth.SessionMeta{})
+	if err != nil || tokens == nil || len(codes) != recoveryCodeCount {
+		t.Fatalf("VerifyMFA enrollment = %v, %v, %v", tokens, codes, err)
+	}
+	if _, _, _, err := s.VerifyMFA(ctx, mfaToken, codes[0], auth.SessionMeta{}); !errors.Is(err, auth.ErrTokenRevoked) {
+		t.Errorf("mfa token should be single use, got %v", err)
+	}
+
+	// 已开启两步验证
+	_, _, err = s.Login(ctx, "carol", "password", auth.SessionMeta{})
+	if !errors.Is(err, ErrMFARequired) {
+		t.Fatalf("expected mfa required, got %v", err)
+	}
+	mfaToken, _, _ = s.MFAChallenge(user.ID)
+	if _, err := s.Tokens.Parse(ctx, mfaToken); err == nil {
+		t.Fatal("mfa token must not work as an access token")
+	}
+	_, tokens, codes, err = s.VerifyMFA(ctx, mfaToken, codes[0], auth.SessionMeta{})
+	if err != nil || tokens == nil || codes != nil {
+		t.Fatalf("VerifyMFA with recovery code = %v, %v, %v", tokens, codes, err)
+	}
+
+	// 连续输错后待完成令牌失效
+	mfaToken, _, _ = s.MFAChallenge(user.ID)
+	for i := 0; i < maxTwoFactorAttempts; i++ {
+		s.VerifyMFA(ctx, mfaToken, "000000", auth.SessionMeta{})
+	}
+	now = now.Add(auth.TOTPPeriod)
+	if _, _, _, err := s.VerifyMFA(ctx, mfaToken, totpAt(t, setup.Secret, now, 0), auth.SessionMeta{}); !errors.Is(err, auth.ErrTokenRevoked) {
+		t.Errorf("locked mfa token should be rejected, got %v", err)
+	}
+
+	// 重新输入密码后锁定仍然有效
+	mfaToken, _, _ = s.MFAChallenge(user.ID)
+	var locked *TwoFactorLockedError
+	if _, _, _, err := s.VerifyMFA(ctx, mfaToken, totpAt(t, setup.Secret, now, 0), auth.SessionMeta{}); !errors.As(err, &locked) {
+		t.Fatalf("VerifyMFA while locked = %v, want TwoFactorLockedError", err)
+	}
+	now = now.Add(twoFactorLockout)
+	if _, tokens, _, err := s.VerifyMFA(ctx, mfaToken, totpAt(t, setup.Secret, now, 0), auth.SessionMeta{}); err != nil || tokens == nil {
+		t.Fatalf("VerifyMFA after lockout = %v, %v", tokens, err)
+	}
+}
+
+func TestUserService_MFAFailuresCountTowardsLogin(t *testing.T) {
+	ctx := context.Background()
+	_, db := newTestPermissionService(t)
+	now := time.Now()
+
+	store, _ := auth.NewRefreshTokenStore(db)
+	denylist, _ := auth.NewGormDenylist(db)
+	s := &UserService{
+		DB:        db,
+		Tokens:    auth.NewTokenManager(auth.Config{Secret: []byte("secret")}, store, denylist),
+		TwoFactor: newTestTwoFactorService(db, &now),
+		Throttle:  newTestLoginThrottle(t, &config.AuthConfig{LoginMaxAttempts: 3, LoginLockout: 15 * time.Minute}, &now),
+	}
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	user := &models.User{Username: "frank", Email: "frank@example.com", Password: string(hashed)}
+	db.Create(user)
+	setup, _ := s.TwoFactor.Begin(user.ID)
+	if _, err := s.TwoFactor.Confirm(user.ID, totpAt(t, setup.Secret, now, 0)); err != nil {
+		t.Fatalf("Confirm error: %v", err)
+	}
+
+	meta := auth.SessionMeta{IP: "10.0.0.1"}
+	if _, _, err := s.Login(ctx, "frank", "password", meta); !errors.Is(err, ErrMFARequired) {
+		t.Fatalf("expected mfa required, got %v", err)
+	}
+	mfaToken, _, _ := s.MFAChallenge(user.ID)
+	for i := 0; i < 3; i++ {
+		if _, _, _, err := s.VerifyMFA(ctx, mfaToken, "000000", meta); !errors.Is(err, ErrTwoFactorCode) {
+			t.Fatalf("attempt %d = %v, want ErrTwoFactorCode", i+1, err)
+		}
+		// 跳过递增延迟
+		now = now.Add(time.Minute)
+	}
+
+	// 账户已被锁定，正确的密码和验证码都被拒绝
+	db.First(user, user.ID)
+	if user.LockedUntil == nil {
+		t.Fatal("two-factor failures should lock the account")
+	}
+	if _, _, err := s.Login(ctx, "frank", "password", meta); !errors.Is(err, ErrLoginThrottled) {
+		t.Errorf("Login while locked = %v, want ErrLoginThrottled", err)
+	}
+	if _, _, _, err := s.VerifyMFA(ctx, mfaToken, totpAt(t, setup.Secret, now, 0), meta); !errors.Is(err, ErrLoginThrottled) {
+		t.Errorf("VerifyMFA while locked = %v, want ErrLoginThrottled", err)
+	}
+
+	if err := s.Unlock(user.ID); err != nil {
+		t.Fatalf("Unlock error: %v", err)
+	}
+	if _, tokens, _, err := s.VerifyMFA(ctx, mfaToken, totpAt(t, setup.Secret, now, 0), meta); err != nil || tokens == nil {
+		t.Fatalf("VerifyMFA after unlock = %v, %v", tokens, err)
+	}
+}
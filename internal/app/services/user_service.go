@@ -1,25 +1,107 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/clarkzhu2020/aidecms/config"
 	"github.com/clarkzhu2020/aidecms/internal/app/models"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
+	envConfig "github.com/clarkzhu2020/aidecms/pkg/config"
+	"github.com/clarkzhu2020/aidecms/pkg/event"
+	"github.com/clarkzhu2020/aidecms/pkg/mail"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrTokenServiceUnavailable 令牌管理器未能初始化
+	ErrTokenServiceUnavailable = errors.New("令牌服务不可用")
+
+	// ErrMFARequired 密码正确，需要提交两步验证码才能完成登录
+	ErrMFARequired = errors.New("需要两步验证")
+
+	// ErrMFAEnrollRequired 密码正确，但用户的角色要求开启两步验证，需要先完成绑定
+	ErrMFAEnrollRequired = errors.New("需要先开启两步验证")
+
+	// ErrEmailNotVerified 开启了 AUTH_REQUIRE_VERIFIED_EMAIL，用户尚未验证邮箱
+	ErrEmailNotVerified = errors.New("邮箱尚未验证")
+
+	// ErrMailUnavailable 邮件服务未配置
+	ErrMailUnavailable = errors.New("邮件服务不可用")
+
+	// ErrInvalidCredentials 用户名不存在和密码错误返回同一个错误，避免暴露用户名是否存在
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+
+	// ErrLoginThrottled 登录失败次数过多，需要等待后重试，具体等待时间见 LoginThrottledError
+	ErrLoginThrottled = errors.New("登录失败次数过多，请稍后再试")
+)
+
+// LoginThrottledError 登录被限制时返回的错误，RetryAfter 为还需等待的时间
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+// Error 实现 error 接口
+func (e *LoginThrottledError) Error() string {
+	return ErrLoginThrottled.Error()
+}
+
+// Unwrap 支持 errors.Is(err, ErrLoginThrottled)
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
+
+// Mailer 发送模板邮件，由 *mail.MailService 实现
+type Mailer interface {
+	SendTemplate(templateName string, data interface{}, m *mail.Mail) error
+}
+
 // UserService 处理用户相关的业务逻辑
 type UserService struct {
-	DB *gorm.DB
+	DB        *gorm.DB
+	Tokens    *auth.TokenManager
+	TwoFactor *TwoFactorService
+	Links     *auth.SignedTokens // 密码重置和邮箱验证链接的令牌
+	Mailer    Mailer
+	Auth      *config.AuthConfig
+	Throttle  *LoginThrottle
+	AppName   string
 }
 
 // NewUserService 创建用户服务实例
 func NewUserService() *UserService {
+	tokens, err := config.LoadTokenManager()
+	if err != nil {
+		hlog.Errorf("failed to load token manager: %v", err)
+	}
+	links, err := config.LoadSignedTokens()
+	if err != nil {
+		hlog.Errorf("failed to load signed tokens: %v", err)
+	}
+	var mailer Mailer
+	if mailService, err := mail.NewMailService(); err != nil {
+		hlog.Errorf("failed to load mail service: %v", err)
+	} else {
+		mailer = mailService
+	}
+	config.InitAuth()
+
 	return &UserService{
-		DB: config.DB,
+		DB:        config.DB,
+		Tokens:    tokens,
+		TwoFactor: NewTwoFactorService(),
+		Links:     links,
+		Mailer:    mailer,
+		Auth:      config.Auth,
+		Throttle:  NewLoginThrottle(config.Auth),
+		AppName:   envConfig.GetEnv("APP_NAME", "AideCMS"),
 	}
 }
 
@@ -55,39 +137,231 @@ func (s *UserService) Register(username, email, password, firstName, lastName st
 		return nil, err
 	}
 
+	// 新用户默认为 user 角色（由 cms:init 创建）
+	var role models.Role
+	if err := s.DB.Where("name = ?", "user").First(&role).Error; err == nil {
+		if err := s.DB.Model(user).Association("Roles").Append(&role); err != nil {
+			return nil, err
+		}
+	}
+
 	return user, nil
 }
 
-// Login 用户登录
-func (s *UserService) Login(usernameOrEmail, password string) (*models.User, string, error) {
+// Login 用户登录，验证通过后创建新会话
+// 用户名不存在和密码错误都返回 ErrInvalidCredentials；连续失败后需要等待递增的时间，
+// 达到 LoginMaxAttempts 次后锁定账户，期间返回 *LoginThrottledError
+// 开启了 AUTH_REQUIRE_VERIFIED_EMAIL 时，未验证邮箱的用户返回 ErrEmailNotVerified
+// 开启了两步验证的用户返回 ErrMFARequired，角色要求两步验证但未开启的用户返回 ErrMFAEnrollRequired，
+// 此时不签发令牌，调用方使用 MFAChallenge 签发待完成令牌
+func (s *UserService) Login(ctx context.Context, usernameOrEmail, password string, meta auth.SessionMeta) (*models.User, *auth.TokenPair, error) {
 	var user models.User
 
 	// 查找用户（通过用户名或邮箱）
 	result := s.DB.Where("username = ? OR email = ?", usernameOrEmail, usernameOrEmail).First(&user)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, "", errors.New("用户不存在")
-		}
-		return nil, "", result.Error
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil, result.Error
+	}
+	found := result.Error == nil
+
+	account := loginAccountKey(usernameOrEmail, user.ID)
+	if wait := s.Throttle.Wait(account, meta.IP); wait > 0 {
+		return nil, nil, &LoginThrottledError{RetryAfter: wait}
+	}
+	now := time.Now()
+	if found && user.IsLocked(now) {
+		return nil, nil, &LoginThrottledError{RetryAfter: user.LockedUntil.Sub(now)}
+	}
+
+	if !found {
+		// 用户不存在时同样计算一次哈希，避免通过响应时间判断用户名是否存在
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		s.loginFailed(account, usernameOrEmail, nil, meta, "unknown_user")
+		return nil, nil, ErrInvalidCredentials
 	}
 
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, "", errors.New("密码错误")
+		s.loginFailed(account, usernameOrEmail, &user, meta, "invalid_password")
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	s.Throttle.Success(account)
+	if user.LockedUntil != nil {
+		if err := s.DB.Model(&user).Update("locked_until", nil).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return s.LoginUser(ctx, &user, meta)
+}
+
+// LoginUser 已通过身份验证（密码、第三方登录或钱包签名）的用户登录，
+// 与密码登录执行相同的邮箱验证和两步验证检查，返回的错误与 Login 相同
+func (s *UserService) LoginUser(ctx context.Context, user *models.User, meta auth.SessionMeta) (*models.User, *auth.TokenPair, error) {
+	if s.Auth != nil && s.Auth.RequireVerifiedEmail && !user.IsEmailVerified() {
+		return user, nil, ErrEmailNotVerified
+	}
+
+	// 两步验证
+	enabled, err := s.TwoFactor.Enabled(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if enabled {
+		return user, nil, ErrMFARequired
+	}
+	required, err := s.TwoFactor.Required(user.ID)
+	if err != nil {
+		return nil, nil, err
 	}
+	if required {
+		return user, nil, ErrMFAEnrollRequired
+	}
+
+	return s.completeLogin(ctx, user, meta)
+}
+
+// MFAChallenge 签发两步验证待完成令牌，返回令牌和有效期（秒）
+func (s *UserService) MFAChallenge(userID uint) (string, int64, error) {
+	if s.Tokens == nil {
+		return "", 0, ErrTokenServiceUnavailable
+	}
+	return s.Tokens.IssueMFAToken(userID)
+}
+
+// BeginMFAEnrollment 角色要求两步验证的用户在登录过程中使用待完成令牌开始绑定
+func (s *UserService) BeginMFAEnrollment(ctx context.Context, mfaToken string) (*TwoFactorSetup, error) {
+	if s.Tokens == nil {
+		return nil, ErrTokenServiceUnavailable
+	}
+	claims, err := s.Tokens.ParseMFAToken(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.TwoFactor.Begin(claims.UserID)
+}
+
+// VerifyMFA 使用待完成令牌和验证码完成登录
+// 登录过程中绑定的用户提交验证码即确认绑定，同时返回恢复码；已开启的用户也可以提交恢复码
+// 输错的验证码与密码错误一样计入账户的登录失败次数；两步验证连续输错后锁定，
+// 锁定时待完成令牌失效，锁定期间返回 *TwoFactorLockedError，账户被锁定时返回 *LoginThrottledError
+func (s *UserService) VerifyMFA(ctx context.Context, mfaToken, code string, meta auth.SessionMeta) (*models.User, *auth.TokenPair, []string, error) {
+	if s.Tokens == nil {
+		return nil, nil, nil, ErrTokenServiceUnavailable
+	}
+	claims, err := s.Tokens.ParseMFAToken(ctx, mfaToken)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var user models.User
+	if err := s.DB.First(&user, claims.UserID).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	account := loginAccountKey("", user.ID)
+	if wait := s.Throttle.Wait(account, meta.IP); wait > 0 {
+		return nil, nil, nil, &LoginThrottledError{RetryAfter: wait}
+	}
+	if now := time.Now(); user.IsLocked(now) {
+		return nil, nil, nil, &LoginThrottledError{RetryAfter: user.LockedUntil.Sub(now)}
+	}
+	locked, err := s.TwoFactor.Locked(user.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if locked > 0 {
+		return nil, nil, nil, &TwoFactorLockedError{RetryAfter: locked}
+	}
+
+	enabled, err := s.TwoFactor.Enabled(claims.UserID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var recoveryCodes []string
+	if enabled {
+		err = s.TwoFactor.Verify(claims.UserID, code)
+	} else {
+		recoveryCodes, err = s.TwoFactor.Confirm(claims.UserID, code)
+	}
+	if errors.Is(err, ErrTwoFactorCode) || errors.Is(err, ErrTwoFactorLocked) {
+		s.loginFailed(account, "", &user, meta, "invalid_two_factor_code")
+	}
+	if errors.Is(err, ErrTwoFactorLocked) {
+		if consumeErr := s.Tokens.ConsumeMFAToken(ctx, claims); consumeErr != nil {
+			return nil, nil, nil, consumeErr
+		}
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// 待完成令牌只能使用一次
+	if err := s.Tokens.ConsumeMFAToken(ctx, claims); err != nil {
+		return nil, nil, nil, err
+	}
+	s.Throttle.Success(account)
 
+	_, tokens, err := s.completeLogin(ctx, &user, meta)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &user, tokens, recoveryCodes, nil
+}
+
+// completeLogin 更新最后登录时间并签发令牌
+func (s *UserService) completeLogin(ctx context.Context, user *models.User, meta auth.SessionMeta) (*models.User, *auth.TokenPair, error) {
 	// 更新最后登录时间
 	now := time.Now()
 	user.LastLogin = &now
-	s.DB.Save(&user)
+	s.DB.Save(user)
 
-	// 生成JWT令牌
-	token, err := s.GenerateJWT(user.ID)
+	// 签发令牌
+	tokens, err := s.IssueTokens(ctx, user.ID, meta)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
-	return &user, token, nil
+	dispatchEvent(event.NewUserLoggedIn(user.ID, meta.IP))
+	return user, tokens, nil
+}
+
+// loginFailed 记录一次登录失败，失败次数达到上限时锁定账户
+// 已存在的用户锁定状态保存到数据库，不存在的用户名只在内存中锁定
+func (s *UserService) loginFailed(account, identifier string, user *models.User, meta auth.SessionMeta, reason string) {
+	var userID uint
+	if user != nil {
+		userID = user.ID
+	}
+	dispatchEvent(event.NewLoginFailed(identifier, userID, meta.IP, reason))
+
+	if !s.Throttle.Failure(account, meta.IP) {
+		return
+	}
+	if user == nil {
+		s.Throttle.Lock(account)
+		return
+	}
+
+	until := time.Now().Add(s.Throttle.lockout)
+	if err := s.DB.Model(user).Update("locked_until", until).Error; err != nil {
+		hlog.Errorf("failed to lock user %d: %v", user.ID, err)
+		return
+	}
+	dispatchEvent(event.NewUserLockedOut(user.ID, meta.IP, until))
+}
+
+// Unlock 解除账户的登录锁定和两步验证连续输错造成的锁定
+func (s *UserService) Unlock(userID uint) error {
+	if err := s.DB.Model(&models.User{}).Where("id = ?", userID).Update("locked_until", nil).Error; err != nil {
+		return err
+	}
+	if err := s.DB.Model(&models.TwoFactorCredential{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"failed_attempts": 0, "locked_until": nil}).Error; err != nil {
+		return err
+	}
+	s.Throttle.Success(loginAccountKey("", userID))
+	return nil
 }
 
 // GetUserByID 通过ID获取用户
@@ -123,53 +397,237 @@ func (s *UserService) UpdateProfile(id uint, firstName, lastName, avatar string)
 	return &user, nil
 }
 
-// GenerateJWT 生成JWT令牌
-func (s *UserService) GenerateJWT(userID uint) (string, error) {
-	// 创建JWT声明
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * time.Duration(config.JWT.ExpiresIn)).Unix(),
-		"iat":     time.Now().Unix(),
+// IssueTokens 为用户创建新会话并签发访问令牌和刷新令牌
+func (s *UserService) IssueTokens(ctx context.Context, userID uint, meta auth.SessionMeta) (*auth.TokenPair, error) {
+	if s.Tokens == nil {
+		return nil, ErrTokenServiceUnavailable
+	}
+	return s.Tokens.Issue(ctx, userID, meta)
+}
+
+// RefreshTokens 使用刷新令牌换取新的令牌
+func (s *UserService) RefreshTokens(ctx context.Context, refreshToken string, meta auth.SessionMeta) (*auth.TokenPair, error) {
+	if s.Tokens == nil {
+		return nil, ErrTokenServiceUnavailable
+	}
+	return s.Tokens.Refresh(ctx, refreshToken, meta)
+}
+
+// ParseToken 校验访问令牌，已注销的令牌返回错误
+func (s *UserService) ParseToken(ctx context.Context, tokenString string) (*auth.Claims, error) {
+	if s.Tokens == nil {
+		return nil, ErrTokenServiceUnavailable
 	}
+	return s.Tokens.Parse(ctx, tokenString)
+}
 
-	// 创建令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+// JWKS 返回用于校验访问令牌的公钥
+func (s *UserService) JWKS() (auth.JWKSet, error) {
+	if s.Tokens == nil {
+		return auth.JWKSet{}, ErrTokenServiceUnavailable
+	}
+	return s.Tokens.JWKS()
+}
+
+// Logout 注销当前会话，all 为true时注销用户的所有会话
+func (s *UserService) Logout(ctx context.Context, claims *auth.Claims, all bool) error {
+	if s.Tokens == nil {
+		return ErrTokenServiceUnavailable
+	}
+	if err := s.Tokens.Logout(ctx, claims); err != nil {
+		return err
+	}
+	if all {
+		return s.Tokens.RevokeAll(ctx, claims.UserID)
+	}
+	return nil
+}
+
+// ChangePassword 修改密码并注销用户的所有会话
+func (s *UserService) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	var user models.User
+	if err := s.DB.First(&user, userID).Error; err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return errors.New("当前密码错误")
+	}
 
-	// 签名令牌
-	tokenString, err := token.SignedString([]byte(config.JWT.SecretKey))
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return "", err
+		return err
+	}
+	if err := s.DB.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
+		return err
 	}
 
-	return tokenString, nil
+	if s.Tokens == nil {
+		return ErrTokenServiceUnavailable
+	}
+	return s.Tokens.RevokeAll(ctx, userID)
 }
 
-// ParseJWT 解析JWT令牌
-func (s *UserService) ParseJWT(tokenString string) (uint, error) {
-	// 解析令牌
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.JWT.SecretKey), nil
-	})
+// SendPasswordReset 发送密码重置邮件，邮箱不存在时不做任何事，避免泄露账户是否存在
+func (s *UserService) SendPasswordReset(email string) error {
+	if s.Links == nil || s.Mailer == nil {
+		return ErrMailUnavailable
+	}
+
+	var user models.User
+	if err := s.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
 
+	token := s.Links.Sign(auth.PurposePasswordReset, user.ID, resetBinding(&user), s.Auth.PasswordResetTTL)
+	return s.sendLink(&user, "password_reset", "重置密码", s.Auth.PasswordResetURL, token, s.Auth.PasswordResetTTL)
+}
+
+// ResetPassword 使用重置链接中的令牌设置新密码，并注销用户的所有会话
+// 令牌绑定了旧密码，密码修改后同一个链接不能再次使用
+func (s *UserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if s.Links == nil {
+		return ErrTokenServiceUnavailable
+	}
+
+	user, err := s.linkUser(token)
 	if err != nil {
-		return 0, err
+		return err
+	}
+	if err := s.Links.Verify(auth.PurposePasswordReset, token, resetBinding(user)); err != nil {
+		return err
 	}
 
-	if !token.Valid {
-		return 0, errors.New("无效的令牌")
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	updates := map[string]interface{}{"password": string(hashedPassword)}
+	// 能收到重置邮件说明邮箱属于该用户
+	if !user.IsEmailVerified() {
+		updates["email_verified_at"] = time.Now()
+	}
+	// 条件更新，并发使用同一个链接时只有一个请求成功
+	result := s.DB.Model(&models.User{}).Where("id = ? AND password = ?", user.ID, user.Password).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrInvalidToken
 	}
 
-	// 获取声明
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, errors.New("无效的令牌声明")
+	if s.Tokens == nil {
+		return ErrTokenServiceUnavailable
 	}
+	return s.Tokens.RevokeAll(ctx, user.ID)
+}
 
-	// 获取用户ID
-	userID, ok := claims["user_id"].(float64)
-	if !ok {
-		return 0, errors.New("无效的用户ID")
+// SendVerificationEmail 发送邮箱验证邮件，邮箱不存在或已验证时不做任何事
+func (s *UserService) SendVerificationEmail(email string) error {
+	if s.Links == nil || s.Mailer == nil {
+		return ErrMailUnavailable
 	}
 
-	return uint(userID), nil
+	var user models.User
+	if err := s.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	token := s.Links.Sign(auth.PurposeVerifyEmail, user.ID, user.Email, s.Auth.VerifyEmailTTL)
+	return s.sendLink(&user, "verify_email", "验证邮箱", s.Auth.VerifyEmailURL, token, s.Auth.VerifyEmailTTL)
+}
+
+// VerifyEmail 使用验证链接中的令牌验证邮箱，已验证时直接返回用户
+// 令牌绑定了邮箱地址，修改邮箱后旧链接失效
+func (s *UserService) VerifyEmail(token string) (*models.User, error) {
+	if s.Links == nil {
+		return nil, ErrTokenServiceUnavailable
+	}
+
+	user, err := s.linkUser(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Links.Verify(auth.PurposeVerifyEmail, token, user.Email); err != nil {
+		return nil, err
+	}
+	if user.IsEmailVerified() {
+		return user, nil
+	}
+
+	now := time.Now()
+	if err := s.DB.Model(user).Update("email_verified_at", &now).Error; err != nil {
+		return nil, err
+	}
+	user.EmailVerifiedAt = &now
+	return user, nil
+}
+
+// linkUser 查找链接令牌对应的用户，用户不存在时视为无效令牌
+func (s *UserService) linkUser(token string) (*models.User, error) {
+	userID, err := s.Links.UserID(token)
+	if err != nil {
+		return nil, err
+	}
+	var user models.User
+	if err := s.DB.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrInvalidToken
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// sendLink 发送包含链接令牌的模板邮件
+func (s *UserService) sendLink(user *models.User, templateName, subject, baseURL, token string, ttl time.Duration) error {
+	link := baseURL + "?token=" + url.QueryEscape(token)
+	if strings.Contains(baseURL, "?") {
+		link = baseURL + "&token=" + url.QueryEscape(token)
+	}
+
+	data := map[string]interface{}{
+		"AppName":   s.AppName,
+		"Username":  user.Username,
+		"URL":       link,
+		"ExpiresIn": humanizeDuration(ttl),
+	}
+	return s.Mailer.SendTemplate(templateName, data, &mail.Mail{
+		To:      []string{user.Email},
+		Subject: s.AppName + " " + subject,
+	})
+}
+
+// resetBinding 密码重置令牌绑定的用户状态
+func resetBinding(user *models.User) string {
+	return user.Password + "|" + user.Email
+}
+
+// humanizeDuration 邮件中显示的有效期
+func humanizeDuration(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d 小时", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%d 分钟", int(d/time.Minute))
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// dummyPasswordHash 用户不存在时用于比较的密码哈希，使响应时间与密码错误时一致
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("aidecms-login-timing"), bcrypt.DefaultCost)
+	})
+	return dummyHash
 }
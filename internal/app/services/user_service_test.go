@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/config"
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
+	"github.com/clarkzhu2020/aidecms/pkg/event"
+	"github.com/clarkzhu2020/aidecms/pkg/mail"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// sentMail 测试中记录发送的模板邮件
+type sentMail struct {
+	template string
+	data     map[string]interface{}
+	mail     *mail.Mail
+}
+
+type fakeMailer struct {
+	sent []sentMail
+}
+
+func (m *fakeMailer) SendTemplate(templateName string, data interface{}, msg *mail.Mail) error {
+	m.sent = append(m.sent, sentMail{template: templateName, data: data.(map[string]interface{}), mail: msg})
+	return nil
+}
+
+// linkToken 取出最近一封邮件链接中的令牌
+func (m *fakeMailer) linkToken(t *testing.T) string {
+	t.Helper()
+	if len(m.sent) == 0 {
+		t.Fatal("no mail sent")
+	}
+	link, err := url.Parse(m.sent[len(m.sent)-1].data["URL"].(string))
+	if err != nil {
+		t.Fatalf("parse link: %v", err)
+	}
+	return link.Query().Get("token")
+}
+
+func newTestAccountService(t *testing.T) (*UserService, *fakeMailer) {
+	t.Helper()
+
+	_, db := newTestPermissionService(t)
+	store, _ := auth.NewRefreshTokenStore(db)
+	denylist, _ := auth.NewGormDenylist(db)
+	mailer := &fakeMailer{}
+	now := time.Now()
+	return &UserService{
+		DB:        db,
+		Tokens:    auth.NewTokenManager(auth.Config{Secret: []byte("secret")}, store, denylist),
+		TwoFactor: newTestTwoFactorService(db, &now),
+		Links:     auth.NewSignedTokens([]byte("0123456789abcdef0123456789abcdef")),
+		Mailer:    mailer,
+		Auth: &config.AuthConfig{
+			PasswordResetTTL: time.Hour,
+			VerifyEmailTTL:   24 * time.Hour,
+			PasswordResetURL: "https://cms.example.com/reset-password",
+			VerifyEmailURL:   "https://cms.example.com/verify-email",
+		},
+		AppName: "AideCMS",
+	}, mailer
+}
+
+func TestUserService_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+	s, mailer := newTestAccountService(t)
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	user := &models.User{Username: "dave", Email: "dave@example.com", Password: string(hashed)}
+	s.DB.Create(user)
+	_, session, err := s.Login(ctx, "dave", "old-password", auth.SessionMeta{})
+	if err != nil {
+		t.Fatalf("Login error: %v", err)
+	}
+
+	if err := s.SendPasswordReset("nobody@example.com"); err != nil || len(mailer.sent) != 0 {
+		t.Fatalf("unknown email should be ignored silently: %v, %d sent", err, len(mailer.sent))
+	}
+	if err := s.SendPasswordReset("dave@example.com"); err != nil {
+		t.Fatalf("SendPasswordReset error: %v", err)
+	}
+	sent := mailer.sent[0]
+	if sent.template != "password_reset" || sent.mail.To[0] != "dave@example.com" || sent.data["ExpiresIn"] != "1 小时" {
+		t.Errorf("unexpected mail %+v", sent)
+	}
+	token := mailer.linkToken(t)
+
+	if err := s.ResetPassword(ctx, token, "new-password"); err != nil {
+		t.Fatalf("ResetPassword error: %v", err)
+	}
+	if err := s.ResetPassword(ctx, token, "another-password"); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("reset link should be single use, got %v", err)
+	}
+	if _, _, err := s.Login(ctx, "dave", "new-password", auth.SessionMeta{}); err != nil {
+		t.Errorf("login with new password: %v", err)
+	}
+	if _, err := s.ParseToken(ctx, session.AccessToken); !errors.Is(err, auth.ErrTokenRevoked) {
+		t.Errorf("existing sessions should be revoked, got %v", err)
+	}
+
+	// 重置密码同时验证了邮箱
+	var reloaded models.User
+	s.DB.First(&reloaded, user.ID)
+	if !reloaded.IsEmailVerified() {
+		t.Error("password reset should verify the email")
+	}
+}
+
+func TestUserService_EmailVerification(t *testing.T) {
+	ctx := context.Background()
+	s, mailer := newTestAccountService(t)
+	s.Auth.RequireVerifiedEmail = true
+
+	user, err := s.Register("erin", "erin@example.com", "password", "", "")
+	if err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	if _, _, err := s.Login(ctx, "erin", "password", auth.SessionMeta{}); !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("unverified login should be blocked, got %v", err)
+	}
+
+	if err := s.SendVerificationEmail("erin@example.com"); err != nil {
+		t.Fatalf("SendVerificationEmail error: %v", err)
+	}
+	if mailer.sent[0].template != "verify_email" {
+		t.Errorf("unexpected template %s", mailer.sent[0].template)
+	}
+	token := mailer.linkToken(t)
+
+	// 密码重置令牌不能用于验证邮箱
+	if _, err := s.VerifyEmail(s.Links.Sign(auth.PurposePasswordReset, user.ID, user.Email, time.Hour)); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("reset token used for verification: %v", err)
+	}
+
+	verified, err := s.VerifyEmail(token)
+	if err != nil || !verified.IsEmailVerified() {
+		t.Fatalf("VerifyEmail = %+v, %v", verified, err)
+	}
+	if _, err := s.VerifyEmail(token); err != nil {
+		t.Errorf("verifying twice should succeed: %v", err)
+	}
+	if _, _, err := s.Login(ctx, "erin", "password", auth.SessionMeta{}); err != nil {
+		t.Errorf("verified login: %v", err)
+	}
+
+	sent := len(mailer.sent)
+	if err := s.SendVerificationEmail("erin@example.com"); err != nil || len(mailer.sent) != sent {
+		t.Errorf("verified users should not get another mail: %v", err)
+	}
+
+	// 修改邮箱后旧链接失效
+	other, _ := s.Register("frank", "frank@example.com", "password", "", "")
+	s.SendVerificationEmail("frank@example.com")
+	token = mailer.linkToken(t)
+	s.DB.Model(other).Update("email", "frank@new.example.com")
+	if _, err := s.VerifyEmail(token); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("link for old email should fail, got %v", err)
+	}
+}
+
+func TestUserService_LoginLockout(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestAccountService(t)
+	now := time.Now()
+	s.Throttle = newTestLoginThrottle(t, &config.AuthConfig{LoginMaxAttempts: 3, LoginLockout: 15 * time.Minute}, &now)
+
+	var locked []*event.UserLockedOut
+	event.GetDispatcher().ListenWithOptions("user.locked_out", "test", func(ctx context.Context, e event.Event) error {
+		locked = append(locked, e.(*event.UserLockedOut))
+		return nil
+	}, 0, false)
+	t.Cleanup(func() { event.Forget("user.locked_out", "test") })
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	user := &models.User{Username: "erin", Email: "erin@example.com", Password: string(hashed)}
+	s.DB.Create(user)
+	meta := auth.SessionMeta{IP: "10.0.0.1"}
+
+	// 用户不存在和密码错误返回相同的错误
+	if _, _, err := s.Login(ctx, "nobody", "password", meta); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("unknown user error = %v, want ErrInvalidCredentials", err)
+	}
+	if _, _, err := s.Login(ctx, "erin", "wrong", meta); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password error = %v, want ErrInvalidCredentials", err)
+	}
+
+	// 第二次失败后需要等待，期间即使密码正确也不能登录
+	if _, _, err := s.Login(ctx, "erin@example.com", "wrong", meta); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password error = %v, want ErrInvalidCredentials", err)
+	}
+	_, _, err := s.Login(ctx, "erin", "password", meta)
+	var throttled *LoginThrottledError
+	if !errors.As(err, &throttled) || throttled.RetryAfter != time.Second {
+		t.Fatalf("Login during delay = %v, want LoginThrottledError(1s)", err)
+	}
+
+	now = now.Add(time.Second)
+	if _, _, err := s.Login(ctx, "erin", "wrong", meta); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password error = %v, want ErrInvalidCredentials", err)
+	}
+	if len(locked) != 1 || locked[0].UserID != user.ID {
+		t.Fatalf("locked events = %v, want one for user %d", locked, user.ID)
+	}
+
+	// 锁定保存在数据库中，正确的密码也被拒绝
+	if _, _, err := s.Login(ctx, "erin", "password", meta); !errors.Is(err, ErrLoginThrottled) {
+		t.Fatalf("Login while locked = %v, want ErrLoginThrottled", err)
+	}
+	var stored models.User
+	s.DB.First(&stored, user.ID)
+	if !stored.IsLocked(time.Now()) {
+		t.Fatal("user should be locked in database")
+	}
+
+	if err := s.Unlock(user.ID); err != nil {
+		t.Fatalf("Unlock error: %v", err)
+	}
+	if _, tokens, err := s.Login(ctx, "erin", "password", meta); err != nil || tokens == nil {
+		t.Fatalf("Login after unlock = %v", err)
+	}
+}
@@ -1,11 +1,8 @@
 package main
 
 import (
-	"context"
-
 	_ "github.com/clarkzhu2020/aidecms/docs" // Swagger docs
 	"github.com/clarkzhu2020/aidecms/pkg/framework"
-	"github.com/clarkzhu2020/aidecms/pkg/swagger"
 	"github.com/clarkzhu2020/aidecms/routes"
 )
 
@@ -37,43 +34,8 @@ func main() {
 		SetDebug(true).
 		Boot()
 
-	// 注册API路由
-	routes.APIRoutes(app)
-
-	// 注册其他路由
-	app.RegisterRoutes(func(router *framework.Router) {
-		// Swagger文档路由 - 访问 http://localhost:8888/swagger/index.html
-		router.GET("/swagger/*any", swagger.SwaggerHandler())
-
-		// 基本API路由
-		api := router.Group("/api")
-		{
-			api.GET("/ping", func(ctx context.Context, c *framework.RequestContext) {
-				c.JSON(200, map[string]interface{}{
-					"message": "pong",
-				})
-			})
-		}
-
-		// 监控路由
-		router.GET("/metrics", framework.PrometheusHandler())
-
-		// Web路由
-		router.GET("/", func(ctx context.Context, c *framework.RequestContext) {
-			c.String(200, "Welcome to AideCMS with AI capabilities! See /doc/ai.md for AI integration guide.")
-		})
-	})
-
-	// 注册中间件
-	app.RegisterMiddleware(
-		framework.Cors(),
-		framework.Recovery(),
-		framework.Logger(),
-		framework.PrometheusMiddleware(),
-	)
-
-	// 注册静态文件目录
-	app.Static("/public", app.GetPublicPath())
+	// 注册中间件和路由
+	routes.Register(app)
 
 	// 运行应用
 	app.Run()
@@ -19,13 +19,16 @@ type Config struct {
 
 // Message 消息结构
 type Message struct {
-	Role    string `json:"role"` // system, user, assistant
-	Content string `json:"content"`
+	Role       string     `json:"role"` // system, user, assistant, tool
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // 助手消息：模型请求的工具调用
+	ToolCallID string     `json:"tool_call_id,omitempty"` // 工具消息：对应的调用ID
 }
 
 // Client AI客户端
 type Client struct {
-	config *Config
+	config   *Config
+	provider Provider
 }
 
 // NewClient 创建AI客户端
@@ -42,8 +45,14 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("model is required")
 	}
 
+	provider, err := newProvider(config)
+	if err != nil {
+		return nil, err
+	}
+
 	client := &Client{
-		config: config,
+		config:   config,
+		provider: provider,
 	}
 
 	return client, nil
@@ -51,23 +60,29 @@ func NewClient(config *Config) (*Client, error) {
 
 // ChatRequest 聊天请求
 type ChatRequest struct {
-	Messages    []*Message `json:"messages"`
-	Temperature *float64   `json:"temperature,omitempty"`
-	MaxTokens   *int       `json:"max_tokens,omitempty"`
-	Stream      bool       `json:"stream,omitempty"`
+	Messages    []*Message        `json:"messages"`
+	Temperature *float64          `json:"temperature,omitempty"`
+	MaxTokens   *int              `json:"max_tokens,omitempty"`
+	Stream      bool              `json:"stream,omitempty"`
+	Tools       []*ToolDefinition `json:"tools,omitempty"`
+	ToolChoice  string            `json:"tool_choice,omitempty"` // auto、none、required 或工具名称
 }
 
 // ChatResponse 聊天响应
 type ChatResponse struct {
-	Message *Message `json:"message"`
-	Usage   *Usage   `json:"usage,omitempty"`
+	Message  *Message         `json:"message"`
+	Usage    *Usage           `json:"usage,omitempty"`
+	Metadata *RoutingMetadata `json:"metadata,omitempty"` // 经 Manager 路由时填充
 }
 
 // StreamResponse 流式响应
 type StreamResponse struct {
 	Delta   string `json:"delta"`
-	Message string `json:"message"`
+	Message string `json:"message"` // 已累计的完整内容
 	Done    bool   `json:"done"`
+	Usage   *Usage `json:"usage,omitempty"` // 仅在Done时返回（提供商支持时）
+
+	Metadata *RoutingMetadata `json:"metadata,omitempty"` // 经 Manager 路由时在Done时返回
 }
 
 // Usage 使用统计
@@ -79,54 +94,36 @@ type Usage struct {
 
 // Chat 聊天对话
 func (c *Client) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
-	// TODO: 集成实际的eino实现
-	// 这里先返回一个模拟响应
-	return &ChatResponse{
-		Message: &Message{
-			Role:    "assistant",
-			Content: "This is a mock response. Eino integration will be implemented soon.",
-		},
-		Usage: &Usage{
-			PromptTokens:     10,
-			CompletionTokens: 20,
-			TotalTokens:      30,
-		},
-	}, nil
+	if req == nil || len(req.Messages) == 0 {
+		return nil, fmt.Errorf("messages cannot be empty")
+	}
+	return c.provider.Chat(ctx, req)
 }
 
 // StreamChat 流式聊天
 func (c *Client) StreamChat(ctx context.Context, req *ChatRequest) (<-chan *StreamResponse, <-chan error) {
-	responseCh := make(chan *StreamResponse, 100)
-	errorCh := make(chan error, 1)
-
-	go func() {
-		defer close(responseCh)
-		defer close(errorCh)
-
-		// TODO: 实现流式聊天
-		// 这需要根据eino的实际API来实现
-		responseCh <- &StreamResponse{
-			Delta:   "Mock",
-			Message: "Mock streaming response",
-			Done:    true,
-		}
-	}()
+	if req == nil || len(req.Messages) == 0 {
+		return streamError(fmt.Errorf("messages cannot be empty"))
+	}
+	if len(req.Tools) > 0 {
+		return streamError(fmt.Errorf("tool calling is not supported in streaming mode"))
+	}
+	return c.provider.StreamChat(ctx, req)
+}
 
+// streamError 返回只包含一个错误的已关闭流
+func streamError(err error) (<-chan *StreamResponse, <-chan error) {
+	responseCh := make(chan *StreamResponse)
+	errorCh := make(chan error, 1)
+	errorCh <- err
+	close(responseCh)
+	close(errorCh)
 	return responseCh, errorCh
 }
 
 // CreateEmbedding 创建嵌入向量
 func (c *Client) CreateEmbedding(ctx context.Context, texts []string) ([][]float64, error) {
-	// TODO: 实现嵌入向量生成
-	embeddings := make([][]float64, len(texts))
-	for i := range texts {
-		// 返回模拟的向量
-		embeddings[i] = make([]float64, 1536) // 假设向量维度为1536
-		for j := range embeddings[i] {
-			embeddings[i][j] = 0.1 // 模拟值
-		}
-	}
-	return embeddings, nil
+	return c.provider.CreateEmbedding(ctx, texts)
 }
 
 // CreateCompletion 文本补全
@@ -183,7 +180,8 @@ func (c *Client) StreamCompletion(ctx context.Context, prompt string, writer Com
 		select {
 		case response, ok := <-responseCh:
 			if !ok {
-				return nil
+				// 通道关闭前发送的错误已在缓冲区中
+				return <-errorCh
 			}
 			if err := writer.WriteResponse(response); err != nil {
 				return err
@@ -0,0 +1,405 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	anthropicDefaultBaseURL   = "https://api.anthropic.com"
+	anthropicAPIVersion       = "2023-06-01"
+	anthropicDefaultMaxTokens = 1024
+)
+
+// AnthropicProvider Anthropic Messages API 适配器
+type AnthropicProvider struct {
+	config     *Config
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewAnthropicProvider 创建Anthropic适配器
+func NewAnthropicProvider(config *Config, httpClient *http.Client) *AnthropicProvider {
+	baseURL := strings.TrimRight(config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+	// 兼容以 /v1 结尾的配置
+	baseURL = strings.TrimSuffix(baseURL, "/v1")
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &AnthropicProvider{
+		config:     config,
+		baseURL:    baseURL,
+		httpClient: httpClient,
+	}
+}
+
+// anthropicRequest Messages API 请求体
+type anthropicRequest struct {
+	Model       string              `json:"model"`
+	System      string              `json:"system,omitempty"`
+	Messages    []*anthropicMessage `json:"messages"`
+	MaxTokens   int                 `json:"max_tokens"`
+	Temperature *float64            `json:"temperature,omitempty"`
+	Stream      bool                `json:"stream,omitempty"`
+	Tools       []anthropicTool     `json:"tools,omitempty"`
+	ToolChoice  map[string]string   `json:"tool_choice,omitempty"`
+}
+
+// anthropicTool 工具定义
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// anthropicMessage Messages API 消息
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+// anthropicContentBlock 内容块（text、tool_use、tool_result）
+type anthropicContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+// anthropicUsage 令牌用量
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicResponse Messages API 响应体
+type anthropicResponse struct {
+	ID         string                  `json:"id"`
+	Role       string                  `json:"role"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+// anthropicStreamEvent 流式事件
+type anthropicStreamEvent struct {
+	Type    string             `json:"type"`
+	Message *anthropicResponse `json:"message,omitempty"`
+	Delta   *struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta,omitempty"`
+	Usage *anthropicUsage `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// anthropicErrorResponse 错误响应体
+type anthropicErrorResponse struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// buildRequest 将ChatRequest转换为Messages API格式
+// system消息合并为独立的system字段，工具调用转换为tool_use块，工具结果转换为用户消息中的tool_result块，
+// 相邻的同角色消息合并为一条
+func (p *AnthropicProvider) buildRequest(req *ChatRequest, stream bool) (*anthropicRequest, error) {
+	body := &anthropicRequest{
+		Model:       p.config.Model,
+		Temperature: req.Temperature,
+		Stream:      stream,
+	}
+
+	var systemParts []string
+	for _, msg := range req.Messages {
+		if msg == nil {
+			continue
+		}
+
+		role := msg.Role
+		var blocks []anthropicContentBlock
+		switch role {
+		case "system":
+			systemParts = append(systemParts, msg.Content)
+			continue
+		case "user":
+			blocks = append(blocks, anthropicContentBlock{Type: "text", Text: msg.Content})
+		case "assistant":
+			if msg.Content != "" || len(msg.ToolCalls) == 0 {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				input := json.RawMessage(call.Function.Arguments)
+				if len(input) == 0 {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, anthropicContentBlock{
+					Type:  "tool_use",
+					ID:    call.ID,
+					Name:  call.Function.Name,
+					Input: input,
+				})
+			}
+		case "tool":
+			role = "user"
+			blocks = append(blocks, anthropicContentBlock{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
+			})
+		default:
+			return nil, fmt.Errorf("unsupported message role for anthropic: %s", role)
+		}
+
+		if n := len(body.Messages); n > 0 && body.Messages[n-1].Role == role {
+			body.Messages[n-1].Content = append(body.Messages[n-1].Content, blocks...)
+			continue
+		}
+		body.Messages = append(body.Messages, &anthropicMessage{
+			Role:    role,
+			Content: blocks,
+		})
+	}
+
+	for _, tool := range req.Tools {
+		body.Tools = append(body.Tools, anthropicTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.Parameters,
+		})
+	}
+	switch req.ToolChoice {
+	case "":
+	case "auto", "none":
+		body.ToolChoice = map[string]string{"type": req.ToolChoice}
+	case "required":
+		body.ToolChoice = map[string]string{"type": "any"}
+	default:
+		body.ToolChoice = map[string]string{"type": "tool", "name": req.ToolChoice}
+	}
+
+	if len(body.Messages) == 0 {
+		return nil, fmt.Errorf("anthropic requires at least one user message")
+	}
+
+	body.System = strings.Join(systemParts, "\n\n")
+
+	switch {
+	case req.MaxTokens != nil:
+		body.MaxTokens = *req.MaxTokens
+	case p.config.MaxTokens > 0:
+		body.MaxTokens = p.config.MaxTokens
+	default:
+		body.MaxTokens = anthropicDefaultMaxTokens
+	}
+
+	if body.Temperature == nil && p.config.Temperature > 0 {
+		temperature := p.config.Temperature
+		body.Temperature = &temperature
+	}
+
+	return body, nil
+}
+
+// Chat 聊天对话
+func (p *AnthropicProvider) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	body, err := p.buildRequest(req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout(p.config))
+	defer cancel()
+
+	resp, err := p.post(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode messages response: %w", err)
+	}
+
+	var content strings.Builder
+	var toolCalls []ToolCall
+	for _, block := range result.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			toolCalls = append(toolCalls, ToolCall{
+				ID:   block.ID,
+				Type: "function",
+				Function: ToolCallFunction{
+					Name:      block.Name,
+					Arguments: string(block.Input),
+				},
+			})
+		}
+	}
+
+	return &ChatResponse{
+		Message: &Message{
+			Role:      "assistant",
+			Content:   content.String(),
+			ToolCalls: toolCalls,
+		},
+		Usage: &Usage{
+			PromptTokens:     result.Usage.InputTokens,
+			CompletionTokens: result.Usage.OutputTokens,
+			TotalTokens:      result.Usage.InputTokens + result.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// StreamChat 流式聊天
+func (p *AnthropicProvider) StreamChat(ctx context.Context, req *ChatRequest) (<-chan *StreamResponse, <-chan error) {
+	responseCh := make(chan *StreamResponse, 100)
+	errorCh := make(chan error, 1)
+
+	go func() {
+		defer close(responseCh)
+		defer close(errorCh)
+
+		body, err := p.buildRequest(req, true)
+		if err != nil {
+			errorCh <- err
+			return
+		}
+
+		resp, err := p.post(ctx, body)
+		if err != nil {
+			errorCh <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		var content strings.Builder
+		usage := &Usage{}
+
+		err = readSSE(resp.Body, func(eventType, data string) (bool, error) {
+			var event anthropicStreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				return false, fmt.Errorf("failed to decode stream event: %w", err)
+			}
+			if event.Type == "" {
+				event.Type = eventType
+			}
+
+			switch event.Type {
+			case "message_start":
+				if event.Message != nil {
+					usage.PromptTokens = event.Message.Usage.InputTokens
+					usage.CompletionTokens = event.Message.Usage.OutputTokens
+				}
+			case "content_block_delta":
+				if event.Delta == nil || event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+					return true, nil
+				}
+				content.WriteString(event.Delta.Text)
+				select {
+				case responseCh <- &StreamResponse{Delta: event.Delta.Text, Message: content.String()}:
+				case <-ctx.Done():
+					return false, ctx.Err()
+				}
+			case "message_delta":
+				if event.Usage != nil {
+					usage.CompletionTokens = event.Usage.OutputTokens
+				}
+			case "message_stop":
+				return false, nil
+			case "error":
+				apiErr := &APIError{Provider: string(ProviderAnthropic), StatusCode: http.StatusOK}
+				if event.Error != nil {
+					apiErr.Type = event.Error.Type
+					apiErr.Message = event.Error.Message
+				}
+				return false, apiErr
+			}
+
+			return true, nil
+		})
+		if err != nil {
+			errorCh <- err
+			return
+		}
+
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+
+		select {
+		case responseCh <- &StreamResponse{Message: content.String(), Done: true, Usage: usage}:
+		case <-ctx.Done():
+		}
+	}()
+
+	return responseCh, errorCh
+}
+
+// CreateEmbedding Anthropic 不提供嵌入接口
+func (p *AnthropicProvider) CreateEmbedding(ctx context.Context, texts []string) ([][]float64, error) {
+	return nil, fmt.Errorf("anthropic does not support embeddings, configure an embedding-capable client instead")
+}
+
+// post 发送Messages API请求，非2xx状态码转换为APIError
+func (p *AnthropicProvider) post(ctx context.Context, body *anthropicRequest) (*http.Response, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/messages", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.config.APIKey)
+	httpReq.Header.Set("anthropic-version", optionString(p.config.Options, "anthropic_version", anthropicAPIVersion))
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+		apiErr := &APIError{
+			Provider:   string(ProviderAnthropic),
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(data)),
+		}
+
+		var errResp anthropicErrorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error.Message != "" {
+			apiErr.Message = errResp.Error.Message
+			apiErr.Type = errResp.Error.Type
+		}
+
+		return nil, apiErr
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,185 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestAnthropicClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&Config{
+		Provider:  "anthropic",
+		APIKey:    "test-key",
+		BaseURL:   server.URL,
+		Model:     "claude-test",
+		MaxTokens: 512,
+	})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	return client
+}
+
+func TestAnthropicProvider_Chat(t *testing.T) {
+	client := newTestAnthropicClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("missing anthropic headers")
+		}
+
+		var body anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if body.System != "You are helpful." {
+			t.Errorf("expected system prompt to be lifted, got %q", body.System)
+		}
+		if body.MaxTokens != 512 {
+			t.Errorf("expected max_tokens 512, got %d", body.MaxTokens)
+		}
+		if len(body.Messages) != 2 {
+			t.Fatalf("expected consecutive user messages to be merged, got %d messages", len(body.Messages))
+		}
+		if body.Messages[0].Role != "user" || len(body.Messages[0].Content) != 2 {
+			t.Errorf("unexpected first message: %+v", body.Messages[0])
+		}
+
+		fmt.Fprint(w, `{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"Bonjour"},{"type":"text","text":"!"}],
+			"stop_reason":"end_turn","usage":{"input_tokens":20,"output_tokens":4}}`)
+	})
+
+	resp, err := client.Chat(context.Background(), &ChatRequest{
+		Messages: []*Message{
+			{Role: "system", Content: "You are helpful."},
+			{Role: "user", Content: "Hello"},
+			{Role: "user", Content: "In French please"},
+			{Role: "assistant", Content: "Sure"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+
+	if resp.Message.Content != "Bonjour!" {
+		t.Errorf("unexpected content: %s", resp.Message.Content)
+	}
+	if resp.Usage.PromptTokens != 20 || resp.Usage.CompletionTokens != 4 || resp.Usage.TotalTokens != 24 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestAnthropicProvider_StreamChat(t *testing.T) {
+	client := newTestAnthropicClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"role\":\"assistant\",\"content\":[],\"usage\":{\"input_tokens\":9,\"output_tokens\":1}}}\n\n")
+		fmt.Fprint(w, "event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")
+		fmt.Fprint(w, "event: ping\ndata: {\"type\":\"ping\"}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\" there\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n")
+		fmt.Fprint(w, "event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":3}}\n\n")
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	})
+
+	responseCh, errorCh := client.StreamChat(context.Background(), &ChatRequest{
+		Messages: []*Message{{Role: "user", Content: "Hello"}},
+		Stream:   true,
+	})
+
+	var last *StreamResponse
+	count := 0
+	for response := range responseCh {
+		count++
+		last = response
+	}
+	if err := <-errorCh; err != nil {
+		t.Fatalf("StreamChat error: %v", err)
+	}
+
+	if count != 3 {
+		t.Errorf("expected 2 deltas and a final response, got %d responses", count)
+	}
+	if last == nil || !last.Done || last.Message != "Hi there" {
+		t.Fatalf("unexpected final response: %+v", last)
+	}
+	if last.Usage.PromptTokens != 9 || last.Usage.CompletionTokens != 3 || last.Usage.TotalTokens != 12 {
+		t.Errorf("unexpected usage: %+v", last.Usage)
+	}
+}
+
+func TestAnthropicProvider_StreamError(t *testing.T) {
+	client := newTestAnthropicClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	})
+
+	responseCh, errorCh := client.StreamChat(context.Background(), &ChatRequest{
+		Messages: []*Message{{Role: "user", Content: "Hello"}},
+	})
+	for range responseCh {
+	}
+
+	err := <-errorCh
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Type != "overloaded_error" {
+		t.Errorf("expected overloaded APIError, got %v", err)
+	}
+}
+
+func TestAnthropicProvider_ToolUse(t *testing.T) {
+	client := newTestAnthropicClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if len(body.Tools) != 1 || body.Tools[0].Name != "get_post" || body.Tools[0].InputSchema["type"] != "object" {
+			t.Errorf("unexpected tools: %+v", body.Tools)
+		}
+		if body.ToolChoice["type"] != "any" {
+			t.Errorf("expected required to map to any, got %+v", body.ToolChoice)
+		}
+		// user → assistant(tool_use) → user(tool_result)
+		if len(body.Messages) != 3 {
+			t.Fatalf("expected 3 messages, got %d", len(body.Messages))
+		}
+		if block := body.Messages[1].Content[0]; block.Type != "tool_use" || block.ID != "call_1" || string(block.Input) != `{"id":1}` {
+			t.Errorf("unexpected tool_use block: %+v", block)
+		}
+		if msg := body.Messages[2]; msg.Role != "user" || msg.Content[0].Type != "tool_result" || msg.Content[0].ToolUseID != "call_1" {
+			t.Errorf("unexpected tool_result message: %+v", msg)
+		}
+
+		fmt.Fprint(w, `{"id":"msg_2","role":"assistant","content":[{"type":"text","text":"Let me check."},
+			{"type":"tool_use","id":"call_2","name":"get_post","input":{"id":2}}],
+			"stop_reason":"tool_use","usage":{"input_tokens":30,"output_tokens":10}}`)
+	})
+
+	resp, err := client.Chat(context.Background(), &ChatRequest{
+		Messages: []*Message{
+			{Role: "user", Content: "Show post 1"},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: ToolCallFunction{Name: "get_post", Arguments: `{"id":1}`}}}},
+			{Role: "tool", ToolCallID: "call_1", Content: `{"title":"Hello"}`},
+		},
+		Tools:      []*ToolDefinition{{Name: "get_post", Parameters: ObjectSchema(map[string]interface{}{"id": map[string]interface{}{"type": "integer"}})}},
+		ToolChoice: "required",
+	})
+	if err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+
+	if resp.Message.Content != "Let me check." || len(resp.Message.ToolCalls) != 1 {
+		t.Fatalf("unexpected message: %+v", resp.Message)
+	}
+	if call := resp.Message.ToolCalls[0]; call.ID != "call_2" || call.Function.Arguments != `{"id":2}` {
+		t.Errorf("unexpected tool call: %+v", call)
+	}
+}
@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Tokenizer 令牌计数器，用于估算消息占用的上下文长度
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc 函数形式的令牌计数器
+type TokenizerFunc func(text string) int
+
+// CountTokens 实现Tokenizer接口
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+// messageTokenOverhead 每条消息的格式开销（角色、分隔符）
+const messageTokenOverhead = 4
+
+// EstimateTokens 近似估算令牌数：中日韩字符按1个令牌计，其余按约4个字符1个令牌计
+func EstimateTokens(text string) int {
+	cjk, other := 0, 0
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
+
+// EstimateTokenizer 默认的估算计数器
+var EstimateTokenizer Tokenizer = TokenizerFunc(EstimateTokens)
+
+var (
+	tokenizersMu sync.RWMutex
+	tokenizers   = make(map[string]Tokenizer)
+)
+
+// RegisterTokenizer 为模型名称前缀注册令牌计数器，如 "gpt-4o"、"claude"
+func RegisterTokenizer(modelPrefix string, tokenizer Tokenizer) {
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+	tokenizers[strings.ToLower(modelPrefix)] = tokenizer
+}
+
+// TokenizerForModel 获取模型的令牌计数器（最长前缀匹配），未注册时使用估算计数器
+func TokenizerForModel(model string) Tokenizer {
+	tokenizersMu.RLock()
+	defer tokenizersMu.RUnlock()
+
+	model = strings.ToLower(model)
+	var matched Tokenizer
+	matchedLen := -1
+	for prefix, tokenizer := range tokenizers {
+		if strings.HasPrefix(model, prefix) && len(prefix) > matchedLen {
+			matched = tokenizer
+			matchedLen = len(prefix)
+		}
+	}
+	if matched == nil {
+		return EstimateTokenizer
+	}
+	return matched
+}
+
+// CountMessageTokens 计算消息列表占用的令牌数
+func CountMessageTokens(tokenizer Tokenizer, messages []*Message) int {
+	if tokenizer == nil {
+		tokenizer = EstimateTokenizer
+	}
+	total := 0
+	for _, msg := range messages {
+		total += tokenizer.CountTokens(msg.Content) + messageTokenOverhead
+		for _, call := range msg.ToolCalls {
+			total += tokenizer.CountTokens(call.Function.Name) + tokenizer.CountTokens(call.Function.Arguments)
+		}
+	}
+	return total
+}
+
+// contextWindows 常见模型的上下文窗口（按模型名称前缀匹配）
+var contextWindows = map[string]int{
+	"gpt-4o":        128000,
+	"gpt-4-turbo":   128000,
+	"gpt-4.1":       1047576,
+	"gpt-4":         8192,
+	"gpt-3.5-turbo": 16385,
+	"o1":            200000,
+	"o3":            200000,
+	"claude":        200000,
+	"qwen-long":     1000000,
+	"qwen":          32768,
+	"glm-4":         128000,
+	"baichuan":      32768,
+	"abab":          245760,
+	"doubao":        32768,
+}
+
+// defaultContextWindow 未知模型的上下文窗口
+const defaultContextWindow = 8192
+
+// ContextWindow 获取客户端模型的上下文窗口，可通过 Options["context_window"] 覆盖
+func ContextWindow(config *Config) int {
+	if window := optionInt(config.Options, "context_window", 0); window > 0 {
+		return window
+	}
+
+	model := strings.ToLower(config.Model)
+	prefixes := make([]string, 0, len(contextWindows))
+	for prefix := range contextWindows {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return contextWindows[prefix]
+		}
+	}
+	return defaultContextWindow
+}
+
+// PromptTokenBudget 计算对话历史可用的令牌预算：上下文窗口减去为回复预留的令牌
+func PromptTokenBudget(config *Config) int {
+	reserve := config.MaxTokens
+	if reserve <= 0 {
+		reserve = 1024
+	}
+	budget := ContextWindow(config) - reserve
+	if budget < reserve {
+		budget = reserve
+	}
+	return budget
+}
+
+// summaryPrefix 摘要消息的内容前缀，用于识别并替换旧摘要
+const summaryPrefix = "Summary of the earlier conversation:\n"
+
+// IsSummaryMessage 判断是否为压缩生成的摘要消息
+func IsSummaryMessage(msg *Message) bool {
+	return msg.Role == "system" && strings.HasPrefix(msg.Content, summaryPrefix)
+}
+
+// Summarizer 对话摘要器，将较早的对话压缩为一段摘要
+type Summarizer interface {
+	// Summarize previous 为已有摘要（可能为空），messages 为需要压缩的消息
+	Summarize(ctx context.Context, previous string, messages []*Message) (string, error)
+}
+
+// defaultSummaryPrompt 默认摘要提示词
+const defaultSummaryPrompt = "You compress conversations. Summarize the conversation below into a concise paragraph " +
+	"that preserves facts, decisions, user preferences and open questions needed to continue it. " +
+	"Reply with the summary only."
+
+// ClientSummarizer 使用同一AI客户端生成摘要
+type ClientSummarizer struct {
+	client    *EinoClient
+	Prompt    string
+	MaxTokens int
+}
+
+// NewClientSummarizer 创建基于客户端的摘要器
+func NewClientSummarizer(client *EinoClient) *ClientSummarizer {
+	return &ClientSummarizer{
+		client:    client,
+		Prompt:    defaultSummaryPrompt,
+		MaxTokens: 512,
+	}
+}
+
+// Summarize 生成摘要
+func (s *ClientSummarizer) Summarize(ctx context.Context, previous string, messages []*Message) (string, error) {
+	var transcript strings.Builder
+	if previous != "" {
+		transcript.WriteString("Earlier summary: ")
+		transcript.WriteString(previous)
+		transcript.WriteString("\n\n")
+	}
+	for _, msg := range messages {
+		if msg.Content != "" {
+			fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, msg.Content)
+		}
+		for _, call := range msg.ToolCalls {
+			fmt.Fprintf(&transcript, "%s called %s(%s)\n", msg.Role, call.Function.Name, call.Function.Arguments)
+		}
+	}
+
+	response, err := s.client.ChatCompletion(ctx, []*Message{
+		{Role: "system", Content: s.Prompt},
+		{Role: "user", Content: transcript.String()},
+	}, WithChatMaxTokens(s.MaxTokens))
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize conversation: %w", err)
+	}
+
+	return strings.TrimSpace(response.Message.Content), nil
+}
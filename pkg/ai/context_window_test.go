@@ -0,0 +1,182 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// wordTokenizer 按空格计数，便于构造精确的令牌预算
+var wordTokenizer = TokenizerFunc(func(text string) int {
+	return len(strings.Fields(text))
+})
+
+func TestEstimateTokens(t *testing.T) {
+	if got := EstimateTokens("abcdefgh"); got != 2 {
+		t.Errorf("expected 2 tokens for 8 latin chars, got %d", got)
+	}
+	if got := EstimateTokens("你好世界"); got != 4 {
+		t.Errorf("expected 4 tokens for 4 CJK chars, got %d", got)
+	}
+}
+
+func TestTokenizerForModel(t *testing.T) {
+	RegisterTokenizer("test-model", wordTokenizer)
+	RegisterTokenizer("test-model-long", EstimateTokenizer)
+	t.Cleanup(func() {
+		tokenizersMu.Lock()
+		delete(tokenizers, "test-model")
+		delete(tokenizers, "test-model-long")
+		tokenizersMu.Unlock()
+	})
+
+	if got := TokenizerForModel("Test-Model-1").CountTokens("a b c"); got != 3 {
+		t.Errorf("expected registered tokenizer, got %d tokens", got)
+	}
+	if got := TokenizerForModel("test-model-long-2").CountTokens("a b c"); got != EstimateTokens("a b c") {
+		t.Errorf("expected longest prefix match, got %d tokens", got)
+	}
+	if got := TokenizerForModel("unknown").CountTokens("abcd"); got != 1 {
+		t.Errorf("expected estimate tokenizer fallback, got %d tokens", got)
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	cases := map[string]int{
+		"gpt-4o-mini":       128000,
+		"gpt-4":             8192,
+		"claude-3-5-sonnet": 200000,
+		"qwen-long":         1000000,
+		"something-else":    defaultContextWindow,
+	}
+	for model, want := range cases {
+		if got := ContextWindow(&Config{Model: model}); got != want {
+			t.Errorf("ContextWindow(%s) = %d, want %d", model, got, want)
+		}
+	}
+
+	config := &Config{Model: "gpt-4", MaxTokens: 2000, Options: map[string]interface{}{"context_window": 32000}}
+	if got := PromptTokenBudget(config); got != 30000 {
+		t.Errorf("expected budget 30000, got %d", got)
+	}
+}
+
+func TestConversationContext_TokenBudgetKeepsSystem(t *testing.T) {
+	c := NewConversationContext(100)
+	c.SetTokenizer(wordTokenizer)
+	// 每条消息 1 个单词 + 4 个格式开销 = 5 个令牌
+	c.SetMaxTokens(20)
+
+	c.AddMessage("system", "rules")
+	for _, content := range []string{"q1", "a1", "q2", "a2", "q3"} {
+		role := "user"
+		if strings.HasPrefix(content, "a") {
+			role = "assistant"
+		}
+		c.AddMessage(role, content)
+	}
+
+	messages := c.GetMessages()
+	if messages[0].Role != "system" || messages[0].Content != "rules" {
+		t.Fatalf("system message should stay first: %+v", messages[0])
+	}
+	if c.TokenCount() > 20 {
+		t.Errorf("token count %d exceeds budget", c.TokenCount())
+	}
+	// 截断后不应以助手回复开头
+	if messages[1].Role != "user" {
+		t.Errorf("history should start with a user turn, got %s", messages[1].Role)
+	}
+	if last := messages[len(messages)-1]; last.Content != "q3" {
+		t.Errorf("latest message should be kept, got %s", last.Content)
+	}
+}
+
+func TestConversationContext_KeepsLatestMessage(t *testing.T) {
+	c := NewConversationContext(100)
+	c.SetTokenizer(wordTokenizer)
+	c.SetMaxTokens(5)
+
+	c.AddMessage("user", "a very long question that exceeds the budget")
+	if len(c.GetMessages()) != 1 {
+		t.Errorf("latest message must never be dropped, got %d messages", len(c.GetMessages()))
+	}
+}
+
+type stubSummarizer struct {
+	previous []string
+	err      error
+}
+
+func (s *stubSummarizer) Summarize(ctx context.Context, previous string, messages []*Message) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	s.previous = append(s.previous, previous)
+	parts := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		parts = append(parts, msg.Content)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+func TestConversationContext_Summarization(t *testing.T) {
+	summarizer := &stubSummarizer{}
+	c := NewConversationContext(4)
+	c.SetSummarizer(summarizer)
+	ctx := context.Background()
+
+	c.AddMessageContext(ctx, "system", "rules")
+	for _, content := range []string{"q1", "a1", "q2", "a2"} {
+		role := "user"
+		if strings.HasPrefix(content, "a") {
+			role = "assistant"
+		}
+		if err := c.AddMessageContext(ctx, role, content); err != nil {
+			t.Fatalf("AddMessageContext error: %v", err)
+		}
+	}
+
+	messages := c.GetMessages()
+	if len(messages) > 4 {
+		t.Fatalf("expected at most 4 messages, got %d", len(messages))
+	}
+	if messages[0].Content != "rules" {
+		t.Errorf("pinned system message should stay first, got %s", messages[0].Content)
+	}
+	if !IsSummaryMessage(messages[1]) || !strings.Contains(messages[1].Content, "q1,a1") {
+		t.Errorf("expected summary of the first turn, got %+v", messages[1])
+	}
+
+	// 再次压缩时带上已有摘要
+	c.AddMessageContext(ctx, "user", "q3")
+	c.AddMessageContext(ctx, "assistant", "a3")
+	if got := summarizer.previous[len(summarizer.previous)-1]; got == "" {
+		t.Error("expected previous summary to be passed to summarizer")
+	}
+	summaries := 0
+	for _, msg := range c.GetMessages() {
+		if IsSummaryMessage(msg) {
+			summaries++
+		}
+	}
+	if summaries != 1 {
+		t.Errorf("expected exactly one summary message, got %d", summaries)
+	}
+}
+
+func TestConversationContext_SummarizationFailureTrims(t *testing.T) {
+	c := NewConversationContext(2)
+	c.SetSummarizer(&stubSummarizer{err: errors.New("upstream down")})
+	ctx := context.Background()
+
+	c.AddMessageContext(ctx, "user", "q1")
+	c.AddMessageContext(ctx, "assistant", "a1")
+	if err := c.AddMessageContext(ctx, "user", "q2"); err == nil {
+		t.Error("expected summarizer error to be returned")
+	}
+	if len(c.GetMessages()) > 2 {
+		t.Errorf("history should still be trimmed, got %d messages", len(c.GetMessages()))
+	}
+}
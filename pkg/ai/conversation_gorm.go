@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConversationRecord 对话数据表模型
+type ConversationRecord struct {
+	ID        uint       `gorm:"primaryKey"`
+	Key       string     `gorm:"column:conversation_key;size:255;uniqueIndex;not null"`
+	SessionID string     `gorm:"size:128;not null"`
+	Client    string     `gorm:"size:64;not null"`
+	UserID    uint       `gorm:"index;not null;default:0"`
+	MaxLen    int        `gorm:"not null;default:0"`
+	Messages  string     `gorm:"type:text"`
+	ExpiresAt *time.Time `gorm:"index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName 指定表名
+func (ConversationRecord) TableName() string {
+	return database.TableName("ai_conversations")
+}
+
+// GormConversationStore 基于GORM的对话存储（支持 sqlite、mysql、postgres）
+type GormConversationStore struct {
+	db *gorm.DB
+}
+
+// NewGormConversationStore 创建GORM对话存储，数据表由 ai_conversations 迁移创建
+func NewGormConversationStore(db *gorm.DB) (*GormConversationStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return &GormConversationStore{db: db}, nil
+}
+
+// Get 获取对话
+func (s *GormConversationStore) Get(ctx context.Context, sessionID, clientName string) (*Conversation, error) {
+	var record ConversationRecord
+	err := s.db.WithContext(ctx).
+		Where("conversation_key = ?", ConversationKey(sessionID, clientName)).
+		First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrConversationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conversation := &Conversation{
+		SessionID: record.SessionID,
+		Client:    record.Client,
+		UserID:    record.UserID,
+		MaxLen:    record.MaxLen,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+	if record.ExpiresAt != nil {
+		conversation.ExpiresAt = *record.ExpiresAt
+	}
+
+	if conversation.Expired(time.Now()) {
+		s.db.WithContext(ctx).Delete(&record)
+		return nil, ErrConversationNotFound
+	}
+
+	if record.Messages != "" {
+		if err := json.Unmarshal([]byte(record.Messages), &conversation.Messages); err != nil {
+			return nil, fmt.Errorf("failed to decode conversation messages: %w", err)
+		}
+	}
+
+	return conversation, nil
+}
+
+// Save 保存对话（按存储键upsert）
+func (s *GormConversationStore) Save(ctx context.Context, conversation *Conversation, ttl time.Duration) error {
+	conversation.touch(ttl)
+
+	messages, err := json.Marshal(conversation.Messages)
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation messages: %w", err)
+	}
+
+	record := &ConversationRecord{
+		Key:       conversation.Key(),
+		SessionID: conversation.SessionID,
+		Client:    conversation.Client,
+		UserID:    conversation.UserID,
+		MaxLen:    conversation.MaxLen,
+		Messages:  string(messages),
+		CreatedAt: conversation.CreatedAt,
+		UpdatedAt: conversation.UpdatedAt,
+	}
+	if !conversation.ExpiresAt.IsZero() {
+		expiresAt := conversation.ExpiresAt
+		record.ExpiresAt = &expiresAt
+	}
+
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "conversation_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "max_len", "messages", "expires_at", "updated_at"}),
+	}).Create(record).Error
+}
+
+// Delete 删除对话
+func (s *GormConversationStore) Delete(ctx context.Context, sessionID, clientName string) error {
+	return s.db.WithContext(ctx).
+		Where("conversation_key = ?", ConversationKey(sessionID, clientName)).
+		Delete(&ConversationRecord{}).Error
+}
+
+// PurgeExpired 删除所有已过期的对话，返回删除数量
+func (s *GormConversationStore) PurgeExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at < ?", time.Now()).
+		Delete(&ConversationRecord{})
+	return result.RowsAffected, result.Error
+}
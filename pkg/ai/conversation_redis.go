@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConversationStore 基于Redis的对话存储，过期由Redis TTL处理
+type RedisConversationStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisConversationStore 创建Redis对话存储
+func NewRedisConversationStore(client *redis.Client, prefix string) *RedisConversationStore {
+	if prefix == "" {
+		prefix = "ai:conversation"
+	}
+	return &RedisConversationStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// key 生成Redis键
+func (s *RedisConversationStore) key(sessionID, clientName string) string {
+	return fmt.Sprintf("%s:%s", s.prefix, ConversationKey(sessionID, clientName))
+}
+
+// Get 获取对话
+func (s *RedisConversationStore) Get(ctx context.Context, sessionID, clientName string) (*Conversation, error) {
+	data, err := s.client.Get(ctx, s.key(sessionID, clientName)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrConversationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var conversation Conversation
+	if err := json.Unmarshal(data, &conversation); err != nil {
+		return nil, fmt.Errorf("failed to decode conversation: %w", err)
+	}
+
+	return &conversation, nil
+}
+
+// Save 保存对话
+func (s *RedisConversationStore) Save(ctx context.Context, conversation *Conversation, ttl time.Duration) error {
+	conversation.touch(ttl)
+
+	data, err := json.Marshal(conversation)
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation: %w", err)
+	}
+
+	if ttl < 0 {
+		ttl = 0
+	}
+	return s.client.Set(ctx, s.key(conversation.SessionID, conversation.Client), data, ttl).Err()
+}
+
+// Delete 删除对话
+func (s *RedisConversationStore) Delete(ctx context.Context, sessionID, clientName string) error {
+	return s.client.Del(ctx, s.key(sessionID, clientName)).Err()
+}
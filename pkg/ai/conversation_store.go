@@ -0,0 +1,179 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrConversationNotFound 对话不存在或已过期
+	ErrConversationNotFound = errors.New("conversation not found")
+
+	// ErrConversationForbidden 对话属于其他用户
+	ErrConversationForbidden = errors.New("conversation belongs to another user")
+)
+
+// Conversation 持久化的对话
+type Conversation struct {
+	SessionID string     `json:"session_id"`
+	Client    string     `json:"client"`
+	UserID    uint       `json:"user_id"` // 0 表示匿名对话
+	MaxLen    int        `json:"max_len"`
+	Messages  []*Message `json:"messages"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ExpiresAt time.Time  `json:"expires_at,omitempty"` // 零值表示不过期
+}
+
+// ConversationKey 生成对话存储键
+func ConversationKey(sessionID, clientName string) string {
+	return fmt.Sprintf("%s:%s", sessionID, clientName)
+}
+
+// Key 对话存储键
+func (c *Conversation) Key() string {
+	return ConversationKey(c.SessionID, c.Client)
+}
+
+// CheckOwner 检查用户是否可以访问该对话
+// 已登录用户创建的对话只能由本人访问，匿名对话只能匿名访问
+func (c *Conversation) CheckOwner(userID uint) error {
+	if c.UserID != userID {
+		return ErrConversationForbidden
+	}
+	return nil
+}
+
+// Expired 检查对话是否过期
+func (c *Conversation) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && now.After(c.ExpiresAt)
+}
+
+// touch 更新时间戳与过期时间
+func (c *Conversation) touch(ttl time.Duration) {
+	now := time.Now()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	c.UpdatedAt = now
+	if ttl > 0 {
+		c.ExpiresAt = now.Add(ttl)
+	} else {
+		c.ExpiresAt = time.Time{}
+	}
+}
+
+// ConversationStore 对话存储接口
+type ConversationStore interface {
+	// Get 获取对话，不存在或已过期时返回 ErrConversationNotFound
+	Get(ctx context.Context, sessionID, clientName string) (*Conversation, error)
+
+	// Save 保存对话并刷新过期时间，ttl<=0 表示不过期
+	Save(ctx context.Context, conversation *Conversation, ttl time.Duration) error
+
+	// Delete 删除对话
+	Delete(ctx context.Context, sessionID, clientName string) error
+}
+
+// MemoryConversationStore 内存对话存储（单实例、重启丢失，适合开发环境）
+type MemoryConversationStore struct {
+	mu       sync.Mutex
+	items    map[string]*Conversation
+	maxItems int
+}
+
+// NewMemoryConversationStore 创建内存对话存储，maxItems<=0 表示不限制数量
+func NewMemoryConversationStore(maxItems int) *MemoryConversationStore {
+	return &MemoryConversationStore{
+		items:    make(map[string]*Conversation),
+		maxItems: maxItems,
+	}
+}
+
+// Get 获取对话
+func (s *MemoryConversationStore) Get(ctx context.Context, sessionID, clientName string) (*Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := ConversationKey(sessionID, clientName)
+	item, found := s.items[key]
+	if !found {
+		return nil, ErrConversationNotFound
+	}
+
+	if item.Expired(time.Now()) {
+		delete(s.items, key)
+		return nil, ErrConversationNotFound
+	}
+
+	return cloneConversation(item), nil
+}
+
+// Save 保存对话
+func (s *MemoryConversationStore) Save(ctx context.Context, conversation *Conversation, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversation.touch(ttl)
+	s.items[conversation.Key()] = cloneConversation(conversation)
+
+	s.evict()
+	return nil
+}
+
+// Delete 删除对话
+func (s *MemoryConversationStore) Delete(ctx context.Context, sessionID, clientName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, ConversationKey(sessionID, clientName))
+	return nil
+}
+
+// Len 当前对话数量
+func (s *MemoryConversationStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// evict 清除过期对话，超出容量时淘汰最久未更新的对话（调用方持有锁）
+func (s *MemoryConversationStore) evict() {
+	now := time.Now()
+	for key, item := range s.items {
+		if item.Expired(now) {
+			delete(s.items, key)
+		}
+	}
+
+	if s.maxItems <= 0 || len(s.items) <= s.maxItems {
+		return
+	}
+
+	keys := make([]string, 0, len(s.items))
+	for key := range s.items {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.items[keys[i]].UpdatedAt.Before(s.items[keys[j]].UpdatedAt)
+	})
+
+	for _, key := range keys[:len(keys)-s.maxItems] {
+		delete(s.items, key)
+	}
+}
+
+// cloneConversation 复制对话，避免调用方修改存储中的数据
+func cloneConversation(c *Conversation) *Conversation {
+	clone := *c
+	clone.Messages = make([]*Message, len(c.Messages))
+	for i, msg := range c.Messages {
+		m := *msg
+		clone.Messages[i] = &m
+	}
+	return &clone
+}
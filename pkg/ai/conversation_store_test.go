@@ -0,0 +1,160 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testConversation(sessionID string, userID uint) *Conversation {
+	return &Conversation{
+		SessionID: sessionID,
+		Client:    "openai",
+		UserID:    userID,
+		MaxLen:    10,
+		Messages: []*Message{
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", Content: "hello"},
+		},
+	}
+}
+
+// testConversationStore 各存储实现的通用行为
+func testConversationStore(t *testing.T, store ConversationStore) {
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "s1", "openai"); !errors.Is(err, ErrConversationNotFound) {
+		t.Fatalf("expected ErrConversationNotFound, got %v", err)
+	}
+
+	if err := store.Save(ctx, testConversation("s1", 7), time.Hour); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	conversation, err := store.Get(ctx, "s1", "openai")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if conversation.UserID != 7 || len(conversation.Messages) != 2 || conversation.Messages[1].Content != "hello" {
+		t.Errorf("unexpected conversation: %+v", conversation)
+	}
+	if conversation.ExpiresAt.IsZero() {
+		t.Error("expected expiry to be set")
+	}
+
+	// 覆盖保存
+	conversation.Messages = append(conversation.Messages, &Message{Role: "user", Content: "again"})
+	if err := store.Save(ctx, conversation, time.Hour); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	conversation, _ = store.Get(ctx, "s1", "openai")
+	if len(conversation.Messages) != 3 {
+		t.Errorf("expected 3 messages after update, got %d", len(conversation.Messages))
+	}
+
+	// 已过期的对话不可见
+	expired := testConversation("s2", 7)
+	if err := store.Save(ctx, expired, time.Hour); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	if gormStore, ok := store.(*GormConversationStore); ok {
+		gormStore.db.Model(&ConversationRecord{}).Where("conversation_key = ?", expired.Key()).Update("expires_at", expired.ExpiresAt)
+	} else if memoryStore, ok := store.(*MemoryConversationStore); ok {
+		memoryStore.items[expired.Key()].ExpiresAt = expired.ExpiresAt
+	}
+	if _, err := store.Get(ctx, "s2", "openai"); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("expected expired conversation to be gone, got %v", err)
+	}
+
+	if err := store.Delete(ctx, "s1", "openai"); err != nil {
+		t.Fatalf("Delete error: %v", err)
+	}
+	if _, err := store.Get(ctx, "s1", "openai"); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("expected deleted conversation to be gone, got %v", err)
+	}
+}
+
+func TestMemoryConversationStore(t *testing.T) {
+	testConversationStore(t, NewMemoryConversationStore(0))
+}
+
+func TestMemoryConversationStore_Eviction(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryConversationStore(2)
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := store.Save(ctx, testConversation(id, 0), 0); err != nil {
+			t.Fatalf("Save error: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if store.Len() != 2 {
+		t.Fatalf("expected 2 conversations, got %d", store.Len())
+	}
+	if _, err := store.Get(ctx, "a", "openai"); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("expected oldest conversation to be evicted, got %v", err)
+	}
+}
+
+func TestMemoryConversationStore_Isolation(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryConversationStore(0)
+	store.Save(ctx, testConversation("s1", 1), 0)
+
+	conversation, _ := store.Get(ctx, "s1", "openai")
+	conversation.Messages[0].Content = "changed"
+
+	conversation, _ = store.Get(ctx, "s1", "openai")
+	if conversation.Messages[0].Content != "hi" {
+		t.Error("stored conversation should not be modified through returned copy")
+	}
+}
+
+func TestGormConversationStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&ConversationRecord{}); err != nil {
+		t.Fatalf("AutoMigrate error: %v", err)
+	}
+
+	store, err := NewGormConversationStore(db)
+	if err != nil {
+		t.Fatalf("NewGormConversationStore error: %v", err)
+	}
+	testConversationStore(t, store)
+
+	store.Save(context.Background(), testConversation("old", 0), time.Hour)
+	db.Model(&ConversationRecord{}).Where("conversation_key = ?", ConversationKey("old", "openai")).
+		Update("expires_at", time.Now().Add(-time.Hour))
+	purged, err := store.PurgeExpired(context.Background())
+	if err != nil || purged != 1 {
+		t.Errorf("expected 1 purged conversation, got %d (%v)", purged, err)
+	}
+}
+
+func TestConversation_CheckOwner(t *testing.T) {
+	owned := testConversation("s1", 7)
+	if err := owned.CheckOwner(7); err != nil {
+		t.Errorf("owner should have access: %v", err)
+	}
+	if err := owned.CheckOwner(8); !errors.Is(err, ErrConversationForbidden) {
+		t.Errorf("other user should be forbidden, got %v", err)
+	}
+	if err := owned.CheckOwner(0); !errors.Is(err, ErrConversationForbidden) {
+		t.Errorf("anonymous user should be forbidden, got %v", err)
+	}
+
+	anonymous := testConversation("s2", 0)
+	if err := anonymous.CheckOwner(7); !errors.Is(err, ErrConversationForbidden) {
+		t.Errorf("anonymous conversation should not be claimed, got %v", err)
+	}
+}
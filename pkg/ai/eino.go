@@ -2,7 +2,9 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 )
 
 // ProviderType 提供商类型
@@ -127,27 +129,15 @@ func (e *EinoClient) StreamCompletion(ctx context.Context, prompt string, callba
 		select {
 		case response, ok := <-responseCh:
 			if !ok {
-				return nil
-			}
-
-			// 假设 response 是字符串类型的简化版本
-			content := fmt.Sprintf("%v", response)
-			streamResp := &StreamResponse{
-				Delta:   content,
-				Message: content,
-				Done:    false,
+				// 通道关闭前发送的错误已在缓冲区中
+				return <-errorCh
 			}
 
-			if err := callback(streamResp); err != nil {
+			if err := callback(response); err != nil {
 				return err
 			}
 
-			// 简化的完成检查
-			if len(content) == 0 {
-				streamResp.Done = true
-				if err := callback(streamResp); err != nil {
-					return err
-				}
+			if response.Done {
 				return nil
 			}
 
@@ -197,9 +187,15 @@ func WithSystemPrompt(systemPrompt string) ChatOption {
 }
 
 // ConversationContext 对话上下文
+// 系统消息始终保留，超出消息数量（MaxLen）或令牌预算（MaxTokens）时优先淘汰最早的对话轮次；
+// 设置摘要器后，被淘汰的消息会被压缩为一条摘要系统消息
 type ConversationContext struct {
-	Messages []*Message `json:"messages"`
-	MaxLen   int        `json:"max_len"`
+	Messages  []*Message `json:"messages"`
+	MaxLen    int        `json:"max_len"`
+	MaxTokens int        `json:"max_tokens,omitempty"` // 0 表示不限制令牌数
+
+	tokenizer  Tokenizer
+	summarizer Summarizer
 }
 
 // NewConversationContext 创建对话上下文
@@ -208,42 +204,167 @@ func NewConversationContext(maxLen int) *ConversationContext {
 		maxLen = 100 // 默认保持100条消息
 	}
 	return &ConversationContext{
-		Messages: make([]*Message, 0),
-		MaxLen:   maxLen,
+		Messages:  make([]*Message, 0),
+		MaxLen:    maxLen,
+		tokenizer: EstimateTokenizer,
+	}
+}
+
+// SetTokenizer 设置令牌计数器
+func (c *ConversationContext) SetTokenizer(tokenizer Tokenizer) {
+	if tokenizer == nil {
+		tokenizer = EstimateTokenizer
 	}
+	c.tokenizer = tokenizer
+}
+
+// SetMaxTokens 设置令牌预算，0 表示不限制
+func (c *ConversationContext) SetMaxTokens(maxTokens int) {
+	c.MaxTokens = maxTokens
 }
 
-// AddMessage 添加消息
+// SetSummarizer 设置摘要器，nil 表示直接丢弃超出的消息
+func (c *ConversationContext) SetSummarizer(summarizer Summarizer) {
+	c.summarizer = summarizer
+}
+
+// AddMessage 添加消息并按限制截断（不做摘要）
 func (c *ConversationContext) AddMessage(role, content string) {
-	message := &Message{
+	c.Messages = append(c.Messages, &Message{
+		Role:    role,
+		Content: content,
+	})
+	c.trim()
+}
+
+// AddMessageContext 添加消息并适配上下文窗口，设置了摘要器时先压缩较早的消息
+// 摘要失败时退化为截断，并返回摘要错误
+func (c *ConversationContext) AddMessageContext(ctx context.Context, role, content string) error {
+	return c.Append(ctx, &Message{
 		Role:    role,
 		Content: content,
+	})
+}
+
+// Append 添加完整消息（如带工具调用的助手消息、工具结果）并适配上下文窗口
+func (c *ConversationContext) Append(ctx context.Context, messages ...*Message) error {
+	c.Messages = append(c.Messages, messages...)
+	return c.Fit(ctx)
+}
+
+// Fit 使消息满足数量与令牌限制
+func (c *ConversationContext) Fit(ctx context.Context) error {
+	var err error
+	if c.summarizer != nil {
+		err = c.compact(ctx)
 	}
+	c.trim()
+	return err
+}
 
-	c.Messages = append(c.Messages, message)
+// TokenCount 当前消息占用的令牌数
+func (c *ConversationContext) TokenCount() int {
+	return CountMessageTokens(c.tokenizer, c.Messages)
+}
 
-	// 保持消息数量不超过限制
-	if len(c.Messages) > c.MaxLen {
-		// 保留系统消息，删除最早的用户/助手消息
-		systemMessages := make([]*Message, 0)
-		otherMessages := make([]*Message, 0)
+// split 拆分为固定的系统消息、摘要消息和对话消息
+func (c *ConversationContext) split() (pinned []*Message, summary *Message, turns []*Message) {
+	for _, msg := range c.Messages {
+		switch {
+		case IsSummaryMessage(msg):
+			summary = msg
+		case msg.Role == "system":
+			pinned = append(pinned, msg)
+		default:
+			turns = append(turns, msg)
+		}
+	}
+	return pinned, summary, turns
+}
 
-		for _, msg := range c.Messages {
-			if msg.Role == "system" {
-				systemMessages = append(systemMessages, msg)
-			} else {
-				otherMessages = append(otherMessages, msg)
-			}
+// overflow 计算需要从最早处淘汰的对话消息数量，始终保留最后一条消息
+func (c *ConversationContext) overflow(pinned []*Message, summary *Message, turns []*Message) int {
+	fixed := len(pinned)
+	fixedTokens := CountMessageTokens(c.tokenizer, pinned)
+	if summary != nil {
+		fixed++
+		fixedTokens += CountMessageTokens(c.tokenizer, []*Message{summary})
+	}
+
+	drop := 0
+	if c.MaxLen > 0 && fixed+len(turns) > c.MaxLen {
+		drop = fixed + len(turns) - c.MaxLen
+	}
+
+	if c.MaxTokens > 0 {
+		tokens := fixedTokens + CountMessageTokens(c.tokenizer, turns[drop:])
+		for tokens > c.MaxTokens && drop < len(turns) {
+			tokens -= CountMessageTokens(c.tokenizer, turns[drop:drop+1])
+			drop++
 		}
+	}
 
-		// 保留最近的消息
-		keepCount := c.MaxLen - len(systemMessages)
-		if keepCount > 0 && len(otherMessages) > keepCount {
-			otherMessages = otherMessages[len(otherMessages)-keepCount:]
+	// 截断后以用户消息开头，避免留下没有提问的回答或没有调用的工具结果
+	for drop > 0 && drop < len(turns) && turns[drop].Role != "user" {
+		drop++
+	}
+
+	// 全部超出时保留最后一个用户轮次
+	if drop >= len(turns) {
+		drop = len(turns) - 1
+		for i := len(turns) - 1; i >= 0; i-- {
+			if turns[i].Role == "user" {
+				drop = i
+				break
+			}
 		}
+	}
+	if drop < 0 {
+		drop = 0
+	}
+	return drop
+}
+
+// trim 丢弃超出限制的最早对话消息
+func (c *ConversationContext) trim() {
+	pinned, summary, turns := c.split()
+	drop := c.overflow(pinned, summary, turns)
+	if drop == 0 {
+		return
+	}
+	c.rebuild(pinned, summary, turns[drop:])
+}
+
+// compact 将需要淘汰的消息压缩为摘要
+func (c *ConversationContext) compact(ctx context.Context) error {
+	pinned, summary, turns := c.split()
+	drop := c.overflow(pinned, summary, turns)
+	if drop == 0 {
+		return nil
+	}
+
+	previous := ""
+	if summary != nil {
+		previous = strings.TrimPrefix(summary.Content, summaryPrefix)
+	}
+
+	text, err := c.summarizer.Summarize(ctx, previous, turns[:drop])
+	if err != nil {
+		return err
+	}
+
+	c.rebuild(pinned, &Message{Role: "system", Content: summaryPrefix + text}, turns[drop:])
+	return nil
+}
 
-		c.Messages = append(systemMessages, otherMessages...)
+// rebuild 按 系统消息 → 摘要 → 对话 的顺序重建消息列表
+func (c *ConversationContext) rebuild(pinned []*Message, summary *Message, turns []*Message) {
+	messages := make([]*Message, 0, len(pinned)+len(turns)+1)
+	messages = append(messages, pinned...)
+	if summary != nil {
+		messages = append(messages, summary)
 	}
+	c.Messages = append(messages, turns...)
 }
 
 // GetMessages 获取所有消息
@@ -251,40 +372,166 @@ func (c *ConversationContext) GetMessages() []*Message {
 	return c.Messages
 }
 
+// SetMessages 替换全部消息（用于从存储恢复对话）
+func (c *ConversationContext) SetMessages(messages []*Message) {
+	c.Messages = append(make([]*Message, 0, len(messages)), messages...)
+}
+
 // Clear 清空消息
 func (c *ConversationContext) Clear() {
 	c.Messages = make([]*Message, 0)
 }
 
+// defaultMaxToolSteps 默认最多执行的工具调用轮数
+const defaultMaxToolSteps = 5
+
+// ErrMaxStepsExceeded 工具调用轮数超过限制仍未得到回答
+var ErrMaxStepsExceeded = errors.New("agent exceeded max tool steps")
+
 // ConversationClient 对话客户端
 type ConversationClient struct {
-	client  *EinoClient
-	context *ConversationContext
+	client   *EinoClient
+	context  *ConversationContext
+	tools    *ToolRegistry
+	maxSteps int
+	usage    Usage
 }
 
-// NewConversationClient 创建对话客户端
+// NewConversationClient 创建对话客户端，令牌计数器按模型选择
 func NewConversationClient(client *EinoClient, maxContextLen int) *ConversationClient {
+	conversationContext := NewConversationContext(maxContextLen)
+	conversationContext.SetTokenizer(TokenizerForModel(client.config.Model))
+
 	return &ConversationClient{
-		client:  client,
-		context: NewConversationContext(maxContextLen),
+		client:   client,
+		context:  conversationContext,
+		maxSteps: defaultMaxToolSteps,
 	}
 }
 
+// SetTools 设置可供模型调用的工具，maxSteps 为最多执行的工具调用轮数（<=0 使用默认值）
+func (c *ConversationClient) SetTools(registry *ToolRegistry, maxSteps int) {
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxToolSteps
+	}
+	c.tools = registry
+	c.maxSteps = maxSteps
+}
+
+// SetTokenBudget 设置对话历史的令牌预算，0 表示不限制
+func (c *ConversationClient) SetTokenBudget(maxTokens int) {
+	c.context.SetMaxTokens(maxTokens)
+}
+
+// SetTokenizer 设置令牌计数器
+func (c *ConversationClient) SetTokenizer(tokenizer Tokenizer) {
+	c.context.SetTokenizer(tokenizer)
+}
+
+// EnableSummarization 使用同一客户端将较早的对话压缩为摘要
+func (c *ConversationClient) EnableSummarization() {
+	c.context.SetSummarizer(NewClientSummarizer(c.client))
+}
+
+// SetSummarizer 设置自定义摘要器，nil 表示关闭摘要
+func (c *ConversationClient) SetSummarizer(summarizer Summarizer) {
+	c.context.SetSummarizer(summarizer)
+}
+
 // Chat 对话聊天（保持上下文）
+// 设置了工具时，执行模型请求的工具调用并回传结果，直到模型给出回答或超过轮数限制
 func (c *ConversationClient) Chat(ctx context.Context, userMessage string, options ...ChatOption) (string, error) {
-	// 添加用户消息到上下文
-	c.context.AddMessage("user", userMessage)
+	return c.ChatWithContext(ctx, userMessage, nil, options...)
+}
 
-	// 获取响应
-	response, err := c.client.ChatCompletion(ctx, c.context.GetMessages(), options...)
-	if err != nil {
-		return "", err
+// ChatWithContext 对话聊天，contextMessages 只在本次请求中插入到最新的用户消息之前，不写入对话历史
+// 用于注入检索到的参考资料等临时上下文
+func (c *ConversationClient) ChatWithContext(ctx context.Context, userMessage string, contextMessages []*Message, options ...ChatOption) (string, error) {
+	// 添加用户消息到上下文，摘要失败时已退化为截断，不影响本次对话
+	_ = c.context.AddMessageContext(ctx, "user", userMessage)
+
+	if c.tools != nil {
+		options = append([]ChatOption{WithTools(c.tools.Definitions()...)}, options...)
 	}
 
-	// 添加助手响应到上下文
-	c.context.AddMessage("assistant", response.Message.Content)
+	c.usage = Usage{}
 
-	return response.Message.Content, nil
+	for step := 0; ; step++ {
+		// 获取响应
+		response, err := c.client.ChatCompletion(ctx, withContextMessages(c.context.GetMessages(), contextMessages), options...)
+		if err != nil {
+			return "", err
+		}
+		message := response.Message
+		if response.Usage != nil {
+			c.usage.PromptTokens += response.Usage.PromptTokens
+			c.usage.CompletionTokens += response.Usage.CompletionTokens
+			c.usage.TotalTokens += response.Usage.TotalTokens
+		}
+
+		if c.tools == nil || len(message.ToolCalls) == 0 {
+			// 添加助手响应到上下文
+			_ = c.context.AddMessageContext(ctx, "assistant", message.Content)
+			return message.Content, nil
+		}
+
+		if step >= c.maxSteps {
+			return "", fmt.Errorf("%w (%d)", ErrMaxStepsExceeded, c.maxSteps)
+		}
+
+		// 执行工具调用并将结果加入上下文
+		results := make([]*Message, 0, len(message.ToolCalls)+1)
+		results = append(results, &Message{
+			Role:      "assistant",
+			Content:   message.Content,
+			ToolCalls: message.ToolCalls,
+		})
+		for _, call := range message.ToolCalls {
+			results = append(results, c.tools.Execute(ctx, call))
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		_ = c.context.Append(ctx, results...)
+	}
+}
+
+// PrepareMessages 将用户消息加入历史，并返回插入临时上下文后的完整消息列表
+// 用于调用方自行发起请求（如流式输出）的场景，回答需通过 AddAssistantMessage 写回历史
+func (c *ConversationClient) PrepareMessages(ctx context.Context, userMessage string, contextMessages []*Message) []*Message {
+	_ = c.context.AddMessageContext(ctx, "user", userMessage)
+	return withContextMessages(c.context.GetMessages(), contextMessages)
+}
+
+// AddAssistantMessage 将助手回答写入对话历史
+func (c *ConversationClient) AddAssistantMessage(ctx context.Context, content string) {
+	_ = c.context.AddMessageContext(ctx, "assistant", content)
+}
+
+// withContextMessages 将临时上下文插入到最后一条用户消息之前
+func withContextMessages(messages, contextMessages []*Message) []*Message {
+	if len(contextMessages) == 0 {
+		return messages
+	}
+
+	at := len(messages)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			at = i
+			break
+		}
+	}
+
+	result := make([]*Message, 0, len(messages)+len(contextMessages))
+	result = append(result, messages[:at]...)
+	result = append(result, contextMessages...)
+	return append(result, messages[at:]...)
+}
+
+// LastUsage 最近一次Chat消耗的令牌（包含工具调用的各轮请求）
+func (c *ConversationClient) LastUsage() *Usage {
+	usage := c.usage
+	return &usage
 }
 
 // ClearHistory 清空对话历史
@@ -297,6 +544,11 @@ func (c *ConversationClient) GetHistory() []*Message {
 	return c.context.GetMessages()
 }
 
+// LoadHistory 载入已有的对话历史
+func (c *ConversationClient) LoadHistory(messages []*Message) {
+	c.context.SetMessages(messages)
+}
+
 // Close 关闭客户端
 func (e *EinoClient) Close() error {
 	return e.aiClient.Close()
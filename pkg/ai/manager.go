@@ -3,6 +3,7 @@ package ai
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sync"
 )
 
@@ -10,6 +11,9 @@ import (
 type Manager struct {
 	clients     map[string]*Client
 	configs     map[string]*Config
+	breakers    map[string]*circuitBreaker
+	policy      *RoutingPolicy
+	randIntn    func(n int) int
 	mutex       sync.RWMutex
 	defaultName string
 }
@@ -17,8 +21,11 @@ type Manager struct {
 // NewManager 创建AI管理器
 func NewManager() *Manager {
 	return &Manager{
-		clients: make(map[string]*Client),
-		configs: make(map[string]*Config),
+		clients:  make(map[string]*Client),
+		configs:  make(map[string]*Config),
+		breakers: make(map[string]*circuitBreaker),
+		policy:   DefaultRoutingPolicy(),
+		randIntn: rand.Intn,
 	}
 }
 
@@ -95,6 +102,7 @@ func (m *Manager) RemoveClient(name string) error {
 
 	delete(m.clients, name)
 	delete(m.configs, name)
+	delete(m.breakers, name)
 
 	// 如果删除的是默认客户端，重新选择默认客户端
 	if m.defaultName == name {
@@ -122,31 +130,113 @@ func (m *Manager) ListClients() []string {
 	return names
 }
 
-// Chat 使用指定客户端进行聊天
+// Chat 使用指定客户端进行聊天，失败时按路由策略重试或转移，
+// 实际应答的客户端记录在 ChatResponse.Metadata 中
 func (m *Manager) Chat(ctx context.Context, clientName string, req *ChatRequest) (*ChatResponse, error) {
-	client, err := m.GetClient(clientName)
+	var response *ChatResponse
+	meta, err := m.route(ctx, clientName, func(ctx context.Context, client *Client) error {
+		resp, err := client.Chat(ctx, req)
+		if err != nil {
+			return err
+		}
+		response = resp
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return client.Chat(ctx, req)
+
+	response.Metadata = meta
+	return response, nil
+}
+
+// StreamChat 流式聊天，在收到首个分片前出错时按路由策略重试或转移，
+// 路由记录附加在 Done 分片的 Metadata 中
+func (m *Manager) StreamChat(ctx context.Context, clientName string, req *ChatRequest) (<-chan *StreamResponse, <-chan error) {
+	responseCh := make(chan *StreamResponse, 100)
+	errorCh := make(chan error, 1)
+
+	go func() {
+		defer close(responseCh)
+		defer close(errorCh)
+
+		var streamCh <-chan *StreamResponse
+		var streamErrCh <-chan error
+		var first *StreamResponse
+
+		meta, err := m.route(ctx, clientName, func(ctx context.Context, client *Client) error {
+			ch, errCh := client.StreamChat(ctx, req)
+			response, ok := <-ch
+			if !ok {
+				if err := <-errCh; err != nil {
+					return err
+				}
+				return fmt.Errorf("stream closed without response")
+			}
+			streamCh, streamErrCh, first = ch, errCh, response
+			return nil
+		})
+		if err != nil {
+			errorCh <- err
+			return
+		}
+
+		for response := first; ; {
+			if response.Done {
+				response.Metadata = meta
+			}
+			select {
+			case responseCh <- response:
+			case <-ctx.Done():
+				return
+			}
+
+			next, ok := <-streamCh
+			if !ok {
+				if err := <-streamErrCh; err != nil {
+					errorCh <- err
+				}
+				return
+			}
+			response = next
+		}
+	}()
+
+	return responseCh, errorCh
 }
 
-// CreateCompletion 使用指定客户端进行文本补全
+// CreateCompletion 使用指定客户端进行文本补全（遵循路由策略）
 func (m *Manager) CreateCompletion(ctx context.Context, clientName string, prompt string, options ...Option) (string, error) {
-	client, err := m.GetClient(clientName)
-	if err != nil {
-		return "", err
-	}
-	return client.CreateCompletion(ctx, prompt, options...)
+	var result string
+	_, err := m.route(ctx, clientName, func(ctx context.Context, client *Client) error {
+		text, err := client.CreateCompletion(ctx, prompt, options...)
+		if err != nil {
+			return err
+		}
+		result = text
+		return nil
+	})
+	return result, err
 }
 
 // CreateEmbedding 使用指定客户端创建嵌入向量
+// 不同客户端的向量空间不兼容，因此只在同一客户端上重试，不做故障转移
 func (m *Manager) CreateEmbedding(ctx context.Context, clientName string, texts []string) ([][]float64, error) {
 	client, err := m.GetClient(clientName)
 	if err != nil {
 		return nil, err
 	}
-	return client.CreateEmbedding(ctx, texts)
+
+	policy := m.GetRoutingPolicy()
+	for attempt := 0; ; attempt++ {
+		embeddings, err := client.CreateEmbedding(ctx, texts)
+		if err == nil || !IsRetryableError(err) || attempt >= policy.MaxRetries {
+			return embeddings, err
+		}
+		if err := sleepContext(ctx, backoff(policy, attempt)); err != nil {
+			return nil, err
+		}
+	}
 }
 
 // Close 关闭所有客户端
@@ -162,6 +252,7 @@ func (m *Manager) Close() error {
 
 	m.clients = make(map[string]*Client)
 	m.configs = make(map[string]*Config)
+	m.breakers = make(map[string]*circuitBreaker)
 	m.defaultName = ""
 
 	return nil
@@ -0,0 +1,388 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAIProvider OpenAI兼容接口适配器（chat/completions 与 embeddings）
+type OpenAIProvider struct {
+	config     *Config
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOpenAIProvider 创建OpenAI兼容适配器
+func NewOpenAIProvider(config *Config, httpClient *http.Client) *OpenAIProvider {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURLs[ProviderType(strings.ToLower(config.Provider))]
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURLs[ProviderOpenAI]
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &OpenAIProvider{
+		config:     config,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// openAIChatRequest chat/completions 请求体
+type openAIChatRequest struct {
+	Model         string               `json:"model"`
+	Messages      []*Message           `json:"messages"`
+	Temperature   *float64             `json:"temperature,omitempty"`
+	MaxTokens     *int                 `json:"max_tokens,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+	Tools         []openAITool         `json:"tools,omitempty"`
+	ToolChoice    interface{}          `json:"tool_choice,omitempty"`
+}
+
+// openAITool 工具定义
+type openAITool struct {
+	Type     string          `json:"type"`
+	Function *ToolDefinition `json:"function"`
+}
+
+// openAIStreamOptions 流式选项
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIChatResponse chat/completions 响应体
+type openAIChatResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int      `json:"index"`
+		Message      *Message `json:"message"`
+		FinishReason string   `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+}
+
+// openAIStreamChunk 流式响应分片
+type openAIStreamChunk struct {
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+}
+
+// openAIEmbeddingRequest embeddings 请求体
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// openAIEmbeddingResponse embeddings 响应体
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Usage *Usage `json:"usage"`
+}
+
+// openAIErrorResponse 错误响应体
+type openAIErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// buildChatRequest 构建请求体，未在请求中指定的参数使用客户端配置
+func (p *OpenAIProvider) buildChatRequest(req *ChatRequest, stream bool) *openAIChatRequest {
+	body := &openAIChatRequest{
+		Model:       p.config.Model,
+		Messages:    req.Messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Stream:      stream,
+	}
+
+	if body.Temperature == nil && p.config.Temperature > 0 {
+		temperature := p.config.Temperature
+		body.Temperature = &temperature
+	}
+	if body.MaxTokens == nil && p.config.MaxTokens > 0 {
+		maxTokens := p.config.MaxTokens
+		body.MaxTokens = &maxTokens
+	}
+	if stream {
+		body.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+
+	for _, tool := range req.Tools {
+		body.Tools = append(body.Tools, openAITool{Type: "function", Function: tool})
+	}
+	switch req.ToolChoice {
+	case "":
+	case "auto", "none", "required":
+		body.ToolChoice = req.ToolChoice
+	default:
+		body.ToolChoice = map[string]interface{}{
+			"type":     "function",
+			"function": map[string]string{"name": req.ToolChoice},
+		}
+	}
+
+	return body
+}
+
+// Chat 聊天对话
+func (p *OpenAIProvider) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout(p.config))
+	defer cancel()
+
+	resp, err := p.post(ctx, "/chat/completions", p.buildChatRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result openAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode chat response: %w", err)
+	}
+
+	if len(result.Choices) == 0 || result.Choices[0].Message == nil {
+		return nil, fmt.Errorf("chat response contains no choices")
+	}
+
+	return &ChatResponse{
+		Message: result.Choices[0].Message,
+		Usage:   result.Usage,
+	}, nil
+}
+
+// StreamChat 流式聊天（SSE）
+func (p *OpenAIProvider) StreamChat(ctx context.Context, req *ChatRequest) (<-chan *StreamResponse, <-chan error) {
+	responseCh := make(chan *StreamResponse, 100)
+	errorCh := make(chan error, 1)
+
+	go func() {
+		defer close(responseCh)
+		defer close(errorCh)
+
+		resp, err := p.post(ctx, "/chat/completions", p.buildChatRequest(req, true))
+		if err != nil {
+			errorCh <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		var content strings.Builder
+		var usage *Usage
+
+		err = readSSE(resp.Body, func(event, data string) (bool, error) {
+			if data == "[DONE]" {
+				return false, nil
+			}
+
+			var chunk openAIStreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				return false, fmt.Errorf("failed to decode stream chunk: %w", err)
+			}
+
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+
+			for _, choice := range chunk.Choices {
+				if choice.Delta.Content == "" {
+					continue
+				}
+				content.WriteString(choice.Delta.Content)
+				select {
+				case responseCh <- &StreamResponse{Delta: choice.Delta.Content, Message: content.String()}:
+				case <-ctx.Done():
+					return false, ctx.Err()
+				}
+			}
+
+			return true, nil
+		})
+		if err != nil {
+			errorCh <- err
+			return
+		}
+
+		select {
+		case responseCh <- &StreamResponse{Message: content.String(), Done: true, Usage: usage}:
+		case <-ctx.Done():
+		}
+	}()
+
+	return responseCh, errorCh
+}
+
+// CreateEmbedding 创建嵌入向量
+func (p *OpenAIProvider) CreateEmbedding(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return [][]float64{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout(p.config))
+	defer cancel()
+
+	body := &openAIEmbeddingRequest{
+		Model: p.embeddingModel(),
+		Input: texts,
+	}
+
+	resp, err := p.post(ctx, "/embeddings", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result openAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding count mismatch: expected %d, got %d", len(texts), len(result.Data))
+	}
+
+	// 按index还原输入顺序
+	embeddings := make([][]float64, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		embeddings[item.Index] = item.Embedding
+	}
+
+	return embeddings, nil
+}
+
+// embeddingModel 获取嵌入模型名称
+func (p *OpenAIProvider) embeddingModel() string {
+	defaultModel := p.config.Model
+	if ProviderType(strings.ToLower(p.config.Provider)) == ProviderOpenAI || p.config.Provider == "" {
+		defaultModel = "text-embedding-3-small"
+	}
+	return optionString(p.config.Options, "embedding_model", defaultModel)
+}
+
+// post 发送JSON请求，非2xx状态码转换为APIError
+func (p *OpenAIProvider) post(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+		apiErr := &APIError{
+			Provider:   p.providerName(),
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(data)),
+		}
+
+		var errResp openAIErrorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error.Message != "" {
+			apiErr.Message = errResp.Error.Message
+			apiErr.Type = errResp.Error.Type
+		}
+
+		return nil, apiErr
+	}
+
+	return resp, nil
+}
+
+// providerName 提供商名称
+func (p *OpenAIProvider) providerName() string {
+	if p.config.Provider == "" {
+		return string(ProviderOpenAI)
+	}
+	return p.config.Provider
+}
+
+// readSSE 逐个读取SSE事件，handler返回false时停止读取
+func readSSE(r io.Reader, handler func(event, data string) (bool, error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// 空行表示一个事件结束
+		if line == "" {
+			if len(data) > 0 {
+				next, err := handler(event, strings.Join(data, "\n"))
+				if err != nil || !next {
+					return err
+				}
+			}
+			event = ""
+			data = data[:0]
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue // 注释/心跳
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	// 处理未以空行结尾的最后一个事件
+	if len(data) > 0 {
+		_, err := handler(event, strings.Join(data, "\n"))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestOpenAIClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&Config{
+		Provider:    "openai",
+		APIKey:      "test-key",
+		BaseURL:     server.URL,
+		Model:       "gpt-test",
+		Temperature: 0.5,
+		MaxTokens:   256,
+	})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	return client
+}
+
+func TestOpenAIProvider_Chat(t *testing.T) {
+	client := newTestOpenAIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("unexpected authorization header: %s", got)
+		}
+
+		var body openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if body.Model != "gpt-test" {
+			t.Errorf("expected model gpt-test, got %s", body.Model)
+		}
+		if body.Temperature == nil || *body.Temperature != 0.5 {
+			t.Errorf("expected default temperature 0.5, got %v", body.Temperature)
+		}
+		if body.MaxTokens == nil || *body.MaxTokens != 64 {
+			t.Errorf("expected request max_tokens 64, got %v", body.MaxTokens)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"Hello!"},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`)
+	})
+
+	maxTokens := 64
+	resp, err := client.Chat(context.Background(), &ChatRequest{
+		Messages:  []*Message{{Role: "user", Content: "Hi"}},
+		MaxTokens: &maxTokens,
+	})
+	if err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+
+	if resp.Message.Content != "Hello!" {
+		t.Errorf("expected content Hello!, got %s", resp.Message.Content)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 15 {
+		t.Errorf("expected total tokens 15, got %+v", resp.Usage)
+	}
+}
+
+func TestOpenAIProvider_ChatAPIError(t *testing.T) {
+	client := newTestOpenAIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"message":"rate limited","type":"rate_limit_error"}}`)
+	})
+
+	_, err := client.Chat(context.Background(), &ChatRequest{
+		Messages: []*Message{{Role: "user", Content: "Hi"}},
+	})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Message != "rate limited" {
+		t.Errorf("unexpected api error: %+v", apiErr)
+	}
+}
+
+func TestOpenAIProvider_StreamChat(t *testing.T) {
+	client := newTestOpenAIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body openAIChatRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if !body.Stream || body.StreamOptions == nil || !body.StreamOptions.IncludeUsage {
+			t.Errorf("expected stream request with include_usage")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":2,\"total_tokens\":7}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	responseCh, errorCh := client.StreamChat(context.Background(), &ChatRequest{
+		Messages: []*Message{{Role: "user", Content: "Hi"}},
+		Stream:   true,
+	})
+
+	var deltas []string
+	var last *StreamResponse
+	for response := range responseCh {
+		if response.Delta != "" {
+			deltas = append(deltas, response.Delta)
+		}
+		last = response
+	}
+	if err := <-errorCh; err != nil {
+		t.Fatalf("StreamChat error: %v", err)
+	}
+
+	if len(deltas) != 2 || deltas[0] != "Hel" || deltas[1] != "lo" {
+		t.Errorf("unexpected deltas: %v", deltas)
+	}
+	if last == nil || !last.Done || last.Message != "Hello" {
+		t.Fatalf("unexpected final response: %+v", last)
+	}
+	if last.Usage == nil || last.Usage.TotalTokens != 7 {
+		t.Errorf("expected total tokens 7, got %+v", last.Usage)
+	}
+}
+
+func TestOpenAIProvider_CreateEmbedding(t *testing.T) {
+	client := newTestOpenAIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var body openAIEmbeddingRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "text-embedding-3-small" {
+			t.Errorf("unexpected embedding model: %s", body.Model)
+		}
+
+		// 故意打乱顺序，验证按index还原
+		fmt.Fprint(w, `{"data":[{"index":1,"embedding":[0.3,0.4]},{"index":0,"embedding":[0.1,0.2]}],
+			"usage":{"prompt_tokens":4,"total_tokens":4}}`)
+	})
+
+	embeddings, err := client.CreateEmbedding(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("CreateEmbedding error: %v", err)
+	}
+
+	if len(embeddings) != 2 || embeddings[0][0] != 0.1 || embeddings[1][0] != 0.3 {
+		t.Errorf("unexpected embeddings: %v", embeddings)
+	}
+}
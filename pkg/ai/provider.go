@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Provider AI提供商适配器接口
+type Provider interface {
+	// Chat 聊天对话
+	Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error)
+
+	// StreamChat 流式聊天
+	StreamChat(ctx context.Context, req *ChatRequest) (<-chan *StreamResponse, <-chan error)
+
+	// CreateEmbedding 创建嵌入向量
+	CreateEmbedding(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// APIError 提供商返回的错误
+type APIError struct {
+	Provider   string `json:"provider"`
+	StatusCode int    `json:"status_code"`
+	Type       string `json:"type,omitempty"`
+	Message    string `json:"message"`
+}
+
+// Error 实现error接口
+func (e *APIError) Error() string {
+	if e.Type != "" {
+		return fmt.Sprintf("%s api error (status %d, %s): %s", e.Provider, e.StatusCode, e.Type, e.Message)
+	}
+	return fmt.Sprintf("%s api error (status %d): %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// defaultBaseURLs OpenAI兼容提供商的默认接口地址
+var defaultBaseURLs = map[ProviderType]string{
+	ProviderOpenAI:   "https://api.openai.com/v1",
+	ProviderDoubao:   "https://ark.cn-beijing.volces.com/api/v3",
+	ProviderQianwen:  "https://dashscope.aliyuncs.com/compatible-mode/v1",
+	ProviderChatGLM:  "https://open.bigmodel.cn/api/paas/v4",
+	ProviderBaichuan: "https://api.baichuan-ai.com/v1",
+	ProviderMiniMax:  "https://api.minimax.chat/v1",
+}
+
+// newProvider 根据配置创建提供商适配器
+func newProvider(config *Config) (Provider, error) {
+	httpClient := &http.Client{
+		// 流式响应可能持续较长时间，超时由请求上下文控制
+		Timeout: 0,
+	}
+
+	switch ProviderType(strings.ToLower(config.Provider)) {
+	case "", ProviderOpenAI, ProviderDoubao, ProviderQianwen, ProviderChatGLM, ProviderBaichuan, ProviderMiniMax:
+		return NewOpenAIProvider(config, httpClient), nil
+	case ProviderAnthropic:
+		return NewAnthropicProvider(config, httpClient), nil
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", config.Provider)
+	}
+}
+
+// requestTimeout 获取单次非流式请求的超时时间
+func requestTimeout(config *Config) time.Duration {
+	if seconds := optionInt(config.Options, "request_timeout", 0); seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 60 * time.Second
+}
+
+// optionString 读取字符串选项
+func optionString(options map[string]interface{}, key, defaultValue string) string {
+	if options == nil {
+		return defaultValue
+	}
+	if value, ok := options[key].(string); ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// optionInt 读取整数选项（兼容JSON解码得到的float64）
+func optionInt(options map[string]interface{}, key string, defaultValue int) int {
+	if options == nil {
+		return defaultValue
+	}
+	switch value := options[key].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	}
+	return defaultValue
+}
+
+// optionFloat 读取浮点数选项
+func optionFloat(options map[string]interface{}, key string, defaultValue float64) float64 {
+	if options == nil {
+		return defaultValue
+	}
+	switch value := options[key].(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	}
+	return defaultValue
+}
@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RAGSource 检索增强生成（RAG）的参考资料
+type RAGSource struct {
+	Index   int     `json:"index"` // 引用编号，从1开始
+	ID      string  `json:"id"`
+	Title   string  `json:"title"`
+	Content string  `json:"-"`
+	Score   float64 `json:"score"`
+}
+
+// DefaultGroundingPrompt 默认的检索增强提示词
+const DefaultGroundingPrompt = "Answer the user's question using only the numbered sources below. " +
+	"Cite the sources you use inline as [n]. If the sources do not contain the answer, " +
+	"say that you don't know instead of guessing. Answer in the language of the question."
+
+// GroundingMessage 将参考资料编号后生成系统消息，prompt 为空时使用默认提示词
+func GroundingMessage(prompt string, sources []RAGSource) *Message {
+	if prompt == "" {
+		prompt = DefaultGroundingPrompt
+	}
+
+	var content strings.Builder
+	content.WriteString(prompt)
+	content.WriteString("\n\nSources:")
+	for _, source := range sources {
+		fmt.Fprintf(&content, "\n\n[%d] %s\n%s", source.Index, source.Title, source.Content)
+	}
+	if len(sources) == 0 {
+		content.WriteString("\n\n(no relevant sources found)")
+	}
+
+	return &Message{Role: "system", Content: content.String()}
+}
+
+// FitSources 按顺序保留令牌数不超过 maxTokens 的参考资料并重新编号，0 表示不限制
+func FitSources(tokenizer Tokenizer, sources []RAGSource, maxTokens int) []RAGSource {
+	if tokenizer == nil {
+		tokenizer = EstimateTokenizer
+	}
+
+	fitted := make([]RAGSource, 0, len(sources))
+	used := 0
+	for _, source := range sources {
+		tokens := tokenizer.CountTokens(source.Title) + tokenizer.CountTokens(source.Content) + messageTokenOverhead
+		if maxTokens > 0 && used+tokens > maxTokens {
+			break
+		}
+		used += tokens
+		source.Index = len(fitted) + 1
+		fitted = append(fitted, source)
+	}
+	return fitted
+}
+
+// citationPattern 匹配 [1]、[2, 3] 形式的引用
+var citationPattern = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// CitedIndexes 解析回答中引用的资料编号（去重、按出现顺序）
+func CitedIndexes(answer string) []int {
+	seen := make(map[int]bool)
+	var indexes []int
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		for _, part := range strings.Split(match[1], ",") {
+			index, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || seen[index] {
+				continue
+			}
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
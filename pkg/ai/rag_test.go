@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroundingMessage(t *testing.T) {
+	sources := FitSources(nil, []RAGSource{
+		{ID: "a", Title: "Install guide", Content: "Run make install."},
+		{ID: "b", Title: "Upgrade guide", Content: strings.Repeat("long text ", 100)},
+		{ID: "c", Title: "FAQ", Content: "Short."},
+	}, 50)
+	if len(sources) != 1 || sources[0].Index != 1 {
+		t.Fatalf("expected only the first source to fit, got %+v", sources)
+	}
+
+	message := GroundingMessage("", sources)
+	if message.Role != "system" || !strings.Contains(message.Content, "[1] Install guide\nRun make install.") {
+		t.Errorf("unexpected grounding message: %q", message.Content)
+	}
+	if empty := GroundingMessage("", nil); !strings.Contains(empty.Content, "no relevant sources") {
+		t.Errorf("expected empty marker, got %q", empty.Content)
+	}
+}
+
+func TestCitedIndexes(t *testing.T) {
+	indexes := CitedIndexes("Use make [2]. See also [1, 3] and again [2].")
+	if !reflect.DeepEqual(indexes, []int{2, 1, 3}) {
+		t.Errorf("unexpected citations: %v", indexes)
+	}
+	if indexes := CitedIndexes("no citations"); len(indexes) != 0 {
+		t.Errorf("expected none, got %v", indexes)
+	}
+}
+
+func TestConversationClient_ChatWithContext(t *testing.T) {
+	var received []*Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		received = body.Messages
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Answer [1]"}}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestConversationClient(t, server)
+	client.LoadHistory([]*Message{{Role: "user", Content: "earlier"}, {Role: "assistant", Content: "reply"}})
+
+	grounding := GroundingMessage("", []RAGSource{{Index: 1, Title: "Doc", Content: "Fact"}})
+	answer, err := client.ChatWithContext(context.Background(), "question", []*Message{grounding})
+	if err != nil || answer != "Answer [1]" {
+		t.Fatalf("unexpected answer %q (%v)", answer, err)
+	}
+
+	// 临时上下文插入在最新的用户消息之前
+	if len(received) != 4 || received[2].Role != "system" || received[3].Content != "question" {
+		t.Errorf("unexpected request messages: %+v", received)
+	}
+	// 对话历史中不保留临时上下文
+	for _, msg := range client.GetHistory() {
+		if msg.Role == "system" {
+			t.Errorf("grounding leaked into history: %+v", msg)
+		}
+	}
+	if len(client.GetHistory()) != 4 {
+		t.Errorf("expected 4 history messages, got %d", len(client.GetHistory()))
+	}
+}
@@ -0,0 +1,432 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RoutingPolicy 路由策略：故障转移、重试、熔断与负载分摊
+type RoutingPolicy struct {
+	// Fallbacks 故障转移链，键为客户端名称，"*" 为所有客户端的默认链
+	Fallbacks map[string][]string `json:"fallbacks"`
+
+	// MaxRetries 单个客户端遇到429/5xx/网络错误时的最大重试次数
+	MaxRetries int `json:"max_retries"`
+
+	// RetryDelay 首次重试等待时间，之后指数退避
+	RetryDelay time.Duration `json:"retry_delay"`
+
+	// MaxRetryDelay 退避上限
+	MaxRetryDelay time.Duration `json:"max_retry_delay"`
+
+	// BreakerThreshold 连续失败多少次后熔断，0表示不熔断
+	BreakerThreshold int `json:"breaker_threshold"`
+
+	// BreakerCooldown 熔断持续时间，过后允许一次试探请求
+	BreakerCooldown time.Duration `json:"breaker_cooldown"`
+
+	// LoadBalance 是否在同一模型家族的客户端之间按权重分摊请求
+	LoadBalance bool `json:"load_balance"`
+}
+
+// DefaultRoutingPolicy 默认路由策略
+func DefaultRoutingPolicy() *RoutingPolicy {
+	return &RoutingPolicy{
+		Fallbacks:        make(map[string][]string),
+		MaxRetries:       2,
+		RetryDelay:       500 * time.Millisecond,
+		MaxRetryDelay:    5 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// RoutingAttempt 单次调用尝试
+type RoutingAttempt struct {
+	Client     string        `json:"client"`
+	Attempt    int           `json:"attempt"`
+	Skipped    string        `json:"skipped,omitempty"` // 跳过原因，如 circuit_open
+	Error      string        `json:"error,omitempty"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// RoutingMetadata 路由决策记录
+type RoutingMetadata struct {
+	Requested string           `json:"requested"`
+	Client    string           `json:"client"`
+	Provider  string           `json:"provider"`
+	Model     string           `json:"model"`
+	Fallback  bool             `json:"fallback"`
+	Attempts  []RoutingAttempt `json:"attempts"`
+}
+
+// RoutingError 所有候选客户端均失败
+type RoutingError struct {
+	Metadata *RoutingMetadata
+	Err      error
+}
+
+// Error 实现error接口
+func (e *RoutingError) Error() string {
+	return fmt.Sprintf("all ai clients failed (requested %s, %d attempts): %v", e.Metadata.Requested, len(e.Metadata.Attempts), e.Err)
+}
+
+// Unwrap 返回最后一个错误
+func (e *RoutingError) Unwrap() error {
+	return e.Err
+}
+
+// ErrCircuitOpen 客户端处于熔断状态
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// circuitBreaker 单个客户端的熔断器
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow 检查是否允许请求；熔断冷却结束后只放行一个试探请求
+func (b *circuitBreaker) allow(now time.Time, threshold int) bool {
+	if threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// success 记录成功，关闭熔断
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.openUntil = time.Time{}
+}
+
+// failure 记录失败，达到阈值后打开熔断
+func (b *circuitBreaker) failure(now time.Time, threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if threshold > 0 && b.failures >= threshold {
+		b.openUntil = now.Add(cooldown)
+	}
+}
+
+// release 结束试探请求但不计入成功或失败，让下一个请求可以重新试探
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// state 熔断状态描述
+func (b *circuitBreaker) state(now time.Time, threshold int) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case threshold <= 0 || b.failures < threshold:
+		return "closed"
+	case now.Before(b.openUntil):
+		return "open"
+	default:
+		return "half-open"
+	}
+}
+
+// IsRetryableError 判断错误是否值得在同一客户端上重试（408、429、5xx、网络错误）
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 408 || apiErr.StatusCode == 429 || apiErr.StatusCode >= 500
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return !errors.Is(urlErr.Err, context.Canceled)
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ModelFamily 获取客户端的模型家族，可通过 Options["family"] 指定，默认取模型名称的第一段
+func ModelFamily(config *Config) string {
+	if family := optionString(config.Options, "family", ""); family != "" {
+		return strings.ToLower(family)
+	}
+
+	model := strings.ToLower(config.Model)
+	if i := strings.IndexAny(model, "-_:/"); i > 0 {
+		return model[:i]
+	}
+	return model
+}
+
+// clientWeight 获取客户端负载权重，默认1
+func clientWeight(config *Config) int {
+	weight := optionInt(config.Options, "weight", 1)
+	if weight < 0 {
+		return 0
+	}
+	return weight
+}
+
+// SetRoutingPolicy 设置路由策略
+func (m *Manager) SetRoutingPolicy(policy *RoutingPolicy) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if policy == nil {
+		policy = DefaultRoutingPolicy()
+	}
+	if policy.Fallbacks == nil {
+		policy.Fallbacks = make(map[string][]string)
+	}
+	m.policy = policy
+}
+
+// GetRoutingPolicy 获取路由策略
+func (m *Manager) GetRoutingPolicy() *RoutingPolicy {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.policy
+}
+
+// SetFallbacks 设置客户端的故障转移链
+func (m *Manager) SetFallbacks(name string, fallbacks ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.policy.Fallbacks[name] = fallbacks
+}
+
+// CircuitState 获取客户端的熔断状态：closed、open、half-open
+func (m *Manager) CircuitState(name string) string {
+	m.mutex.RLock()
+	breaker, exists := m.breakers[name]
+	threshold := m.policy.BreakerThreshold
+	m.mutex.RUnlock()
+
+	if !exists {
+		return "closed"
+	}
+	return breaker.state(time.Now(), threshold)
+}
+
+// candidates 计算候选客户端顺序：负载分摊 → 请求的客户端 → 故障转移链
+func (m *Manager) candidates(requested string) []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	order := make([]string, 0, len(m.clients))
+	add := func(name string) {
+		if _, exists := m.clients[name]; exists && !seen[name] {
+			seen[name] = true
+			order = append(order, name)
+		}
+	}
+
+	if m.policy.LoadBalance {
+		for _, name := range m.familyOrder(requested) {
+			add(name)
+		}
+	}
+	add(requested)
+
+	fallbacks, exists := m.policy.Fallbacks[requested]
+	if !exists {
+		fallbacks = m.policy.Fallbacks["*"]
+	}
+	for _, name := range fallbacks {
+		add(name)
+	}
+
+	return order
+}
+
+// familyOrder 对同一模型家族的客户端按权重随机排序（调用方持有读锁）
+func (m *Manager) familyOrder(requested string) []string {
+	config, exists := m.configs[requested]
+	if !exists {
+		return nil
+	}
+	family := ModelFamily(config)
+
+	type weighted struct {
+		name   string
+		weight int
+	}
+	pool := make([]weighted, 0)
+	for name, cfg := range m.configs {
+		if ModelFamily(cfg) == family {
+			if w := clientWeight(cfg); w > 0 {
+				pool = append(pool, weighted{name: name, weight: w})
+			}
+		}
+	}
+	// 保证随机数相同时结果稳定
+	sort.Slice(pool, func(i, j int) bool { return pool[i].name < pool[j].name })
+
+	order := make([]string, 0, len(pool))
+	for len(pool) > 0 {
+		total := 0
+		for _, item := range pool {
+			total += item.weight
+		}
+		pick := m.randIntn(total)
+		for i, item := range pool {
+			if pick < item.weight {
+				order = append(order, item.name)
+				pool = append(pool[:i], pool[i+1:]...)
+				break
+			}
+			pick -= item.weight
+		}
+	}
+	return order
+}
+
+// breaker 获取客户端的熔断器
+func (m *Manager) breaker(name string) *circuitBreaker {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	b, exists := m.breakers[name]
+	if !exists {
+		b = &circuitBreaker{}
+		m.breakers[name] = b
+	}
+	return b
+}
+
+// route 按路由策略依次尝试候选客户端，call 返回错误时重试或转移到下一个客户端
+func (m *Manager) route(ctx context.Context, clientName string, call func(ctx context.Context, client *Client) error) (*RoutingMetadata, error) {
+	if clientName == "" {
+		clientName = m.GetDefault()
+	}
+	if _, err := m.GetClient(clientName); err != nil {
+		return nil, err
+	}
+
+	policy := m.GetRoutingPolicy()
+	meta := &RoutingMetadata{Requested: clientName}
+	var lastErr error
+
+	for _, name := range m.candidates(clientName) {
+		client, err := m.GetClient(name)
+		if err != nil {
+			continue
+		}
+		breaker := m.breaker(name)
+
+		for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
+			if !breaker.allow(time.Now(), policy.BreakerThreshold) {
+				meta.Attempts = append(meta.Attempts, RoutingAttempt{Client: name, Attempt: attempt + 1, Skipped: "circuit_open"})
+				lastErr = fmt.Errorf("client %s: %w", name, ErrCircuitOpen)
+				break
+			}
+
+			start := time.Now()
+			err := call(ctx, client)
+			record := RoutingAttempt{Client: name, Attempt: attempt + 1, Duration: time.Since(start)}
+
+			if err == nil {
+				breaker.success()
+				meta.Attempts = append(meta.Attempts, record)
+				meta.Client = name
+				meta.Provider = client.GetConfig().Provider
+				meta.Model = client.GetConfig().Model
+				meta.Fallback = name != clientName
+				return meta, nil
+			}
+
+			record.Error = err.Error()
+			var apiErr *APIError
+			if errors.As(err, &apiErr) {
+				record.StatusCode = apiErr.StatusCode
+			}
+			meta.Attempts = append(meta.Attempts, record)
+			lastErr = err
+
+			// 调用方取消时立即返回，不计入熔断
+			if ctx.Err() != nil {
+				breaker.release()
+				return meta, &RoutingError{Metadata: meta, Err: ctx.Err()}
+			}
+
+			// 只有超时、限流、5xx 和网络错误说明上游异常，请求本身的错误（400、401、422等）不计入熔断
+			if !IsRetryableError(err) {
+				breaker.release()
+				break
+			}
+			breaker.failure(time.Now(), policy.BreakerThreshold, policy.BreakerCooldown)
+
+			if attempt == policy.MaxRetries {
+				break
+			}
+			if err := sleepContext(ctx, backoff(policy, attempt)); err != nil {
+				return meta, &RoutingError{Metadata: meta, Err: err}
+			}
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no available client")
+	}
+	return meta, &RoutingError{Metadata: meta, Err: lastErr}
+}
+
+// backoff 计算第n次重试的等待时间
+func backoff(policy *RoutingPolicy, attempt int) time.Duration {
+	delay := policy.RetryDelay << attempt
+	if policy.MaxRetryDelay > 0 && (delay > policy.MaxRetryDelay || delay <= 0) {
+		delay = policy.MaxRetryDelay
+	}
+	return delay
+}
+
+// sleepContext 可被上下文取消的等待
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,262 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestServer 返回一个按 status 响应的OpenAI兼容服务器及其调用计数
+func newTestServer(t *testing.T, status *int32, content string) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if code := atomic.LoadInt32(status); code != http.StatusOK {
+			w.WriteHeader(int(code))
+			fmt.Fprint(w, `{"error":{"message":"upstream failure"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%q}}],"usage":{"total_tokens":1}}`, content)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newTestManager(t *testing.T, policy *RoutingPolicy, servers map[string]*httptest.Server, models map[string]string) *Manager {
+	t.Helper()
+
+	manager := NewManager()
+	manager.SetRoutingPolicy(policy)
+	for _, name := range []string{"openai", "qianwen", "doubao"} {
+		server, ok := servers[name]
+		if !ok {
+			continue
+		}
+		if err := manager.AddClient(name, &Config{
+			Provider: name,
+			APIKey:   "key",
+			BaseURL:  server.URL,
+			Model:    models[name],
+			Options:  map[string]interface{}{"weight": 1},
+		}); err != nil {
+			t.Fatalf("AddClient error: %v", err)
+		}
+	}
+	return manager
+}
+
+func testChatRequest() *ChatRequest {
+	return &ChatRequest{Messages: []*Message{{Role: "user", Content: "hi"}}}
+}
+
+func TestManager_FallbackChain(t *testing.T) {
+	failing := int32(http.StatusServiceUnavailable)
+	ok := int32(http.StatusOK)
+	openaiServer, openaiCalls := newTestServer(t, &failing, "openai")
+	qianwenServer, _ := newTestServer(t, &failing, "qianwen")
+	doubaoServer, _ := newTestServer(t, &ok, "doubao")
+
+	policy := &RoutingPolicy{
+		Fallbacks:  map[string][]string{"openai": {"qianwen", "doubao"}},
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+	}
+	manager := newTestManager(t, policy,
+		map[string]*httptest.Server{"openai": openaiServer, "qianwen": qianwenServer, "doubao": doubaoServer},
+		map[string]string{"openai": "gpt-4", "qianwen": "qwen-max", "doubao": "ep-1"})
+
+	resp, err := manager.Chat(context.Background(), "openai", testChatRequest())
+	if err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+
+	if resp.Message.Content != "doubao" {
+		t.Errorf("expected doubao to answer, got %s", resp.Message.Content)
+	}
+	meta := resp.Metadata
+	if meta == nil || meta.Client != "doubao" || !meta.Fallback || meta.Requested != "openai" {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+	// openai、qianwen 各重试1次，doubao 成功
+	if len(meta.Attempts) != 5 {
+		t.Errorf("expected 5 attempts, got %d: %+v", len(meta.Attempts), meta.Attempts)
+	}
+	if atomic.LoadInt32(openaiCalls) != 2 {
+		t.Errorf("expected openai to be called twice, got %d", *openaiCalls)
+	}
+}
+
+func TestManager_NoRetryOnClientError(t *testing.T) {
+	badRequest := int32(http.StatusBadRequest)
+	server, calls := newTestServer(t, &badRequest, "")
+
+	policy := &RoutingPolicy{MaxRetries: 3, RetryDelay: time.Millisecond}
+	manager := newTestManager(t, policy, map[string]*httptest.Server{"openai": server}, map[string]string{"openai": "gpt-4"})
+
+	_, err := manager.Chat(context.Background(), "openai", testChatRequest())
+
+	var routingErr *RoutingError
+	if !errors.As(err, &routingErr) {
+		t.Fatalf("expected RoutingError, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected wrapped 400 APIError, got %v", err)
+	}
+	if atomic.LoadInt32(calls) != 1 {
+		t.Errorf("400 should not be retried, got %d calls", *calls)
+	}
+}
+
+func TestManager_CircuitBreaker(t *testing.T) {
+	status := int32(http.StatusInternalServerError)
+	server, calls := newTestServer(t, &status, "openai")
+
+	policy := &RoutingPolicy{
+		MaxRetries:       0,
+		BreakerThreshold: 2,
+		BreakerCooldown:  50 * time.Millisecond,
+	}
+	manager := newTestManager(t, policy, map[string]*httptest.Server{"openai": server}, map[string]string{"openai": "gpt-4"})
+
+	for i := 0; i < 2; i++ {
+		manager.Chat(context.Background(), "openai", testChatRequest())
+	}
+	if state := manager.CircuitState("openai"); state != "open" {
+		t.Fatalf("expected circuit open, got %s", state)
+	}
+
+	// 熔断期间不再请求上游
+	_, err := manager.Chat(context.Background(), "openai", testChatRequest())
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if atomic.LoadInt32(calls) != 2 {
+		t.Errorf("expected 2 upstream calls, got %d", *calls)
+	}
+
+	// 冷却后放行试探请求，成功则关闭熔断
+	time.Sleep(60 * time.Millisecond)
+	atomic.StoreInt32(&status, http.StatusOK)
+	if _, err := manager.Chat(context.Background(), "openai", testChatRequest()); err != nil {
+		t.Fatalf("probe request failed: %v", err)
+	}
+	if state := manager.CircuitState("openai"); state != "closed" {
+		t.Errorf("expected circuit closed, got %s", state)
+	}
+}
+
+func TestManager_CircuitBreakerIgnoresClientErrors(t *testing.T) {
+	for _, code := range []int32{http.StatusBadRequest, http.StatusUnauthorized, http.StatusUnprocessableEntity} {
+		status := code
+		server, calls := newTestServer(t, &status, "openai")
+
+		policy := &RoutingPolicy{BreakerThreshold: 2, BreakerCooldown: time.Minute}
+		manager := newTestManager(t, policy, map[string]*httptest.Server{"openai": server}, map[string]string{"openai": "gpt-4"})
+
+		for i := 0; i < 3; i++ {
+			manager.Chat(context.Background(), "openai", testChatRequest())
+		}
+		if state := manager.CircuitState("openai"); state != "closed" {
+			t.Errorf("status %d: expected circuit closed, got %s", code, state)
+		}
+		if atomic.LoadInt32(calls) != 3 {
+			t.Errorf("status %d: expected 3 upstream calls, got %d", code, *calls)
+		}
+	}
+}
+
+func TestManager_CircuitBreakerProbeCancelled(t *testing.T) {
+	status := int32(http.StatusInternalServerError)
+	server, _ := newTestServer(t, &status, "openai")
+
+	policy := &RoutingPolicy{BreakerThreshold: 1, BreakerCooldown: 20 * time.Millisecond}
+	manager := newTestManager(t, policy, map[string]*httptest.Server{"openai": server}, map[string]string{"openai": "gpt-4"})
+
+	manager.Chat(context.Background(), "openai", testChatRequest())
+	if state := manager.CircuitState("openai"); state != "open" {
+		t.Fatalf("expected circuit open, got %s", state)
+	}
+
+	// 试探请求被调用方取消后，熔断器应允许下一次试探
+	time.Sleep(30 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := manager.Chat(ctx, "openai", testChatRequest()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	if _, err := manager.Chat(context.Background(), "openai", testChatRequest()); err != nil {
+		t.Fatalf("probe after cancellation failed: %v", err)
+	}
+	if state := manager.CircuitState("openai"); state != "closed" {
+		t.Errorf("expected circuit closed, got %s", state)
+	}
+}
+
+func TestManager_WeightedLoadBalance(t *testing.T) {
+	ok := int32(http.StatusOK)
+	openaiServer, _ := newTestServer(t, &ok, "openai")
+	qianwenServer, _ := newTestServer(t, &ok, "qianwen")
+	doubaoServer, _ := newTestServer(t, &ok, "doubao")
+
+	policy := &RoutingPolicy{LoadBalance: true}
+	manager := newTestManager(t, policy,
+		map[string]*httptest.Server{"openai": openaiServer, "qianwen": qianwenServer, "doubao": doubaoServer},
+		// openai 与 qianwen 同属 qwen 家族（模拟同一模型的多个部署）
+		map[string]string{"openai": "qwen-max", "qianwen": "qwen-plus", "doubao": "ep-1"})
+
+	// 权重相同：随机数落在第二个区间时选中 qianwen
+	manager.randIntn = func(n int) int { return n - 1 }
+	resp, err := manager.Chat(context.Background(), "openai", testChatRequest())
+	if err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+	if resp.Metadata.Client != "qianwen" {
+		t.Errorf("expected qianwen from weighted pick, got %s", resp.Metadata.Client)
+	}
+
+	// 不同家族的客户端不参与分摊
+	resp, _ = manager.Chat(context.Background(), "doubao", testChatRequest())
+	if resp.Metadata.Client != "doubao" {
+		t.Errorf("expected doubao, got %s", resp.Metadata.Client)
+	}
+}
+
+func TestManager_StreamChatFallback(t *testing.T) {
+	failing := int32(http.StatusTooManyRequests)
+	openaiServer, _ := newTestServer(t, &failing, "")
+	qianwenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"ok\"}}]}\n\ndata: [DONE]\n\n")
+	}))
+	t.Cleanup(qianwenServer.Close)
+
+	policy := &RoutingPolicy{Fallbacks: map[string][]string{"*": {"qianwen"}}}
+	manager := newTestManager(t, policy,
+		map[string]*httptest.Server{"openai": openaiServer, "qianwen": qianwenServer},
+		map[string]string{"openai": "gpt-4", "qianwen": "qwen-max"})
+
+	responseCh, errorCh := manager.StreamChat(context.Background(), "openai", testChatRequest())
+
+	var last *StreamResponse
+	for response := range responseCh {
+		last = response
+	}
+	if err := <-errorCh; err != nil {
+		t.Fatalf("StreamChat error: %v", err)
+	}
+	if last == nil || !last.Done || last.Message != "ok" {
+		t.Fatalf("unexpected final response: %+v", last)
+	}
+	if last.Metadata == nil || last.Metadata.Client != "qianwen" {
+		t.Errorf("expected qianwen in metadata, got %+v", last.Metadata)
+	}
+}
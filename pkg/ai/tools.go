@@ -0,0 +1,161 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// ToolDefinition 工具定义，Parameters 为 JSON Schema（object 类型）
+type ToolDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// ToolCall 模型发起的工具调用（与 OpenAI tool_calls 格式一致）
+type ToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"` // function
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction 工具调用的函数名与参数
+type ToolCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON 字符串
+}
+
+// ToolHandler 工具处理函数，返回值作为工具结果回传给模型
+type ToolHandler func(ctx context.Context, arguments json.RawMessage) (string, error)
+
+// ErrToolNotFound 工具未注册
+var ErrToolNotFound = errors.New("tool not found")
+
+// toolNamePattern 各提供商通用的工具名称格式
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// ObjectSchema 构建 object 类型的参数 JSON Schema
+func ObjectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// registeredTool 已注册的工具
+type registeredTool struct {
+	definition *ToolDefinition
+	handler    ToolHandler
+}
+
+// ToolRegistry 工具注册表
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]*registeredTool
+}
+
+// NewToolRegistry 创建工具注册表
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{
+		tools: make(map[string]*registeredTool),
+	}
+}
+
+// Register 注册工具
+func (r *ToolRegistry) Register(definition *ToolDefinition, handler ToolHandler) error {
+	if definition == nil || handler == nil {
+		return fmt.Errorf("tool definition and handler are required")
+	}
+	if !toolNamePattern.MatchString(definition.Name) {
+		return fmt.Errorf("invalid tool name: %q", definition.Name)
+	}
+	if definition.Parameters == nil {
+		definition.Parameters = ObjectSchema(map[string]interface{}{})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tools[definition.Name]; exists {
+		return fmt.Errorf("tool %s already registered", definition.Name)
+	}
+	r.tools[definition.Name] = &registeredTool{definition: definition, handler: handler}
+	return nil
+}
+
+// Unregister 移除工具
+func (r *ToolRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tools, name)
+}
+
+// Definitions 获取所有工具定义（按名称排序）
+func (r *ToolRegistry) Definitions() []*ToolDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definitions := make([]*ToolDefinition, 0, len(r.tools))
+	for _, tool := range r.tools {
+		definitions = append(definitions, tool.definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+	return definitions
+}
+
+// Call 执行工具调用
+func (r *ToolRegistry) Call(ctx context.Context, call ToolCall) (string, error) {
+	r.mu.RLock()
+	tool, exists := r.tools[call.Function.Name]
+	r.mu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrToolNotFound, call.Function.Name)
+	}
+
+	arguments := json.RawMessage(call.Function.Arguments)
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+	if !json.Valid(arguments) {
+		return "", fmt.Errorf("invalid arguments for tool %s", call.Function.Name)
+	}
+
+	return tool.handler(ctx, arguments)
+}
+
+// Execute 执行工具调用并生成工具结果消息，错误以文本形式回传以便模型自行纠正
+func (r *ToolRegistry) Execute(ctx context.Context, call ToolCall) *Message {
+	result, err := r.Call(ctx, call)
+	if err != nil {
+		result = "error: " + err.Error()
+	}
+	return &Message{
+		Role:       "tool",
+		Content:    result,
+		ToolCallID: call.ID,
+	}
+}
+
+// WithTools 设置可用工具
+func WithTools(tools ...*ToolDefinition) ChatOption {
+	return func(req *ChatRequest) {
+		req.Tools = tools
+	}
+}
+
+// WithToolChoice 设置工具选择策略：auto、none、required 或指定工具名称
+func WithToolChoice(choice string) ChatOption {
+	return func(req *ChatRequest) {
+		req.ToolChoice = choice
+	}
+}
@@ -0,0 +1,171 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestToolRegistry(t *testing.T) *ToolRegistry {
+	t.Helper()
+
+	registry := NewToolRegistry()
+	err := registry.Register(&ToolDefinition{
+		Name:        "get_weather",
+		Description: "Get the weather for a city",
+		Parameters: ObjectSchema(map[string]interface{}{
+			"city": map[string]interface{}{"type": "string"},
+		}, "city"),
+	}, func(ctx context.Context, arguments json.RawMessage) (string, error) {
+		var args struct {
+			City string `json:"city"`
+		}
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return "", err
+		}
+		if args.City == "" {
+			return "", errors.New("city is required")
+		}
+		return fmt.Sprintf(`{"city":%q,"weather":"sunny"}`, args.City), nil
+	})
+	if err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	return registry
+}
+
+func TestToolRegistry(t *testing.T) {
+	registry := newTestToolRegistry(t)
+
+	if err := registry.Register(&ToolDefinition{Name: "get_weather"}, func(ctx context.Context, arguments json.RawMessage) (string, error) {
+		return "", nil
+	}); err == nil {
+		t.Error("expected duplicate registration to fail")
+	}
+	if err := registry.Register(&ToolDefinition{Name: "bad name"}, func(ctx context.Context, arguments json.RawMessage) (string, error) {
+		return "", nil
+	}); err == nil {
+		t.Error("expected invalid name to be rejected")
+	}
+
+	result := registry.Execute(context.Background(), ToolCall{ID: "1", Function: ToolCallFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`}})
+	if result.Role != "tool" || result.ToolCallID != "1" || result.Content != `{"city":"Paris","weather":"sunny"}` {
+		t.Errorf("unexpected tool result: %+v", result)
+	}
+
+	result = registry.Execute(context.Background(), ToolCall{ID: "2", Function: ToolCallFunction{Name: "missing"}})
+	if result.Content != "error: tool not found: missing" {
+		t.Errorf("unexpected error result: %s", result.Content)
+	}
+
+	if _, err := registry.Call(context.Background(), ToolCall{Function: ToolCallFunction{Name: "get_weather", Arguments: "{"}}); err == nil {
+		t.Error("expected invalid JSON arguments to fail")
+	}
+}
+
+// newToolCallingServer 第一次请求返回工具调用，收到工具结果后返回回答
+func newToolCallingServer(t *testing.T, alwaysCall bool) (*httptest.Server, *int) {
+	t.Helper()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		var body openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if len(body.Tools) != 1 || body.Tools[0].Type != "function" || body.Tools[0].Function.Name != "get_weather" {
+			t.Errorf("unexpected tools: %+v", body.Tools)
+		}
+
+		last := body.Messages[len(body.Messages)-1]
+		if alwaysCall || last.Role == "user" {
+			fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":null,"tool_calls":[
+				{"id":"call_%d","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},
+				"finish_reason":"tool_calls"}]}`, calls)
+			return
+		}
+
+		if last.Role != "tool" || last.ToolCallID != "call_1" {
+			t.Errorf("expected tool result as last message, got %+v", last)
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":"It is sunny in Paris."}}]}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newTestConversationClient(t *testing.T, server *httptest.Server) *ConversationClient {
+	t.Helper()
+
+	einoClient, err := NewEinoClient(&ModelConfig{
+		Provider: ProviderOpenAI,
+		APIKey:   "key",
+		BaseURL:  server.URL,
+		Model:    "gpt-4o",
+	})
+	if err != nil {
+		t.Fatalf("NewEinoClient error: %v", err)
+	}
+	return NewConversationClient(einoClient, 20)
+}
+
+func TestConversationClient_AgentLoop(t *testing.T) {
+	server, calls := newToolCallingServer(t, false)
+	client := newTestConversationClient(t, server)
+	client.SetTools(newTestToolRegistry(t), 3)
+
+	answer, err := client.Chat(context.Background(), "What's the weather in Paris?")
+	if err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+	if answer != "It is sunny in Paris." {
+		t.Errorf("unexpected answer: %s", answer)
+	}
+	if *calls != 2 {
+		t.Errorf("expected 2 model calls, got %d", *calls)
+	}
+
+	// user → assistant(tool_calls) → tool → assistant
+	history := client.GetHistory()
+	if len(history) != 4 || len(history[1].ToolCalls) != 1 || history[2].Role != "tool" || history[3].Content != answer {
+		t.Errorf("unexpected history: %+v", history)
+	}
+}
+
+func TestConversationClient_MaxSteps(t *testing.T) {
+	server, calls := newToolCallingServer(t, true)
+	client := newTestConversationClient(t, server)
+	client.SetTools(newTestToolRegistry(t), 2)
+
+	_, err := client.Chat(context.Background(), "What's the weather in Paris?")
+	if !errors.Is(err, ErrMaxStepsExceeded) {
+		t.Fatalf("expected ErrMaxStepsExceeded, got %v", err)
+	}
+	// 2 轮工具调用 + 1 次超限的请求
+	if *calls != 3 {
+		t.Errorf("expected 3 model calls, got %d", *calls)
+	}
+}
+
+func TestClient_StreamChatRejectsTools(t *testing.T) {
+	client, err := NewClient(&Config{Provider: "openai", APIKey: "key", Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	responseCh, errorCh := client.StreamChat(context.Background(), &ChatRequest{
+		Messages: []*Message{{Role: "user", Content: "hi"}},
+		Tools:    []*ToolDefinition{{Name: "noop"}},
+	})
+	for range responseCh {
+	}
+	if err := <-errorCh; err == nil {
+		t.Error("expected tools in streaming mode to be rejected")
+	}
+}
@@ -0,0 +1,315 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// UsageRecord 令牌用量记录
+type UsageRecord struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	UserID           uint      `gorm:"index;not null;default:0" json:"user_id"` // 0 表示匿名请求
+	Client           string    `gorm:"size:64;index;not null" json:"client"`
+	Provider         string    `gorm:"size:32" json:"provider"`
+	Model            string    `gorm:"size:128;index" json:"model"`
+	Endpoint         string    `gorm:"size:32" json:"endpoint"` // chat、completion、embedding、conversation
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Cost             float64   `json:"cost"`
+	CreatedAt        time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (UsageRecord) TableName() string {
+	return database.TableName("ai_usage_records")
+}
+
+// CalculateCost 按客户端配置的单价计算费用
+// 单价通过 Options["prompt_price"]、Options["completion_price"] 设置，单位为每百万令牌
+func CalculateCost(config *Config, usage *Usage) float64 {
+	if config == nil || usage == nil {
+		return 0
+	}
+	promptPrice := optionFloat(config.Options, "prompt_price", 0)
+	completionPrice := optionFloat(config.Options, "completion_price", 0)
+	return (float64(usage.PromptTokens)*promptPrice + float64(usage.CompletionTokens)*completionPrice) / 1e6
+}
+
+// NewUsageRecord 根据客户端配置和用量创建记录
+func NewUsageRecord(userID uint, endpoint, clientName string, config *Config, usage *Usage) *UsageRecord {
+	record := &UsageRecord{
+		UserID:   userID,
+		Client:   clientName,
+		Endpoint: endpoint,
+	}
+	if config != nil {
+		record.Provider = config.Provider
+		record.Model = config.Model
+	}
+	if usage != nil {
+		record.PromptTokens = usage.PromptTokens
+		record.CompletionTokens = usage.CompletionTokens
+		record.TotalTokens = usage.TotalTokens
+		if record.TotalTokens == 0 {
+			record.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+		}
+	}
+	record.Cost = CalculateCost(config, usage)
+	return record
+}
+
+// QuotaLimit 额度限制，0 表示不限制
+type QuotaLimit struct {
+	DailyTokens   int64   `json:"daily_tokens,omitempty"`
+	MonthlyTokens int64   `json:"monthly_tokens,omitempty"`
+	DailyCost     float64 `json:"daily_cost,omitempty"`
+	MonthlyCost   float64 `json:"monthly_cost,omitempty"`
+}
+
+// Unlimited 是否不限制
+func (l QuotaLimit) Unlimited() bool {
+	return l.DailyTokens <= 0 && l.MonthlyTokens <= 0 && l.DailyCost <= 0 && l.MonthlyCost <= 0
+}
+
+// QuotaPolicy 额度策略：用户单独设置优先，其次取用户所有角色中最宽松的限制，最后使用默认限制
+type QuotaPolicy struct {
+	Default QuotaLimit            `json:"default"`
+	Roles   map[string]QuotaLimit `json:"roles,omitempty"`
+	Users   map[uint]QuotaLimit   `json:"users,omitempty"`
+}
+
+// Resolve 获取用户的额度限制
+func (p *QuotaPolicy) Resolve(userID uint, roles []string) QuotaLimit {
+	if p == nil {
+		return QuotaLimit{}
+	}
+	if limit, exists := p.Users[userID]; exists {
+		return limit
+	}
+
+	var resolved *QuotaLimit
+	for _, role := range roles {
+		limit, exists := p.Roles[role]
+		if !exists {
+			continue
+		}
+		if resolved == nil {
+			resolved = &limit
+			continue
+		}
+		resolved.DailyTokens = looserInt(resolved.DailyTokens, limit.DailyTokens)
+		resolved.MonthlyTokens = looserInt(resolved.MonthlyTokens, limit.MonthlyTokens)
+		resolved.DailyCost = looserFloat(resolved.DailyCost, limit.DailyCost)
+		resolved.MonthlyCost = looserFloat(resolved.MonthlyCost, limit.MonthlyCost)
+	}
+	if resolved != nil {
+		return *resolved
+	}
+	return p.Default
+}
+
+// looserInt 取更宽松的限制（0 表示不限制）
+func looserInt(a, b int64) int64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// looserFloat 取更宽松的限制（0 表示不限制）
+func looserFloat(a, b float64) float64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// ErrQuotaExceeded 超出额度
+var ErrQuotaExceeded = errors.New("ai usage quota exceeded")
+
+// QuotaExceededError 超出额度的详细信息
+type QuotaExceededError struct {
+	Period  string    `json:"period"` // daily、monthly
+	Metric  string    `json:"metric"` // tokens、cost
+	Limit   float64   `json:"limit"`
+	Used    float64   `json:"used"`
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// Error 实现error接口
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s %s quota exceeded: used %g of %g", e.Period, e.Metric, e.Used, e.Limit)
+}
+
+// Unwrap 支持 errors.Is(err, ErrQuotaExceeded)
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// UsageTotals 用量汇总
+type UsageTotals struct {
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// UsageSummary 分组汇总
+type UsageSummary struct {
+	UserID uint   `json:"user_id"`
+	Client string `json:"client"`
+	Model  string `json:"model"`
+	UsageTotals
+}
+
+// UsageFilter 报表筛选条件
+type UsageFilter struct {
+	Since   time.Time
+	Until   time.Time
+	UserID  *uint
+	Client  string
+	GroupBy []string // user、client、model
+}
+
+// UsageLedger 基于GORM的用量账本
+type UsageLedger struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+// NewUsageLedger 创建用量账本，数据表由 ai_usage_records 迁移创建
+func NewUsageLedger(db *gorm.DB) (*UsageLedger, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return &UsageLedger{db: db, now: time.Now}, nil
+}
+
+// Record 记录用量
+func (l *UsageLedger) Record(ctx context.Context, record *UsageRecord) error {
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = l.now()
+	}
+	return l.db.WithContext(ctx).Create(record).Error
+}
+
+// Totals 统计用户在时间段内的用量
+func (l *UsageLedger) Totals(ctx context.Context, userID uint, since, until time.Time) (*UsageTotals, error) {
+	var totals UsageTotals
+	err := l.db.WithContext(ctx).Model(&UsageRecord{}).
+		Select("COUNT(*) AS requests, COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, "+
+			"COALESCE(SUM(completion_tokens), 0) AS completion_tokens, "+
+			"COALESCE(SUM(total_tokens), 0) AS total_tokens, COALESCE(SUM(cost), 0) AS cost").
+		Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, since, until).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// CheckQuota 检查用户当日及当月用量是否超出限制
+func (l *UsageLedger) CheckQuota(ctx context.Context, userID uint, limit QuotaLimit) error {
+	if limit.Unlimited() {
+		return nil
+	}
+
+	now := l.now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	until := now.Add(time.Second)
+
+	if limit.DailyTokens > 0 || limit.DailyCost > 0 {
+		daily, err := l.Totals(ctx, userID, dayStart, until)
+		if err != nil {
+			return err
+		}
+		if err := checkLimit("daily", daily, limit.DailyTokens, limit.DailyCost, dayStart.AddDate(0, 0, 1)); err != nil {
+			return err
+		}
+	}
+
+	if limit.MonthlyTokens > 0 || limit.MonthlyCost > 0 {
+		monthly, err := l.Totals(ctx, userID, monthStart, until)
+		if err != nil {
+			return err
+		}
+		if err := checkLimit("monthly", monthly, limit.MonthlyTokens, limit.MonthlyCost, monthStart.AddDate(0, 1, 0)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkLimit 比较用量与限制
+func checkLimit(period string, totals *UsageTotals, tokens int64, cost float64, resetAt time.Time) error {
+	if tokens > 0 && totals.TotalTokens >= tokens {
+		return &QuotaExceededError{Period: period, Metric: "tokens", Limit: float64(tokens), Used: float64(totals.TotalTokens), ResetAt: resetAt}
+	}
+	if cost > 0 && totals.Cost >= cost {
+		return &QuotaExceededError{Period: period, Metric: "cost", Limit: cost, Used: totals.Cost, ResetAt: resetAt}
+	}
+	return nil
+}
+
+// usageGroupColumns 报表分组字段
+var usageGroupColumns = map[string]string{
+	"user":   "user_id",
+	"client": "client",
+	"model":  "model",
+}
+
+// Report 生成分组用量报表，按费用和令牌数降序
+func (l *UsageLedger) Report(ctx context.Context, filter UsageFilter) ([]UsageSummary, error) {
+	columns := make([]string, 0, len(filter.GroupBy))
+	for _, group := range filter.GroupBy {
+		column, exists := usageGroupColumns[group]
+		if !exists {
+			return nil, fmt.Errorf("unsupported group: %s", group)
+		}
+		columns = append(columns, column)
+	}
+
+	selects := append([]string{}, columns...)
+	selects = append(selects, "COUNT(*) AS requests", "COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens",
+		"COALESCE(SUM(completion_tokens), 0) AS completion_tokens", "COALESCE(SUM(total_tokens), 0) AS total_tokens",
+		"COALESCE(SUM(cost), 0) AS cost")
+
+	query := l.db.WithContext(ctx).Model(&UsageRecord{}).Select(strings.Join(selects, ", "))
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Client != "" {
+		query = query.Where("client = ?", filter.Client)
+	}
+	if len(columns) > 0 {
+		query = query.Group(strings.Join(columns, ", "))
+	}
+
+	var summaries []UsageSummary
+	if err := query.Order("cost DESC, total_tokens DESC").Scan(&summaries).Error; err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
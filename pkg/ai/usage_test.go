@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestUsageLedger(t *testing.T, now time.Time) *UsageLedger {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&UsageRecord{}); err != nil {
+		t.Fatalf("AutoMigrate error: %v", err)
+	}
+	ledger, err := NewUsageLedger(db)
+	if err != nil {
+		t.Fatalf("NewUsageLedger error: %v", err)
+	}
+	ledger.now = func() time.Time { return now }
+	return ledger
+}
+
+func TestCalculateCost(t *testing.T) {
+	config := &Config{Options: map[string]interface{}{"prompt_price": 2.5, "completion_price": 10}}
+	cost := CalculateCost(config, &Usage{PromptTokens: 1000, CompletionTokens: 500})
+	if math.Abs(cost-0.0075) > 1e-9 {
+		t.Errorf("expected cost 0.0075, got %f", cost)
+	}
+
+	record := NewUsageRecord(1, "chat", "openai", &Config{Provider: "openai", Model: "gpt-4o"}, &Usage{PromptTokens: 3, CompletionTokens: 4})
+	if record.TotalTokens != 7 || record.Model != "gpt-4o" || record.Cost != 0 {
+		t.Errorf("unexpected record: %+v", record)
+	}
+}
+
+func TestQuotaPolicy_Resolve(t *testing.T) {
+	policy := &QuotaPolicy{
+		Default: QuotaLimit{DailyTokens: 1000},
+		Roles: map[string]QuotaLimit{
+			"editor": {DailyTokens: 5000, MonthlyCost: 10},
+			"author": {DailyTokens: 2000, MonthlyCost: 20},
+			"admin":  {},
+		},
+		Users: map[uint]QuotaLimit{42: {DailyTokens: 1}},
+	}
+
+	if limit := policy.Resolve(7, nil); limit.DailyTokens != 1000 {
+		t.Errorf("expected default limit, got %+v", limit)
+	}
+	if limit := policy.Resolve(7, []string{"editor", "author"}); limit.DailyTokens != 5000 || limit.MonthlyCost != 20 {
+		t.Errorf("expected loosest role limits, got %+v", limit)
+	}
+	if limit := policy.Resolve(7, []string{"editor", "admin"}); !limit.Unlimited() {
+		t.Errorf("unlimited role should win, got %+v", limit)
+	}
+	if limit := policy.Resolve(42, []string{"admin"}); limit.DailyTokens != 1 {
+		t.Errorf("user override should win, got %+v", limit)
+	}
+}
+
+func TestUsageLedger_CheckQuota(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	ledger := newTestUsageLedger(t, now)
+	ctx := context.Background()
+
+	// 上月与当月早些时候的记录
+	ledger.Record(ctx, &UsageRecord{UserID: 1, Client: "openai", TotalTokens: 5000, Cost: 5, CreatedAt: now.AddDate(0, -1, 0)})
+	ledger.Record(ctx, &UsageRecord{UserID: 1, Client: "openai", TotalTokens: 300, Cost: 0.3, CreatedAt: now.AddDate(0, 0, -2)})
+	ledger.Record(ctx, &UsageRecord{UserID: 1, Client: "openai", TotalTokens: 600, Cost: 0.6, CreatedAt: now.Add(-time.Hour)})
+	ledger.Record(ctx, &UsageRecord{UserID: 2, Client: "openai", TotalTokens: 9999, CreatedAt: now})
+
+	if err := ledger.CheckQuota(ctx, 1, QuotaLimit{DailyTokens: 1000, MonthlyTokens: 1000}); err != nil {
+		t.Errorf("user 1 is within quota: %v", err)
+	}
+
+	err := ledger.CheckQuota(ctx, 1, QuotaLimit{DailyTokens: 500})
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) || quotaErr.Period != "daily" || quotaErr.Used != 600 {
+		t.Fatalf("expected daily quota error, got %v", err)
+	}
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Error("expected errors.Is ErrQuotaExceeded")
+	}
+	if !quotaErr.ResetAt.Equal(time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected reset time: %v", quotaErr.ResetAt)
+	}
+
+	err = ledger.CheckQuota(ctx, 1, QuotaLimit{MonthlyCost: 0.8})
+	if !errors.As(err, &quotaErr) || quotaErr.Period != "monthly" || quotaErr.Metric != "cost" {
+		t.Errorf("expected monthly cost quota error, got %v", err)
+	}
+
+	if err := ledger.CheckQuota(ctx, 3, QuotaLimit{DailyTokens: 1}); err != nil {
+		t.Errorf("user without usage should pass: %v", err)
+	}
+}
+
+func TestUsageLedger_Report(t *testing.T) {
+	now := time.Now()
+	ledger := newTestUsageLedger(t, now)
+	ctx := context.Background()
+
+	ledger.Record(ctx, &UsageRecord{UserID: 1, Client: "openai", Model: "gpt-4o", PromptTokens: 10, TotalTokens: 15, Cost: 1})
+	ledger.Record(ctx, &UsageRecord{UserID: 1, Client: "openai", Model: "gpt-4o", PromptTokens: 20, TotalTokens: 25, Cost: 2})
+	ledger.Record(ctx, &UsageRecord{UserID: 2, Client: "qianwen", Model: "qwen-max", PromptTokens: 5, TotalTokens: 5, Cost: 0.5})
+
+	summaries, err := ledger.Report(ctx, UsageFilter{GroupBy: []string{"client", "model"}})
+	if err != nil {
+		t.Fatalf("Report error: %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(summaries))
+	}
+	if top := summaries[0]; top.Client != "openai" || top.Requests != 2 || top.TotalTokens != 40 || top.Cost != 3 {
+		t.Errorf("unexpected top group: %+v", top)
+	}
+
+	userID := uint(2)
+	summaries, err = ledger.Report(ctx, UsageFilter{UserID: &userID, GroupBy: []string{"user"}})
+	if err != nil || len(summaries) != 1 || summaries[0].UserID != 2 || summaries[0].TotalTokens != 5 {
+		t.Errorf("unexpected user report: %+v (%v)", summaries, err)
+	}
+
+	if _, err := ledger.Report(ctx, UsageFilter{GroupBy: []string{"bogus"}}); err == nil {
+		t.Error("expected unsupported group to fail")
+	}
+}
@@ -0,0 +1,288 @@
+package ai
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// VectorChunk 待写入的文本分块及其向量
+type VectorChunk struct {
+	Content string
+	Vector  []float64
+}
+
+// VectorMatch 向量检索结果
+type VectorMatch struct {
+	DocumentID string  `json:"document_id"`
+	Chunk      int     `json:"chunk"`
+	Content    string  `json:"content"`
+	Score      float64 `json:"score"` // 余弦相似度
+}
+
+// VectorStore 向量存储，按命名空间（如 posts）隔离不同来源的文档
+type VectorStore interface {
+	// Upsert 替换文档的全部分块
+	Upsert(ctx context.Context, namespace, documentID, model string, chunks []VectorChunk) error
+	// Delete 删除文档的全部分块
+	Delete(ctx context.Context, namespace, documentID string) error
+	// Clear 清空命名空间
+	Clear(ctx context.Context, namespace string) error
+	// Search 返回与查询向量最相似的分块，按相似度降序
+	Search(ctx context.Context, namespace string, vector []float64, limit int) ([]VectorMatch, error)
+}
+
+// EmbeddingRecord 向量数据表模型
+type EmbeddingRecord struct {
+	ID         uint   `gorm:"primaryKey"`
+	Namespace  string `gorm:"size:64;not null;index:idx_ai_embeddings_document"`
+	DocumentID string `gorm:"size:128;not null;index:idx_ai_embeddings_document"`
+	Chunk      int    `gorm:"not null;default:0"`
+	Content    string `gorm:"type:text"`
+	Model      string `gorm:"size:128"`
+	Dimensions int    `gorm:"not null;default:0"`
+	Vector     []byte
+	CreatedAt  time.Time
+}
+
+// TableName 指定表名
+func (EmbeddingRecord) TableName() string {
+	return database.TableName("ai_embeddings")
+}
+
+// GormVectorStore 基于GORM的向量存储（支持 sqlite、mysql、postgres）
+// 相似度在Go中计算，适合数万个分块以内的站点规模
+type GormVectorStore struct {
+	db        *gorm.DB
+	batchSize int
+}
+
+// NewGormVectorStore 创建GORM向量存储，数据表由 ai_embeddings 迁移创建
+func NewGormVectorStore(db *gorm.DB) (*GormVectorStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return &GormVectorStore{db: db, batchSize: 500}, nil
+}
+
+// Upsert 替换文档的全部分块
+func (s *GormVectorStore) Upsert(ctx context.Context, namespace, documentID, model string, chunks []VectorChunk) error {
+	records := make([]EmbeddingRecord, 0, len(chunks))
+	for i, chunk := range chunks {
+		records = append(records, EmbeddingRecord{
+			Namespace:  namespace,
+			DocumentID: documentID,
+			Chunk:      i,
+			Content:    chunk.Content,
+			Model:      model,
+			Dimensions: len(chunk.Vector),
+			Vector:     encodeVector(chunk.Vector),
+		})
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("namespace = ? AND document_id = ?", namespace, documentID).
+			Delete(&EmbeddingRecord{}).Error; err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		return tx.Create(&records).Error
+	})
+}
+
+// Delete 删除文档的全部分块
+func (s *GormVectorStore) Delete(ctx context.Context, namespace, documentID string) error {
+	return s.db.WithContext(ctx).
+		Where("namespace = ? AND document_id = ?", namespace, documentID).
+		Delete(&EmbeddingRecord{}).Error
+}
+
+// Clear 清空命名空间
+func (s *GormVectorStore) Clear(ctx context.Context, namespace string) error {
+	return s.db.WithContext(ctx).Where("namespace = ?", namespace).Delete(&EmbeddingRecord{}).Error
+}
+
+// Search 分批扫描命名空间内的向量并计算余弦相似度
+func (s *GormVectorStore) Search(ctx context.Context, namespace string, vector []float64, limit int) ([]VectorMatch, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	var matches []VectorMatch
+	var records []EmbeddingRecord
+	err := s.db.WithContext(ctx).
+		Where("namespace = ? AND dimensions = ?", namespace, len(vector)).
+		FindInBatches(&records, s.batchSize, func(tx *gorm.DB, batch int) error {
+			for _, record := range records {
+				matches = append(matches, VectorMatch{
+					DocumentID: record.DocumentID,
+					Chunk:      record.Chunk,
+					Content:    record.Content,
+					Score:      CosineSimilarity(vector, decodeVector(record.Vector)),
+				})
+			}
+			// 只保留当前最好的结果，控制内存占用
+			if len(matches) > limit*4 {
+				matches = topMatches(matches, limit)
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return topMatches(matches, limit), nil
+}
+
+// topMatches 按相似度降序取前 limit 个结果
+func topMatches(matches []VectorMatch, limit int) []VectorMatch {
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// CosineSimilarity 计算两个向量的余弦相似度，维度不一致或为零向量时返回0
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// encodeVector 将向量编码为 float32 小端字节序，节省存储空间
+func encodeVector(vector []float64) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(v)))
+	}
+	return buf
+}
+
+// decodeVector 解码 encodeVector 生成的字节
+func decodeVector(buf []byte) []float64 {
+	vector := make([]float64, len(buf)/4)
+	for i := range vector {
+		vector[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:])))
+	}
+	return vector
+}
+
+// ChunkText 按段落和句子将文本切分为不超过 maxTokens 的分块
+func ChunkText(text string, maxTokens int) []string {
+	if maxTokens <= 0 {
+		maxTokens = 400
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentTokens := 0
+	flush := func() {
+		if content := strings.TrimSpace(current.String()); content != "" {
+			chunks = append(chunks, content)
+		}
+		current.Reset()
+		currentTokens = 0
+	}
+
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		pieces := []string{paragraph}
+		if EstimateTokens(paragraph) > maxTokens {
+			pieces = splitLong(paragraph, maxTokens)
+		}
+
+		for _, piece := range pieces {
+			tokens := EstimateTokens(piece)
+			if currentTokens > 0 && currentTokens+tokens > maxTokens {
+				flush()
+			}
+			if current.Len() > 0 {
+				current.WriteString("\n\n")
+			}
+			current.WriteString(piece)
+			currentTokens += tokens
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+// splitLong 将超长段落按句子切分，单句仍超长时按字符硬切
+func splitLong(paragraph string, maxTokens int) []string {
+	var pieces []string
+	var sentence strings.Builder
+	tokens := 0.0
+	limit := float64(maxTokens)
+
+	emit := func() {
+		if piece := strings.TrimSpace(sentence.String()); piece != "" {
+			pieces = append(pieces, piece)
+		}
+		sentence.Reset()
+		tokens = 0
+	}
+
+	for _, r := range paragraph {
+		cost := 0.25
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cost = 1
+		}
+		if tokens+cost > limit {
+			emit()
+		}
+		sentence.WriteRune(r)
+		tokens += cost
+		if strings.ContainsRune(".!?。！？\n", r) {
+			emit()
+		}
+	}
+	emit()
+
+	// 合并相邻的短句，避免产生过多碎片
+	var merged []string
+	var current strings.Builder
+	currentTokens := 0
+	for _, piece := range pieces {
+		pieceTokens := EstimateTokens(piece)
+		if currentTokens > 0 && currentTokens+pieceTokens > maxTokens {
+			merged = append(merged, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+		if current.Len() > 0 {
+			current.WriteString(" ")
+		}
+		current.WriteString(piece)
+		currentTokens += pieceTokens
+	}
+	if current.Len() > 0 {
+		merged = append(merged, current.String())
+	}
+	return merged
+}
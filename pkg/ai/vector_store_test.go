@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	if score := CosineSimilarity([]float64{1, 0}, []float64{1, 0}); math.Abs(score-1) > 1e-9 {
+		t.Errorf("expected 1, got %f", score)
+	}
+	if score := CosineSimilarity([]float64{1, 0}, []float64{0, 1}); score != 0 {
+		t.Errorf("expected 0, got %f", score)
+	}
+	if score := CosineSimilarity([]float64{1, 0}, []float64{1, 0, 0}); score != 0 {
+		t.Errorf("mismatched dimensions should score 0, got %f", score)
+	}
+
+	decoded := decodeVector(encodeVector([]float64{0.5, -1.25, 3}))
+	if len(decoded) != 3 || decoded[0] != 0.5 || decoded[1] != -1.25 || decoded[2] != 3 {
+		t.Errorf("unexpected round trip: %v", decoded)
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	text := "First paragraph.\n\nSecond paragraph.\n\n" + strings.Repeat("Long sentence here. ", 40)
+	chunks := ChunkText(text, 50)
+	if len(chunks) < 3 {
+		t.Fatalf("expected long text to be split, got %d chunks", len(chunks))
+	}
+	if !strings.HasPrefix(chunks[0], "First paragraph.\n\nSecond paragraph.") {
+		t.Errorf("short paragraphs should be merged, got %q", chunks[0])
+	}
+	for _, chunk := range chunks {
+		if tokens := EstimateTokens(chunk); tokens > 50 {
+			t.Errorf("chunk exceeds budget (%d tokens): %q", tokens, chunk)
+		}
+	}
+
+	if chunks := ChunkText("   \n\n  ", 50); len(chunks) != 0 {
+		t.Errorf("expected no chunks for blank text, got %v", chunks)
+	}
+}
+
+func TestGormVectorStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&EmbeddingRecord{}); err != nil {
+		t.Fatalf("AutoMigrate error: %v", err)
+	}
+	store, err := NewGormVectorStore(db)
+	if err != nil {
+		t.Fatalf("NewGormVectorStore error: %v", err)
+	}
+	store.batchSize = 2
+	ctx := context.Background()
+
+	store.Upsert(ctx, "posts", "1", "test", []VectorChunk{
+		{Content: "cats", Vector: []float64{1, 0, 0}},
+		{Content: "dogs", Vector: []float64{0, 1, 0}},
+	})
+	store.Upsert(ctx, "posts", "2", "test", []VectorChunk{{Content: "birds", Vector: []float64{0, 0, 1}}})
+	store.Upsert(ctx, "pages", "1", "test", []VectorChunk{{Content: "cats page", Vector: []float64{1, 0, 0}}})
+
+	matches, err := store.Search(ctx, "posts", []float64{0.9, 0.1, 0}, 2)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(matches) != 2 || matches[0].Content != "cats" || matches[1].Content != "dogs" {
+		t.Errorf("unexpected matches: %+v", matches)
+	}
+
+	// 重新写入会替换旧分块
+	store.Upsert(ctx, "posts", "1", "test", []VectorChunk{{Content: "fish", Vector: []float64{0, 1, 0}}})
+	matches, _ = store.Search(ctx, "posts", []float64{1, 0, 0}, 10)
+	for _, match := range matches {
+		if match.Content == "cats" || match.Content == "dogs" {
+			t.Errorf("stale chunk returned: %+v", match)
+		}
+	}
+
+	store.Delete(ctx, "posts", "2")
+	store.Clear(ctx, "pages")
+	var count int64
+	db.Model(&EmbeddingRecord{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 remaining chunk, got %d", count)
+	}
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Denylist 已注销令牌列表，记录到令牌自然过期为止
+// 键为 jti:<令牌ID> 或 sid:<会话ID>
+type Denylist interface {
+	// Deny 拒绝 key 直到 until
+	Deny(ctx context.Context, key string, until time.Time) error
+
+	// IsDenied 任一 key 被拒绝时返回true
+	IsDenied(ctx context.Context, keys ...string) (bool, error)
+}
+
+// DeniedToken 注销记录数据表模型
+type DeniedToken struct {
+	Key       string    `gorm:"column:token_key;primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
+
+// TableName 指定表名
+func (DeniedToken) TableName() string {
+	return database.TableName("denied_tokens")
+}
+
+// GormDenylist 基于GORM的注销列表
+type GormDenylist struct {
+	db *gorm.DB
+}
+
+// NewGormDenylist 创建GORM注销列表，数据表由 denied_tokens 迁移创建
+func NewGormDenylist(db *gorm.DB) (*GormDenylist, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	// 注销后需要立即生效，不能读取有延迟的副本
+	return &GormDenylist{db: database.UsePrimary(db)}, nil
+}
+
+// Deny 拒绝 key 直到 until
+func (d *GormDenylist) Deny(ctx context.Context, key string, until time.Time) error {
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expires_at"}),
+	}).Create(&DeniedToken{Key: key, ExpiresAt: until}).Error
+}
+
+// IsDenied 任一 key 被拒绝时返回true
+func (d *GormDenylist) IsDenied(ctx context.Context, keys ...string) (bool, error) {
+	var count int64
+	err := d.db.WithContext(ctx).Model(&DeniedToken{}).
+		Where("token_key IN ? AND expires_at > ?", keys, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Prune 删除已过期的注销记录
+func (d *GormDenylist) Prune(ctx context.Context) (int64, error) {
+	result := d.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&DeniedToken{})
+	return result.RowsAffected, result.Error
+}
+
+// RedisDenylist 基于Redis的注销列表，记录随过期时间自动删除
+type RedisDenylist struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisDenylist 创建Redis注销列表
+func NewRedisDenylist(client *redis.Client, prefix string) *RedisDenylist {
+	if prefix == "" {
+		prefix = "auth:denied"
+	}
+	return &RedisDenylist{client: client, prefix: prefix}
+}
+
+// Deny 拒绝 key 直到 until
+func (d *RedisDenylist) Deny(ctx context.Context, key string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	return d.client.Set(ctx, d.prefix+":"+key, 1, ttl).Err()
+}
+
+// IsDenied 任一 key 被拒绝时返回true
+func (d *RedisDenylist) IsDenied(ctx context.Context, keys ...string) (bool, error) {
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = d.prefix + ":" + key
+	}
+	count, err := d.client.Exists(ctx, redisKeys...).Result()
+	return count > 0, err
+}
@@ -0,0 +1,445 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// ErrNoSigningKey 没有可用于签名的密钥
+var ErrNoSigningKey = errors.New("no active signing key")
+
+// Key 令牌签名密钥
+// 对称密钥（HS256）只保存 Secret，不会出现在 JWKS 中
+type Key struct {
+	ID        string
+	Algorithm string
+	Secret    []byte
+	Private   crypto.Signer
+	NotBefore time.Time // 从此时起用于签名，之前只发布公钥
+	ExpiresAt time.Time // 之后不再用于校验，零值表示不过期
+}
+
+// Method 密钥对应的签名方法
+func (k *Key) Method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// signingKey 传给 jwt 签名的密钥
+func (k *Key) signingKey() interface{} {
+	if k.Algorithm == AlgHS256 {
+		return k.Secret
+	}
+	return k.Private
+}
+
+// verificationKey 传给 jwt 校验的密钥
+func (k *Key) verificationKey() interface{} {
+	if k.Algorithm == AlgHS256 {
+		return k.Secret
+	}
+	return k.Private.Public()
+}
+
+// activeAt 密钥在 now 时是否可用于校验
+func (k *Key) activeAt(now time.Time) bool {
+	return k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt)
+}
+
+// GenerateKey 生成指定算法的新密钥，kid 由日期和随机数组成
+func GenerateKey(algorithm string, now time.Time) (*Key, error) {
+	key := &Key{Algorithm: algorithm, NotBefore: now}
+
+	var err error
+	switch algorithm {
+	case AlgHS256:
+		key.Secret = make([]byte, 32)
+		_, err = rand.Read(key.Secret)
+	case AlgRS256:
+		key.Private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgES256:
+		key.Private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, key.Private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	key.ID = now.UTC().Format("20060102") + "-" + hex.EncodeToString(suffix)
+	return key, nil
+}
+
+// KeySource 提供令牌签名和校验使用的密钥
+type KeySource interface {
+	Keys() ([]*Key, error)
+}
+
+// StaticKeys 固定的密钥列表
+type StaticKeys []*Key
+
+// Keys 返回密钥列表
+func (s StaticKeys) Keys() ([]*Key, error) {
+	return s, nil
+}
+
+// SecretKey 使用单个 HS256 共享密钥，令牌不带 kid
+func SecretKey(secret []byte) StaticKeys {
+	return StaticKeys{{Algorithm: AlgHS256, Secret: secret}}
+}
+
+// SigningKey 返回 now 时用于签名的密钥：已生效的密钥中最新的一个
+func SigningKey(keys []*Key, now time.Time) (*Key, error) {
+	var current *Key
+	for _, key := range keys {
+		if now.Before(key.NotBefore) || !key.activeAt(now) {
+			continue
+		}
+		if current == nil || key.NotBefore.After(current.NotBefore) {
+			current = key
+		}
+	}
+	if current == nil {
+		return nil, ErrNoSigningKey
+	}
+	return current, nil
+}
+
+// JWK JSON Web Key（RFC 7517）公钥
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKSet JWKS 文档
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS 返回仍可用于校验的非对称密钥的公钥，包括尚未开始签名的新密钥
+func PublicJWKS(keys []*Key, now time.Time) JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range keys {
+		if key.Algorithm == AlgHS256 || key.Private == nil || !key.activeAt(now) {
+			continue
+		}
+		jwk := JWK{KeyID: key.ID, Algorithm: key.Algorithm, Use: "sig"}
+		switch pub := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = b64(pub.N.Bytes())
+			jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			jwk.KeyType = "EC"
+			jwk.Curve = pub.Curve.Params().Name
+			jwk.X = b64(pub.X.FillBytes(make([]byte, size)))
+			jwk.Y = b64(pub.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = b64(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// PublicKey 将 JWK 转换为公钥，用于校验其他签发方（如 OIDC 身份提供方）的令牌
+// 支持 RSA、EC（P-256、P-384、P-521）和 Ed25519
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil || len(n) == 0 {
+			return nil, fmt.Errorf("invalid RSA modulus in key %q", k.KeyID)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA exponent in key %q", k.KeyID)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q in key %q", k.Curve, k.KeyID)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid EC point in key %q", k.KeyID)
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if _, err := pub.ECDH(); err != nil {
+			return nil, fmt.Errorf("invalid EC point in key %q", k.KeyID)
+		}
+		return pub, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Curve != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key %q", k.KeyID)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q in key %q", k.KeyType, k.KeyID)
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// keyFile 密钥文件格式
+type keyFile struct {
+	Keys []keyFileEntry `json:"keys"`
+}
+
+type keyFileEntry struct {
+	ID         string     `json:"kid"`
+	Algorithm  string     `json:"alg"`
+	Secret     string     `json:"secret,omitempty"`      // HS256，base64url
+	PrivateKey string     `json:"private_key,omitempty"` // PKCS#8 PEM
+	NotBefore  time.Time  `json:"not_before"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// FileKeyStore 保存在 JSON 文件中的密钥，文件被其他进程修改后自动重新加载
+type FileKeyStore struct {
+	path           string
+	reloadInterval time.Duration
+
+	mu        sync.Mutex
+	keys      []*Key
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewFileKeyStore 创建文件密钥存储，文件不存在时密钥为空
+func NewFileKeyStore(path string) *FileKeyStore {
+	return &FileKeyStore{path: path, reloadInterval: 30 * time.Second}
+}
+
+// Path 密钥文件路径
+func (s *FileKeyStore) Path() string {
+	return s.path
+}
+
+// Exists 密钥文件是否存在
+func (s *FileKeyStore) Exists() bool {
+	_, err := os.Stat(s.path)
+	return err == nil
+}
+
+// Keys 返回文件中的密钥
+func (s *FileKeyStore) Keys() ([]*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys != nil && time.Since(s.checkedAt) < s.reloadInterval {
+		return s.keys, nil
+	}
+	s.checkedAt = time.Now()
+
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.keys = []*Key{}
+		return s.keys, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.keys != nil && info.ModTime().Equal(s.modTime) {
+		return s.keys, nil
+	}
+
+	keys, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	s.keys, s.modTime = keys, info.ModTime()
+	return s.keys, nil
+}
+
+// Rotate 生成新密钥并写入文件
+// 新密钥在 publishFor 之后开始签名，让校验方先通过 JWKS 获取公钥（没有可用的签名密钥时立即生效）；
+// 旧密钥在新密钥生效后继续用于校验 retainFor（应不小于访问令牌有效期），之后被删除
+func (s *FileKeyStore) Rotate(algorithm string, now time.Time, publishFor, retainFor time.Duration) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if _, err := SigningKey(keys, now); err != nil {
+		publishFor = 0
+	}
+	key, err := GenerateKey(algorithm, now.Add(publishFor))
+	if err != nil {
+		return nil, err
+	}
+
+	retired := key.NotBefore.Add(retainFor)
+	kept := make([]*Key, 0, len(keys)+1)
+	for _, old := range keys {
+		if !old.activeAt(now) {
+			continue
+		}
+		if old.ExpiresAt.IsZero() || old.ExpiresAt.After(retired) {
+			old.ExpiresAt = retired
+		}
+		kept = append(kept, old)
+	}
+	kept = append(kept, key)
+
+	if err := s.write(kept); err != nil {
+		return nil, err
+	}
+	s.keys, s.checkedAt = kept, time.Now()
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return key, nil
+}
+
+// read 读取并解析密钥文件
+func (s *FileKeyStore) read() ([]*Key, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", s.path, err)
+	}
+
+	keys := make([]*Key, 0, len(file.Keys))
+	for _, entry := range file.Keys {
+		key := &Key{ID: entry.ID, Algorithm: entry.Algorithm, NotBefore: entry.NotBefore}
+		if entry.ExpiresAt != nil {
+			key.ExpiresAt = *entry.ExpiresAt
+		}
+		if entry.Algorithm == AlgHS256 {
+			if key.Secret, err = base64.RawURLEncoding.DecodeString(entry.Secret); err != nil {
+				return nil, fmt.Errorf("invalid secret for key %s: %w", entry.ID, err)
+			}
+		} else if key.Private, err = parsePrivateKey(entry.PrivateKey, entry.Algorithm); err != nil {
+			return nil, fmt.Errorf("invalid private key for key %s: %w", entry.ID, err)
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].NotBefore.Before(keys[j].NotBefore) })
+	return keys, nil
+}
+
+// write 原子地写入密钥文件，权限 0600
+func (s *FileKeyStore) write(keys []*Key) error {
+	file := keyFile{Keys: make([]keyFileEntry, 0, len(keys))}
+	for _, key := range keys {
+		entry := keyFileEntry{ID: key.ID, Algorithm: key.Algorithm, NotBefore: key.NotBefore}
+		if !key.ExpiresAt.IsZero() {
+			expiresAt := key.ExpiresAt
+			entry.ExpiresAt = &expiresAt
+		}
+		if key.Algorithm == AlgHS256 {
+			entry.Secret = b64(key.Secret)
+		} else {
+			der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+			if err != nil {
+				return err
+			}
+			entry.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+		}
+		file.Keys = append(file.Keys, entry)
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// parsePrivateKey 解析 PKCS#8 PEM 私钥并检查与算法匹配
+func parsePrivateKey(data, algorithm string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if algorithm == AlgRS256 {
+			return key, nil
+		}
+	case *ecdsa.PrivateKey:
+		if algorithm == AlgES256 && key.Curve == elliptic.P256() {
+			return key, nil
+		}
+	case ed25519.PrivateKey:
+		if algorithm == AlgEdDSA {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("key type %T does not match algorithm %s", parsed, algorithm)
+}
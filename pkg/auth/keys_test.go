@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestTokenManager_AsymmetricAlgorithms(t *testing.T) {
+	ctx := context.Background()
+
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			key, err := GenerateKey(alg, time.Now().Add(-time.Minute))
+			if err != nil {
+				t.Fatalf("GenerateKey error: %v", err)
+			}
+			m := newTestManager(t)
+			m.config.Keys = StaticKeys{key}
+
+			pair, err := m.Issue(ctx, 3, SessionMeta{})
+			if err != nil {
+				t.Fatalf("Issue error: %v", err)
+			}
+			token, _, _ := jwt.NewParser().ParseUnverified(pair.AccessToken, &Claims{})
+			if token.Header["kid"] != key.ID || token.Method.Alg() != alg {
+				t.Errorf("unexpected header: %v", token.Header)
+			}
+			if claims, err := m.Parse(ctx, pair.AccessToken); err != nil || claims.UserID != 3 {
+				t.Fatalf("Parse = %+v, %v", claims, err)
+			}
+
+			jwks, _ := m.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != key.ID || jwks.Keys[0].Algorithm != alg {
+				t.Errorf("unexpected JWKS: %+v", jwks)
+			}
+		})
+	}
+}
+
+func TestTokenManager_RejectsAlgorithmConfusion(t *testing.T) {
+	ctx := context.Background()
+	key, _ := GenerateKey(AlgRS256, time.Now().Add(-time.Minute))
+	m := newTestManager(t)
+	m.config.Keys = StaticKeys{key}
+
+	// 以公钥作为 HS256 密钥伪造令牌
+	public, _ := x509.MarshalPKIXPublicKey(key.Private.Public())
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID:    1,
+		SessionID: "sid",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti",
+			Issuer:    "aidecms",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	forged.Header["kid"] = key.ID
+	tokenString, _ := forged.SignedString(public)
+
+	if _, err := m.Parse(ctx, tokenString); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected invalid token, got %v", err)
+	}
+}
+
+func TestFileKeyStore_Rotate(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys", "jwt.json")
+	store := NewFileKeyStore(path)
+	now := time.Now()
+
+	// 没有签名密钥时新密钥立即生效
+	first, err := store.Rotate(AlgES256, now, 10*time.Minute, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	if !first.NotBefore.Equal(now) {
+		t.Errorf("first key should be active immediately, not before %v", first.NotBefore)
+	}
+
+	m := newTestManager(t)
+	m.config.Keys = store
+	oldPair, _ := m.Issue(ctx, 1, SessionMeta{})
+
+	second, err := store.Rotate(AlgES256, now, 10*time.Minute, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	// 发布期内新密钥出现在 JWKS 中，但仍使用旧密钥签名
+	jwks, _ := m.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected 2 published keys, got %+v", jwks)
+	}
+	pair, _ := m.Issue(ctx, 1, SessionMeta{})
+	if kid := tokenKeyID(t, pair.AccessToken); kid != first.ID {
+		t.Errorf("expected old key before activation, got %s", kid)
+	}
+
+	// 新密钥生效后签名切换，旧令牌仍可校验
+	m.now = func() time.Time { return now.Add(11 * time.Minute) }
+	pair, _ = m.Issue(ctx, 1, SessionMeta{})
+	if kid := tokenKeyID(t, pair.AccessToken); kid != second.ID {
+		t.Errorf("expected new key after activation, got %s", kid)
+	}
+	if _, err := m.Parse(ctx, oldPair.AccessToken); err != nil {
+		t.Errorf("tokens signed by the old key should stay valid: %v", err)
+	}
+
+	// 其他进程读取同一文件得到相同的密钥
+	reloaded, err := NewFileKeyStore(path).Keys()
+	if err != nil || len(reloaded) != 2 || reloaded[1].ID != second.ID {
+		t.Fatalf("reloaded keys = %v, %v", reloaded, err)
+	}
+	if !reloaded[0].ExpiresAt.Equal(second.NotBefore.Add(15 * time.Minute)) {
+		t.Errorf("old key should expire after retention, got %v", reloaded[0].ExpiresAt)
+	}
+
+	// 旧密钥过期后不再发布，下次轮换时删除
+	m.now = func() time.Time { return now.Add(30 * time.Minute) }
+	if jwks, _ := m.JWKS(); len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != second.ID {
+		t.Errorf("expected only the new key, got %+v", jwks)
+	}
+	if _, err := store.Rotate(AlgES256, now.Add(30*time.Minute), 0, time.Minute); err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	if keys, _ := NewFileKeyStore(path).Keys(); len(keys) != 2 {
+		t.Errorf("expected expired key pruned, got %d keys", len(keys))
+	}
+}
+
+func tokenKeyID(t *testing.T, tokenString string) string {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified error: %v", err)
+	}
+	kid, _ := token.Header["kid"].(string)
+	return kid
+}
+
+func TestJWK_PublicKeyRoundTrip(t *testing.T) {
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			key, err := GenerateKey(alg, time.Now())
+			if err != nil {
+				t.Fatalf("GenerateKey error: %v", err)
+			}
+			jwks := PublicJWKS([]*Key{key}, time.Now())
+
+			pub, err := jwks.Keys[0].PublicKey()
+			if err != nil {
+				t.Fatalf("PublicKey error: %v", err)
+			}
+			if !key.Private.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(pub) {
+				t.Error("public key does not match the signing key")
+			}
+		})
+	}
+
+	if _, err := (JWK{KeyType: "EC", Curve: "P-256", X: "AQ", Y: "AQ"}).PublicKey(); err == nil {
+		t.Error("expected error for a point that is not on the curve")
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// MFAAudience 两步验证待完成令牌的 aud，访问令牌校验时会拒绝带 aud 的令牌
+const MFAAudience = "mfa"
+
+// MFAClaims 两步验证待完成令牌声明
+// 密码验证通过但尚未提交验证码时签发，只能用于完成两步验证
+type MFAClaims struct {
+	UserID uint `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// IssueMFAToken 签发两步验证待完成令牌
+func (m *TokenManager) IssueMFAToken(userID uint) (string, int64, error) {
+	now := m.now()
+	claims := MFAClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			Issuer:    m.config.Issuer,
+			Audience:  jwt.ClaimStrings{MFAAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.config.MFATTL)),
+		},
+	}
+	token, err := m.sign(claims, now)
+	if err != nil {
+		return "", 0, err
+	}
+	return token, int64(m.config.MFATTL.Seconds()), nil
+}
+
+// ParseMFAToken 校验两步验证待完成令牌
+func (m *TokenManager) ParseMFAToken(ctx context.Context, tokenString string) (*MFAClaims, error) {
+	keys, err := m.config.Keys.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	options := append(m.parserOptions(), jwt.WithAudience(MFAAudience))
+	claims := &MFAClaims{}
+	if _, err := jwt.ParseWithClaims(tokenString, claims, m.keyFunc(keys), options...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.UserID == 0 || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	denied, err := m.denylist.IsDenied(ctx, jtiKey(claims.ID))
+	if err != nil {
+		return nil, err
+	}
+	if denied {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// ConsumeMFAToken 使两步验证待完成令牌失效，验证成功或失败次数过多后调用
+func (m *TokenManager) ConsumeMFAToken(ctx context.Context, claims *MFAClaims) error {
+	if claims.ExpiresAt == nil {
+		return nil
+	}
+	return m.denylist.Deny(ctx, jtiKey(claims.ID), claims.ExpiresAt.Time)
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"gorm.io/gorm"
+)
+
+// RefreshToken 刷新令牌记录，同一会话轮换出的令牌共享 SessionID
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null"`
+	UserID    uint       `gorm:"index;not null"`
+	SessionID string     `gorm:"size:36;index;not null"`
+	UserAgent string     `gorm:"size:255"`
+	IP        string     `gorm:"size:45"`
+	ExpiresAt time.Time  `gorm:"index;not null"`
+	UsedAt    *time.Time // 已轮换
+	RevokedAt *time.Time // 已注销
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (RefreshToken) TableName() string {
+	return database.TableName("refresh_tokens")
+}
+
+// RefreshTokenStore 基于GORM的刷新令牌存储（支持 sqlite、mysql、postgres）
+type RefreshTokenStore struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenStore 创建刷新令牌存储，数据表由 refresh_tokens 迁移创建
+func NewRefreshTokenStore(db *gorm.DB) (*RefreshTokenStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	// 令牌状态必须读写主库
+	return &RefreshTokenStore{db: database.UsePrimary(db)}, nil
+}
+
+// Save 保存刷新令牌
+func (s *RefreshTokenStore) Save(ctx context.Context, token *RefreshToken) error {
+	return s.db.WithContext(ctx).Create(token).Error
+}
+
+// Find 按哈希值查找刷新令牌
+func (s *RefreshTokenStore) Find(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	var token RefreshToken
+	err := s.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed 标记刷新令牌已轮换，令牌已被使用时返回false
+func (s *RefreshTokenStore) MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeSession 注销会话的所有刷新令牌
+func (s *RefreshTokenStore) RevokeSession(ctx context.Context, sessionID string, at time.Time) error {
+	return s.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", at).Error
+}
+
+// ActiveSessions 返回用户未注销且未过期的会话
+func (s *RefreshTokenStore) ActiveSessions(ctx context.Context, userID uint, now time.Time) ([]string, error) {
+	var sessions []string
+	err := s.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Distinct().Pluck("session_id", &sessions).Error
+	return sessions, err
+}
+
+// Prune 删除已过期的刷新令牌
+func (s *RefreshTokenStore) Prune(ctx context.Context, now time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&RefreshToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// 签名令牌用途，不同用途的令牌不能互相使用
+const (
+	PurposePasswordReset = "password_reset"
+	PurposeVerifyEmail   = "verify_email"
+)
+
+// ErrTokenExpired 签名令牌已过期
+var ErrTokenExpired = errors.New("token has expired")
+
+// SignedTokens 签发和校验无状态的一次性链接令牌（密码重置、邮箱验证）
+// 令牌包含用户ID和过期时间，签名时绑定用户的当前状态（如密码哈希、邮箱），
+// 状态改变后旧令牌自动失效，因此不需要在数据库中保存令牌
+type SignedTokens struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewSignedTokens 创建签名令牌，secret 应至少32字节
+func NewSignedTokens(secret []byte) *SignedTokens {
+	return &SignedTokens{secret: secret, now: time.Now}
+}
+
+// Sign 签发令牌，binding 为签名时绑定的用户状态
+func (s *SignedTokens) Sign(purpose string, userID uint, binding string, ttl time.Duration) string {
+	payload := make([]byte, 16)
+	binary.BigEndian.PutUint64(payload[:8], uint64(userID))
+	binary.BigEndian.PutUint64(payload[8:], uint64(s.now().Add(ttl).Unix()))
+	return base64.RawURLEncoding.EncodeToString(append(payload, s.mac(purpose, payload, binding)...))
+}
+
+// UserID 解析令牌中的用户ID，不校验签名，调用方需要再用 Verify 校验
+func (s *SignedTokens) UserID(token string) (uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != 16+sha256.Size {
+		return 0, ErrInvalidToken
+	}
+	return uint(binary.BigEndian.Uint64(raw[:8])), nil
+}
+
+// Verify 校验令牌的签名、用途和有效期，binding 必须与签发时一致
+func (s *SignedTokens) Verify(purpose, token, binding string) error {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != 16+sha256.Size {
+		return ErrInvalidToken
+	}
+
+	payload, signature := raw[:16], raw[16:]
+	if subtle.ConstantTimeCompare(signature, s.mac(purpose, payload, binding)) != 1 {
+		return ErrInvalidToken
+	}
+	if expires := int64(binary.BigEndian.Uint64(payload[8:])); s.now().Unix() > expires {
+		return ErrTokenExpired
+	}
+	return nil
+}
+
+// mac 计算 HMAC-SHA256(purpose | payload | binding)
+func (s *SignedTokens) mac(purpose string, payload []byte, binding string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	mac.Write([]byte{0})
+	mac.Write([]byte(binding))
+	return mac.Sum(nil)
+}
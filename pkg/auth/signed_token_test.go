@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSignedTokens(t *testing.T) {
+	s := NewSignedTokens([]byte("0123456789abcdef0123456789abcdef"))
+	token := s.Sign(PurposePasswordReset, 42, "hash|a@example.com", time.Hour)
+
+	if userID, err := s.UserID(token); err != nil || userID != 42 {
+		t.Fatalf("UserID = %d, %v", userID, err)
+	}
+	if err := s.Verify(PurposePasswordReset, token, "hash|a@example.com"); err != nil {
+		t.Fatalf("Verify error: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		purpose string
+		token   string
+		binding string
+		want    error
+	}{
+		{"other purpose", PurposeVerifyEmail, token, "hash|a@example.com", ErrInvalidToken},
+		{"binding changed", PurposePasswordReset, token, "newhash|a@example.com", ErrInvalidToken},
+		{"tampered", PurposePasswordReset, token[:len(token)-2] + "AA", "hash|a@example.com", ErrInvalidToken},
+		{"garbage", PurposePasswordReset, "not-a-token", "hash|a@example.com", ErrInvalidToken},
+	}
+	for _, tc := range cases {
+		if err := s.Verify(tc.purpose, tc.token, tc.binding); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Verify = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	other := NewSignedTokens([]byte("another secret another secret 00"))
+	if err := other.Verify(PurposePasswordReset, token, "hash|a@example.com"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token signed with another secret should fail, got %v", err)
+	}
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if err := s.Verify(PurposePasswordReset, token, "hash|a@example.com"); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expired token should fail, got %v", err)
+	}
+}
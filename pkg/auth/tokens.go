@@ -0,0 +1,334 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidToken 令牌格式、签名或声明无效
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrTokenRevoked 令牌已注销
+	ErrTokenRevoked = errors.New("token has been revoked")
+
+	// ErrRefreshTokenReused 刷新令牌被重复使用，所属会话已被注销
+	ErrRefreshTokenReused = errors.New("refresh token reused, session revoked")
+)
+
+// ClaimsKey 认证中间件在请求上下文中保存 *Claims 的键
+const ClaimsKey = "auth_claims"
+
+// Claims 访问令牌声明
+type Claims struct {
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// TokenPair 登录或刷新后返回的令牌
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresIn        int64     `json:"expires_in"`         // 访问令牌有效期（秒）
+	RefreshExpiresIn int64     `json:"refresh_expires_in"` // 刷新令牌有效期（秒）
+	ExpiresAt        time.Time `json:"expires_at"`
+	SessionID        string    `json:"-"`
+}
+
+// SessionMeta 会话的客户端信息，记录在刷新令牌上
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
+
+// Config 令牌配置
+type Config struct {
+	Secret     []byte        // HS256 签名密钥，未设置 Keys 时使用
+	Keys       KeySource     // 签名和校验密钥，支持多个密钥按 kid 轮换
+	Issuer     string        // 签发者（iss），为空时不校验
+	AccessTTL  time.Duration // 访问令牌有效期
+	RefreshTTL time.Duration // 刷新令牌有效期
+	MFATTL     time.Duration // 两步验证待完成令牌有效期
+}
+
+// TokenManager 签发和校验访问令牌，管理可轮换的刷新令牌和令牌注销
+type TokenManager struct {
+	config   Config
+	store    *RefreshTokenStore
+	denylist Denylist
+	now      func() time.Time
+}
+
+// NewTokenManager 创建令牌管理器
+func NewTokenManager(config Config, store *RefreshTokenStore, denylist Denylist) *TokenManager {
+	if config.AccessTTL <= 0 {
+		config.AccessTTL = 15 * time.Minute
+	}
+	if config.RefreshTTL <= 0 {
+		config.RefreshTTL = 30 * 24 * time.Hour
+	}
+	if config.MFATTL <= 0 {
+		config.MFATTL = 5 * time.Minute
+	}
+	if config.Keys == nil {
+		config.Keys = SecretKey(config.Secret)
+	}
+	return &TokenManager{
+		config:   config,
+		store:    store,
+		denylist: denylist,
+		now:      time.Now,
+	}
+}
+
+// AccessTTL 访问令牌有效期
+func (m *TokenManager) AccessTTL() time.Duration {
+	return m.config.AccessTTL
+}
+
+// Issue 为用户创建新会话并签发令牌
+func (m *TokenManager) Issue(ctx context.Context, userID uint, meta SessionMeta) (*TokenPair, error) {
+	return m.issue(ctx, userID, uuid.NewString(), meta)
+}
+
+// Parse 校验访问令牌的签名、有效期和注销状态
+func (m *TokenManager) Parse(ctx context.Context, tokenString string) (*Claims, error) {
+	keys, err := m.config.Keys.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	options := m.parserOptions()
+
+	claims := &Claims{}
+	if _, err = jwt.ParseWithClaims(tokenString, claims, m.keyFunc(keys), options...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	// 带 aud 的令牌（如两步验证待完成令牌）不能作为访问令牌使用
+	if claims.UserID == 0 || claims.ID == "" || claims.SessionID == "" || len(claims.Audience) > 0 {
+		return nil, ErrInvalidToken
+	}
+
+	denied, err := m.denylist.IsDenied(ctx, jtiKey(claims.ID), sessionKey(claims.SessionID))
+	if err != nil {
+		return nil, err
+	}
+	if denied {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// JWKS 返回用于校验访问令牌的公钥，使用共享密钥时为空
+func (m *TokenManager) JWKS() (JWKSet, error) {
+	keys, err := m.config.Keys.Keys()
+	if err != nil {
+		return JWKSet{}, err
+	}
+	return PublicJWKS(keys, m.now()), nil
+}
+
+// Refresh 使用刷新令牌换取新的令牌，旧的刷新令牌随即失效
+// 已使用过的刷新令牌再次出现时视为泄露，注销整个会话
+func (m *TokenManager) Refresh(ctx context.Context, refreshToken string, meta SessionMeta) (*TokenPair, error) {
+	record, err := m.store.Find(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	switch {
+	case record.RevokedAt != nil:
+		return nil, ErrTokenRevoked
+	case record.UsedAt != nil:
+		if err := m.revokeSession(ctx, record.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	case now.After(record.ExpiresAt):
+		return nil, fmt.Errorf("%w: refresh token expired", ErrInvalidToken)
+	}
+
+	// 并发刷新时只有一个请求能标记成功
+	used, err := m.store.MarkUsed(ctx, record.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		if err := m.revokeSession(ctx, record.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	return m.issue(ctx, record.UserID, record.SessionID, meta)
+}
+
+// Logout 注销访问令牌所属的会话，同一会话签发的其他令牌一并失效
+func (m *TokenManager) Logout(ctx context.Context, claims *Claims) error {
+	if claims.ExpiresAt != nil {
+		if err := m.denylist.Deny(ctx, jtiKey(claims.ID), claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+	return m.revokeSession(ctx, claims.SessionID)
+}
+
+// RevokeAll 注销用户的所有会话，用于修改密码或账号被盗
+func (m *TokenManager) RevokeAll(ctx context.Context, userID uint) error {
+	sessions, err := m.store.ActiveSessions(ctx, userID, m.now())
+	if err != nil {
+		return err
+	}
+	for _, sessionID := range sessions {
+		if err := m.revokeSession(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeSession 注销会话的刷新令牌，并在访问令牌有效期内拒绝该会话的访问令牌
+func (m *TokenManager) revokeSession(ctx context.Context, sessionID string) error {
+	now := m.now()
+	if err := m.store.RevokeSession(ctx, sessionID, now); err != nil {
+		return err
+	}
+	return m.denylist.Deny(ctx, sessionKey(sessionID), now.Add(m.config.AccessTTL))
+}
+
+// issue 签发访问令牌和刷新令牌
+func (m *TokenManager) issue(ctx context.Context, userID uint, sessionID string, meta SessionMeta) (*TokenPair, error) {
+	now := m.now()
+	expiresAt := now.Add(m.config.AccessTTL)
+
+	claims := Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			Issuer:    m.config.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	accessToken, err := m.sign(claims, now)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	record := &RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		UserID:    userID,
+		SessionID: sessionID,
+		UserAgent: truncate(meta.UserAgent, 255),
+		IP:        truncate(meta.IP, 45),
+		ExpiresAt: now.Add(m.config.RefreshTTL),
+	}
+	if err := m.store.Save(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(m.config.AccessTTL.Seconds()),
+		RefreshExpiresIn: int64(m.config.RefreshTTL.Seconds()),
+		ExpiresAt:        expiresAt,
+		SessionID:        sessionID,
+	}, nil
+}
+
+// sign 使用当前签名密钥签发令牌
+func (m *TokenManager) sign(claims jwt.Claims, now time.Time) (string, error) {
+	keys, err := m.config.Keys.Keys()
+	if err != nil {
+		return "", err
+	}
+	key, err := SigningKey(keys, now)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method(), claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.signingKey())
+}
+
+// parserOptions 校验令牌的通用选项
+func (m *TokenManager) parserOptions() []jwt.ParserOption {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgES256, AlgEdDSA}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(m.now),
+	}
+	if m.config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(m.config.Issuer))
+	}
+	return options
+}
+
+// keyFunc 按 kid 查找校验密钥，算法必须与密钥一致，防止算法混淆
+func (m *TokenManager) keyFunc(keys []*Key) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range keys {
+			if key.ID == kid && key.activeAt(m.now()) {
+				if token.Method.Alg() != key.Algorithm {
+					return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+				}
+				return key.verificationKey(), nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+}
+
+// randomToken 生成不透明的刷新令牌
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken 刷新令牌只保存哈希值
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func jtiKey(jti string) string {
+	return "jti:" + jti
+}
+
+func sessionKey(sessionID string) string {
+	return "sid:" + sessionID
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestManager(t *testing.T) *TokenManager {
+	t.Helper()
+
+	d := database.NewDatabase(&database.Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "auth.db")})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	if err := d.DB.AutoMigrate(&RefreshToken{}, &DeniedToken{}); err != nil {
+		t.Fatalf("AutoMigrate error: %v", err)
+	}
+
+	store, err := NewRefreshTokenStore(d.DB)
+	if err != nil {
+		t.Fatalf("NewRefreshTokenStore error: %v", err)
+	}
+	denylist, err := NewGormDenylist(d.DB)
+	if err != nil {
+		t.Fatalf("NewGormDenylist error: %v", err)
+	}
+	return NewTokenManager(Config{Secret: []byte("secret"), Issuer: "aidecms"}, store, denylist)
+}
+
+func TestTokenManager_IssueAndParse(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+
+	pair, err := m.Issue(ctx, 7, SessionMeta{UserAgent: "test", IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Issue error: %v", err)
+	}
+	if pair.ExpiresIn != 900 || pair.RefreshToken == "" {
+		t.Errorf("unexpected pair: %+v", pair)
+	}
+
+	claims, err := m.Parse(ctx, pair.AccessToken)
+	if err != nil || claims.UserID != 7 || claims.SessionID != pair.SessionID || claims.ID == "" {
+		t.Fatalf("Parse = %+v, %v", claims, err)
+	}
+
+	// 其他算法和密钥签名的令牌无效
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodHS384, claims).SignedString([]byte("secret"))
+	if _, err := m.Parse(ctx, forged); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected invalid token for HS384, got %v", err)
+	}
+
+	// 过期
+	m.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if _, err := m.Parse(ctx, pair.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected expired token to be invalid, got %v", err)
+	}
+}
+
+func TestTokenManager_RefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+
+	first, _ := m.Issue(ctx, 1, SessionMeta{})
+	second, err := m.Refresh(ctx, first.RefreshToken, SessionMeta{})
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	if second.SessionID != first.SessionID || second.RefreshToken == first.RefreshToken {
+		t.Errorf("refresh should rotate within the same session: %+v", second)
+	}
+	if _, err := m.Parse(ctx, second.AccessToken); err != nil {
+		t.Errorf("refreshed access token should be valid: %v", err)
+	}
+
+	// 重复使用旧的刷新令牌会注销整个会话
+	if _, err := m.Refresh(ctx, first.RefreshToken, SessionMeta{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("expected reuse detection, got %v", err)
+	}
+	if _, err := m.Refresh(ctx, second.RefreshToken, SessionMeta{}); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("expected latest refresh token revoked, got %v", err)
+	}
+	if _, err := m.Parse(ctx, second.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("expected access token revoked, got %v", err)
+	}
+
+	if _, err := m.Refresh(ctx, "unknown", SessionMeta{}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected invalid refresh token, got %v", err)
+	}
+}
+
+func TestTokenManager_LogoutAndRevokeAll(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+
+	phone, _ := m.Issue(ctx, 1, SessionMeta{})
+	laptop, _ := m.Issue(ctx, 1, SessionMeta{})
+	other, _ := m.Issue(ctx, 2, SessionMeta{})
+
+	claims, _ := m.Parse(ctx, phone.AccessToken)
+	if err := m.Logout(ctx, claims); err != nil {
+		t.Fatalf("Logout error: %v", err)
+	}
+	if _, err := m.Parse(ctx, phone.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("expected logged out token revoked, got %v", err)
+	}
+	if _, err := m.Refresh(ctx, phone.RefreshToken, SessionMeta{}); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("expected logged out refresh token revoked, got %v", err)
+	}
+	if _, err := m.Parse(ctx, laptop.AccessToken); err != nil {
+		t.Errorf("other sessions should stay valid: %v", err)
+	}
+
+	if err := m.RevokeAll(ctx, 1); err != nil {
+		t.Fatalf("RevokeAll error: %v", err)
+	}
+	if _, err := m.Parse(ctx, laptop.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("expected all sessions revoked, got %v", err)
+	}
+	if _, err := m.Parse(ctx, other.AccessToken); err != nil {
+		t.Errorf("other users should not be affected: %v", err)
+	}
+}
+
+func TestTokenManager_MFAToken(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+
+	token, expiresIn, err := m.IssueMFAToken(7)
+	if err != nil {
+		t.Fatalf("IssueMFAToken error: %v", err)
+	}
+	if expiresIn != 300 {
+		t.Errorf("expiresIn = %d, want 300", expiresIn)
+	}
+
+	// 待完成令牌不能作为访问令牌使用
+	if _, err := m.Parse(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("mfa token used as access token: %v", err)
+	}
+	// 访问令牌也不能作为待完成令牌使用
+	pair, _ := m.Issue(ctx, 7, SessionMeta{})
+	if _, err := m.ParseMFAToken(ctx, pair.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("access token used as mfa token: %v", err)
+	}
+
+	claims, err := m.ParseMFAToken(ctx, token)
+	if err != nil || claims.UserID != 7 {
+		t.Fatalf("ParseMFAToken = %+v, %v", claims, err)
+	}
+	if err := m.ConsumeMFAToken(ctx, claims); err != nil {
+		t.Fatalf("ConsumeMFAToken error: %v", err)
+	}
+	if _, err := m.ParseMFAToken(ctx, token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("consumed mfa token should be rejected, got %v", err)
+	}
+
+	m.now = func() time.Time { return time.Now().Add(6 * time.Minute) }
+	other, _, _ := m.IssueMFAToken(7)
+	m.now = func() time.Time { return time.Now().Add(12 * time.Minute) }
+	if _, err := m.ParseMFAToken(ctx, other); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expired mfa token should be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录B的 SHA1 测试密钥 "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238(t *testing.T) {
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tc := range cases {
+		got, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode error: %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := TOTPStep(now)
+
+	if got, ok := ValidateTOTP(rfcSecret, "081804", now); !ok || got != step {
+		t.Errorf("current code: step=%d ok=%v", got, ok)
+	}
+	previous, _ := TOTPCode(rfcSecret, step-1)
+	if got, ok := ValidateTOTP(rfcSecret, previous, now); !ok || got != step-1 {
+		t.Errorf("previous window should be accepted: step=%d ok=%v", got, ok)
+	}
+	stale, _ := TOTPCode(rfcSecret, step-2)
+	if _, ok := ValidateTOTP(rfcSecret, stale, now); ok {
+		t.Error("code outside skew should be rejected")
+	}
+	for _, code := range []string{"", "12345", "abcdef", "0818045"} {
+		if _, ok := ValidateTOTP(rfcSecret, code, now); ok {
+			t.Errorf("code %q should be rejected", code)
+		}
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret error: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret length = %d, want 32", len(secret))
+	}
+	if _, err := TOTPCode(secret, 1); err != nil {
+		t.Errorf("generated secret should be usable: %v", err)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Aide CMS", "admin@example.com", rfcSecret)
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("parse uri: %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Aide CMS:admin@example.com" {
+		t.Errorf("unexpected uri %s", uri)
+	}
+	query := u.Query()
+	if query.Get("secret") != rfcSecret || query.Get("issuer") != "Aide CMS" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes error: %v", err)
+	}
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("unexpected code format %q", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+
+	code := codes[0]
+	variants := []string{code, strings.ToUpper(code), strings.ReplaceAll(code, "-", ""), " " + code + " "}
+	for _, v := range variants {
+		if HashRecoveryCode(v) != HashRecoveryCode(code) {
+			t.Errorf("HashRecoveryCode(%q) should match %q", v, code)
+		}
+	}
+	if HashRecoveryCode(codes[1]) == HashRecoveryCode(code) {
+		t.Error("different codes should not share a hash")
+	}
+}
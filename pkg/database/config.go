@@ -0,0 +1,148 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/config"
+)
+
+// 各驱动的默认端口
+var defaultPorts = map[string]string{
+	"mysql":    "3306",
+	"postgres": "5432",
+}
+
+// LoadConfig 读取数据库配置，config/database.json 中的配置优先，其次为 DB_* 环境变量
+// cfg 为nil时只读取环境变量
+func LoadConfig(cfg *config.Config) *Config {
+	source := configSource{cfg: cfg}
+
+	driver := source.String("driver", "DB_CONNECTION", "sqlite")
+	database := source.String("database", "DB_DATABASE", "")
+	if driver == "sqlite" && database == "" {
+		database = "storage/database/data.db"
+	}
+
+	dbConfig := &Config{
+		Driver:      driver,
+		Host:        source.String("host", "DB_HOST", "127.0.0.1"),
+		Port:        source.String("port", "DB_PORT", defaultPorts[driver]),
+		Database:    database,
+		Username:    source.String("username", "DB_USERNAME", ""),
+		Password:    source.String("password", "DB_PASSWORD", ""),
+		Charset:     source.String("charset", "DB_CHARSET", "utf8mb4"),
+		SSLMode:     source.String("sslmode", "DB_SSLMODE", "disable"),
+		TimeZone:    source.String("timezone", "DB_TIMEZONE", "Asia/Shanghai"),
+		Prefix:      source.String("prefix", "DB_PREFIX", ""),
+		MaxIdleConn: source.Int("max_idle_conns", "DB_MAX_IDLE_CONNS", 10),
+		MaxOpenConn: source.Int("max_open_conns", "DB_MAX_OPEN_CONNS", 100),
+		MaxLifetime: time.Duration(source.Int("conn_max_lifetime", "DB_CONN_MAX_LIFETIME", 3600)) * time.Second,
+		MaxIdleTime: time.Duration(source.Int("conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME", 0)) * time.Second,
+		Debug:       source.Bool("debug", "DB_DEBUG", false),
+	}
+	dbConfig.Replicas = source.Replicas()
+
+	return dbConfig
+}
+
+// configSource 按配置文件、环境变量、默认值的顺序读取配置
+type configSource struct {
+	cfg *config.Config
+}
+
+// value 读取 database.<key> 配置项
+func (s configSource) value(key string) (string, bool) {
+	if s.cfg == nil {
+		return "", false
+	}
+	value := s.cfg.Get("database." + key)
+	if value == nil {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+// String 读取字符串配置
+func (s configSource) String(key, env, defaultValue string) string {
+	if value, ok := s.value(key); ok {
+		return value
+	}
+	return config.GetEnv(env, defaultValue)
+}
+
+// Int 读取整数配置
+func (s configSource) Int(key, env string, defaultValue int) int {
+	if value, ok := s.value(key); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return config.GetEnvInt(env, defaultValue)
+}
+
+// Bool 读取布尔配置
+func (s configSource) Bool(key, env string, defaultValue bool) bool {
+	if value, ok := s.value(key); ok {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return config.GetEnvBool(env, defaultValue)
+}
+
+// Replicas 读取只读副本配置
+// 配置文件: "replicas": [{"host": "10.0.0.2", "port": "3306"}]
+// 环境变量: DB_READ_HOSTS=10.0.0.2,10.0.0.3:3307，可选 DB_READ_USERNAME、DB_READ_PASSWORD
+func (s configSource) Replicas() []ReplicaConfig {
+	if s.cfg != nil {
+		if items, ok := s.cfg.Get("database.replicas").([]interface{}); ok {
+			replicas := make([]ReplicaConfig, 0, len(items))
+			for _, item := range items {
+				fields, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				field := func(name string) string {
+					if value, ok := fields[name]; ok && value != nil {
+						return fmt.Sprint(value)
+					}
+					return ""
+				}
+				replicas = append(replicas, ReplicaConfig{
+					Host:     field("host"),
+					Port:     field("port"),
+					Database: field("database"),
+					Username: field("username"),
+					Password: field("password"),
+				})
+			}
+			return replicas
+		}
+	}
+
+	hosts := config.GetEnv("DB_READ_HOSTS", "")
+	if hosts == "" {
+		return nil
+	}
+
+	var replicas []ReplicaConfig
+	for _, host := range strings.Split(hosts, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		replica := ReplicaConfig{
+			Host:     host,
+			Username: config.GetEnv("DB_READ_USERNAME", ""),
+			Password: config.GetEnv("DB_READ_PASSWORD", ""),
+		}
+		if h, port, found := strings.Cut(host, ":"); found {
+			replica.Host, replica.Port = h, port
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas
+}
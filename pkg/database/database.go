@@ -2,6 +2,9 @@ package database
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"gorm.io/driver/mysql"
@@ -9,6 +12,7 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 )
 
 // Config 数据库配置
@@ -20,22 +24,44 @@ type Config struct {
 	Username    string
 	Password    string
 	Charset     string
+	SSLMode     string // postgres
+	TimeZone    string // postgres
 	Prefix      string
 	MaxIdleConn int
 	MaxOpenConn int
 	MaxLifetime time.Duration
+	MaxIdleTime time.Duration
+	Replicas    []ReplicaConfig // 只读副本，配置后查询走副本，写入和事务走主库
 	Debug       bool
 }
 
+// ReplicaConfig 只读副本配置，未设置的字段沿用主库配置
+type ReplicaConfig struct {
+	Host     string
+	Port     string
+	Database string
+	Username string
+	Password string
+}
+
 // Database 数据库连接管理器
 type Database struct {
-	DB     *gorm.DB
-	Config *Config
+	DB       *gorm.DB
+	Config   *Config
+	replicas []*gorm.DB
 }
 
 // 全局数据库实例
 var globalDB *gorm.DB
 
+// 表名前缀，供定义了 TableName 的模型使用
+var tablePrefix string
+
+// TableName 返回加上表名前缀的表名，定义了 TableName 方法的模型和手写SQL应使用该函数
+func TableName(name string) string {
+	return tablePrefix + name
+}
+
 // NewDatabase 创建一个新的数据库连接管理器
 func NewDatabase(config *Config) *Database {
 	return &Database{
@@ -53,58 +79,138 @@ func GetDB() *gorm.DB {
 	return globalDB
 }
 
-// Connect 连接数据库
+// Connect 连接数据库，配置了只读副本时同时连接副本并启用读写分离
 func (d *Database) Connect() error {
-	var err error
-	var db *gorm.DB
+	if d.Config.Driver == "sqlite" {
+		if err := ensureSQLiteDir(d.Config.Database); err != nil {
+			return err
+		}
+	}
+
+	dialector, err := d.dialector(d.Config)
+	if err != nil {
+		return err
+	}
 
-	switch d.Config.Driver {
+	tablePrefix = d.Config.Prefix
+	db, err := gorm.Open(dialector, d.getGormConfig())
+	if err != nil {
+		return err
+	}
+	if err := d.configurePool(db); err != nil {
+		return err
+	}
+
+	if len(d.Config.Replicas) > 0 {
+		replicas := make([]*gorm.DB, 0, len(d.Config.Replicas))
+		for i, replica := range d.Config.Replicas {
+			dialector, err := d.dialector(d.replicaConfig(replica))
+			if err != nil {
+				return err
+			}
+			replicaDB, err := gorm.Open(dialector, d.getGormConfig())
+			if err != nil {
+				return fmt.Errorf("failed to connect replica %d: %w", i, err)
+			}
+			if err := d.configurePool(replicaDB); err != nil {
+				return err
+			}
+			replicas = append(replicas, replicaDB)
+		}
+
+		if err := db.Use(newResolver(replicas)); err != nil {
+			return err
+		}
+		d.replicas = replicas
+	}
+
+	d.DB = db
+	return nil
+}
+
+// dialector 根据驱动创建GORM方言
+func (d *Database) dialector(config *Config) (gorm.Dialector, error) {
+	switch config.Driver {
 	case "mysql":
 		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=%s&parseTime=True&loc=Local",
-			d.Config.Username,
-			d.Config.Password,
-			d.Config.Host,
-			d.Config.Port,
-			d.Config.Database,
-			d.Config.Charset,
+			config.Username,
+			config.Password,
+			config.Host,
+			config.Port,
+			config.Database,
+			config.Charset,
 		)
-		db, err = gorm.Open(mysql.Open(dsn), d.getGormConfig())
+		return mysql.Open(dsn), nil
 	case "postgres":
-		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Shanghai",
-			d.Config.Host,
-			d.Config.Username,
-			d.Config.Password,
-			d.Config.Database,
-			d.Config.Port,
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+			config.Host,
+			config.Username,
+			config.Password,
+			config.Database,
+			config.Port,
+			config.SSLMode,
+			config.TimeZone,
 		)
-		db, err = gorm.Open(postgres.Open(dsn), d.getGormConfig())
+		return postgres.Open(dsn), nil
 	case "sqlite":
-		db, err = gorm.Open(sqlite.Open(d.Config.Database), d.getGormConfig())
+		return sqlite.Open(config.Database), nil
 	default:
-		return fmt.Errorf("unsupported database driver: %s", d.Config.Driver)
+		return nil, fmt.Errorf("unsupported database driver: %s", config.Driver)
 	}
+}
 
-	if err != nil {
-		return err
+// replicaConfig 合并副本配置和主库配置
+func (d *Database) replicaConfig(replica ReplicaConfig) *Config {
+	config := *d.Config
+	config.Replicas = nil
+	if replica.Host != "" {
+		config.Host = replica.Host
+	}
+	if replica.Port != "" {
+		config.Port = replica.Port
+	}
+	if replica.Database != "" {
+		config.Database = replica.Database
+	}
+	if replica.Username != "" {
+		config.Username = replica.Username
 	}
+	if replica.Password != "" {
+		config.Password = replica.Password
+	}
+	return &config
+}
 
+// configurePool 设置连接池
+func (d *Database) configurePool(db *gorm.DB) error {
 	sqlDB, err := db.DB()
 	if err != nil {
 		return err
 	}
 
-	// 设置连接池
 	sqlDB.SetMaxIdleConns(d.Config.MaxIdleConn)
 	sqlDB.SetMaxOpenConns(d.Config.MaxOpenConn)
 	sqlDB.SetConnMaxLifetime(d.Config.MaxLifetime)
+	sqlDB.SetConnMaxIdleTime(d.Config.MaxIdleTime)
+	return nil
+}
 
-	d.DB = db
+// ensureSQLiteDir 确保SQLite数据库文件所在目录存在
+func ensureSQLiteDir(path string) error {
+	if path == "" || path == ":memory:" || strings.HasPrefix(path, "file:") {
+		return nil
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		return os.MkdirAll(dir, 0755)
+	}
 	return nil
 }
 
 // getGormConfig 获取GORM配置
 func (d *Database) getGormConfig() *gorm.Config {
-	config := &gorm.Config{}
+	config := &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: d.Config.Prefix},
+	}
 
 	if d.Config.Debug {
 		config.Logger = logger.Default.LogMode(logger.Info)
@@ -115,8 +221,14 @@ func (d *Database) getGormConfig() *gorm.Config {
 	return config
 }
 
-// Close 关闭数据库连接
+// Close 关闭数据库连接（包括只读副本）
 func (d *Database) Close() error {
+	for _, replica := range d.replicas {
+		if sqlDB, err := replica.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+
 	if d.DB != nil {
 		sqlDB, err := d.DB.DB()
 		if err != nil {
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/config"
+	"gorm.io/gorm"
+)
+
+type testItem struct {
+	ID   uint
+	Name string
+}
+
+type testTabler struct {
+	ID uint
+}
+
+func (testTabler) TableName() string {
+	return TableName("tablers")
+}
+
+// seed 在指定数据库中创建表并写入一条记录
+func seed(t *testing.T, path, name string) {
+	t.Helper()
+
+	d := NewDatabase(&Config{Driver: "sqlite", Database: path})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("connect %s: %v", path, err)
+	}
+	defer d.Close()
+
+	if err := d.DB.AutoMigrate(&testItem{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	d.DB.Create(&testItem{Name: name})
+}
+
+func TestDatabase_ReadWriteSplitting(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "primary.db")
+	replica := filepath.Join(dir, "replica.db")
+	seed(t, primary, "primary")
+	seed(t, replica, "replica")
+
+	d := NewDatabase(&Config{
+		Driver:      "sqlite",
+		Database:    primary,
+		MaxOpenConn: 1,
+		Replicas:    []ReplicaConfig{{Database: replica}},
+	})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	defer d.Close()
+	db := d.DB
+
+	var item testItem
+	db.First(&item)
+	if item.Name != "replica" {
+		t.Errorf("expected read from replica, got %q", item.Name)
+	}
+
+	var names []string
+	db.Raw("SELECT name FROM test_items").Scan(&names)
+	if len(names) != 1 || names[0] != "replica" {
+		t.Errorf("expected raw select from replica, got %v", names)
+	}
+
+	// 写入走主库
+	db.Create(&testItem{Name: "written"})
+	var count int64
+	UsePrimary(db).Model(&testItem{}).Count(&count)
+	if count != 2 {
+		t.Errorf("expected 2 rows on primary, got %d", count)
+	}
+	db.Model(&testItem{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 row on replica, got %d", count)
+	}
+
+	// 事务内的读取走主库
+	db.Transaction(func(tx *gorm.DB) error {
+		var inTx testItem
+		tx.First(&inTx)
+		if inTx.Name != "primary" {
+			t.Errorf("expected read from primary inside transaction, got %q", inTx.Name)
+		}
+		return nil
+	})
+}
+
+func TestDatabase_TablePrefix(t *testing.T) {
+	defer func() { tablePrefix = "" }()
+
+	d := NewDatabase(&Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "nested", "app.db"), Prefix: "cms_"})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	defer d.Close()
+
+	if err := d.DB.AutoMigrate(&testItem{}, &testTabler{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	for _, table := range []string{"cms_test_items", "cms_tablers"} {
+		if !d.DB.Migrator().HasTable(table) {
+			t.Errorf("expected table %s", table)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("DB_CONNECTION", "mysql")
+	t.Setenv("DB_PREFIX", "env_")
+	t.Setenv("DB_READ_HOSTS", "10.0.0.2, 10.0.0.3:3307")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "60")
+
+	dbConfig := LoadConfig(nil)
+	if dbConfig.Driver != "mysql" || dbConfig.Port != "3306" || dbConfig.Prefix != "env_" || dbConfig.MaxLifetime != time.Minute {
+		t.Errorf("unexpected env config: %+v", dbConfig)
+	}
+	if len(dbConfig.Replicas) != 2 || dbConfig.Replicas[1].Host != "10.0.0.3" || dbConfig.Replicas[1].Port != "3307" {
+		t.Errorf("unexpected replicas: %+v", dbConfig.Replicas)
+	}
+
+	// 配置文件优先于环境变量
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "database.json"), []byte(`{
+		"driver": "postgres", "port": 6432, "max_open_conns": 20,
+		"replicas": [{"host": "replica-1"}]
+	}`), 0644)
+	cfg := config.NewConfig([]string{dir})
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	dbConfig = LoadConfig(cfg)
+	if dbConfig.Driver != "postgres" || dbConfig.Port != "6432" || dbConfig.MaxOpenConn != 20 || dbConfig.Prefix != "env_" {
+		t.Errorf("unexpected file config: %+v", dbConfig)
+	}
+	if len(dbConfig.Replicas) != 1 || dbConfig.Replicas[0].Host != "replica-1" {
+		t.Errorf("unexpected file replicas: %+v", dbConfig.Replicas)
+	}
+}
+
+func TestUsePrimary_Reusable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	seed(t, path, "primary")
+
+	d := NewDatabase(&Config{Driver: "sqlite", Database: path})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	defer d.Close()
+	d.DB.AutoMigrate(&testTabler{})
+
+	// 同一个实例上的多次查询互不影响
+	db := UsePrimary(d.DB)
+	var count int64
+	db.Model(&testItem{}).Count(&count)
+	var tablers []testTabler
+	if err := db.Find(&tablers).Error; err != nil || len(tablers) != 0 {
+		t.Errorf("expected empty tablers, got %v (%v)", tablers, err)
+	}
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// Factory 模型工厂，按定义生成模型并可写入数据库
+// 工厂是不可变的，Count、State 等方法返回新的工厂
+type Factory[T any] struct {
+	faker      *Faker
+	definition func(fake *Faker) T
+	states     []func(model *T)
+	before     []func(tx *gorm.DB, model *T) error
+	after      []func(tx *gorm.DB, model *T) error
+	count      int
+}
+
+// NewFactory 创建模型工厂
+func NewFactory[T any](faker *Faker, definition func(fake *Faker) T) *Factory[T] {
+	return &Factory[T]{faker: faker, definition: definition, count: 1}
+}
+
+// clone 复制工厂
+func (f *Factory[T]) clone() *Factory[T] {
+	c := *f
+	c.states = append([]func(*T){}, f.states...)
+	c.before = append([]func(*gorm.DB, *T) error{}, f.before...)
+	c.after = append([]func(*gorm.DB, *T) error{}, f.after...)
+	return &c
+}
+
+// Count 设置 MakeMany、CreateMany 生成的数量
+func (f *Factory[T]) Count(n int) *Factory[T] {
+	c := f.clone()
+	c.count = n
+	return c
+}
+
+// State 在定义生成的模型上追加修改，按添加顺序执行
+func (f *Factory[T]) State(state func(model *T)) *Factory[T] {
+	c := f.clone()
+	c.states = append(c.states, state)
+	return c
+}
+
+// BeforeCreating 写入数据库前执行，用于创建模型依赖的关联记录
+func (f *Factory[T]) BeforeCreating(fn func(tx *gorm.DB, model *T) error) *Factory[T] {
+	c := f.clone()
+	c.before = append(c.before, fn)
+	return c
+}
+
+// AfterCreating 写入数据库后执行，用于创建依赖该模型的记录
+func (f *Factory[T]) AfterCreating(fn func(tx *gorm.DB, model *T) error) *Factory[T] {
+	c := f.clone()
+	c.after = append(c.after, fn)
+	return c
+}
+
+// Faker 返回工厂使用的数据生成器
+func (f *Factory[T]) Faker() *Faker {
+	return f.faker
+}
+
+// Make 生成一个模型，不写入数据库
+func (f *Factory[T]) Make() T {
+	model := f.definition(f.faker)
+	for _, state := range f.states {
+		state(&model)
+	}
+	return model
+}
+
+// MakeMany 生成 Count 个模型，不写入数据库
+func (f *Factory[T]) MakeMany() []T {
+	models := make([]T, f.count)
+	for i := range models {
+		models[i] = f.Make()
+	}
+	return models
+}
+
+// Create 生成一个模型并写入数据库
+func (f *Factory[T]) Create(db *gorm.DB) (T, error) {
+	model := f.Make()
+	for _, fn := range f.before {
+		if err := fn(db, &model); err != nil {
+			return model, err
+		}
+	}
+	if err := db.Create(&model).Error; err != nil {
+		return model, err
+	}
+	for _, fn := range f.after {
+		if err := fn(db, &model); err != nil {
+			return model, err
+		}
+	}
+	return model, nil
+}
+
+// CreateMany 生成 Count 个模型并写入数据库
+func (f *Factory[T]) CreateMany(db *gorm.DB) ([]T, error) {
+	models := make([]T, 0, f.count)
+	for i := 0; i < f.count; i++ {
+		model, err := f.Create(db)
+		if err != nil {
+			return models, err
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
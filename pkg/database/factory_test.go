@@ -0,0 +1,74 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func newItemFactory(seed int64) *Factory[testItem] {
+	return NewFactory(NewFaker(seed), func(fake *Faker) testItem {
+		return testItem{Name: fake.Slug(2)}
+	})
+}
+
+func TestFactory_Make(t *testing.T) {
+	items := newItemFactory(42).Count(3).MakeMany()
+	again := newItemFactory(42).Count(3).MakeMany()
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+	for i := range items {
+		if items[i].Name != again[i].Name {
+			t.Errorf("same seed should generate same data: %q != %q", items[i].Name, again[i].Name)
+		}
+	}
+	if items[0].Name == items[1].Name {
+		t.Errorf("slugs should be unique: %q", items[0].Name)
+	}
+
+	base := newItemFactory(1)
+	named := base.State(func(item *testItem) { item.Name = "fixed" })
+	if named.Make().Name != "fixed" || base.Make().Name == "fixed" {
+		t.Error("State should not modify the original factory")
+	}
+}
+
+func TestFactory_Create(t *testing.T) {
+	d := NewDatabase(&Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "factory.db")})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	defer d.Close()
+	d.DB.AutoMigrate(&testItem{}, &testTabler{})
+
+	var order []string
+	factory := newItemFactory(1).
+		BeforeCreating(func(tx *gorm.DB, item *testItem) error {
+			order = append(order, "before")
+			if item.ID != 0 {
+				t.Error("before hook should run before insert")
+			}
+			return tx.Create(&testTabler{}).Error
+		}).
+		AfterCreating(func(tx *gorm.DB, item *testItem) error {
+			order = append(order, "after")
+			if item.ID == 0 {
+				t.Error("after hook should see the inserted id")
+			}
+			return nil
+		})
+
+	items, err := factory.Count(2).CreateMany(d.DB)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("CreateMany = %v, %v", items, err)
+	}
+
+	var items64, tablers int64
+	d.DB.Model(&testItem{}).Count(&items64)
+	d.DB.Model(&testTabler{}).Count(&tablers)
+	if items64 != 2 || tablers != 2 || len(order) != 4 {
+		t.Errorf("unexpected counts: items=%d tablers=%d hooks=%v", items64, tablers, order)
+	}
+}
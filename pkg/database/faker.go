@@ -0,0 +1,191 @@
+package database
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	fakeFirstNames = []string{"James", "Mary", "Robert", "Linda", "Michael", "Emma", "David", "Olivia", "Daniel", "Sophia", "Wei", "Fang", "Lei", "Na", "Jun", "Yan", "Hao", "Jing", "Ming", "Xiu"}
+	fakeLastNames  = []string{"Smith", "Johnson", "Brown", "Taylor", "Miller", "Wilson", "Moore", "Clark", "Lewis", "Walker", "Wang", "Li", "Zhang", "Liu", "Chen", "Yang", "Zhao", "Huang", "Zhou", "Wu"}
+	fakeWords      = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor
+		incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation ullamco laboris
+		nisi aliquip ex ea commodo consequat duis aute irure in reprehenderit voluptate velit esse cillum fugiat
+		nulla pariatur excepteur sint occaecat cupidatat non proident sunt culpa qui officia deserunt mollit anim
+		id est laborum`)
+	fakeDomains = []string{"example.com", "example.org", "example.net"}
+)
+
+// Faker 生成测试和种子数据，同一个种子生成的数据序列相同
+type Faker struct {
+	mu     sync.Mutex
+	rand   *rand.Rand
+	unique map[string]bool
+}
+
+// NewFaker 创建数据生成器，seed 为0时使用当前时间
+func NewFaker(seed int64) *Faker {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Faker{
+		rand:   rand.New(rand.NewSource(seed)),
+		unique: make(map[string]bool),
+	}
+}
+
+// IntBetween 返回 [min, max] 之间的整数
+func (f *Faker) IntBetween(min, max int) int {
+	if max <= min {
+		return min
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return min + f.rand.Intn(max-min+1)
+}
+
+// Bool 按百分比概率返回true
+func (f *Faker) Bool(percent int) bool {
+	return f.IntBetween(1, 100) <= percent
+}
+
+// Pick 从候选值中随机选择一个
+func (f *Faker) Pick(values ...string) string {
+	return values[f.IntBetween(0, len(values)-1)]
+}
+
+// Word 返回一个单词
+func (f *Faker) Word() string {
+	return f.Pick(fakeWords...)
+}
+
+// Words 返回 n 个单词
+func (f *Faker) Words(n int) []string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = f.Word()
+	}
+	return words
+}
+
+// Sentence 返回包含 n 个单词的句子
+func (f *Faker) Sentence(n int) string {
+	sentence := strings.Join(f.Words(n), " ")
+	return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+}
+
+// Title 返回标题格式的 n 个单词
+func (f *Faker) Title(n int) string {
+	words := f.Words(n)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// Paragraph 返回包含 n 个句子的段落
+func (f *Faker) Paragraph(n int) string {
+	sentences := make([]string, n)
+	for i := range sentences {
+		sentences[i] = f.Sentence(f.IntBetween(6, 14))
+	}
+	return strings.Join(sentences, " ")
+}
+
+// Paragraphs 返回 n 个段落，以空行分隔
+func (f *Faker) Paragraphs(n int) string {
+	paragraphs := make([]string, n)
+	for i := range paragraphs {
+		paragraphs[i] = f.Paragraph(f.IntBetween(3, 6))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// FirstName 返回名
+func (f *Faker) FirstName() string {
+	return f.Pick(fakeFirstNames...)
+}
+
+// LastName 返回姓
+func (f *Faker) LastName() string {
+	return f.Pick(fakeLastNames...)
+}
+
+// Username 返回唯一的用户名
+func (f *Faker) Username() string {
+	return f.Unique("username", func() string {
+		return fmt.Sprintf("%s.%s%d", strings.ToLower(f.FirstName()), strings.ToLower(f.LastName()), f.IntBetween(1, 9999))
+	})
+}
+
+// Email 返回唯一的邮箱地址
+func (f *Faker) Email() string {
+	return f.Unique("email", func() string {
+		return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(f.FirstName()), strings.ToLower(f.LastName()), f.IntBetween(1, 99999), f.Pick(fakeDomains...))
+	})
+}
+
+// Slug 返回唯一的 URL 别名
+func (f *Faker) Slug(words int) string {
+	return f.Unique("slug", func() string {
+		return fmt.Sprintf("%s-%d", strings.Join(f.Words(words), "-"), f.IntBetween(1, 99999))
+	})
+}
+
+// URL 返回网址
+func (f *Faker) URL() string {
+	return fmt.Sprintf("https://%s/%s", f.Pick(fakeDomains...), f.Word())
+}
+
+// ImageURL 返回图片地址
+func (f *Faker) ImageURL(width, height int) string {
+	return fmt.Sprintf("https://picsum.photos/seed/%d/%d/%d", f.IntBetween(1, 100000), width, height)
+}
+
+// IPv4 返回IPv4地址
+func (f *Faker) IPv4() string {
+	return fmt.Sprintf("%d.%d.%d.%d", f.IntBetween(1, 223), f.IntBetween(0, 255), f.IntBetween(0, 255), f.IntBetween(1, 254))
+}
+
+// Hex 返回 n 个字符的十六进制字符串
+func (f *Faker) Hex(n int) string {
+	const digits = "0123456789abcdef"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = digits[f.IntBetween(0, 15)]
+	}
+	return string(b)
+}
+
+// TimeBetween 返回 [from, to] 之间的时间
+func (f *Faker) TimeBetween(from, to time.Time) time.Time {
+	span := to.Sub(from)
+	if span <= 0 {
+		return from
+	}
+	f.mu.Lock()
+	offset := time.Duration(f.rand.Int63n(int64(span)))
+	f.mu.Unlock()
+	return from.Add(offset)
+}
+
+// Unique 在同一个 Faker 内为 key 生成不重复的值
+func (f *Faker) Unique(key string, generate func() string) string {
+	for i := 0; ; i++ {
+		value := generate()
+		f.mu.Lock()
+		seen := f.unique[key+":"+value]
+		if !seen || i >= 100 {
+			if seen {
+				value = fmt.Sprintf("%d-%s", len(f.unique), value)
+			}
+			f.unique[key+":"+value] = true
+			f.mu.Unlock()
+			return value
+		}
+		f.mu.Unlock()
+	}
+}
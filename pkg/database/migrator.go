@@ -0,0 +1,240 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 数据库迁移，Down 需要撤销 Up 的全部变更
+type Migration interface {
+	Up(tx *gorm.DB) error
+	Down(tx *gorm.DB) error
+}
+
+// MigrationRecord 已执行的迁移记录，同一次 migrate 执行的迁移属于同一批次
+type MigrationRecord struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Migration string    `gorm:"size:255;not null;uniqueIndex" json:"migration"`
+	Batch     int       `gorm:"not null;index" json:"batch"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (MigrationRecord) TableName() string {
+	return TableName("migrations")
+}
+
+// MigrationStatus 迁移执行状态
+type MigrationStatus struct {
+	Name  string     `json:"name"`
+	Ran   bool       `json:"ran"`
+	Batch int        `json:"batch,omitempty"`
+	RanAt *time.Time `json:"ran_at,omitempty"`
+}
+
+// Migrator 按名称顺序执行迁移，迁移名称以时间戳开头，如 20240101000000_create_posts_table
+type Migrator struct {
+	db         *gorm.DB
+	migrations map[string]Migration
+}
+
+// NewMigrator 创建迁移执行器
+func NewMigrator(db *gorm.DB) *Migrator {
+	return &Migrator{
+		// 迁移记录必须读写主库
+		db:         UsePrimary(db),
+		migrations: make(map[string]Migration),
+	}
+}
+
+// Register 注册迁移，名称重复时panic
+func (m *Migrator) Register(name string, migration Migration) *Migrator {
+	if _, exists := m.migrations[name]; exists {
+		panic(fmt.Sprintf("database: migration %s registered twice", name))
+	}
+	m.migrations[name] = migration
+	return m
+}
+
+// Names 按执行顺序返回已注册的迁移名称
+func (m *Migrator) Names() []string {
+	names := make([]string, 0, len(m.migrations))
+	for name := range m.migrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Migrate 执行所有未执行的迁移，返回本次执行的迁移名称
+func (m *Migrator) Migrate() ([]string, error) {
+	records, err := m.records()
+	if err != nil {
+		return nil, err
+	}
+
+	ran := make(map[string]bool, len(records))
+	batch := 0
+	for _, record := range records {
+		ran[record.Migration] = true
+		if record.Batch > batch {
+			batch = record.Batch
+		}
+	}
+	batch++
+
+	var migrated []string
+	for _, name := range m.Names() {
+		if ran[name] {
+			continue
+		}
+		migration := m.migrations[name]
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&MigrationRecord{Migration: name, Batch: batch}).Error
+		})
+		if err != nil {
+			return migrated, fmt.Errorf("migration %s failed: %w", name, err)
+		}
+		migrated = append(migrated, name)
+	}
+	return migrated, nil
+}
+
+// Rollback 回滚迁移，steps 大于0时回滚最近的 steps 个迁移，否则回滚最近一个批次
+func (m *Migrator) Rollback(steps int) ([]string, error) {
+	records, err := m.records()
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	// 按批次和执行顺序倒序
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Batch != records[j].Batch {
+			return records[i].Batch > records[j].Batch
+		}
+		return records[i].ID > records[j].ID
+	})
+
+	var targets []MigrationRecord
+	if steps > 0 {
+		if steps > len(records) {
+			steps = len(records)
+		}
+		targets = records[:steps]
+	} else {
+		for _, record := range records {
+			if record.Batch != records[0].Batch {
+				break
+			}
+			targets = append(targets, record)
+		}
+	}
+	return m.rollback(targets)
+}
+
+// Reset 回滚所有已执行的迁移
+func (m *Migrator) Reset() ([]string, error) {
+	records, err := m.records()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].ID > records[j].ID
+	})
+	return m.rollback(records)
+}
+
+// Fresh 删除所有表后重新执行全部迁移，配置了表前缀时只删除带前缀的表
+func (m *Migrator) Fresh() ([]string, error) {
+	tables, err := m.db.Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		// sqlite_sequence 等内部表由 SQLite 维护
+		if strings.HasPrefix(table, "sqlite_") {
+			continue
+		}
+		if tablePrefix != "" && !strings.HasPrefix(table, tablePrefix) {
+			continue
+		}
+		if err := m.db.Migrator().DropTable(table); err != nil {
+			return nil, fmt.Errorf("failed to drop table %s: %w", table, err)
+		}
+	}
+	return m.Migrate()
+}
+
+// Status 返回所有迁移的执行状态，包含已执行但未注册的迁移
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	records, err := m.records()
+	if err != nil {
+		return nil, err
+	}
+
+	ran := make(map[string]MigrationRecord, len(records))
+	for _, record := range records {
+		ran[record.Migration] = record
+	}
+
+	names := m.Names()
+	for name := range ran {
+		if _, registered := m.migrations[name]; !registered {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	statuses := make([]MigrationStatus, 0, len(names))
+	for _, name := range names {
+		status := MigrationStatus{Name: name}
+		if record, ok := ran[name]; ok {
+			ranAt := record.CreatedAt
+			status.Ran, status.Batch, status.RanAt = true, record.Batch, &ranAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// rollback 依次执行迁移的 Down 并删除记录
+func (m *Migrator) rollback(records []MigrationRecord) ([]string, error) {
+	var rolledBack []string
+	for _, record := range records {
+		migration, ok := m.migrations[record.Migration]
+		if !ok {
+			return rolledBack, fmt.Errorf("migration %s not found", record.Migration)
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&MigrationRecord{}, record.ID).Error
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("rollback %s failed: %w", record.Migration, err)
+		}
+		rolledBack = append(rolledBack, record.Migration)
+	}
+	return rolledBack, nil
+}
+
+// records 读取已执行的迁移记录，迁移表不存在时自动创建
+func (m *Migrator) records() ([]MigrationRecord, error) {
+	if err := m.db.AutoMigrate(&MigrationRecord{}); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	var records []MigrationRecord
+	if err := m.db.Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// tableMigration 创建和删除一张只有 id 列的表
+type tableMigration struct {
+	table string
+	fail  bool
+}
+
+func (m tableMigration) Up(tx *gorm.DB) error {
+	if err := tx.Exec("CREATE TABLE " + TableName(m.table) + " (id INTEGER PRIMARY KEY)").Error; err != nil {
+		return err
+	}
+	if m.fail {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func (m tableMigration) Down(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(TableName(m.table))
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, *gorm.DB) {
+	t.Helper()
+
+	// 使用表前缀，确保迁移记录和数据表都按前缀读写
+	d := NewDatabase(&Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "migrate.db"), Prefix: "t_"})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	t.Cleanup(func() {
+		d.Close()
+		tablePrefix = ""
+	})
+
+	m := NewMigrator(d.DB).
+		Register("20240101000000_create_a", tableMigration{table: "a"}).
+		Register("20240102000000_create_b", tableMigration{table: "b"})
+	return m, d.DB
+}
+
+func TestMigrator_MigrateAndRollback(t *testing.T) {
+	m, db := newTestMigrator(t)
+
+	migrated, err := m.Migrate()
+	if err != nil || len(migrated) != 2 {
+		t.Fatalf("Migrate = %v, %v", migrated, err)
+	}
+	if migrated, _ := m.Migrate(); len(migrated) != 0 {
+		t.Errorf("expected nothing to migrate, got %v", migrated)
+	}
+
+	// 第二批次
+	m.Register("20240103000000_create_c", tableMigration{table: "c"})
+	m.Migrate()
+
+	statuses, _ := m.Status()
+	if len(statuses) != 3 || statuses[0].Batch != 1 || statuses[2].Batch != 2 || !statuses[2].Ran {
+		t.Fatalf("unexpected status: %+v", statuses)
+	}
+
+	// 默认回滚最近一个批次
+	rolledBack, err := m.Rollback(0)
+	if err != nil || strings.Join(rolledBack, ",") != "20240103000000_create_c" {
+		t.Fatalf("Rollback = %v, %v", rolledBack, err)
+	}
+	if db.Migrator().HasTable("t_c") {
+		t.Error("table c should be dropped")
+	}
+
+	// 按步数回滚跨批次
+	m.Migrate()
+	rolledBack, _ = m.Rollback(2)
+	if strings.Join(rolledBack, ",") != "20240103000000_create_c,20240102000000_create_b" {
+		t.Errorf("unexpected step rollback: %v", rolledBack)
+	}
+
+	rolledBack, _ = m.Reset()
+	if strings.Join(rolledBack, ",") != "20240101000000_create_a" || db.Migrator().HasTable("t_a") {
+		t.Errorf("unexpected reset: %v", rolledBack)
+	}
+	statuses, _ = m.Status()
+	for _, status := range statuses {
+		if status.Ran {
+			t.Errorf("%s should not be ran after reset", status.Name)
+		}
+	}
+}
+
+func TestMigrator_FailedMigration(t *testing.T) {
+	m, db := newTestMigrator(t)
+	m.Register("20240103000000_broken", tableMigration{table: "broken", fail: true})
+
+	migrated, err := m.Migrate()
+	if err == nil || len(migrated) != 2 {
+		t.Fatalf("expected failure after 2 migrations, got %v, %v", migrated, err)
+	}
+
+	// 失败的迁移在事务中回滚，不留下记录
+	var count int64
+	db.Model(&MigrationRecord{}).Where("migration = ?", "20240103000000_broken").Count(&count)
+	if count != 0 || db.Migrator().HasTable("t_broken") {
+		t.Error("failed migration should not be recorded")
+	}
+}
+
+func TestMigrator_Fresh(t *testing.T) {
+	m, db := newTestMigrator(t)
+	m.Migrate()
+	db.Exec("CREATE TABLE t_stray (id INTEGER)")
+	db.Exec("CREATE TABLE other (id INTEGER)")
+
+	if _, err := m.Fresh(); err != nil {
+		t.Fatalf("Fresh error: %v", err)
+	}
+	if db.Migrator().HasTable("t_stray") || !db.Migrator().HasTable("t_a") {
+		t.Error("fresh should drop all prefixed tables and migrate again")
+	}
+	if !db.Migrator().HasTable("other") {
+		t.Error("fresh should keep tables without the prefix")
+	}
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// usePrimaryKey 强制走主库的会话设置
+const usePrimaryKey = "database:use_primary"
+
+// UsePrimary 强制查询走主库，用于写入后需要立即读到最新数据的场景
+// 返回的实例可以重复使用
+func UsePrimary(db *gorm.DB) *gorm.DB {
+	return db.Set(usePrimaryKey, true).Session(&gorm.Session{})
+}
+
+// resolver 读写分离插件：查询轮询分配到只读副本，写入、事务内的查询和加锁查询走主库
+type resolver struct {
+	replicas []gorm.ConnPool
+	next     uint64
+}
+
+// newResolver 创建读写分离插件
+func newResolver(replicas []*gorm.DB) *resolver {
+	pools := make([]gorm.ConnPool, len(replicas))
+	for i, replica := range replicas {
+		pools[i] = replica.ConnPool
+	}
+	return &resolver{replicas: pools}
+}
+
+// Name 插件名称
+func (r *resolver) Name() string {
+	return "database:resolver"
+}
+
+// Initialize 注册查询回调
+func (r *resolver) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("database:read_replica", r.route); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("database:read_replica", r.route)
+}
+
+// route 为只读查询选择副本
+func (r *resolver) route(db *gorm.DB) {
+	stmt := db.Statement
+
+	// 事务中的查询必须使用事务连接
+	if _, inTransaction := stmt.ConnPool.(gorm.TxCommitter); inTransaction {
+		return
+	}
+	if usePrimary, ok := db.Get(usePrimaryKey); ok && usePrimary == true {
+		return
+	}
+	// SELECT ... FOR UPDATE 等加锁查询走主库
+	if _, locking := stmt.Clauses["FOR"]; locking {
+		return
+	}
+	// Raw 语句只有 SELECT 可以走副本
+	if stmt.SQL.Len() > 0 && !isSelect(stmt.SQL.String()) {
+		return
+	}
+
+	index := atomic.AddUint64(&r.next, 1) % uint64(len(r.replicas))
+	stmt.ConnPool = r.replicas[index]
+}
+
+// isSelect 检查SQL是否为只读查询
+func isSelect(sql string) bool {
+	sql = strings.ToLower(strings.TrimSpace(sql))
+	return strings.HasPrefix(sql, "select") && !strings.Contains(sql, "for update")
+}
@@ -0,0 +1,18 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// Seeder 种子数据，Run 可以重复执行
+type Seeder interface {
+	Run(db *gorm.DB) error
+}
+
+// SeederFunc 将函数适配为 Seeder
+type SeederFunc func(db *gorm.DB) error
+
+// Run 执行种子函数
+func (f SeederFunc) Run(db *gorm.DB) error {
+	return f(db)
+}
@@ -1,5 +1,7 @@
 package event
 
+import "time"
+
 // BaseEvent 基础事件结构
 type BaseEvent struct {
 	Name string
@@ -46,6 +48,44 @@ func NewUserLoggedIn(userID uint, ip string) *UserLoggedIn {
 	}
 }
 
+// LoginFailed 登录失败事件，用户名不存在时 UserID 为0
+type LoginFailed struct {
+	BaseEvent
+	Identifier string
+	UserID     uint
+	IP         string
+	Reason     string
+}
+
+// NewLoginFailed 创建登录失败事件
+func NewLoginFailed(identifier string, userID uint, ip, reason string) *LoginFailed {
+	return &LoginFailed{
+		BaseEvent:  BaseEvent{Name: "user.login_failed"},
+		Identifier: identifier,
+		UserID:     userID,
+		IP:         ip,
+		Reason:     reason,
+	}
+}
+
+// UserLockedOut 账户因连续登录失败被锁定事件
+type UserLockedOut struct {
+	BaseEvent
+	UserID uint
+	IP     string
+	Until  time.Time
+}
+
+// NewUserLockedOut 创建账户锁定事件
+func NewUserLockedOut(userID uint, ip string, until time.Time) *UserLockedOut {
+	return &UserLockedOut{
+		BaseEvent: BaseEvent{Name: "user.locked_out"},
+		UserID:    userID,
+		IP:        ip,
+		Until:     until,
+	}
+}
+
 // PostCreated 文章创建事件
 type PostCreated struct {
 	BaseEvent
@@ -35,6 +35,18 @@ type Application struct {
 	booted     bool
 }
 
+// BootCheck 应用启动时在加载配置后执行的检查，返回错误时拒绝启动
+type BootCheck func(app *Application) error
+
+// bootChecks 已注册的启动检查
+var bootChecks []BootCheck
+
+// RegisterBootCheck 注册启动检查，如生产环境禁止使用默认密钥
+// 应在 init 中调用，检查按注册顺序执行
+func RegisterBootCheck(check BootCheck) {
+	bootChecks = append(bootChecks, check)
+}
+
 // NewApplication 创建一个新的应用实例
 func NewApplication() *Application {
 	app := &Application{
@@ -58,6 +70,11 @@ func (app *Application) Boot() *Application {
 	// 加载配置
 	app.loadConfig()
 
+	// 启动检查，环境以 app.Env 为准
+	if err := app.runBootChecks(); err != nil {
+		hlog.Fatalf("Boot check failed: %v", err)
+	}
+
 	// 初始化日志
 	app.initLogger()
 
@@ -79,6 +96,10 @@ func (app *Application) Boot() *Application {
 
 // loadConfig 加载配置
 func (app *Application) loadConfig() {
+	// .env 中的变量不覆盖已设置的环境变量
+	config.LoadEnv(".env")
+	app.Env = config.GetEnv("APP_ENV", app.Env)
+
 	app.Config = config.NewConfig([]string{app.ConfigPath})
 	if err := app.Config.Load(); err != nil {
 		hlog.Fatalf("Failed to load config: %v", err)
@@ -96,6 +117,21 @@ func (app *Application) loadConfig() {
 	app.Debug = app.Config.GetBool("app.debug", app.Debug)
 }
 
+// runBootChecks 依次执行启动检查，返回第一个错误
+func (app *Application) runBootChecks() error {
+	for _, check := range bootChecks {
+		if err := check(app); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsProduction 是否为生产环境
+func (app *Application) IsProduction() bool {
+	return app.Env == "production"
+}
+
 // initLogger 初始化日志
 func (app *Application) initLogger() {
 	logConfig := &log.Config{
@@ -131,25 +167,25 @@ func (app *Application) initRouter() {
 		app.initServer()
 	}
 	app.Router = NewRouter(app.Server)
+
+	// 站点根地址，用于生成绝对链接
+	port := app.Config.GetInt("server.port", 8888)
+	app.Router.SetBaseURL(app.Config.GetString("app.url", config.GetEnv("APP_URL", fmt.Sprintf("http://localhost:%d", port))))
 }
 
-// initDatabase 初始化数据库
+// initDatabase 初始化数据库，驱动、连接池、表名前缀和只读副本来自 config/database.json 或 DB_* 环境变量
 func (app *Application) initDatabase() {
-	// 强制使用SQLite
-	dbConfig := &database.Config{
-		Driver:   "sqlite",
-		Database: "storage/database/data.db",
-		Debug:    app.Debug,
-	}
-
-	// 确保数据库目录存在
-	if err := os.MkdirAll("storage/database", 0755); err != nil {
-		hlog.Fatalf("Failed to create database directory: %v", err)
-	}
+	dbConfig := database.LoadConfig(app.Config)
+	dbConfig.Debug = dbConfig.Debug || app.Debug
 
 	app.DB = database.NewDatabase(dbConfig)
 	if err := app.DB.Connect(); err != nil {
-		hlog.Fatalf("Failed to connect to database: %v", err)
+		hlog.Fatalf("Failed to connect to %s database: %v", dbConfig.Driver, err)
+	}
+	database.SetDB(app.DB.DB)
+
+	if len(dbConfig.Replicas) > 0 {
+		hlog.Infof("Database read/write splitting enabled with %d replicas", len(dbConfig.Replicas))
 	}
 }
 
@@ -243,9 +279,14 @@ func (app *Application) RegisterRoutes(fn func(*Router)) {
 	fn(app.Router)
 }
 
-// RegisterMiddleware 注册全局中间件
+// URL 根据路由名称生成路径
+func (app *Application) URL(name string, params ...interface{}) (string, error) {
+	return app.Router.URL(name, params...)
+}
+
+// RegisterMiddleware 注册全局中间件，只对之后注册的路由生效，应在注册路由之前调用
 func (app *Application) RegisterMiddleware(handlers ...app.HandlerFunc) {
-	app.Server.Use(handlers...)
+	app.Router.useNative(handlers...)
 }
 
 // Static 注册静态文件目录
@@ -0,0 +1,41 @@
+package framework
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 启动检查使用配置文件确定的运行环境，配置文件中的 env 优先于 APP_ENV
+func TestApplication_BootChecksUseConfiguredEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.json"), []byte(`{"env": "production"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_ENV", "development")
+
+	saved := bootChecks
+	t.Cleanup(func() { bootChecks = saved })
+	bootChecks = nil
+
+	var env string
+	insecure := errors.New("insecure secret")
+	RegisterBootCheck(func(app *Application) error {
+		env = app.Env
+		return insecure
+	})
+	RegisterBootCheck(func(app *Application) error {
+		t.Error("checks after a failure should not run")
+		return nil
+	})
+
+	app := NewApplication().SetConfigPath(dir)
+	app.loadConfig()
+	if err := app.runBootChecks(); !errors.Is(err, insecure) {
+		t.Fatalf("runBootChecks = %v, want %v", err, insecure)
+	}
+	if env != "production" || !app.IsProduction() {
+		t.Errorf("boot check saw env %q, want production", env)
+	}
+}
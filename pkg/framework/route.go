@@ -3,24 +3,66 @@ package framework
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"runtime"
 	"sort"
+	"strings"
+	"sync"
+	"unsafe"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/route"
 )
 
 // RouteInfo 存储路由信息
 type RouteInfo struct {
-	Method  string
-	Path    string
-	Handler string
+	Name       string
+	Method     string
+	Path       string
+	Handler    string
+	Middleware []string // 按执行顺序排列的中间件名称（全局、路由组、单个路由）
 }
 
-// Router 路由管理器
+// routeTable 所有路由组共享的路由表
+type routeTable struct {
+	mu      sync.RWMutex
+	routes  []RouteInfo
+	names   map[string]string // 路由名称 -> 路径模板
+	baseURL string
+}
+
+// Route 已注册的路由，用于设置路由名称
+type Route struct {
+	table   *routeTable
+	path    string
+	indexes []int // 在路由表中的位置，Any 路由对应多条记录
+}
+
+// Name 设置路由名称，名称在所有路由组中唯一，用于 Router.URL 生成链接
+func (rt *Route) Name(name string) *Route {
+	rt.table.mu.Lock()
+	defer rt.table.mu.Unlock()
+
+	if path, exists := rt.table.names[name]; exists && path != rt.path {
+		panic(fmt.Sprintf("framework: route name %q is already used by %s", name, path))
+	}
+	rt.table.names[name] = rt.path
+	for _, i := range rt.indexes {
+		rt.table.routes[i].Name = name
+	}
+	return rt
+}
+
+// Router 路由管理器，每个路由组拥有独立的中间件链，注册的路由汇总到同一张路由表
 type Router struct {
-	server *server.Hertz
-	prefix string
-	routes []RouteInfo // 存储所有注册的路由
+	server     *server.Hertz
+	group      *route.RouterGroup
+	prefix     string
+	middleware []string // 当前路由组生效的中间件名称
+	table      *routeTable
 }
 
 // HandlerFunc 路由处理函数类型
@@ -30,234 +72,367 @@ type HandlerFunc func(context.Context, *RequestContext)
 func NewRouter(server *server.Hertz) *Router {
 	return &Router{
 		server: server,
+		group:  &server.Engine.RouterGroup,
 		prefix: "",
-		routes: []RouteInfo{},
+		table:  &routeTable{names: make(map[string]string)},
 	}
 }
 
 // PrintRoutes 打印所有已注册的路由
 func (r *Router) PrintRoutes() {
-	if len(r.routes) == 0 {
+	routes := r.GetRoutes()
+	if len(routes) == 0 {
 		fmt.Println("No routes registered.")
 		return
 	}
 
 	// 按路径排序
-	sort.Slice(r.routes, func(i, j int) bool {
-		return r.routes[i].Path < r.routes[j].Path
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].Path < routes[j].Path
 	})
 
 	// 打印表头
-	fmt.Println("\n+--------+------------------------------------+------------------------------------+")
-	fmt.Println("| METHOD | PATH                               | HANDLER                            |")
-	fmt.Println("+--------+------------------------------------+------------------------------------+")
+	fmt.Println("\n+---------+------------------------------------+----------------------+------------------------------------+------------------------------------+")
+	fmt.Println("| METHOD  | PATH                               | NAME                 | HANDLER                            | MIDDLEWARE                         |")
+	fmt.Println("+---------+------------------------------------+----------------------+------------------------------------+------------------------------------+")
 
 	// 打印路由
-	for _, route := range r.routes {
-		method := fmt.Sprintf("%-6s", route.Method)
-		path := route.Path
-		if len(path) > 34 {
-			path = path[:31] + "..."
-		} else {
-			path = fmt.Sprintf("%-34s", path)
-		}
+	for _, route := range routes {
+		fmt.Printf("| %-7s | %s | %s | %s | %s |\n",
+			route.Method,
+			fitColumn(route.Path, 34),
+			fitColumn(route.Name, 20),
+			fitColumn(route.Handler, 34),
+			fitColumn(strings.Join(route.Middleware, ","), 34))
+	}
 
-		handler := route.Handler
-		if len(handler) > 34 {
-			handler = handler[:31] + "..."
-		} else {
-			handler = fmt.Sprintf("%-34s", handler)
-		}
+	fmt.Println("+---------+------------------------------------+----------------------+------------------------------------+------------------------------------+")
+	fmt.Printf("\nTotal routes: %d\n\n", len(routes))
+}
 
-		fmt.Printf("| %s | %s | %s |\n", method, path, handler)
+// fitColumn 将文本截断或填充到指定宽度
+func fitColumn(text string, width int) string {
+	if len(text) > width {
+		return text[:width-3] + "..."
 	}
-
-	fmt.Println("+--------+------------------------------------+------------------------------------+")
-	fmt.Printf("\nTotal routes: %d\n\n", len(r.routes))
+	return fmt.Sprintf("%-*s", width, text)
 }
 
-// GetRoutes 获取所有已注册的路由
+// GetRoutes 获取所有已注册的路由（包括各路由组中的路由），按注册顺序返回
 func (r *Router) GetRoutes() []RouteInfo {
-	return r.routes
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+
+	routes := make([]RouteInfo, len(r.table.routes))
+	copy(routes, r.table.routes)
+	return routes
 }
 
-// Group 创建一个路由组
+// Prefix 获取路由组的路径前缀
+func (r *Router) Prefix() string {
+	return r.prefix
+}
+
+// Group 创建一个路由组，handlers 作为该组的中间件，只对组内（包括嵌套组）的路由生效
 func (r *Router) Group(prefix string, handlers ...HandlerFunc) *Router {
-	// 将我们的 HandlerFunc 转换为 Hertz 的 HandlerFunc
-	h := make([]app.HandlerFunc, len(handlers))
-	for i, handler := range handlers {
-		h[i] = func(ctx context.Context, c *app.RequestContext) {
-			handler(ctx, NewRequestContext(c))
-		}
-	}
+	middleware := make([]string, len(r.middleware), len(r.middleware)+len(handlers))
+	copy(middleware, r.middleware)
 
-	// 创建路由组
-	r.server.Group(r.prefix+prefix, h...)
 	return &Router{
-		server: r.server,
-		prefix: r.prefix + prefix,
+		server:     r.server,
+		group:      r.group.Group(prefix, wrapHandlers(handlers)...),
+		prefix:     r.prefix + prefix,
+		middleware: append(middleware, handlerNames(handlers)...),
+		table:      r.table,
 	}
 }
 
-// GET 注册GET路由
-func (r *Router) GET(path string, handler HandlerFunc) {
-	r.server.GET(r.prefix+path, func(ctx context.Context, c *app.RequestContext) {
-		handler(ctx, NewRequestContext(c))
-	})
+// Use 为当前路由组添加中间件，只对之后注册的路由生效；在根路由上调用时为全局中间件
+func (r *Router) Use(handlers ...HandlerFunc) {
+	r.group.Use(wrapHandlers(handlers)...)
+	r.middleware = append(r.middleware, handlerNames(handlers)...)
+}
 
-	// 收集路由信息
-	handlerName := fmt.Sprintf("%T", handler)
-	r.routes = append(r.routes, RouteInfo{
-		Method:  "GET",
-		Path:    r.prefix + path,
-		Handler: handlerName,
-	})
+// useNative 添加 Hertz 原生中间件
+func (r *Router) useNative(handlers ...app.HandlerFunc) {
+	r.group.Use(handlers...)
+	for _, handler := range handlers {
+		r.middleware = append(r.middleware, funcName(handler))
+	}
 }
 
-// POST 注册POST路由
-func (r *Router) POST(path string, handler HandlerFunc) {
-	r.server.POST(r.prefix+path, func(ctx context.Context, c *app.RequestContext) {
-		handler(ctx, NewRequestContext(c))
-	})
+// Handle 注册路由，handlers 的最后一个为处理函数，之前的为该路由单独使用的中间件
+func (r *Router) Handle(method, path string, handlers ...HandlerFunc) *Route {
+	if len(handlers) == 0 {
+		panic(fmt.Sprintf("framework: route %s %s has no handler", method, r.prefix+path))
+	}
 
-	// 收集路由信息
-	handlerName := fmt.Sprintf("%T", handler)
-	r.routes = append(r.routes, RouteInfo{
-		Method:  "POST",
-		Path:    r.prefix + path,
-		Handler: handlerName,
-	})
+	if method == "ANY" {
+		r.group.Any(path, wrapHandlers(handlers)...)
+	} else {
+		r.group.Handle(method, path, wrapHandlers(handlers)...)
+	}
+
+	methods := []string{method}
+	if method == "ANY" {
+		methods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS", "CONNECT", "TRACE"}
+	}
+	return r.addRoute(methods, path, handlerName(handlers[len(handlers)-1]), handlerNames(handlers[:len(handlers)-1]))
 }
 
-// PUT 注册PUT路由
-func (r *Router) PUT(path string, handler HandlerFunc) {
-	r.server.PUT(r.prefix+path, func(ctx context.Context, c *app.RequestContext) {
-		handler(ctx, NewRequestContext(c))
-	})
+// addRoute 将路由写入路由表
+func (r *Router) addRoute(methods []string, path, handler string, routeMiddleware []string) *Route {
+	middleware := make([]string, 0, len(r.middleware)+len(routeMiddleware))
+	middleware = append(middleware, r.middleware...)
+	middleware = append(middleware, routeMiddleware...)
 
-	// 收集路由信息
-	handlerName := fmt.Sprintf("%T", handler)
-	r.routes = append(r.routes, RouteInfo{
-		Method:  "PUT",
-		Path:    r.prefix + path,
-		Handler: handlerName,
-	})
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	route := &Route{table: r.table, path: r.prefix + path}
+	for _, method := range methods {
+		route.indexes = append(route.indexes, len(r.table.routes))
+		r.table.routes = append(r.table.routes, RouteInfo{
+			Method:     method,
+			Path:       route.path,
+			Handler:    handler,
+			Middleware: middleware,
+		})
+	}
+	return route
 }
 
-// DELETE 注册DELETE路由
-func (r *Router) DELETE(path string, handler HandlerFunc) {
-	r.server.DELETE(r.prefix+path, func(ctx context.Context, c *app.RequestContext) {
-		handler(ctx, NewRequestContext(c))
-	})
+// GET 注册GET路由
+func (r *Router) GET(path string, handlers ...HandlerFunc) *Route {
+	return r.Handle("GET", path, handlers...)
+}
 
-	// 收集路由信息
-	handlerName := fmt.Sprintf("%T", handler)
-	r.routes = append(r.routes, RouteInfo{
-		Method:  "DELETE",
-		Path:    r.prefix + path,
-		Handler: handlerName,
-	})
+// POST 注册POST路由
+func (r *Router) POST(path string, handlers ...HandlerFunc) *Route {
+	return r.Handle("POST", path, handlers...)
 }
 
-// PATCH 注册PATCH路由
-func (r *Router) PATCH(path string, handler HandlerFunc) {
-	r.server.PATCH(r.prefix+path, func(ctx context.Context, c *app.RequestContext) {
-		handler(ctx, NewRequestContext(c))
-	})
+// PUT 注册PUT路由
+func (r *Router) PUT(path string, handlers ...HandlerFunc) *Route {
+	return r.Handle("PUT", path, handlers...)
+}
 
-	// 收集路由信息
-	handlerName := fmt.Sprintf("%T", handler)
-	r.routes = append(r.routes, RouteInfo{
-		Method:  "PATCH",
-		Path:    r.prefix + path,
-		Handler: handlerName,
-	})
+// DELETE 注册DELETE路由
+func (r *Router) DELETE(path string, handlers ...HandlerFunc) *Route {
+	return r.Handle("DELETE", path, handlers...)
 }
 
-// OPTIONS 注册OPTIONS路由
-func (r *Router) OPTIONS(path string, handler HandlerFunc) {
-	r.server.OPTIONS(r.prefix+path, func(ctx context.Context, c *app.RequestContext) {
-		handler(ctx, NewRequestContext(c))
-	})
+// PATCH 注册PATCH路由
+func (r *Router) PATCH(path string, handlers ...HandlerFunc) *Route {
+	return r.Handle("PATCH", path, handlers...)
+}
 
-	// 收集路由信息
-	handlerName := fmt.Sprintf("%T", handler)
-	r.routes = append(r.routes, RouteInfo{
-		Method:  "OPTIONS",
-		Path:    r.prefix + path,
-		Handler: handlerName,
-	})
+// OPTIONS 注册OPTIONS路由
+func (r *Router) OPTIONS(path string, handlers ...HandlerFunc) *Route {
+	return r.Handle("OPTIONS", path, handlers...)
 }
 
 // HEAD 注册HEAD路由
-func (r *Router) HEAD(path string, handler HandlerFunc) {
-	r.server.HEAD(r.prefix+path, func(ctx context.Context, c *app.RequestContext) {
-		handler(ctx, NewRequestContext(c))
-	})
-
-	// 收集路由信息
-	handlerName := fmt.Sprintf("%T", handler)
-	r.routes = append(r.routes, RouteInfo{
-		Method:  "HEAD",
-		Path:    r.prefix + path,
-		Handler: handlerName,
-	})
+func (r *Router) HEAD(path string, handlers ...HandlerFunc) *Route {
+	return r.Handle("HEAD", path, handlers...)
 }
 
 // Any 注册所有HTTP方法的路由
-func (r *Router) Any(path string, handler HandlerFunc) {
-	r.server.Any(r.prefix+path, func(ctx context.Context, c *app.RequestContext) {
-		handler(ctx, NewRequestContext(c))
-	})
-
-	// 收集路由信息
-	handlerName := fmt.Sprintf("%T", handler)
-	methods := []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"}
-	for _, method := range methods {
-		r.routes = append(r.routes, RouteInfo{
-			Method:  method,
-			Path:    r.prefix + path,
-			Handler: handlerName,
-		})
-	}
+func (r *Router) Any(path string, handlers ...HandlerFunc) *Route {
+	return r.Handle("ANY", path, handlers...)
 }
 
 // Static 注册静态文件路由
-func (r *Router) Static(path, root string) {
-	r.server.Static(r.prefix+path, root)
-
-	// 收集路由信息
-	r.routes = append(r.routes, RouteInfo{
-		Method:  "GET",
-		Path:    r.prefix + path + "/*filepath",
-		Handler: "Static(" + root + ")",
-	})
+func (r *Router) Static(path, root string) *Route {
+	r.group.Static(path, root)
+	return r.addRoute([]string{"GET"}, path+"/*filepath", "Static("+root+")", nil)
 }
 
 // StaticFile 注册静态文件路由
-func (r *Router) StaticFile(path, filepath string) {
-	r.server.StaticFile(r.prefix+path, filepath)
-
-	// 收集路由信息
-	r.routes = append(r.routes, RouteInfo{
-		Method:  "GET",
-		Path:    r.prefix + path,
-		Handler: "StaticFile(" + filepath + ")",
-	})
+func (r *Router) StaticFile(path, filepath string) *Route {
+	r.group.StaticFile(path, filepath)
+	return r.addRoute([]string{"GET"}, path, "StaticFile("+filepath+")", nil)
 }
 
 // StaticFS 注册静态文件系统路由
-func (r *Router) StaticFS(path string, fs *app.FS) {
-	r.server.StaticFS(r.prefix+path, fs)
+func (r *Router) StaticFS(path string, fs *app.FS) *Route {
+	r.group.StaticFS(path, fs)
+	return r.addRoute([]string{"GET"}, path+"/*filepath", "StaticFS("+fs.Root+")", nil)
 }
 
-// Use 使用中间件
-func (r *Router) Use(handlers ...HandlerFunc) {
+// SetBaseURL 设置站点根地址，用于 FullURL 生成绝对链接
+func (r *Router) SetBaseURL(baseURL string) {
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+	r.table.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// BaseURL 获取站点根地址
+func (r *Router) BaseURL() string {
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+	return r.table.baseURL
+}
+
+// HasRoute 检查是否存在指定名称的路由
+func (r *Router) HasRoute(name string) bool {
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+	_, exists := r.table.names[name]
+	return exists
+}
+
+// URL 根据路由名称生成路径，params 为键值对，路径参数之外的键作为查询参数，如
+// URL("posts.show", "id", 5, "page", 2) 返回 /api/posts/5?page=2
+func (r *Router) URL(name string, params ...interface{}) (string, error) {
+	r.table.mu.RLock()
+	pattern, exists := r.table.names[name]
+	r.table.mu.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("route %q is not defined", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("route %q: params must be key/value pairs", name)
+	}
+
+	values := make(url.Values)
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("route %q: param key %v is not a string", name, params[i])
+		}
+		switch value := params[i+1].(type) {
+		case []string:
+			values[key] = append(values[key], value...)
+		default:
+			values.Add(key, fmt.Sprint(value))
+		}
+	}
+
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+
+		key := segment[1:]
+		value := values.Get(key)
+		values.Del(key)
+		if segment[0] == ':' {
+			if value == "" {
+				return "", fmt.Errorf("route %q: missing param %q", name, key)
+			}
+			segments[i] = url.PathEscape(value)
+			continue
+		}
+
+		// 通配参数可以包含多级路径
+		parts := strings.Split(strings.TrimPrefix(value, "/"), "/")
+		for j, part := range parts {
+			parts[j] = url.PathEscape(part)
+		}
+		segments[i] = strings.Join(parts, "/")
+	}
+
+	path := strings.Join(segments, "/")
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+	return path, nil
+}
+
+// FullURL 根据路由名称生成包含站点根地址的绝对链接
+func (r *Router) FullURL(name string, params ...interface{}) (string, error) {
+	path, err := r.URL(name, params...)
+	if err != nil {
+		return "", err
+	}
+
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+	return r.table.baseURL + path, nil
+}
+
+// wrapHandlers 将 HandlerFunc 转换为 Hertz 的处理函数
+func wrapHandlers(handlers []HandlerFunc) []app.HandlerFunc {
 	h := make([]app.HandlerFunc, len(handlers))
 	for i, handler := range handlers {
 		h[i] = func(ctx context.Context, c *app.RequestContext) {
 			handler(ctx, NewRequestContext(c))
 		}
 	}
-	r.server.Use(h...)
+	return h
+}
+
+// handlerLabels 适配器包装的处理函数（闭包地址）-> 原处理函数名称
+var handlerLabels sync.Map
+
+// LabelHandler 为适配器包装出的处理函数记录原处理函数的名称，路由表中显示原名称
+func LabelHandler(handler HandlerFunc, original interface{}) HandlerFunc {
+	handlerLabels.Store(closurePointer(handler), funcName(original))
+	return handler
+}
+
+// closurePointer 获取函数值的闭包地址，同一段代码创建的不同闭包地址不同
+func closurePointer(handler HandlerFunc) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&handler))
+}
+
+// handlerName 获取处理函数名称，优先使用 LabelHandler 记录的名称
+func handlerName(handler HandlerFunc) string {
+	if label, ok := handlerLabels.Load(closurePointer(handler)); ok {
+		return label.(string)
+	}
+	return funcName(handler)
+}
+
+// handlerNames 获取处理函数名称列表
+func handlerNames(handlers []HandlerFunc) []string {
+	names := make([]string, len(handlers))
+	for i, handler := range handlers {
+		names[i] = handlerName(handler)
+	}
+	return names
+}
+
+// funcName 获取函数的简短名称，如 Middleware.JWTMiddleware、framework.Cors
+func funcName(fn interface{}) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return fmt.Sprintf("%T", fn)
+	}
+	f := runtime.FuncForPC(value.Pointer())
+	if f == nil {
+		return fmt.Sprintf("%T", fn)
+	}
+
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, "-fm")
+
+	// 去掉闭包后缀（.func1、.func1.2），保留创建它的函数名
+	parts := strings.Split(name, ".")
+	closure := false
+	for len(parts) > 2 {
+		last := parts[len(parts)-1]
+		if !strings.HasPrefix(last, "func") && strings.Trim(last, "0123456789") != "" {
+			break
+		}
+		parts = parts[:len(parts)-1]
+		closure = true
+	}
+
+	// 构造函数被内联时名称中会带上调用方（如 routes.APIRoutes.JWTMiddleware），
+	// 只保留最内层的函数及其接收者，包名取闭包所在源文件的目录
+	if closure && len(parts) > 2 {
+		keep := 1
+		if strings.HasPrefix(parts[len(parts)-2], "(") {
+			keep = 2
+		}
+		file, _ := f.FileLine(value.Pointer())
+		parts = append([]string{filepath.Base(filepath.Dir(file))}, parts[len(parts)-keep:]...)
+	}
+	return strings.Join(parts, ".")
 }
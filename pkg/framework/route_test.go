@@ -0,0 +1,153 @@
+package framework
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+// traceMiddleware 在响应头中追加中间件名称，用于检查执行顺序
+func traceMiddleware(name string) HandlerFunc {
+	return func(ctx context.Context, c *RequestContext) {
+		c.Response.Header.Add("X-Trace", name)
+		c.Next(ctx)
+	}
+}
+
+func denyMiddleware() HandlerFunc {
+	return func(ctx context.Context, c *RequestContext) {
+		c.AbortWithStatus(401)
+	}
+}
+
+func okHandler(ctx context.Context, c *RequestContext) {
+	c.String(200, "ok")
+}
+
+func traces(h *server.Hertz, method, path string) (int, string) {
+	w := ut.PerformRequest(h.Engine, method, path, nil)
+	resp := w.Result()
+
+	var names []string
+	resp.Header.VisitAll(func(key, value []byte) {
+		if string(key) == "X-Trace" {
+			names = append(names, string(value))
+		}
+	})
+	return resp.StatusCode(), strings.Join(names, ",")
+}
+
+func TestRouter_GroupMiddleware(t *testing.T) {
+	h := server.New()
+	router := NewRouter(h)
+	router.useNative(func(ctx context.Context, c *app.RequestContext) {
+		c.Response.Header.Add("X-Trace", "global")
+		c.Next(ctx)
+	})
+
+	router.GET("/public", okHandler)
+
+	api := router.Group("/api", traceMiddleware("api"))
+	api.GET("/ping", okHandler)
+	api.GET("/single", traceMiddleware("route"), okHandler)
+
+	admin := api.Group("/admin", denyMiddleware())
+	admin.GET("/users", okHandler)
+
+	tests := []struct {
+		path   string
+		status int
+		trace  string
+	}{
+		{"/public", 200, "global"},
+		{"/api/ping", 200, "global,api"},
+		{"/api/single", 200, "global,api,route"},
+		{"/api/admin/users", 401, "global,api"},
+	}
+	for _, tt := range tests {
+		status, trace := traces(h, "GET", tt.path)
+		if status != tt.status || trace != tt.trace {
+			t.Errorf("%s: got %d %q, want %d %q", tt.path, status, trace, tt.status, tt.trace)
+		}
+	}
+}
+
+func TestRouter_GetRoutes(t *testing.T) {
+	router := NewRouter(server.New())
+	router.GET("/", okHandler)
+
+	api := router.Group("/api", traceMiddleware("api"))
+	api.POST("/posts", denyMiddleware(), okHandler)
+	api.Group("/v1").Any("/any", okHandler)
+
+	routes := router.GetRoutes()
+	if len(routes) != 11 {
+		t.Fatalf("expected 11 routes, got %d: %+v", len(routes), routes)
+	}
+
+	post := routes[1]
+	if post.Method != "POST" || post.Path != "/api/posts" || post.Handler != "framework.okHandler" {
+		t.Errorf("unexpected route: %+v", post)
+	}
+	if strings.Join(post.Middleware, ",") != "framework.traceMiddleware,framework.denyMiddleware" {
+		t.Errorf("unexpected middleware: %v", post.Middleware)
+	}
+	if routes[2].Path != "/api/v1/any" || len(routes[2].Middleware) != 1 {
+		t.Errorf("nested group route not recorded: %+v", routes[2])
+	}
+}
+
+func TestRouter_URL(t *testing.T) {
+	router := NewRouter(server.New())
+	router.SetBaseURL("https://example.com/")
+
+	api := router.Group("/api")
+	api.GET("/posts/:id", okHandler).Name("posts.show")
+	api.GET("/files/*path", okHandler).Name("files.show")
+
+	url, err := router.URL("posts.show", "id", 5, "page", 2, "tag", []string{"go", "web"})
+	if err != nil || url != "/api/posts/5?page=2&tag=go&tag=web" {
+		t.Errorf("unexpected url %q (%v)", url, err)
+	}
+
+	url, _ = api.URL("files.show", "path", "docs/a b.md")
+	if url != "/api/files/docs/a%20b.md" {
+		t.Errorf("unexpected wildcard url %q", url)
+	}
+
+	full, _ := router.FullURL("posts.show", "id", "hello world")
+	if full != "https://example.com/api/posts/hello%20world" {
+		t.Errorf("unexpected full url %q", full)
+	}
+
+	if _, err := router.URL("posts.show"); err == nil {
+		t.Error("expected error for missing param")
+	}
+	if _, err := router.URL("posts.missing", "id", 1); err == nil {
+		t.Error("expected error for unknown route")
+	}
+	if _, err := router.URL("posts.show", "id"); err == nil {
+		t.Error("expected error for odd params")
+	}
+
+	routes := router.GetRoutes()
+	if routes[0].Name != "posts.show" || !router.HasRoute("files.show") {
+		t.Errorf("route name not recorded: %+v", routes[0])
+	}
+}
+
+func TestLabelHandler(t *testing.T) {
+	router := NewRouter(server.New())
+	native := func(ctx context.Context, c *app.RequestContext) {}
+	router.GET("/native", LabelHandler(func(ctx context.Context, c *RequestContext) {
+		native(ctx, c.RequestContext)
+	}, okHandler))
+
+	if handler := router.GetRoutes()[0].Handler; handler != "framework.okHandler" {
+		t.Errorf("unexpected handler name %q", handler)
+	}
+}
@@ -3,17 +3,24 @@ package mail
 import (
 	"bytes"
 	"crypto/tls"
+	"embed"
 	"encoding/base64"
 	"fmt"
 	"html/template"
 	"net/mail"
 	"net/smtp"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/clarkzhu2020/aidecms/config"
 )
 
+// bundledTemplates 内置邮件模板
+//
+//go:embed templates/*.html
+var bundledTemplates embed.FS
+
 // MailService 邮件服务
 type MailService struct {
 	config *config.MailConfig
@@ -259,20 +266,37 @@ func (s *MailService) buildMessage(mail *Mail) ([]byte, error) {
 
 // SendTemplate 发送模板邮件
 func (s *MailService) SendTemplate(templateName string, data interface{}, mail *Mail) error {
+	body, err := RenderTemplate(templateName, data)
+	if err != nil {
+		return err
+	}
+
+	mail.HTMLBody = body
+	return s.SendMail(mail)
+}
+
+// RenderTemplate 渲染邮件模板
+// 优先使用 templates/email/<name>.html，不存在时使用内置模板（password_reset、verify_email）
+func RenderTemplate(templateName string, data interface{}) (string, error) {
 	// 加载模板
-	tmpl, err := template.ParseFiles(fmt.Sprintf("templates/email/%s.html", templateName))
+	path := fmt.Sprintf("templates/email/%s.html", templateName)
+	var tmpl *template.Template
+	var err error
+	if _, statErr := os.Stat(path); statErr == nil {
+		tmpl, err = template.ParseFiles(path)
+	} else {
+		tmpl, err = template.ParseFS(bundledTemplates, "templates/"+templateName+".html")
+	}
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
 
 	// 渲染模板
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+		return "", fmt.Errorf("failed to execute template: %w", err)
 	}
-
-	mail.HTMLBody = buf.String()
-	return s.SendMail(mail)
+	return buf.String(), nil
 }
 
 // ValidateEmail 验证邮箱地址
@@ -0,0 +1,28 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate_Bundled(t *testing.T) {
+	data := map[string]interface{}{
+		"AppName":   "AideCMS",
+		"Username":  "alice",
+		"URL":       "https://cms.example.com/reset-password?token=abc&x=<y>",
+		"ExpiresIn": "1 小时",
+	}
+	for _, name := range []string{"password_reset", "verify_email"} {
+		body, err := RenderTemplate(name, data)
+		if err != nil {
+			t.Fatalf("%s: RenderTemplate error: %v", name, err)
+		}
+		if !strings.Contains(body, "alice") || !strings.Contains(body, "token=abc&amp;x=%3cy%3e") {
+			t.Errorf("%s: unexpected body %s", name, body)
+		}
+	}
+
+	if _, err := RenderTemplate("missing", data); err == nil {
+		t.Error("missing template should fail")
+	}
+}
@@ -0,0 +1,252 @@
+// Package oidc 实现 OpenID Connect 依赖方（Relying Party）的授权码流程：
+// 服务发现、授权码 + PKCE、ID 令牌校验和 userinfo 接口
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResponseSize 身份提供方响应的最大长度
+const maxResponseSize = 1 << 20
+
+// jwksRefreshInterval 遇到未知的 kid 时重新获取 JWKS 的最小间隔
+const jwksRefreshInterval = time.Minute
+
+// ErrNoIDToken 令牌响应中没有 id_token（请求的 scope 中缺少 openid）
+var ErrNoIDToken = errors.New("oidc: token response has no id_token")
+
+// Config 依赖方配置
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string     // 为空时使用 openid email profile
+	HTTPClient   *http.Client // 为空时使用10秒超时的默认客户端
+}
+
+// Metadata 服务发现文档（/.well-known/openid-configuration）中使用的字段
+type Metadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// Token 令牌接口的响应
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	IDToken      string `json:"id_token"`
+}
+
+// Error 身份提供方返回的 OAuth2 错误
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	if e.Description != "" {
+		return "oidc: " + e.Code + ": " + e.Description
+	}
+	return "oidc: " + e.Code
+}
+
+// Provider 一个身份提供方，通过 Discover 创建，可以并发使用
+type Provider struct {
+	config   Config
+	metadata Metadata
+	client   *http.Client
+	now      func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+// Discover 读取身份提供方的服务发现文档，文档中的 issuer 必须与配置一致
+func Discover(ctx context.Context, config Config) (*Provider, error) {
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	issuer := strings.TrimRight(config.Issuer, "/")
+
+	var metadata Metadata
+	if err := getJSON(ctx, client, issuer+"/.well-known/openid-configuration", "", &metadata); err != nil {
+		return nil, fmt.Errorf("oidc: discovery failed: %w", err)
+	}
+	if strings.TrimRight(metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc: issuer mismatch: configured %q, discovered %q", config.Issuer, metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is missing required endpoints")
+	}
+	if len(metadata.CodeChallengeMethodsSupported) > 0 && !slices.Contains(metadata.CodeChallengeMethodsSupported, "S256") {
+		return nil, errors.New("oidc: provider does not support PKCE with S256")
+	}
+
+	return &Provider{
+		config:   config,
+		metadata: metadata,
+		client:   client,
+		now:      time.Now,
+	}, nil
+}
+
+// Metadata 服务发现文档
+func (p *Provider) Metadata() Metadata {
+	return p.metadata
+}
+
+// AuthCodeURL 生成跳转到身份提供方的授权地址
+// state 和 nonce 由调用方保存，verifier 为 PKCE 的 code_verifier，地址中只包含其 S256 哈希
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	scopes := p.config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", S256Challenge(verifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// Exchange 用授权码和 PKCE code_verifier 换取令牌
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (*Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.config.ClientID)
+
+	// 默认使用 client_secret_basic，身份提供方只支持 client_secret_post 时放在表单中
+	basic := p.config.ClientSecret != ""
+	if methods := p.metadata.TokenEndpointAuthMethodsSupported; basic && len(methods) > 0 &&
+		!slices.Contains(methods, "client_secret_basic") && slices.Contains(methods, "client_secret_post") {
+		basic = false
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if basic {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var token Token
+	if err := doJSON(p.client, req, &token); err != nil {
+		return nil, fmt.Errorf("oidc: token exchange failed: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, ErrNoIDToken
+	}
+	return &token, nil
+}
+
+// UserInfo 使用访问令牌读取 userinfo 接口，身份提供方没有该接口时返回 nil
+func (p *Provider) UserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	if p.metadata.UserinfoEndpoint == "" {
+		return nil, nil
+	}
+
+	var info UserInfo
+	if err := getJSON(ctx, p.client, p.metadata.UserinfoEndpoint, accessToken, &info); err != nil {
+		return nil, fmt.Errorf("oidc: userinfo request failed: %w", err)
+	}
+	if info.Subject == "" {
+		return nil, errors.New("oidc: userinfo response has no sub")
+	}
+	return &info, nil
+}
+
+// RandomToken 生成256位随机字符串，用于 state、nonce 和 PKCE code_verifier
+func RandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// S256Challenge 计算 PKCE 的 code_challenge（RFC 7636 S256）
+func S256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应，bearer 不为空时放在 Authorization 头中
+func getJSON(ctx context.Context, client *http.Client, endpoint, bearer string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	return doJSON(client, req, v)
+}
+
+// doJSON 发送请求并解析 JSON 响应，非2xx响应解析为 *Error
+func doJSON(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var oauthErr Error
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Code != "" {
+			return &oauthErr
+		}
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", req.URL.Host, err)
+	}
+	return nil
+}
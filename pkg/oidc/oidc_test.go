@@ -0,0 +1,167 @@
+package oidc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/oidc"
+	"github.com/clarkzhu2020/aidecms/pkg/oidc/oidctest"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestProvider(t *testing.T) (*oidc.Provider, *oidctest.Server) {
+	t.Helper()
+	server := oidctest.NewServer("client", "secret")
+	t.Cleanup(server.Close)
+
+	provider, err := oidc.Discover(context.Background(), oidc.Config{
+		Issuer:       server.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "https://cms.example.com/auth/oidc/mock/callback",
+	})
+	if err != nil {
+		t.Fatalf("Discover error: %v", err)
+	}
+	return provider, server
+}
+
+// authorize 完成授权并换取令牌
+func authorize(t *testing.T, provider *oidc.Provider, server *oidctest.Server, nonce, verifier string) *oidc.Token {
+	t.Helper()
+	code, state, err := server.Authorize(provider.AuthCodeURL("state-1", nonce, verifier))
+	if err != nil {
+		t.Fatalf("Authorize error: %v", err)
+	}
+	if state != "state-1" {
+		t.Fatalf("state = %q, want state-1", state)
+	}
+	token, err := provider.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange error: %v", err)
+	}
+	return token
+}
+
+func TestProvider_AuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	provider, server := newTestProvider(t)
+	server.SetUser(oidctest.User{Subject: "u-1", Email: "alice@example.com", EmailVerified: true, Name: "Alice", PreferredUsername: "alice"})
+
+	authURL, _ := url.Parse(provider.AuthCodeURL("state-1", "nonce-1", "verifier-1"))
+	query := authURL.Query()
+	if query.Get("code_challenge") != oidc.S256Challenge("verifier-1") || query.Get("code_challenge_method") != "S256" {
+		t.Errorf("missing PKCE parameters: %v", query)
+	}
+	if query.Get("scope") != "openid email profile" {
+		t.Errorf("scope = %q", query.Get("scope"))
+	}
+
+	token := authorize(t, provider, server, "nonce-1", "verifier-1")
+	idToken, err := provider.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken error: %v", err)
+	}
+	if idToken.Subject != "u-1" || idToken.Claims.Email != "alice@example.com" || !idToken.Claims.EmailVerified {
+		t.Errorf("unexpected id token: %+v", idToken)
+	}
+
+	info, err := provider.UserInfo(ctx, token.AccessToken)
+	if err != nil {
+		t.Fatalf("UserInfo error: %v", err)
+	}
+	if info.Subject != "u-1" || info.Claims.PreferredUsername != "alice" {
+		t.Errorf("unexpected userinfo: %+v", info)
+	}
+}
+
+func TestProvider_ExchangeRejectsWrongVerifier(t *testing.T) {
+	provider, server := newTestProvider(t)
+
+	code, _, err := server.Authorize(provider.AuthCodeURL("state", "nonce", "right-verifier"))
+	if err != nil {
+		t.Fatalf("Authorize error: %v", err)
+	}
+	_, err = provider.Exchange(context.Background(), code, "wrong-verifier")
+	var oauthErr *oidc.Error
+	if !errors.As(err, &oauthErr) || oauthErr.Code != "invalid_grant" {
+		t.Fatalf("Exchange error = %v, want invalid_grant", err)
+	}
+}
+
+func TestProvider_VerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		nonce  string
+		mutate func(jwt.MapClaims)
+	}{
+		{name: "nonce mismatch", nonce: "other"},
+		{name: "wrong audience", nonce: "nonce", mutate: func(c jwt.MapClaims) { c["aud"] = "someone-else" }},
+		{name: "wrong issuer", nonce: "nonce", mutate: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "expired", nonce: "nonce", mutate: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "azp mismatch", nonce: "nonce", mutate: func(c jwt.MapClaims) {
+			c["aud"] = []string{"client", "other"}
+			c["azp"] = "other"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, server := newTestProvider(t)
+			server.IDTokenClaims = tt.mutate
+
+			token := authorize(t, provider, server, "nonce", "verifier")
+			if _, err := provider.VerifyIDToken(context.Background(), token.IDToken, tt.nonce); !errors.Is(err, oidc.ErrInvalidIDToken) {
+				t.Fatalf("VerifyIDToken error = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestProvider_VerifyIDTokenRejectsForeignKey(t *testing.T) {
+	provider, server := newTestProvider(t)
+	other := oidctest.NewServer("client", "secret")
+	defer other.Close()
+
+	// 使用另一个身份提供方的密钥签名，但 kid 相同
+	other.Key.ID = server.Key.ID
+	other.IDTokenClaims = func(c jwt.MapClaims) { c["iss"] = server.Issuer() }
+	otherProvider, err := oidc.Discover(context.Background(), oidc.Config{Issuer: other.Issuer(), ClientID: "client", ClientSecret: "secret", RedirectURL: "https://cms.example.com/cb"})
+	if err != nil {
+		t.Fatalf("Discover error: %v", err)
+	}
+	token := authorize(t, otherProvider, other, "nonce", "verifier")
+
+	if _, err := provider.VerifyIDToken(context.Background(), token.IDToken, "nonce"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Fatalf("VerifyIDToken error = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestDiscover_IssuerMismatch(t *testing.T) {
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+
+	_, err := oidc.Discover(context.Background(), oidc.Config{Issuer: server.Issuer() + "/tenant", ClientID: "client"})
+	if err == nil {
+		t.Fatal("expected discovery error")
+	}
+}
+
+func TestClaims_EmailVerifiedString(t *testing.T) {
+	var claims oidc.Claims
+	if err := json.Unmarshal([]byte(`{"email":"a@example.com","email_verified":"true","name":"A"}`), &claims); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if !claims.EmailVerified || claims.Name != "A" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+
+	claims.Merge(oidc.Claims{Email: "b@example.com", Name: "B", PreferredUsername: "b"})
+	if claims.Email != "b@example.com" || claims.EmailVerified || claims.Name != "A" || claims.PreferredUsername != "b" {
+		t.Errorf("unexpected merged claims: %+v", claims)
+	}
+}
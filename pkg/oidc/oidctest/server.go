@@ -0,0 +1,271 @@
+// Package oidctest 提供用于测试的本地 OpenID Connect 身份提供方
+package oidctest
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
+	"github.com/clarkzhu2020/aidecms/pkg/oidc"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// User 在模拟身份提供方登录的用户
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// Server 模拟身份提供方，实现服务发现、授权、令牌、userinfo 和 JWKS 接口
+// 授权接口不显示登录页面，直接以当前 User 的身份签发授权码
+type Server struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+	Key          *auth.Key
+
+	// IDTokenClaims 签发 ID 令牌前调用，测试中可以修改声明（如过期时间、受众）
+	IDTokenClaims func(claims jwt.MapClaims)
+
+	mu     sync.Mutex
+	user   User
+	codes  map[string]*authRequest
+	tokens map[string]User
+}
+
+// authRequest 已签发的授权码
+type authRequest struct {
+	redirectURI string
+	nonce       string
+	challenge   string
+	user        User
+}
+
+// NewServer 启动模拟身份提供方，使用 RS256 签名 ID 令牌，测试结束时需要调用 Close
+func NewServer(clientID, clientSecret string) *Server {
+	key, err := auth.GenerateKey(auth.AlgRS256, time.Now())
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: generate key: %v", err))
+	}
+
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Key:          key,
+		user:         User{Subject: "mock-user", Email: "mock@example.com", EmailVerified: true, Name: "Mock User"},
+		codes:        make(map[string]*authRequest),
+		tokens:       make(map[string]User),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/userinfo", s.userinfo)
+	mux.HandleFunc("/jwks", s.jwks)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Issuer 身份提供方的 issuer，即服务地址
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// SetUser 设置之后授权时登录的用户
+func (s *Server) SetUser(user User) {
+	s.mu.Lock()
+	s.user = user
+	s.mu.Unlock()
+}
+
+// Authorize 模拟浏览器打开授权地址并同意授权，返回回调地址中的 code 和 state
+func (s *Server) Authorize(authURL string) (code, state string, err error) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("oidctest: authorize returned %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+	query := location.Query()
+	if query.Get("error") != "" {
+		return "", "", fmt.Errorf("oidctest: authorize error %s", query.Get("error"))
+	}
+	return query.Get("code"), query.Get("state"), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, oidc.Metadata{
+		Issuer:                            s.URL,
+		AuthorizationEndpoint:             s.URL + "/authorize",
+		TokenEndpoint:                     s.URL + "/token",
+		UserinfoEndpoint:                  s.URL + "/userinfo",
+		JWKSURI:                           s.URL + "/jwks",
+		IDTokenSigningAlgValuesSupported:  []string{s.Key.Algorithm},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post"},
+	})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	if query.Get("client_id") != s.ClientID || redirectURI == "" {
+		http.Error(w, "invalid client", http.StatusBadRequest)
+		return
+	}
+
+	callback, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := callback.Query()
+	params.Set("state", query.Get("state"))
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		params.Set("error", "invalid_request")
+	} else {
+		code, _ := oidc.RandomToken()
+		s.mu.Lock()
+		s.codes[code] = &authRequest{
+			redirectURI: redirectURI,
+			nonce:       query.Get("nonce"),
+			challenge:   query.Get("code_challenge"),
+			user:        s.user,
+		}
+		s.mu.Unlock()
+		params.Set("code", code)
+	}
+	callback.RawQuery = params.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, oidc.Error{Code: "unsupported_grant_type"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != s.ClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.ClientSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, oidc.Error{Code: "invalid_client"})
+		return
+	}
+
+	// 授权码只能使用一次
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	request, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+	if !ok || request.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, oidc.Error{Code: "invalid_grant"})
+		return
+	}
+	if oidc.S256Challenge(r.PostForm.Get("code_verifier")) != request.challenge {
+		writeJSON(w, http.StatusBadRequest, oidc.Error{Code: "invalid_grant", Description: "PKCE verification failed"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            request.user.Subject,
+		"aud":            s.ClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          request.nonce,
+		"email":          request.user.Email,
+		"email_verified": request.user.EmailVerified,
+		"name":           request.user.Name,
+	}
+	if request.user.PreferredUsername != "" {
+		claims["preferred_username"] = request.user.PreferredUsername
+	}
+	if s.IDTokenClaims != nil {
+		s.IDTokenClaims(claims)
+	}
+	token := jwt.NewWithClaims(s.Key.Method(), claims)
+	token.Header["kid"] = s.Key.ID
+	idToken, err := token.SignedString(s.Key.Private)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, oidc.Error{Code: "server_error", Description: err.Error()})
+		return
+	}
+
+	accessToken, _ := oidc.RandomToken()
+	s.mu.Lock()
+	s.tokens[accessToken] = request.user
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, oidc.Token{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   3600,
+		IDToken:     idToken,
+	})
+}
+
+func (s *Server) userinfo(w http.ResponseWriter, r *http.Request) {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(prefix) || header[:len(prefix)] != prefix {
+		writeJSON(w, http.StatusUnauthorized, oidc.Error{Code: "invalid_token"})
+		return
+	}
+
+	s.mu.Lock()
+	user, ok := s.tokens[header[len(prefix):]]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, oidc.Error{Code: "invalid_token"})
+		return
+	}
+
+	info := map[string]interface{}{
+		"sub":            user.Subject,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+	}
+	if user.PreferredUsername != "" {
+		info["preferred_username"] = user.PreferredUsername
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, auth.PublicJWKS([]*auth.Key{s.Key}, time.Now()))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
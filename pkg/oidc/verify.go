@@ -0,0 +1,217 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/clarkzhu2020/aidecms/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidIDToken ID 令牌无效（签名、签发方、受众、有效期或 nonce 不匹配）
+var ErrInvalidIDToken = errors.New("oidc: invalid id token")
+
+// idTokenLeeway 校验 exp、iat 时允许的时钟偏差
+const idTokenLeeway = time.Minute
+
+// supportedAlgorithms ID 令牌支持的签名算法，不接受 none 和 HS*
+var supportedAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Claims ID 令牌和 userinfo 中的用户资料
+type Claims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	GivenName         string `json:"given_name"`
+	FamilyName        string `json:"family_name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+}
+
+// UnmarshalJSON 兼容把 email_verified 返回为字符串 "true" 的身份提供方
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	type plain Claims
+	aux := struct {
+		*plain
+		EmailVerified interface{} `json:"email_verified"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	switch v := aux.EmailVerified.(type) {
+	case bool:
+		c.EmailVerified = v
+	case string:
+		c.EmailVerified = v == "true"
+	}
+	return nil
+}
+
+// Merge 用 other 中的非空字段补充当前资料，邮箱不同时以 other 为准并使用其验证状态
+func (c *Claims) Merge(other Claims) {
+	if other.Email != "" && other.Email != c.Email {
+		c.Email = other.Email
+		c.EmailVerified = other.EmailVerified
+	} else if other.Email == c.Email && other.EmailVerified {
+		c.EmailVerified = true
+	}
+	for dst, src := range map[*string]string{
+		&c.Name:              other.Name,
+		&c.GivenName:         other.GivenName,
+		&c.FamilyName:        other.FamilyName,
+		&c.PreferredUsername: other.PreferredUsername,
+		&c.Picture:           other.Picture,
+	} {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+}
+
+// IDToken 校验通过的 ID 令牌
+type IDToken struct {
+	Issuer   string
+	Subject  string
+	Audience []string
+	Expiry   time.Time
+	IssuedAt time.Time
+	Nonce    string
+	Claims   Claims
+}
+
+// UserInfo userinfo 接口的响应
+type UserInfo struct {
+	Subject string
+	Claims  Claims
+}
+
+// UnmarshalJSON 解析 sub 和用户资料
+func (u *UserInfo) UnmarshalJSON(data []byte) error {
+	var sub struct {
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(data, &sub); err != nil {
+		return err
+	}
+	u.Subject = sub.Subject
+	return json.Unmarshal(data, &u.Claims)
+}
+
+// idTokenPayload ID 令牌中需要额外校验的声明
+type idTokenPayload struct {
+	jwt.RegisteredClaims
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+}
+
+// VerifyIDToken 校验 ID 令牌的签名（身份提供方的 JWKS）、iss、aud、azp、exp、iat 和 nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*IDToken, error) {
+	algorithms := supportedAlgorithms
+	if supported := p.metadata.IDTokenSigningAlgValuesSupported; len(supported) > 0 {
+		algorithms = slices.DeleteFunc(slices.Clone(supportedAlgorithms), func(alg string) bool {
+			return !slices.Contains(supported, alg)
+		})
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(algorithms),
+		jwt.WithIssuer(p.metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(idTokenLeeway),
+		jwt.WithTimeFunc(p.now),
+	)
+
+	var payload idTokenPayload
+	_, err := parser.ParseWithClaims(rawIDToken, &payload, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.verificationKey(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if payload.Subject == "" || payload.IssuedAt == nil {
+		return nil, fmt.Errorf("%w: missing sub or iat", ErrInvalidIDToken)
+	}
+	if len(payload.Audience) > 1 && payload.AuthorizedParty != p.config.ClientID {
+		return nil, fmt.Errorf("%w: azp does not match client id", ErrInvalidIDToken)
+	}
+	if payload.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	token := &IDToken{
+		Issuer:   payload.Issuer,
+		Subject:  payload.Subject,
+		Audience: payload.Audience,
+		Expiry:   payload.ExpiresAt.Time,
+		IssuedAt: payload.IssuedAt.Time,
+		Nonce:    payload.Nonce,
+	}
+	// 签名已校验，直接解析资料字段
+	raw, err := jwt.NewParser().DecodeSegment(strings.Split(rawIDToken, ".")[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if err := json.Unmarshal(raw, &token.Claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	return token, nil
+}
+
+// verificationKey 查找 kid 对应的公钥，未知的 kid 会触发重新获取 JWKS（身份提供方轮换了密钥）
+// 令牌没有 kid 时，JWKS 中只有一个密钥才使用它
+func (p *Provider) verificationKey(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if !p.keysFetched.IsZero() && p.now().Sub(p.keysFetched) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	var set auth.JWKSet
+	if err := getJSON(ctx, p.client, p.metadata.JWKSURI, "", &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// 跳过不支持的密钥类型，不影响其他密钥
+		if key, err := jwk.PublicKey(); err == nil {
+			keys[jwk.KeyID] = key
+		}
+	}
+	p.keys = keys
+	p.keysFetched = p.now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// lookupKey 在已缓存的 JWKS 中查找公钥，调用方需持有锁
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" {
+		if len(p.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
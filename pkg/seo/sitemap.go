@@ -2,7 +2,7 @@ package seo
 
 import (
 	"encoding/xml"
-	"fmt"
+	"errors"
 	"time"
 
 	"github.com/clarkzhu2020/aidecms/internal/app/models"
@@ -24,20 +24,48 @@ type URL struct {
 	Priority   float32 `xml:"priority,omitempty"`
 }
 
+// URLGenerator 根据路由名称生成绝对链接，framework.Router 实现了该接口
+type URLGenerator interface {
+	FullURL(name string, params ...interface{}) (string, error)
+}
+
+// Sitemap 中使用的路由名称，文章、分类和标签的参数为 id
+const (
+	RouteHome     = "home"
+	RoutePost     = "posts.show"
+	RouteCategory = "categories.show"
+	RouteTag      = "tags.show"
+)
+
+// ErrNoURLGenerator 未设置链接生成器
+var ErrNoURLGenerator = errors.New("sitemap: URL generator is not set")
+
 // SitemapGenerator Sitemap 生成器
 type SitemapGenerator struct {
-	baseURL string
-	db      *gorm.DB
+	db   *gorm.DB
+	urls URLGenerator
 }
 
-// NewSitemapGenerator 创建 Sitemap 生成器
-func NewSitemapGenerator(baseURL string, db *gorm.DB) *SitemapGenerator {
+// NewSitemapGenerator 创建 Sitemap 生成器，生成前需要用 SetURLGenerator 设置链接生成器
+func NewSitemapGenerator(db *gorm.DB) *SitemapGenerator {
 	return &SitemapGenerator{
-		baseURL: baseURL,
-		db:      db,
+		db: db,
 	}
 }
 
+// SetURLGenerator 设置链接生成器，按路由名称生成首页、文章、分类和标签的链接
+func (g *SitemapGenerator) SetURLGenerator(urls URLGenerator) {
+	g.urls = urls
+}
+
+// link 按路由名称生成链接，未设置链接生成器或路由未注册时返回错误
+func (g *SitemapGenerator) link(name string, params ...interface{}) (string, error) {
+	if g.urls == nil {
+		return "", ErrNoURLGenerator
+	}
+	return g.urls.FullURL(name, params...)
+}
+
 // Generate 生成完整的 sitemap
 func (g *SitemapGenerator) Generate() (string, error) {
 	urlset := URLSet{
@@ -46,8 +74,12 @@ func (g *SitemapGenerator) Generate() (string, error) {
 	}
 
 	// 添加首页
+	home, err := g.link(RouteHome)
+	if err != nil {
+		return "", err
+	}
 	urlset.URLs = append(urlset.URLs, URL{
-		Loc:        g.baseURL,
+		Loc:        home,
 		LastMod:    time.Now().Format("2006-01-02"),
 		ChangeFreq: "daily",
 		Priority:   1.0,
@@ -65,8 +97,12 @@ func (g *SitemapGenerator) Generate() (string, error) {
 			lastMod = post.PublishedAt.Format("2006-01-02")
 		}
 
+		loc, err := g.link(RoutePost, "id", post.ID)
+		if err != nil {
+			return "", err
+		}
 		urlset.URLs = append(urlset.URLs, URL{
-			Loc:        fmt.Sprintf("%s/posts/%s", g.baseURL, post.Slug),
+			Loc:        loc,
 			LastMod:    lastMod,
 			ChangeFreq: "weekly",
 			Priority:   0.8,
@@ -80,8 +116,12 @@ func (g *SitemapGenerator) Generate() (string, error) {
 	}
 
 	for _, category := range categories {
+		loc, err := g.link(RouteCategory, "id", category.ID)
+		if err != nil {
+			return "", err
+		}
 		urlset.URLs = append(urlset.URLs, URL{
-			Loc:        fmt.Sprintf("%s/categories/%s", g.baseURL, category.Slug),
+			Loc:        loc,
 			LastMod:    category.UpdatedAt.Format("2006-01-02"),
 			ChangeFreq: "weekly",
 			Priority:   0.6,
@@ -95,8 +135,12 @@ func (g *SitemapGenerator) Generate() (string, error) {
 	}
 
 	for _, tag := range tags {
+		loc, err := g.link(RouteTag, "id", tag.ID)
+		if err != nil {
+			return "", err
+		}
 		urlset.URLs = append(urlset.URLs, URL{
-			Loc:        fmt.Sprintf("%s/tags/%s", g.baseURL, tag.Slug),
+			Loc:        loc,
 			LastMod:    tag.UpdatedAt.Format("2006-01-02"),
 			ChangeFreq: "weekly",
 			Priority:   0.5,
@@ -132,8 +176,12 @@ func (g *SitemapGenerator) GenerateForPosts() (string, error) {
 			lastMod = post.PublishedAt.Format("2006-01-02")
 		}
 
+		loc, err := g.link(RoutePost, "id", post.ID)
+		if err != nil {
+			return "", err
+		}
 		urlset.URLs = append(urlset.URLs, URL{
-			Loc:        fmt.Sprintf("%s/posts/%s", g.baseURL, post.Slug),
+			Loc:        loc,
 			LastMod:    lastMod,
 			ChangeFreq: "weekly",
 			Priority:   0.8,
@@ -0,0 +1,92 @@
+package seo
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clarkzhu2020/aidecms/internal/app/models"
+	"github.com/clarkzhu2020/aidecms/pkg/database"
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"gorm.io/gorm"
+)
+
+func setupDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	d := database.NewDatabase(&database.Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "seo.db")})
+	if err := d.Connect(); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	if err := d.DB.AutoMigrate(&models.User{}, &models.Category{}, &models.Tag{}, &models.Post{}); err != nil {
+		t.Fatal(err)
+	}
+	return d.DB
+}
+
+func noop(ctx context.Context, c *framework.RequestContext) {}
+
+// sitemap 的链接全部由路由表生成
+func TestSitemapGenerator_UsesNamedRoutes(t *testing.T) {
+	db := setupDB(t)
+	category := &models.Category{Name: "News", Slug: "news"}
+	db.Create(category)
+	tag := &models.Tag{Name: "Go", Slug: "go"}
+	db.Create(tag)
+	post := &models.Post{Title: "Hello", Slug: "hello", Status: "published", CategoryID: category.ID}
+	db.Create(post)
+	db.Create(&models.Post{Title: "Draft", Slug: "draft", Status: "draft", CategoryID: category.ID})
+
+	router := framework.NewRouter(server.New())
+	router.SetBaseURL("https://example.com")
+	router.GET("/", noop).Name(RouteHome)
+	router.GET("/api/posts/:id", noop).Name(RoutePost)
+	router.GET("/api/categories/:id", noop).Name(RouteCategory)
+	router.GET("/api/tags/:id", noop).Name(RouteTag)
+
+	g := NewSitemapGenerator(db)
+	g.SetURLGenerator(router)
+	sitemap, err := g.Generate()
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+
+	postURL, _ := router.FullURL(RoutePost, "id", post.ID)
+	categoryURL, _ := router.FullURL(RouteCategory, "id", category.ID)
+	tagURL, _ := router.FullURL(RouteTag, "id", tag.ID)
+	for _, loc := range []string{"https://example.com/", postURL, categoryURL, tagURL} {
+		if !strings.Contains(sitemap, "<loc>"+loc+"</loc>") {
+			t.Errorf("sitemap missing %s:\n%s", loc, sitemap)
+		}
+	}
+	if strings.Count(sitemap, "/api/posts/") != 1 {
+		t.Errorf("only published posts should be listed:\n%s", sitemap)
+	}
+
+	posts, err := g.GenerateForPosts()
+	if err != nil || !strings.Contains(posts, "<loc>"+postURL+"</loc>") {
+		t.Errorf("GenerateForPosts = %v:\n%s", err, posts)
+	}
+}
+
+// 路由未注册时返回错误，而不是输出手工拼接的链接
+func TestSitemapGenerator_MissingRoute(t *testing.T) {
+	db := setupDB(t)
+	db.Create(&models.Post{Title: "Hello", Slug: "hello", Status: "published"})
+
+	g := NewSitemapGenerator(db)
+	if _, err := g.Generate(); !errors.Is(err, ErrNoURLGenerator) {
+		t.Fatalf("Generate without URL generator = %v, want ErrNoURLGenerator", err)
+	}
+
+	router := framework.NewRouter(server.New())
+	router.GET("/", noop).Name(RouteHome)
+	g.SetURLGenerator(router)
+	if _, err := g.GenerateForPosts(); err == nil || !strings.Contains(err.Error(), RoutePost) {
+		t.Fatalf("GenerateForPosts without %s = %v, want error", RoutePost, err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -240,3 +242,29 @@ func (c *EthereumClient) IsContract(ctx context.Context, address string) (bool,
 	}
 	return len(code) > 0, nil
 }
+
+// callContract 调用合约的只读方法，并将唯一的返回值解码到 out
+func (c *EthereumClient) callContract(ctx context.Context, contractABI abi.ABI, contract, method string, out interface{}, args ...interface{}) error {
+	if err := ValidateAddress(c.chain, contract); err != nil {
+		return err
+	}
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+
+	to := common.HexToAddress(contract)
+	result, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	if len(result) == 0 {
+		return fmt.Errorf("failed to call %s: empty result, %s may not be a contract", method, contract)
+	}
+
+	values, err := contractABI.Unpack(method, result)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return contractABI.Methods[method].Outputs.Copy(out, values)
+}
@@ -0,0 +1,279 @@
+package web3
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Sign-In With Ethereum（EIP-4361）消息的解析和签名校验
+
+var (
+	// ErrSIWEInvalidMessage 消息不符合 EIP-4361 格式
+	ErrSIWEInvalidMessage = errors.New("siwe: invalid message")
+
+	// ErrSIWEInvalidSignature 签名无效或签名者不是消息中的地址
+	ErrSIWEInvalidSignature = errors.New("siwe: invalid signature")
+
+	// ErrSIWEDomainMismatch 消息中的 domain 或 URI 与本站不符
+	ErrSIWEDomainMismatch = errors.New("siwe: domain mismatch")
+
+	// ErrSIWEChainNotAllowed 消息中的 Chain ID 不在允许列表中
+	ErrSIWEChainNotAllowed = errors.New("siwe: chain id not allowed")
+
+	// ErrSIWEExpired 消息已过期、尚未生效或签发时间无效
+	ErrSIWEExpired = errors.New("siwe: message expired or not yet valid")
+
+	// ErrSIWENonceMismatch 消息中的 nonce 与服务端签发的不一致
+	ErrSIWENonceMismatch = errors.New("siwe: nonce mismatch")
+)
+
+const siweHeaderSuffix = " wants you to sign in with your Ethereum account:"
+
+// siweNoncePattern EIP-4361 要求 nonce 为至少8位字母数字
+var siweNoncePattern = regexp.MustCompile(`^[a-zA-Z0-9]{8,}$`)
+
+// SIWEMessage EIP-4361 登录消息
+type SIWEMessage struct {
+	Scheme         string // 可选，如 https
+	Domain         string // 请求签名的站点，host[:port]
+	Address        string // EIP-55 校验和格式的地址
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// SIWEVerifyOptions 校验消息时的要求
+type SIWEVerifyOptions struct {
+	Domain   string        // 本站的 host[:port]，消息的 domain 和 URI 必须与之相同
+	ChainIDs []int64       // 允许的 Chain ID，为空不限制
+	Nonce    string        // 服务端签发的 nonce，为空时由调用方自行校验
+	Now      time.Time     // 当前时间，为零值时使用 time.Now()
+	Leeway   time.Duration // 时间校验允许的误差
+}
+
+// ParseSIWEMessage 解析 EIP-4361 消息
+func ParseSIWEMessage(raw string) (*SIWEMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	if len(lines) < 2 || !strings.HasSuffix(lines[0], siweHeaderSuffix) {
+		return nil, fmt.Errorf("%w: missing header", ErrSIWEInvalidMessage)
+	}
+
+	m := &SIWEMessage{}
+	m.Domain = strings.TrimSuffix(lines[0], siweHeaderSuffix)
+	if scheme, domain, ok := strings.Cut(m.Domain, "://"); ok {
+		m.Scheme, m.Domain = scheme, domain
+	}
+	if m.Domain == "" || strings.ContainsAny(m.Domain, " /") {
+		return nil, fmt.Errorf("%w: invalid domain", ErrSIWEInvalidMessage)
+	}
+
+	m.Address = lines[1]
+	if !common.IsHexAddress(m.Address) || common.HexToAddress(m.Address).Hex() != m.Address {
+		return nil, fmt.Errorf("%w: address must be an EIP-55 checksummed address", ErrSIWEInvalidMessage)
+	}
+
+	// 地址后为空行、可选的 statement 和空行，然后是字段
+	i := 2
+	var statement []string
+	for ; i < len(lines) && !strings.HasPrefix(lines[i], "URI: "); i++ {
+		if lines[i] != "" {
+			statement = append(statement, lines[i])
+		}
+	}
+	if len(statement) > 1 {
+		return nil, fmt.Errorf("%w: statement must be a single line", ErrSIWEInvalidMessage)
+	}
+	if len(statement) == 1 {
+		m.Statement = statement[0]
+	}
+
+	// 字段按规定的顺序出现，前五个必填
+	fields := []struct {
+		name     string
+		required bool
+		set      func(string) error
+	}{
+		{"URI", true, func(v string) error {
+			_, err := url.Parse(v)
+			m.URI = v
+			return err
+		}},
+		{"Version", true, func(v string) error {
+			if v != "1" {
+				return errors.New("unsupported version")
+			}
+			m.Version = v
+			return nil
+		}},
+		{"Chain ID", true, func(v string) (err error) {
+			m.ChainID, err = strconv.ParseInt(v, 10, 64)
+			return err
+		}},
+		{"Nonce", true, func(v string) error {
+			if !siweNoncePattern.MatchString(v) {
+				return errors.New("nonce must be at least 8 alphanumeric characters")
+			}
+			m.Nonce = v
+			return nil
+		}},
+		{"Issued At", true, func(v string) (err error) {
+			m.IssuedAt, err = time.Parse(time.RFC3339, v)
+			return err
+		}},
+		{"Expiration Time", false, func(v string) error {
+			t, err := time.Parse(time.RFC3339, v)
+			m.ExpirationTime = &t
+			return err
+		}},
+		{"Not Before", false, func(v string) error {
+			t, err := time.Parse(time.RFC3339, v)
+			m.NotBefore = &t
+			return err
+		}},
+		{"Request ID", false, func(v string) error {
+			m.RequestID = v
+			return nil
+		}},
+	}
+	for _, field := range fields {
+		prefix := field.name + ": "
+		if i < len(lines) && strings.HasPrefix(lines[i], prefix) {
+			if err := field.set(strings.TrimPrefix(lines[i], prefix)); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrSIWEInvalidMessage, field.name, err)
+			}
+			i++
+		} else if field.required {
+			return nil, fmt.Errorf("%w: missing %s", ErrSIWEInvalidMessage, field.name)
+		}
+	}
+
+	if i < len(lines) && lines[i] == "Resources:" {
+		for i++; i < len(lines) && strings.HasPrefix(lines[i], "- "); i++ {
+			m.Resources = append(m.Resources, strings.TrimPrefix(lines[i], "- "))
+		}
+	}
+	// 允许末尾有一个换行
+	if i < len(lines) && !(i == len(lines)-1 && lines[i] == "") {
+		return nil, fmt.Errorf("%w: unexpected line %q", ErrSIWEInvalidMessage, lines[i])
+	}
+	return m, nil
+}
+
+// String 按 EIP-4361 格式生成待签名的消息
+func (m *SIWEMessage) String() string {
+	var b strings.Builder
+	if m.Scheme != "" {
+		b.WriteString(m.Scheme + "://")
+	}
+	b.WriteString(m.Domain + siweHeaderSuffix + "\n")
+	b.WriteString(m.Address + "\n\n")
+	if m.Statement != "" {
+		b.WriteString(m.Statement + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString("URI: " + m.URI + "\n")
+	b.WriteString("Version: " + m.Version + "\n")
+	b.WriteString("Chain ID: " + strconv.FormatInt(m.ChainID, 10) + "\n")
+	b.WriteString("Nonce: " + m.Nonce + "\n")
+	b.WriteString("Issued At: " + m.IssuedAt.UTC().Format(time.RFC3339))
+	if m.ExpirationTime != nil {
+		b.WriteString("\nExpiration Time: " + m.ExpirationTime.UTC().Format(time.RFC3339))
+	}
+	if m.NotBefore != nil {
+		b.WriteString("\nNot Before: " + m.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if m.RequestID != "" {
+		b.WriteString("\nRequest ID: " + m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\nResources:")
+		for _, resource := range m.Resources {
+			b.WriteString("\n- " + resource)
+		}
+	}
+	return b.String()
+}
+
+// Verify 校验消息的 domain、URI、Chain ID、有效期和 nonce，
+// 并从 personal_sign 签名中恢复签名者地址，要求与消息中的地址一致
+// raw 为用户实际签名的原始消息，签名按原文校验
+func (m *SIWEMessage) Verify(raw, signature string, opts SIWEVerifyOptions) error {
+	if opts.Domain != "" {
+		uri, err := url.Parse(m.URI)
+		if err != nil || !strings.EqualFold(m.Domain, opts.Domain) || !strings.EqualFold(uri.Host, opts.Domain) {
+			return ErrSIWEDomainMismatch
+		}
+	}
+	if len(opts.ChainIDs) > 0 && !slices.Contains(opts.ChainIDs, m.ChainID) {
+		return ErrSIWEChainNotAllowed
+	}
+	if opts.Nonce != "" && m.Nonce != opts.Nonce {
+		return ErrSIWENonceMismatch
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if m.IssuedAt.After(now.Add(opts.Leeway)) ||
+		(m.ExpirationTime != nil && !m.ExpirationTime.After(now.Add(-opts.Leeway))) ||
+		(m.NotBefore != nil && m.NotBefore.After(now.Add(opts.Leeway))) {
+		return ErrSIWEExpired
+	}
+
+	signer, err := RecoverPersonalSignAddress(raw, signature)
+	if err != nil {
+		return err
+	}
+	if signer != common.HexToAddress(m.Address) {
+		return ErrSIWEInvalidSignature
+	}
+	return nil
+}
+
+// RecoverPersonalSignAddress 从 personal_sign（EIP-191）签名中恢复签名者地址
+// 只支持外部账户的 ECDSA 签名，不支持合约钱包（EIP-1271）
+func RecoverPersonalSignAddress(message, signature string) (common.Address, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return common.Address{}, ErrSIWEInvalidSignature
+	}
+	// 钱包返回的 v 为 27/28，恢复公钥需要 0/1
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return common.Address{}, ErrSIWEInvalidSignature
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// NewSIWENonce 生成 nonce（32位十六进制字符）
+func NewSIWENonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
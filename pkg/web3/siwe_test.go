@@ -0,0 +1,126 @@
+package web3
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// signSIWE 用钱包的方式（personal_sign，v 为 27/28）签名
+func signSIWE(t *testing.T, message string) (string, string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	m := &SIWEMessage{
+		Domain:    "example.com",
+		Address:   address,
+		Statement: "Sign in to Example.",
+		URI:       "https://example.com/login",
+		Version:   "1",
+		ChainID:   1,
+		Nonce:     "32891756abcdef12",
+		IssuedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	expires := m.IssuedAt.Add(10 * time.Minute)
+	m.ExpirationTime = &expires
+	m.Resources = []string{"https://example.com/terms"}
+	if message == "" {
+		message = m.String()
+	}
+
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return message, hexutil.Encode(sig)
+}
+
+func TestParseSIWEMessage(t *testing.T) {
+	message, _ := signSIWE(t, "")
+
+	m, err := ParseSIWEMessage(message)
+	if err != nil {
+		t.Fatalf("ParseSIWEMessage error: %v", err)
+	}
+	if m.Domain != "example.com" || m.Statement != "Sign in to Example." || m.ChainID != 1 ||
+		m.Nonce != "32891756abcdef12" || m.ExpirationTime == nil || len(m.Resources) != 1 {
+		t.Errorf("unexpected message: %+v", m)
+	}
+	if m.String() != message {
+		t.Errorf("String() = %q, want %q", m.String(), message)
+	}
+
+	// 没有 statement 的消息
+	m.Statement = ""
+	if parsed, err := ParseSIWEMessage(m.String()); err != nil || parsed.Statement != "" {
+		t.Errorf("parse without statement = %+v, %v", parsed, err)
+	}
+
+	invalid := []string{
+		"",
+		"example.com wants you to sign in with your Ethereum account:\n0x0000000000000000000000000000000000000000",
+		// 地址不是 EIP-55 格式
+		"example.com wants you to sign in with your Ethereum account:\n0xabcdefabcdefabcdefabcdefabcdefabcdefabcd\n\n\nURI: https://example.com\nVersion: 1\nChain ID: 1\nNonce: 12345678\nIssued At: 2024-01-01T00:00:00Z",
+	}
+	for _, raw := range invalid {
+		if _, err := ParseSIWEMessage(raw); !errors.Is(err, ErrSIWEInvalidMessage) {
+			t.Errorf("ParseSIWEMessage(%q) error = %v, want ErrSIWEInvalidMessage", raw, err)
+		}
+	}
+}
+
+func TestSIWEMessage_Verify(t *testing.T) {
+	message, signature := signSIWE(t, "")
+	m, err := ParseSIWEMessage(message)
+	if err != nil {
+		t.Fatalf("ParseSIWEMessage error: %v", err)
+	}
+	valid := SIWEVerifyOptions{
+		Domain:   "example.com",
+		ChainIDs: []int64{1, 137},
+		Nonce:    "32891756abcdef12",
+		Now:      time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC),
+	}
+	if err := m.Verify(message, signature, valid); err != nil {
+		t.Fatalf("Verify error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*SIWEVerifyOptions)
+		want   error
+	}{
+		{"other domain", func(o *SIWEVerifyOptions) { o.Domain = "evil.com" }, ErrSIWEDomainMismatch},
+		{"chain not allowed", func(o *SIWEVerifyOptions) { o.ChainIDs = []int64{137} }, ErrSIWEChainNotAllowed},
+		{"nonce mismatch", func(o *SIWEVerifyOptions) { o.Nonce = "otherNonce1" }, ErrSIWENonceMismatch},
+		{"expired", func(o *SIWEVerifyOptions) { o.Now = o.Now.Add(time.Hour) }, ErrSIWEExpired},
+		{"issued in the future", func(o *SIWEVerifyOptions) { o.Now = o.Now.Add(-time.Hour) }, ErrSIWEExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.modify(&opts)
+			if err := m.Verify(message, signature, opts); !errors.Is(err, tt.want) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// 其他钱包对同一消息的签名
+	_, otherSignature := signSIWE(t, message)
+	if err := m.Verify(message, otherSignature, valid); !errors.Is(err, ErrSIWEInvalidSignature) {
+		t.Fatalf("Verify with other signer error = %v, want ErrSIWEInvalidSignature", err)
+	}
+	if err := m.Verify(message, "0x1234", valid); !errors.Is(err, ErrSIWEInvalidSignature) {
+		t.Fatalf("Verify with malformed signature error = %v, want ErrSIWEInvalidSignature", err)
+	}
+}
@@ -3,6 +3,11 @@ package web3
 import (
 	"context"
 	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // ContractABI 合约 ABI（简化版）
@@ -11,6 +16,34 @@ type ContractABI struct {
 	ABI     string
 }
 
+// erc20ABI ERC20 只读方法
+const erc20ABI = `[
+	{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"}
+]`
+
+// erc721ABI ERC721 只读方法
+const erc721ABI = `[
+	{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"constant":true,"inputs":[{"name":"tokenId","type":"uint256"}],"name":"ownerOf","outputs":[{"name":"","type":"address"}],"type":"function"},
+	{"constant":true,"inputs":[{"name":"tokenId","type":"uint256"}],"name":"tokenURI","outputs":[{"name":"","type":"string"}],"type":"function"}
+]`
+
+var (
+	erc20Contract  = mustParseABI(erc20ABI)
+	erc721Contract = mustParseABI(erc721ABI)
+)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
 // ERC20Token ERC20 代币接口
 type ERC20Token struct {
 	client   *EthereumClient
@@ -25,29 +58,47 @@ func NewERC20Token(client *EthereumClient, contractAddress string) *ERC20Token {
 	}
 }
 
-// GetBalance 获取代币余额
+// GetBalance 获取代币余额（最小单位）
 func (t *ERC20Token) GetBalance(ctx context.Context, address string) (string, error) {
-	// Note: This requires ABI encoding/decoding
-	// Placeholder implementation
-	return "", fmt.Errorf("ERC20 token balance query not fully implemented")
+	balance, err := t.BalanceOf(ctx, address)
+	if err != nil {
+		return "", err
+	}
+	return balance.String(), nil
+}
+
+// BalanceOf 获取代币余额（最小单位）
+func (t *ERC20Token) BalanceOf(ctx context.Context, address string) (*big.Int, error) {
+	if err := ValidateAddress(t.client.chain, address); err != nil {
+		return nil, err
+	}
+	var balance *big.Int
+	err := t.client.callContract(ctx, erc20Contract, t.contract, "balanceOf", &balance, common.HexToAddress(address))
+	return balance, err
 }
 
 // GetName 获取代币名称
 func (t *ERC20Token) GetName(ctx context.Context) (string, error) {
-	return "", fmt.Errorf("ERC20 token name query not fully implemented")
+	var name string
+	err := t.client.callContract(ctx, erc20Contract, t.contract, "name", &name)
+	return name, err
 }
 
 // GetSymbol 获取代币符号
 func (t *ERC20Token) GetSymbol(ctx context.Context) (string, error) {
-	return "", fmt.Errorf("ERC20 token symbol query not fully implemented")
+	var symbol string
+	err := t.client.callContract(ctx, erc20Contract, t.contract, "symbol", &symbol)
+	return symbol, err
 }
 
 // GetDecimals 获取代币精度
 func (t *ERC20Token) GetDecimals(ctx context.Context) (uint8, error) {
-	return 0, fmt.Errorf("ERC20 token decimals query not fully implemented")
+	var decimals uint8
+	err := t.client.callContract(ctx, erc20Contract, t.contract, "decimals", &decimals)
+	return decimals, err
 }
 
-// NFT NFT 相关功能
+// NFT NFT 相关功能（ERC721）
 type NFT struct {
 	client   *EthereumClient
 	contract string
@@ -61,14 +112,38 @@ func NewNFT(client *EthereumClient, contractAddress string) *NFT {
 	}
 }
 
+// BalanceOf 获取地址持有的 NFT 数量
+func (n *NFT) BalanceOf(ctx context.Context, address string) (*big.Int, error) {
+	if err := ValidateAddress(n.client.chain, address); err != nil {
+		return nil, err
+	}
+	var balance *big.Int
+	err := n.client.callContract(ctx, erc721Contract, n.contract, "balanceOf", &balance, common.HexToAddress(address))
+	return balance, err
+}
+
 // GetOwner 获取 NFT 所有者
 func (n *NFT) GetOwner(ctx context.Context, tokenID string) (string, error) {
-	return "", fmt.Errorf("NFT owner query not fully implemented")
+	id, ok := new(big.Int).SetString(tokenID, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid token id: %s", tokenID)
+	}
+	var owner common.Address
+	if err := n.client.callContract(ctx, erc721Contract, n.contract, "ownerOf", &owner, id); err != nil {
+		return "", err
+	}
+	return owner.Hex(), nil
 }
 
 // GetTokenURI 获取 NFT 元数据 URI
 func (n *NFT) GetTokenURI(ctx context.Context, tokenID string) (string, error) {
-	return "", fmt.Errorf("NFT tokenURI query not fully implemented")
+	id, ok := new(big.Int).SetString(tokenID, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid token id: %s", tokenID)
+	}
+	var uri string
+	err := n.client.callContract(ctx, erc721Contract, n.contract, "tokenURI", &uri, id)
+	return uri, err
 }
 
 // TokenInfo 代币信息
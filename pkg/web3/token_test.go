@@ -0,0 +1,120 @@
+package web3
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// newTestContractClient 模拟只处理 eth_call 的 JSON-RPC 节点，call 按方法选择器返回结果
+func newTestContractClient(t *testing.T, call func(selector string, input []byte) []byte) *EthereumClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "eth_call" {
+			http.Error(w, "unsupported", http.StatusBadRequest)
+			return
+		}
+		var msg struct {
+			Input hexutil.Bytes `json:"input"`
+			Data  hexutil.Bytes `json:"data"`
+		}
+		json.Unmarshal(req.Params[0], &msg)
+		input := msg.Input
+		if len(input) == 0 {
+			input = msg.Data
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  hexutil.Bytes(call(hexutil.Encode(input[:4]), input[4:])),
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewEthereumClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewEthereumClient error: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestERC20Token(t *testing.T) {
+	holder := "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+	client := newTestContractClient(t, func(selector string, input []byte) []byte {
+		switch selector {
+		case "0x70a08231": // balanceOf(address)
+			if common.BytesToAddress(input) != common.HexToAddress(holder) {
+				return common.LeftPadBytes(nil, 32)
+			}
+			return common.LeftPadBytes(big.NewInt(1500).Bytes(), 32)
+		case "0x313ce567": // decimals()
+			return common.LeftPadBytes([]byte{18}, 32)
+		case "0x95d89b41": // symbol()
+			out, _ := erc20Contract.Methods["symbol"].Outputs.Pack("AIDE")
+			return out
+		}
+		return nil
+	})
+	token := NewERC20Token(client, "0x0000000000000000000000000000000000000abc")
+	ctx := context.Background()
+
+	balance, err := token.GetBalance(ctx, holder)
+	if err != nil || balance != "1500" {
+		t.Fatalf("GetBalance = %q, %v, want 1500", balance, err)
+	}
+	if balance, err := token.BalanceOf(ctx, "0x0000000000000000000000000000000000000001"); err != nil || balance.Sign() != 0 {
+		t.Fatalf("BalanceOf other = %v, %v, want 0", balance, err)
+	}
+	if decimals, err := token.GetDecimals(ctx); err != nil || decimals != 18 {
+		t.Fatalf("GetDecimals = %d, %v", decimals, err)
+	}
+	if symbol, err := token.GetSymbol(ctx); err != nil || symbol != "AIDE" {
+		t.Fatalf("GetSymbol = %q, %v", symbol, err)
+	}
+	// 没有实现该方法的地址返回空结果
+	if _, err := token.GetName(ctx); err == nil {
+		t.Fatal("expected error for empty result")
+	}
+}
+
+func TestNFT(t *testing.T) {
+	owner := common.HexToAddress("0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0")
+	client := newTestContractClient(t, func(selector string, input []byte) []byte {
+		switch selector {
+		case "0x6352211e": // ownerOf(uint256)
+			if new(big.Int).SetBytes(input).Int64() == 7 {
+				return common.LeftPadBytes(owner.Bytes(), 32)
+			}
+			return common.LeftPadBytes(nil, 32)
+		case "0x70a08231": // balanceOf(address)
+			return common.LeftPadBytes([]byte{2}, 32)
+		}
+		return nil
+	})
+	nft := NewNFT(client, "0x0000000000000000000000000000000000000def")
+	ctx := context.Background()
+
+	if got, err := nft.GetOwner(ctx, "7"); err != nil || got != owner.Hex() {
+		t.Fatalf("GetOwner = %q, %v, want %s", got, err, owner.Hex())
+	}
+	if balance, err := nft.BalanceOf(ctx, owner.Hex()); err != nil || balance.Int64() != 2 {
+		t.Fatalf("BalanceOf = %v, %v, want 2", balance, err)
+	}
+	if _, err := nft.GetOwner(ctx, "not-a-number"); err == nil {
+		t.Fatal("expected error for invalid token id")
+	}
+}
@@ -1,50 +1,113 @@
 package routes
 
 import (
-	"fmt"
-
 	controllers "github.com/clarkzhu2020/aidecms/app/Http/Controllers"
 	middleware "github.com/clarkzhu2020/aidecms/app/Http/Middleware"
 	"github.com/clarkzhu2020/aidecms/config"
 	"github.com/clarkzhu2020/aidecms/internal/app/adapters"
+	"github.com/clarkzhu2020/aidecms/internal/app/services"
+	"github.com/clarkzhu2020/aidecms/pkg/ai"
 	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 )
 
 func APIRoutes(app *framework.Application) {
+	// 令牌签名密钥配置错误（如生产环境使用默认密钥）时拒绝启动
+	if _, err := config.LoadTokenManager(); err != nil {
+		hlog.Fatalf("Failed to load token manager: %v", err)
+	}
+
+	// 登录成功、登录失败和账户锁定写入日志
+	services.LogAuthEvents()
+
 	userController := controllers.NewUserController(app)
 
 	// 创建AI控制器
 	manager, err := config.LoadAIManager()
 	if err != nil {
 		// 如果AI配置加载失败，记录错误但不影响其他路由
-		fmt.Printf("Warning: Failed to load AI manager: %v\n", err)
-		fmt.Println("AI routes will not be available.")
+		hlog.Warnf("Failed to load AI manager, AI routes will not be available: %v", err)
 	} else {
-		fmt.Printf("AI manager loaded successfully with %d clients\n", len(manager.ListClients()))
+		hlog.Infof("AI manager loaded with %d clients", len(manager.ListClients()))
 	}
 
 	var aiController *controllers.AIController
+	var usageLedger *ai.UsageLedger
 	if manager != nil {
 		aiController = controllers.NewAIController(manager)
+
+		// 对话存储（memory、database、redis）
+		if store, ttl, err := config.LoadConversationStore(); err != nil {
+			hlog.Warnf("Failed to load AI conversation store, using memory: %v", err)
+		} else {
+			aiController.SetConversationStore(store, ttl)
+		}
+		aiController.SetConversationSummarization(config.IsAIFeatureEnabled("conversation_summarize"))
+
+		// 用量统计与额度
+		if ledger, quotas, err := config.LoadUsageLedger(); err != nil {
+			hlog.Warnf("Failed to load AI usage ledger, usage will not be metered: %v", err)
+		} else if ledger != nil {
+			aiController.SetUsageLedger(ledger, quotas)
+			usageLedger = ledger
+		}
 	}
 
 	// 创建邮件控制器
 	mailController, err := controllers.NewMailController()
 	if err != nil {
-		fmt.Printf("Warning: Failed to create mail controller: %v\n", err)
-		fmt.Println("Mail routes will not be available.")
+		hlog.Warnf("Failed to create mail controller, mail routes will not be available: %v", err)
 	}
 
 	// 创建CMS控制器
 	mediaController := controllers.NewMediaController()
 	postController := controllers.NewPostController()
+
+	// 文章检索（语义检索需要AI管理器和向量存储，否则只提供关键词检索）
+	if manager != nil {
+		store, searchConfig, err := config.LoadSemanticSearch()
+		if err != nil {
+			hlog.Warnf("Failed to load vector store, post search will be keyword only: %v", err)
+		}
+		postSearch := services.NewPostSearchService(manager, store, searchConfig)
+		postController.SetSearchService(postSearch)
+		aiController.SetPostSearch(postSearch)
+	}
+
+	// 文章AI编辑辅助（摘要、SEO、标签、翻译），批量任务通过队列执行
+	var postAIController *controllers.PostAIController
+	if manager != nil {
+		editorial := services.NewPostEditorialService(manager, "")
+		if usageLedger != nil {
+			editorial.SetUsageLedger(usageLedger)
+		}
+
+		q, err := config.LoadQueue()
+		if err != nil {
+			hlog.Warnf("Failed to load queue, AI suggestions will run synchronously only: %v", err)
+		} else {
+			editorial.RegisterJobs(q)
+			// memory 驱动的任务只能由当前进程处理
+			if config.QueueDriver() == "memory" {
+				go q.Work()
+			}
+		}
+		postAIController = controllers.NewPostAIController(editorial, q)
+	}
 	categoryController := controllers.NewCategoryController()
 	tagController := controllers.NewTagController()
 	menuController := controllers.NewMenuController()
 	commentController := controllers.NewCommentController()
+	roleController := controllers.NewRoleController()
+	accessTokenController := controllers.NewAccessTokenController()
+	twoFactorController := controllers.NewTwoFactorController()
+	// 第三方登录与密码登录共用登录限制和令牌签发
+	oidcController := controllers.NewOIDCController(userController.UserService)
+	walletController := controllers.NewWalletController(userController.UserService)
 
 	// 创建SEO控制器
-	seoController := controllers.NewSEOController("http://localhost:8888")
+	seoController := controllers.NewSEOController(app.Router.BaseURL())
+	seoController.SetURLGenerator(app.Router)
 
 	// 创建Web3控制器
 	web3Controller := &controllers.Web3Controller{}
@@ -54,124 +117,204 @@ func APIRoutes(app *framework.Application) {
 
 	app.RegisterRoutes(func(r *framework.Router) {
 		// SEO 路由（公开）
-		r.GET("/sitemap.xml", adapters.HertzToFramework(seoController.Sitemap))
-		r.GET("/sitemap-posts.xml", adapters.HertzToFramework(seoController.PostsSitemap))
-		r.GET("/robots.txt", adapters.HertzToFramework(seoController.Robots))
+		r.GET("/sitemap.xml", adapters.HertzToFramework(seoController.Sitemap)).Name("sitemap")
+		r.GET("/sitemap-posts.xml", adapters.HertzToFramework(seoController.PostsSitemap)).Name("sitemap.posts")
+		r.GET("/robots.txt", adapters.HertzToFramework(seoController.Robots)).Name("robots")
 
 		// 公开路由
-		r.POST("/register", userController.Register)
-		r.POST("/login", userController.Login)
+		r.POST("/register", userController.Register).Name("register")
+		r.POST("/login", userController.Login).Name("login")
+		r.POST("/login/2fa", userController.LoginMFA).Name("login.2fa")
+		r.POST("/login/2fa/enroll", userController.LoginMFAEnroll).Name("login.2fa.enroll")
+		r.GET("/auth/oidc/providers", oidcController.Providers).Name("oidc.providers")
+		r.GET("/auth/oidc/:provider/login", oidcController.Login).Name("oidc.login")
+		r.GET("/auth/oidc/:provider/callback", oidcController.Callback).Name("oidc.callback")
+		r.GET("/auth/siwe/nonce", walletController.Nonce).Name("siwe.nonce")
+		r.POST("/auth/siwe/verify", walletController.Verify).Name("siwe.verify")
+		r.POST("/password/forgot", userController.ForgotPassword).Name("password.forgot")
+		r.POST("/password/reset", userController.ResetPassword).Name("password.reset")
+		r.POST("/email/verify", userController.VerifyEmail).Name("email.verify")
+		r.POST("/email/resend", userController.ResendVerification).Name("email.resend")
+		r.POST("/refresh", userController.Refresh).Name("token.refresh")
+		r.POST("/logout", middleware.JWTMiddleware(), userController.Logout).Name("logout")
+		r.GET("/.well-known/jwks.json", userController.JWKS).Name("jwks")
 
 		// AI 路由
 		if aiController != nil {
-			fmt.Println("Registering AI routes...")
-			r.POST("/api/ai/chat", adapters.HertzToFramework(aiController.Chat))
-			r.POST("/api/ai/completion", adapters.HertzToFramework(aiController.Completion))
-			r.POST("/api/ai/embedding", adapters.HertzToFramework(aiController.Embedding))
+			hlog.Debug("Registering AI routes")
+			// 可以匿名调用；带令牌时用量、额度和问答对话归属当前用户
+			r.POST("/api/ai/chat", middleware.OptionalAuthMiddleware(), adapters.HertzToFramework(aiController.Chat)).Name("ai.chat")
+			r.POST("/api/ai/completion", middleware.OptionalAuthMiddleware(), adapters.HertzToFramework(aiController.Completion)).Name("ai.completion")
+			r.POST("/api/ai/embedding", middleware.OptionalAuthMiddleware(), adapters.HertzToFramework(aiController.Embedding)).Name("ai.embedding")
+			r.POST("/api/ai/ask", middleware.OptionalAuthMiddleware(), adapters.HertzToFramework(aiController.Ask)).Name("ai.ask")
+
+			// 对话路由（需要认证，对话归属当前用户）
+			aiConversationGroup := r.Group("/api/ai/conversation", middleware.JWTMiddleware())
+			{
+				aiConversationGroup.POST("", adapters.HertzToFramework(aiController.Conversation)).Name("ai.conversation.store")
+				aiConversationGroup.GET("/:session_id", adapters.HertzToFramework(aiController.GetConversationHistory)).Name("ai.conversation.show")
+				aiConversationGroup.DELETE("/:session_id", adapters.HertzToFramework(aiController.ClearConversationHistory)).Name("ai.conversation.destroy")
+			}
 		}
 
 		// 邮件 API 路由
 		if mailController != nil {
-			fmt.Println("Registering mail routes...")
-			r.POST("/api/mail/send", adapters.HertzToFramework(mailController.SendMail))
-			r.POST("/api/mail/send-template", adapters.HertzToFramework(mailController.SendTemplate))
-			r.POST("/api/mail/send-bulk", adapters.HertzToFramework(mailController.SendBulkMail))
-			r.GET("/api/mail/test", adapters.HertzToFramework(mailController.TestConnection))
-			r.GET("/api/mail/config", adapters.HertzToFramework(mailController.GetMailConfig))
-			r.GET("/api/mail/validate", adapters.HertzToFramework(mailController.ValidateEmail))
+			hlog.Debug("Registering mail routes")
+			r.POST("/api/mail/send", adapters.HertzToFramework(mailController.SendMail)).Name("mail.send")
+			r.POST("/api/mail/send-template", adapters.HertzToFramework(mailController.SendTemplate)).Name("mail.send_template")
+			r.POST("/api/mail/send-bulk", adapters.HertzToFramework(mailController.SendBulkMail)).Name("mail.send_bulk")
+			r.GET("/api/mail/test", adapters.HertzToFramework(mailController.TestConnection)).Name("mail.test")
+			r.GET("/api/mail/config", adapters.HertzToFramework(mailController.GetMailConfig)).Name("mail.config")
+			r.GET("/api/mail/validate", adapters.HertzToFramework(mailController.ValidateEmail)).Name("mail.validate")
 		}
 
-		// CMS 公开路由（只读）
-		fmt.Println("Registering CMS routes...")
-		r.GET("/api/posts", adapters.HertzToFramework(postController.List))
-		r.GET("/api/posts/:id", adapters.HertzToFramework(postController.Get))
-		r.GET("/api/categories", adapters.HertzToFramework(categoryController.List))
-		r.GET("/api/categories/:id", adapters.HertzToFramework(categoryController.Get))
-		r.GET("/api/tags", adapters.HertzToFramework(tagController.List))
-		r.GET("/api/tags/:id", adapters.HertzToFramework(tagController.Get))
-		r.GET("/api/media", adapters.HertzToFramework(mediaController.List))
-		r.GET("/api/media/:id", adapters.HertzToFramework(mediaController.Get))
-		r.GET("/api/menus", adapters.HertzToFramework(menuController.List))
-		r.GET("/api/menus/:id", adapters.HertzToFramework(menuController.Get))
-		r.GET("/api/comments", adapters.HertzToFramework(commentController.List))
-		r.GET("/api/comments/:id", adapters.HertzToFramework(commentController.Get))
-		r.POST("/api/comments", adapters.HertzToFramework(commentController.Create))
+		// CMS 公开路由（只读），文章按读者身份判断访问规则
+		hlog.Debug("Registering CMS routes")
+		optionalAuth := middleware.OptionalAuthMiddleware()
+		r.GET("/api/posts", optionalAuth, adapters.HertzToFramework(postController.List)).Name("posts.index")
+		r.GET("/api/posts/search", optionalAuth, adapters.HertzToFramework(postController.Search)).Name("posts.search")
+		r.GET("/api/posts/:id", optionalAuth, adapters.HertzToFramework(postController.Get)).Name("posts.show")
+		r.GET("/api/categories", adapters.HertzToFramework(categoryController.List)).Name("categories.index")
+		r.GET("/api/categories/:id", adapters.HertzToFramework(categoryController.Get)).Name("categories.show")
+		r.GET("/api/tags", adapters.HertzToFramework(tagController.List)).Name("tags.index")
+		r.GET("/api/tags/:id", adapters.HertzToFramework(tagController.Get)).Name("tags.show")
+		r.GET("/api/media", adapters.HertzToFramework(mediaController.List)).Name("media.index")
+		r.GET("/api/media/:id", adapters.HertzToFramework(mediaController.Get)).Name("media.show")
+		r.GET("/api/menus", adapters.HertzToFramework(menuController.List)).Name("menus.index")
+		r.GET("/api/menus/:id", adapters.HertzToFramework(menuController.Get)).Name("menus.show")
+		r.GET("/api/comments", adapters.HertzToFramework(commentController.List)).Name("comments.index")
+		r.GET("/api/comments/:id", adapters.HertzToFramework(commentController.Get)).Name("comments.show")
+		r.POST("/api/comments", adapters.HertzToFramework(commentController.Create)).Name("comments.store")
 
 		// 需要认证的路由
 		authGroup := r.Group("/user", middleware.JWTMiddleware())
 		{
-			authGroup.GET("/profile", userController.Profile)
-			authGroup.PUT("/profile", userController.UpdateProfile)
+			authGroup.GET("/profile", userController.Profile).Name("profile.show")
+			authGroup.PUT("/profile", userController.UpdateProfile).Name("profile.update")
+			authGroup.PUT("/password", userController.ChangePassword).Name("password.update")
+
+			// 两步验证
+			authGroup.GET("/2fa", twoFactorController.Show).Name("2fa.show")
+			authGroup.POST("/2fa", twoFactorController.Enable).Name("2fa.enable")
+			authGroup.POST("/2fa/confirm", twoFactorController.Confirm).Name("2fa.confirm")
+			authGroup.DELETE("/2fa", twoFactorController.Disable).Name("2fa.disable")
+			authGroup.POST("/2fa/recovery-codes", twoFactorController.RecoveryCodes).Name("2fa.recovery_codes")
+
+			// 个人访问令牌（只能通过登录会话管理）
+			authGroup.GET("/tokens", accessTokenController.Index).Name("tokens.index")
+			authGroup.POST("/tokens", accessTokenController.Store).Name("tokens.store")
+			authGroup.PUT("/tokens/:id", accessTokenController.Update).Name("tokens.update")
+			authGroup.DELETE("/tokens/:id", accessTokenController.Destroy).Name("tokens.destroy")
+
+			// 第三方身份关联
+			authGroup.GET("/identities", oidcController.Identities).Name("identities.index")
+			authGroup.POST("/identities/:provider", oidcController.Link).Name("identities.link")
+			authGroup.DELETE("/identities/:provider", oidcController.Unlink).Name("identities.unlink")
+
+			// 钱包关联
+			authGroup.GET("/wallets", walletController.Wallets).Name("wallets.index")
+			authGroup.POST("/wallets/nonce", walletController.LinkNonce).Name("wallets.nonce")
+			authGroup.POST("/wallets", walletController.Link).Name("wallets.link")
+			authGroup.DELETE("/wallets/:address", walletController.Unlink).Name("wallets.unlink")
 		}
 
-		// CMS 管理路由（需要认证）
-		cmsGroup := r.Group("/api/cms", middleware.JWTMiddleware())
+		// CMS 管理路由（需要认证，按角色权限和资源所有者授权；个人访问令牌限于其权限范围）
+		cmsGroup := r.Group("/api/cms", middleware.AuthMiddleware())
 		{
 			// 文章管理
-			cmsGroup.POST("/posts", adapters.HertzToFramework(postController.Create))
-			cmsGroup.PUT("/posts/:id", adapters.HertzToFramework(postController.Update))
-			cmsGroup.DELETE("/posts/:id", adapters.HertzToFramework(postController.Delete))
-			cmsGroup.POST("/posts/:id/publish", adapters.HertzToFramework(postController.Publish))
+			cmsGroup.POST("/posts", middleware.ResourcePermissionMiddleware("post", "create"), adapters.HertzToFramework(postController.Create)).Name("cms.posts.store")
+			cmsGroup.PUT("/posts/:id", middleware.ResourcePermissionMiddleware("post", "update"), adapters.HertzToFramework(postController.Update)).Name("cms.posts.update")
+			cmsGroup.DELETE("/posts/:id", middleware.ResourcePermissionMiddleware("post", "delete"), adapters.HertzToFramework(postController.Delete)).Name("cms.posts.destroy")
+			cmsGroup.POST("/posts/:id/publish", middleware.ResourcePermissionMiddleware("post", "publish"), adapters.HertzToFramework(postController.Publish)).Name("cms.posts.publish")
+
+			// 文章AI编辑建议（只返回建议，不修改文章）
+			if postAIController != nil {
+				cmsGroup.POST("/posts/:id/ai/excerpt", middleware.ResourcePermissionMiddleware("post", "update"), adapters.HertzToFramework(postAIController.Excerpt)).Name("cms.posts.ai.excerpt")
+				cmsGroup.POST("/posts/:id/ai/seo", middleware.ResourcePermissionMiddleware("post", "update"), adapters.HertzToFramework(postAIController.SEO)).Name("cms.posts.ai.seo")
+				cmsGroup.POST("/posts/:id/ai/tags", middleware.ResourcePermissionMiddleware("post", "update"), adapters.HertzToFramework(postAIController.Tags)).Name("cms.posts.ai.tags")
+				cmsGroup.POST("/posts/:id/ai/translate", middleware.ResourcePermissionMiddleware("post", "update"), adapters.HertzToFramework(postAIController.Translate)).Name("cms.posts.ai.translate")
+				cmsGroup.GET("/posts/:id/ai/suggestions", middleware.ResourcePermissionMiddleware("post", "update"), adapters.HertzToFramework(postAIController.Suggestions)).Name("cms.posts.ai.suggestions")
+				cmsGroup.POST("/ai/posts/backfill", middleware.ResourcePermissionMiddleware("post", "update"), adapters.HertzToFramework(postAIController.Backfill)).Name("cms.posts.ai.backfill")
+				cmsGroup.PUT("/ai/suggestions/:id", middleware.PolicyMiddleware("post", "update", "post_suggestion"), adapters.HertzToFramework(postAIController.UpdateSuggestion)).Name("cms.posts.ai.suggestions.update")
+			}
 
 			// 分类管理
-			cmsGroup.POST("/categories", adapters.HertzToFramework(categoryController.Create))
-			cmsGroup.PUT("/categories/:id", adapters.HertzToFramework(categoryController.Update))
-			cmsGroup.DELETE("/categories/:id", adapters.HertzToFramework(categoryController.Delete))
+			cmsGroup.POST("/categories", middleware.ResourcePermissionMiddleware("category", "create"), adapters.HertzToFramework(categoryController.Create)).Name("cms.categories.store")
+			cmsGroup.PUT("/categories/:id", middleware.ResourcePermissionMiddleware("category", "update"), adapters.HertzToFramework(categoryController.Update)).Name("cms.categories.update")
+			cmsGroup.DELETE("/categories/:id", middleware.ResourcePermissionMiddleware("category", "delete"), adapters.HertzToFramework(categoryController.Delete)).Name("cms.categories.destroy")
 
 			// 标签管理
-			cmsGroup.POST("/tags", adapters.HertzToFramework(tagController.Create))
-			cmsGroup.PUT("/tags/:id", adapters.HertzToFramework(tagController.Update))
-			cmsGroup.DELETE("/tags/:id", adapters.HertzToFramework(tagController.Delete))
+			cmsGroup.POST("/tags", middleware.ResourcePermissionMiddleware("tag", "create"), adapters.HertzToFramework(tagController.Create)).Name("cms.tags.store")
+			cmsGroup.PUT("/tags/:id", middleware.ResourcePermissionMiddleware("tag", "update"), adapters.HertzToFramework(tagController.Update)).Name("cms.tags.update")
+			cmsGroup.DELETE("/tags/:id", middleware.ResourcePermissionMiddleware("tag", "delete"), adapters.HertzToFramework(tagController.Delete)).Name("cms.tags.destroy")
 
 			// 媒体管理
-			cmsGroup.POST("/media/upload", adapters.HertzToFramework(mediaController.Upload))
-			cmsGroup.PUT("/media/:id", adapters.HertzToFramework(mediaController.Update))
-			cmsGroup.DELETE("/media/:id", adapters.HertzToFramework(mediaController.Delete))
+			cmsGroup.POST("/media/upload", middleware.ResourcePermissionMiddleware("media", "upload"), adapters.HertzToFramework(mediaController.Upload)).Name("cms.media.store")
+			cmsGroup.PUT("/media/:id", middleware.ResourcePermissionMiddleware("media", "update"), adapters.HertzToFramework(mediaController.Update)).Name("cms.media.update")
+			cmsGroup.DELETE("/media/:id", middleware.ResourcePermissionMiddleware("media", "delete"), adapters.HertzToFramework(mediaController.Delete)).Name("cms.media.destroy")
 
 			// 菜单管理
-			cmsGroup.POST("/menus", adapters.HertzToFramework(menuController.Create))
-			cmsGroup.PUT("/menus/:id", adapters.HertzToFramework(menuController.Update))
-			cmsGroup.DELETE("/menus/:id", adapters.HertzToFramework(menuController.Delete))
-			cmsGroup.POST("/menus/reorder", adapters.HertzToFramework(menuController.Reorder))
+			cmsGroup.POST("/menus", middleware.ResourcePermissionMiddleware("menu", "create"), adapters.HertzToFramework(menuController.Create)).Name("cms.menus.store")
+			cmsGroup.PUT("/menus/:id", middleware.ResourcePermissionMiddleware("menu", "update"), adapters.HertzToFramework(menuController.Update)).Name("cms.menus.update")
+			cmsGroup.DELETE("/menus/:id", middleware.ResourcePermissionMiddleware("menu", "delete"), adapters.HertzToFramework(menuController.Delete)).Name("cms.menus.destroy")
+			cmsGroup.POST("/menus/reorder", middleware.ResourcePermissionMiddleware("menu", "update"), adapters.HertzToFramework(menuController.Reorder)).Name("cms.menus.reorder")
 
 			// 评论管理
-			cmsGroup.PUT("/comments/:id", adapters.HertzToFramework(commentController.Update))
-			cmsGroup.DELETE("/comments/:id", adapters.HertzToFramework(commentController.Delete))
-			cmsGroup.POST("/comments/:id/approve", adapters.HertzToFramework(commentController.Approve))
-			cmsGroup.POST("/comments/:id/spam", adapters.HertzToFramework(commentController.MarkAsSpam))
+			cmsGroup.PUT("/comments/:id", middleware.ResourcePermissionMiddleware("comment", "update"), adapters.HertzToFramework(commentController.Update)).Name("cms.comments.update")
+			cmsGroup.DELETE("/comments/:id", middleware.ResourcePermissionMiddleware("comment", "delete"), adapters.HertzToFramework(commentController.Delete)).Name("cms.comments.destroy")
+			cmsGroup.POST("/comments/:id/approve", middleware.ResourcePermissionMiddleware("comment", "moderate"), adapters.HertzToFramework(commentController.Approve)).Name("cms.comments.approve")
+			cmsGroup.POST("/comments/:id/spam", middleware.ResourcePermissionMiddleware("comment", "moderate"), adapters.HertzToFramework(commentController.MarkAsSpam)).Name("cms.comments.spam")
+		}
+
+		// 角色与权限管理
+		adminGroup := r.Group("/api/admin", middleware.AuthMiddleware())
+		{
+			roles := middleware.ResourcePermissionMiddleware("role", "manage")
+			adminGroup.GET("/roles", roles, adapters.HertzToFramework(roleController.ListRoles)).Name("admin.roles.index")
+			adminGroup.POST("/roles", roles, adapters.HertzToFramework(roleController.CreateRole)).Name("admin.roles.store")
+			adminGroup.PUT("/roles/:id", roles, adapters.HertzToFramework(roleController.UpdateRole)).Name("admin.roles.update")
+			adminGroup.DELETE("/roles/:id", roles, adapters.HertzToFramework(roleController.DeleteRole)).Name("admin.roles.destroy")
+			adminGroup.PUT("/roles/:id/permissions", roles, adapters.HertzToFramework(roleController.SyncPermissions)).Name("admin.roles.permissions")
+			adminGroup.GET("/users/:id/roles", roles, adapters.HertzToFramework(roleController.UserRoles)).Name("admin.users.roles")
+			adminGroup.PUT("/users/:id/roles", roles, adapters.HertzToFramework(roleController.SyncUserRoles)).Name("admin.users.roles.update")
+
+			permissions := middleware.ResourcePermissionMiddleware("permission", "manage")
+			adminGroup.GET("/permissions", permissions, adapters.HertzToFramework(roleController.ListPermissions)).Name("admin.permissions.index")
+			adminGroup.POST("/permissions", permissions, adapters.HertzToFramework(roleController.CreatePermission)).Name("admin.permissions.store")
+			adminGroup.DELETE("/permissions/:id", permissions, adapters.HertzToFramework(roleController.DeletePermission)).Name("admin.permissions.destroy")
 		}
 
 		// Web3 路由（公开）
 		web3Group := r.Group("/api/web3")
 		{
 			// 区块链基本操作
-			web3Group.GET("/:chain/balance/:address", adapters.HertzToFramework(web3Controller.GetBalance))
-			web3Group.GET("/:chain/transaction/:hash", adapters.HertzToFramework(web3Controller.GetTransaction))
-			web3Group.GET("/:chain/block-number", adapters.HertzToFramework(web3Controller.GetBlockNumber))
-			web3Group.GET("/:chain/wallet/:address", adapters.HertzToFramework(web3Controller.GetWalletInfo))
-			web3Group.GET("/:chain/validate/:address", adapters.HertzToFramework(web3Controller.ValidateAddress))
+			web3Group.GET("/:chain/balance/:address", adapters.HertzToFramework(web3Controller.GetBalance)).Name("web3.balance")
+			web3Group.GET("/:chain/transaction/:hash", adapters.HertzToFramework(web3Controller.GetTransaction)).Name("web3.transaction")
+			web3Group.GET("/:chain/block-number", adapters.HertzToFramework(web3Controller.GetBlockNumber)).Name("web3.block_number")
+			web3Group.GET("/:chain/wallet/:address", adapters.HertzToFramework(web3Controller.GetWalletInfo)).Name("web3.wallet")
+			web3Group.GET("/:chain/validate/:address", adapters.HertzToFramework(web3Controller.ValidateAddress)).Name("web3.validate")
 
 			// 支持的链列表
-			web3Group.GET("/chains", adapters.HertzToFramework(web3Controller.GetSupportedChains))
+			web3Group.GET("/chains", adapters.HertzToFramework(web3Controller.GetSupportedChains)).Name("web3.chains")
 
 			// 多链查询
-			web3Group.POST("/multi-balance", adapters.HertzToFramework(web3Controller.GetMultiChainBalances))
+			web3Group.POST("/multi-balance", adapters.HertzToFramework(web3Controller.GetMultiChainBalances)).Name("web3.multi_balance")
 		}
 
 		// Exchange 路由（公开）
 		exchangeGroup := r.Group("/api/exchange")
 		{
 			// 单交易所查询
-			exchangeGroup.GET("/:exchange/balance/:currency", adapters.HertzToFramework(exchangeController.GetBalance))
-			exchangeGroup.GET("/:exchange/balances", adapters.HertzToFramework(exchangeController.GetBalances))
-			exchangeGroup.GET("/:exchange/price/:pair", adapters.HertzToFramework(exchangeController.GetPrice))
+			exchangeGroup.GET("/:exchange/balance/:currency", adapters.HertzToFramework(exchangeController.GetBalance)).Name("exchange.balance")
+			exchangeGroup.GET("/:exchange/balances", adapters.HertzToFramework(exchangeController.GetBalances)).Name("exchange.balances")
+			exchangeGroup.GET("/:exchange/price/:pair", adapters.HertzToFramework(exchangeController.GetPrice)).Name("exchange.price")
 
 			// 支持的交易所列表
-			exchangeGroup.GET("/supported", adapters.HertzToFramework(exchangeController.GetSupportedExchanges))
+			exchangeGroup.GET("/supported", adapters.HertzToFramework(exchangeController.GetSupportedExchanges)).Name("exchange.supported")
 
 			// 多交易所查询
-			exchangeGroup.GET("/all/balance/:currency", adapters.HertzToFramework(exchangeController.GetAllBalances))
-			exchangeGroup.GET("/all/price/:pair", adapters.HertzToFramework(exchangeController.GetAllPrices))
+			exchangeGroup.GET("/all/balance/:currency", adapters.HertzToFramework(exchangeController.GetAllBalances)).Name("exchange.all_balances")
+			exchangeGroup.GET("/all/price/:pair", adapters.HertzToFramework(exchangeController.GetAllPrices)).Name("exchange.all_prices")
 		}
 	})
 }
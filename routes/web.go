@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"context"
+
+	"github.com/clarkzhu2020/aidecms/pkg/framework"
+	"github.com/clarkzhu2020/aidecms/pkg/swagger"
+)
+
+// Register 注册全局中间件和全部路由，Web服务和 route:list 命令共用
+func Register(app *framework.Application) {
+	// 注册全局中间件（需在注册路由之前，之后注册的路由才会经过这些中间件）
+	app.RegisterMiddleware(
+		framework.Cors(),
+		framework.Recovery(),
+		framework.Logger(),
+		framework.PrometheusMiddleware(),
+	)
+
+	// 注册API路由
+	APIRoutes(app)
+
+	// 注册其他路由
+	WebRoutes(app)
+
+	// 注册静态文件目录
+	app.Static("/public", app.GetPublicPath())
+}
+
+// WebRoutes 文档、监控和首页路由
+func WebRoutes(app *framework.Application) {
+	app.RegisterRoutes(func(router *framework.Router) {
+		// Swagger文档路由 - 访问 http://localhost:8888/swagger/index.html
+		router.GET("/swagger/*any", swagger.SwaggerHandler()).Name("swagger")
+
+		// 基本API路由
+		api := router.Group("/api")
+		{
+			api.GET("/ping", func(ctx context.Context, c *framework.RequestContext) {
+				c.JSON(200, map[string]interface{}{
+					"message": "pong",
+				})
+			}).Name("ping")
+		}
+
+		// 监控路由
+		router.GET("/metrics", framework.PrometheusHandler()).Name("metrics")
+
+		// Web路由
+		router.GET("/", func(ctx context.Context, c *framework.RequestContext) {
+			c.String(200, "Welcome to AideCMS with AI capabilities! See /doc/ai.md for AI integration guide.")
+		}).Name("home")
+	})
+}